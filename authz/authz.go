@@ -0,0 +1,60 @@
+// Package authz decides what an authenticated user may do. Handlers and
+// services describe the action as a Permission and ask the policy instead of
+// comparing user IDs by hand.
+package authz
+
+import (
+	"errors"
+	"native-free-pollings/helper"
+	"slices"
+)
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+var Roles = []string{RoleUser, RoleModerator, RoleAdmin}
+
+type Permission string
+
+const (
+	// PollManageAny allows updating and deleting polls owned by other users.
+	PollManageAny Permission = "poll:manage:any"
+	// UserManage allows listing users, changing roles and disabling accounts.
+	UserManage Permission = "user:manage"
+	// LoginUnlock allows clearing failed-login lockouts.
+	LoginUnlock Permission = "login:unlock"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleUser:      {},
+	RoleModerator: {PollManageAny},
+	RoleAdmin:     {PollManageAny, UserManage, LoginUnlock},
+}
+
+var ErrForbidden = errors.New("forbidden")
+
+type Subject struct {
+	UserID int64
+	Role   string
+}
+
+func SubjectFromAuth(auth *helper.AuthContext) Subject {
+	return Subject{UserID: auth.UserID, Role: auth.Role}
+}
+
+func ValidRole(role string) bool {
+	return slices.Contains(Roles, role)
+}
+
+func Allowed(sub Subject, perm Permission) bool {
+	return slices.Contains(rolePermissions[sub.Role], perm)
+}
+
+// CanManage reports whether sub may modify a resource owned by ownerID:
+// owners always can, everybody else needs the elevated permission.
+func CanManage(sub Subject, ownerID int64, anyPerm Permission) bool {
+	return sub.UserID == ownerID || Allowed(sub, anyPerm)
+}
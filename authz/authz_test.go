@@ -0,0 +1,34 @@
+package authz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanManage(t *testing.T) {
+	tests := []struct {
+		name    string
+		sub     Subject
+		ownerID int64
+		want    bool
+	}{
+		{"owner", Subject{UserID: 1, Role: RoleUser}, 1, true},
+		{"other user", Subject{UserID: 2, Role: RoleUser}, 1, false},
+		{"moderator", Subject{UserID: 2, Role: RoleModerator}, 1, true},
+		{"admin", Subject{UserID: 2, Role: RoleAdmin}, 1, true},
+		{"unknown role", Subject{UserID: 2, Role: "root"}, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CanManage(tt.sub, tt.ownerID, PollManageAny))
+		})
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	assert.True(t, Allowed(Subject{Role: RoleAdmin}, UserManage))
+	assert.False(t, Allowed(Subject{Role: RoleModerator}, UserManage))
+	assert.False(t, Allowed(Subject{Role: RoleUser}, LoginUnlock))
+}
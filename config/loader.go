@@ -1,33 +1,96 @@
 package config
 
 import (
-	"log"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
 
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
-func Get() *Config {
-	err := godotenv.Load()
+// Load builds the configuration from, in increasing priority: defaults, the
+// YAML file named by -config or CONFIG_FILE, environment variables (a .env
+// file is read first when present) and command-line flags. Flags must come
+// before the subcommand; the remaining arguments are returned. All problems
+// are reported together in one error.
+func Load(args []string) (*Config, []string, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("read .env: %w", err)
+	}
+
+	return load(args, os.LookupEnv, os.Stderr)
+}
+
+func load(args []string, lookupEnv func(string) (string, bool), output io.Writer) (*Config, []string, error) {
+	flags := flag.NewFlagSet("native-free-pollings", flag.ContinueOnError)
+	flags.SetOutput(output)
+
+	configFile, _ := lookupEnv("CONFIG_FILE")
+	flags.StringVar(&configFile, "config", configFile, "path to a YAML config file (env CONFIG_FILE)")
+
+	type flagValue struct {
+		setting setting
+		value   string
+	}
+	var flagValues []flagValue
+	for _, s := range settings {
+		flags.Func(s.flag, s.usage+" (env "+s.env+")", func(value string) error {
+			flagValues = append(flagValues, flagValue{s, value})
+			return nil
+		})
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	conf := Default()
+	if configFile != "" {
+		if err := loadFile(conf, configFile); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var errs []error
+	for _, s := range settings {
+		if value, ok := lookupEnv(s.env); ok {
+			if err := s.set(conf, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+			}
+		}
+	}
+	for _, fv := range flagValues {
+		if err := fv.setting.set(conf, fv.value); err != nil {
+			errs = append(errs, fmt.Errorf("-%s: %w", fv.setting.flag, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	return conf, flags.Args(), nil
+}
 
+func loadFile(conf *Config, path string) error {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		log.Fatal("Failed to load environment")
+		return fmt.Errorf("read config file: %w", err)
 	}
 
-	return &Config{
-		Server: Server{
-			Host: os.Getenv("APP_HOST"),
-			Port: os.Getenv("APP_PORT"),
-		},
-		Database: Database{
-			Host: os.Getenv("DB_HOST"),
-			Port: os.Getenv("DB_PORT"),
-			Name: os.Getenv("DB_NAME"),
-			User: os.Getenv("DB_USER"),
-			Pass: os.Getenv("DB_PASS"),
-			SSL:  os.Getenv("DB_SSLMODE"),
-		},
-		JwtKey: []byte(os.Getenv("JWT_KEY")),
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(conf); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
 	}
 
+	return nil
 }
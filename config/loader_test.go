@@ -0,0 +1,211 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testJwtKey = "0123456789abcdef0123456789abcdef"
+
+func envOf(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func requiredEnv() map[string]string {
+	return map[string]string{
+		"DB_NAME": "polling",
+		"DB_USER": "polling_user",
+		"JWT_KEY": testJwtKey,
+	}
+}
+
+func TestLoad_Layers(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(file, []byte(`
+server:
+  port: 8000
+  write_timeout: 45s
+database:
+  max_open_conns: 40
+cors:
+  allowed_origins: [https://app.example.com]
+`), 0o600)
+	assert.NoError(t, err)
+
+	env := requiredEnv()
+	env["CONFIG_FILE"] = file
+	env["APP_PORT"] = "8080"
+	env["TOKEN_TTL"] = "12h"
+
+	conf, rest, err := load([]string{"-port", "9090", "migrate", "up"}, envOf(env), io.Discard)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"migrate", "up"}, rest)
+	assert.Equal(t, "9090", conf.Server.Port, "flag beats env and file")
+	assert.Equal(t, 45*time.Second, conf.Server.WriteTimeout, "file beats default")
+	assert.Equal(t, 40, conf.Database.MaxOpenConns)
+	assert.Equal(t, 10, conf.Database.MaxIdleConns, "default kept")
+	assert.Equal(t, 12*time.Hour, conf.Auth.TokenTTL, "env beats default")
+	assert.Equal(t, []string{"https://app.example.com"}, conf.CORS.AllowedOrigins)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		wantErr []string
+	}{
+		{
+			name:    "missing jwt key and database name",
+			env:     map[string]string{"DB_USER": "u"},
+			wantErr: []string{"auth.jwt_key is required", "database.name is required"},
+		},
+		{
+			name:    "short jwt key",
+			env:     map[string]string{"DB_NAME": "n", "DB_USER": "u", "JWT_KEY": "secret"},
+			wantErr: []string{"auth.jwt_key must be at least 32 bytes"},
+		},
+		{
+			name:    "unparsable values are all reported",
+			env:     map[string]string{"DB_MAX_OPEN_CONNS": "many", "TOKEN_TTL": "forever"},
+			wantErr: []string{"DB_MAX_OPEN_CONNS", "TOKEN_TTL"},
+		},
+		{
+			name:    "bad flag value",
+			env:     requiredEnv(),
+			args:    []string{"-auto-migrate", "maybe"},
+			wantErr: []string{"-auto-migrate"},
+		},
+		{
+			name: "wildcard origin with credentials",
+			env: func() map[string]string {
+				env := requiredEnv()
+				env["CORS_ALLOWED_ORIGINS"] = "*"
+				env["CORS_ALLOW_CREDENTIALS"] = "true"
+				return env
+			}(),
+			wantErr: []string{"cannot be combined with allow_credentials"},
+		},
+		{
+			name: "oidc without client id",
+			env: func() map[string]string {
+				env := requiredEnv()
+				env["OIDC_ISSUER"] = "https://sso.example.com"
+				return env
+			}(),
+			wantErr: []string{"oidc.client_id is required", "oidc.redirect_url"},
+		},
+		{
+			name: "tls cert without key",
+			env: func() map[string]string {
+				env := requiredEnv()
+				env["TLS_CERT_FILE"] = "cert.pem"
+				return env
+			}(),
+			wantErr: []string{"must be set together"},
+		},
+		{
+			name: "poll option bounds",
+			env: func() map[string]string {
+				env := requiredEnv()
+				env["POLL_MIN_OPTIONS"] = "1"
+				env["POLL_MAX_OPTIONS"] = "0"
+				return env
+			}(),
+			wantErr: []string{"polls.min_options must be at least 2", "polls.max_options must not be less than min_options"},
+		},
+		{
+			name: "stream limits",
+			env: func() map[string]string {
+				env := requiredEnv()
+				env["STREAM_HEARTBEAT_INTERVAL"] = "0s"
+				env["STREAM_MAX_PER_CLIENT"] = "2000"
+				return env
+			}(),
+			wantErr: []string{"stream.heartbeat_interval must be positive", "stream.max_per_client must be between 1 and max_connections"},
+		},
+		{
+			name: "live session limits",
+			env: func() map[string]string {
+				env := requiredEnv()
+				env["LIVE_PING_INTERVAL"] = "0s"
+				env["LIVE_MAX_PARTICIPANTS"] = "0"
+				return env
+			}(),
+			wantErr: []string{"live.ping_interval must be positive", "live.max_participants must be positive"},
+		},
+		{
+			name: "webhook retries",
+			env: func() map[string]string {
+				env := requiredEnv()
+				env["WEBHOOK_MAX_ATTEMPTS"] = "0"
+				env["WEBHOOK_BACKOFF_BASE"] = "2h"
+				env["WEBHOOK_BACKOFF_MAX"] = "1h"
+				return env
+			}(),
+			wantErr: []string{"webhooks.max_attempts must be positive", "webhooks.backoff_base must be positive and at most backoff_max"},
+		},
+		{
+			name: "outbox dispatch",
+			env: func() map[string]string {
+				env := requiredEnv()
+				env["OUTBOX_HANDLER_TIMEOUT"] = "0s"
+				env["OUTBOX_BACKOFF_BASE"] = "0s"
+				env["OUTBOX_MAX_ATTEMPTS"] = "0"
+				return env
+			}(),
+			wantErr: []string{"outbox.handler_timeout must be positive", "outbox.backoff_base must be positive and at most backoff_max", "outbox.max_attempts must be positive"},
+		},
+		{
+			name: "login lockout",
+			env: func() map[string]string {
+				env := requiredEnv()
+				env["LOCKOUT_MAX_ACCOUNT_FAILURES"] = "0"
+				env["LOCKOUT_BASE_DELAY"] = "2h"
+				env["LOCKOUT_WINDOW"] = "0s"
+				return env
+			}(),
+			wantErr: []string{"lockout.max_account_failures must be positive", "lockout.base_delay must be positive and at most max_delay", "lockout.window must be positive"},
+		},
+		{
+			name: "missing config file",
+			env: func() map[string]string {
+				env := requiredEnv()
+				env["CONFIG_FILE"] = "does-not-exist.yaml"
+				return env
+			}(),
+			wantErr: []string{"read config file"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := load(tt.args, envOf(tt.env), io.Discard)
+
+			assert.Error(t, err)
+			for _, want := range tt.wantErr {
+				assert.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
+
+func TestLoad_UnknownFileField(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("server:\n  prot: 8000\n"), 0o600))
+
+	env := requiredEnv()
+	env["CONFIG_FILE"] = file
+	_, _, err := load(nil, envOf(env), io.Discard)
+
+	assert.ErrorContains(t, err, "prot")
+}
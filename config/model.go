@@ -1,21 +1,274 @@
 package config
 
+import "time"
+
 type Config struct {
-	Server   Server
-	Database Database
-	JwtKey   []byte
+	Server   Server   `yaml:"server"`
+	Database Database `yaml:"database"`
+	Auth     Auth     `yaml:"auth"`
+	CORS     CORS     `yaml:"cors"`
+	OIDC     OIDC     `yaml:"oidc"`
+	Tracing  Tracing  `yaml:"tracing"`
+	Log      Log      `yaml:"log"`
+	Polls    Polls    `yaml:"polls"`
+	Stream   Stream   `yaml:"stream"`
+	Live     Live     `yaml:"live"`
+	Webhooks Webhooks `yaml:"webhooks"`
+	Outbox   Outbox   `yaml:"outbox"`
+	Lockout  Lockout  `yaml:"lockout"`
 }
 
 type Server struct {
-	Host string
-	Port string
+	Host string `yaml:"host"`
+	Port string `yaml:"port"`
+
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes"`
+	// MaxBodyBytes caps request bodies; larger ones are answered with 413.
+	MaxBodyBytes int `yaml:"max_body_bytes"`
+	// ShutdownTimeout bounds the whole shutdown: draining requests,
+	// stopping background workers and closing the database pool.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// HealthCheckTimeout bounds each readiness check.
+	HealthCheckTimeout time.Duration `yaml:"health_check_timeout"`
+
+	TLS TLS `yaml:"tls"`
+}
+
+// TLS is enabled when CertFile and KeyFile are set. The files are checked
+// every ReloadInterval and a renewed certificate is picked up without a
+// restart.
+type TLS struct {
+	CertFile       string        `yaml:"cert_file"`
+	KeyFile        string        `yaml:"key_file"`
+	ReloadInterval time.Duration `yaml:"reload_interval"`
 }
 
 type Database struct {
-	Host string
-	Port string
-	Name string
-	User string
-	Pass string
-	SSL  string
+	Host string `yaml:"host"`
+	Port string `yaml:"port"`
+	Name string `yaml:"name"`
+	User string `yaml:"user"`
+	Pass string `yaml:"pass"`
+	SSL  string `yaml:"sslmode"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	// ConnectTimeout is how long startup keeps retrying an unreachable
+	// database before giving up.
+	ConnectTimeout time.Duration `yaml:"connect_timeout"`
+
+	// AutoMigrate applies pending migrations when the server starts.
+	AutoMigrate bool `yaml:"auto_migrate"`
+}
+
+type Auth struct {
+	JwtKey   string        `yaml:"jwt_key"`
+	TokenTTL time.Duration `yaml:"token_ttl"`
+}
+
+// CORS headers are only sent when AllowedOrigins is not empty.
+type CORS struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins"`
+	AllowedMethods   []string      `yaml:"allowed_methods"`
+	AllowedHeaders   []string      `yaml:"allowed_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"`
+}
+
+// OIDC login is enabled when Issuer is set.
+type OIDC struct {
+	Issuer       string `yaml:"issuer"`
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	RedirectURL  string `yaml:"redirect_url"`
+}
+
+// Tracing selects where spans go: "none" keeps trace context propagation
+// but records nothing, "stdout" prints spans for local debugging and "otlp"
+// sends them over OTLP/HTTP to Endpoint (or OTEL_EXPORTER_OTLP_ENDPOINT).
+type Tracing struct {
+	Exporter    string  `yaml:"exporter"`
+	Endpoint    string  `yaml:"endpoint"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// Log configures the process-wide slog logger. Format is "json" or "text";
+// Level is one of debug, info, warn or error.
+type Log struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// Polls bounds the size of a poll. Lengths count characters, not bytes.
+type Polls struct {
+	MaxTitleLength       int `yaml:"max_title_length"`
+	MaxDescriptionLength int `yaml:"max_description_length"`
+	MaxOptionLength      int `yaml:"max_option_length"`
+	MinOptions           int `yaml:"min_options"`
+	MaxOptions           int `yaml:"max_options"`
+	// RequireIfMatch makes updates and deletes without If-Match fail with
+	// 428 instead of overwriting blindly.
+	RequireIfMatch bool `yaml:"require_if_match"`
+}
+
+// Stream limits the live results streams. A heartbeat comment is sent every
+// HeartbeatInterval so proxies keep idle streams open, and each stream is
+// closed after MaxDuration (zero for never); clients reconnect and resume
+// with Last-Event-ID.
+type Stream struct {
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval"`
+	MaxDuration       time.Duration `yaml:"max_duration"`
+	MaxConnections    int           `yaml:"max_connections"`
+	MaxPerClient      int           `yaml:"max_per_client"`
+}
+
+// Live configures the WebSocket live sessions. Clients are pinged every
+// PingInterval and dropped when nothing arrives for twice as long. A session
+// admits MaxParticipants besides its presenter, and ends PresenterGrace after
+// the presenter's last connection dropped.
+type Live struct {
+	PingInterval    time.Duration `yaml:"ping_interval"`
+	MaxParticipants int           `yaml:"max_participants"`
+	PresenterGrace  time.Duration `yaml:"presenter_grace"`
+}
+
+// Webhooks configures outgoing webhook deliveries. Due deliveries are
+// picked up every PollInterval, BatchSize at a time, and each request may
+// take up to Timeout. A failed delivery is retried after BackoffBase,
+// doubling up to BackoffMax, until MaxAttempts attempts were made. A webhook
+// is disabled after DisableAfter failed attempts in a row. AllowPrivate
+// lets webhooks reach loopback and private addresses, which is only meant
+// for development.
+type Webhooks struct {
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size"`
+	Timeout      time.Duration `yaml:"timeout"`
+	MaxAttempts  int           `yaml:"max_attempts"`
+	BackoffBase  time.Duration `yaml:"backoff_base"`
+	BackoffMax   time.Duration `yaml:"backoff_max"`
+	DisableAfter int           `yaml:"disable_after"`
+	AllowPrivate bool          `yaml:"allow_private"`
+}
+
+// Outbox configures the dispatcher of the transactional outbox. Recorded
+// events are picked up every PollInterval, BatchSize at a time, and the
+// subscribers of one event get HandlerTimeout to handle it. An event a
+// subscriber failed on is retried after BackoffBase, doubling up to
+// BackoffMax, until MaxAttempts attempts were made.
+type Outbox struct {
+	PollInterval   time.Duration `yaml:"poll_interval"`
+	BatchSize      int           `yaml:"batch_size"`
+	HandlerTimeout time.Duration `yaml:"handler_timeout"`
+	BackoffBase    time.Duration `yaml:"backoff_base"`
+	BackoffMax     time.Duration `yaml:"backoff_max"`
+	MaxAttempts    int           `yaml:"max_attempts"`
+}
+
+// Lockout throttles failed logins. After MaxAccountFailures failures for an
+// account, or MaxIPFailures from one client IP, each within Window of the
+// previous one, logins are locked for BaseDelay, doubling with every further
+// failure up to MaxDelay.
+type Lockout struct {
+	MaxAccountFailures int           `yaml:"max_account_failures"`
+	MaxIPFailures      int           `yaml:"max_ip_failures"`
+	BaseDelay          time.Duration `yaml:"base_delay"`
+	MaxDelay           time.Duration `yaml:"max_delay"`
+	Window             time.Duration `yaml:"window"`
+}
+
+// Default returns the values used for every setting no source overrides.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Host:               "localhost",
+			Port:               "3000",
+			ReadTimeout:        15 * time.Second,
+			ReadHeaderTimeout:  5 * time.Second,
+			WriteTimeout:       30 * time.Second,
+			IdleTimeout:        2 * time.Minute,
+			MaxHeaderBytes:     1 << 20,
+			MaxBodyBytes:       1 << 20,
+			ShutdownTimeout:    20 * time.Second,
+			HealthCheckTimeout: 2 * time.Second,
+			TLS: TLS{
+				ReloadInterval: time.Minute,
+			},
+		},
+		Database: Database{
+			Host:            "localhost",
+			Port:            "5432",
+			SSL:             "disable",
+			MaxOpenConns:    100,
+			MaxIdleConns:    10,
+			ConnMaxIdleTime: 3 * time.Minute,
+			ConnMaxLifetime: 60 * time.Minute,
+			ConnectTimeout:  time.Minute,
+		},
+		Auth: Auth{
+			TokenTTL: 72 * time.Hour,
+		},
+		CORS: CORS{
+			AllowedMethods: []string{"GET", "POST", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Content-Type", "Authorization", "If-Match", "If-None-Match"},
+			MaxAge:         10 * time.Minute,
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			ServiceName: "native-free-pollings",
+			SampleRatio: 1,
+		},
+		Log: Log{
+			Level:  "info",
+			Format: "json",
+		},
+		Polls: Polls{
+			MaxTitleLength:       200,
+			MaxDescriptionLength: 2000,
+			MaxOptionLength:      200,
+			MinOptions:           2,
+			MaxOptions:           20,
+		},
+		Stream: Stream{
+			HeartbeatInterval: 15 * time.Second,
+			MaxDuration:       30 * time.Minute,
+			MaxConnections:    1000,
+			MaxPerClient:      5,
+		},
+		Live: Live{
+			PingInterval:    30 * time.Second,
+			MaxParticipants: 500,
+			PresenterGrace:  2 * time.Minute,
+		},
+		Webhooks: Webhooks{
+			PollInterval: 5 * time.Second,
+			BatchSize:    20,
+			Timeout:      10 * time.Second,
+			MaxAttempts:  8,
+			BackoffBase:  30 * time.Second,
+			BackoffMax:   6 * time.Hour,
+			DisableAfter: 20,
+		},
+		Outbox: Outbox{
+			PollInterval:   time.Second,
+			BatchSize:      20,
+			HandlerTimeout: 10 * time.Second,
+			BackoffBase:    5 * time.Second,
+			BackoffMax:     10 * time.Minute,
+			MaxAttempts:    25,
+		},
+		Lockout: Lockout{
+			MaxAccountFailures: 5,
+			MaxIPFailures:      20,
+			BaseDelay:          30 * time.Second,
+			MaxDelay:           time.Hour,
+			Window:             15 * time.Minute,
+		},
+	}
 }
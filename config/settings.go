@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting binds one field to its environment variable and command-line flag.
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{"APP_HOST", "host", "host name the server is reachable at", stringVar(func(c *Config) *string { return &c.Server.Host })},
+	{"APP_PORT", "port", "port to listen on", stringVar(func(c *Config) *string { return &c.Server.Port })},
+	{"APP_READ_TIMEOUT", "read-timeout", "maximum time to read a whole request", durationVar(func(c *Config) *time.Duration { return &c.Server.ReadTimeout })},
+	{"APP_READ_HEADER_TIMEOUT", "read-header-timeout", "maximum time to read request headers", durationVar(func(c *Config) *time.Duration { return &c.Server.ReadHeaderTimeout })},
+	{"APP_WRITE_TIMEOUT", "write-timeout", "maximum time to write a response", durationVar(func(c *Config) *time.Duration { return &c.Server.WriteTimeout })},
+	{"APP_IDLE_TIMEOUT", "idle-timeout", "how long idle keep-alive connections stay open", durationVar(func(c *Config) *time.Duration { return &c.Server.IdleTimeout })},
+	{"APP_MAX_HEADER_BYTES", "max-header-bytes", "maximum size of request headers", intVar(func(c *Config) *int { return &c.Server.MaxHeaderBytes })},
+	{"APP_MAX_BODY_BYTES", "max-body-bytes", "maximum size of request bodies", intVar(func(c *Config) *int { return &c.Server.MaxBodyBytes })},
+	{"APP_SHUTDOWN_TIMEOUT", "shutdown-timeout", "how long a graceful shutdown may take", durationVar(func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout })},
+	{"APP_HEALTH_CHECK_TIMEOUT", "health-check-timeout", "time limit for each readiness check", durationVar(func(c *Config) *time.Duration { return &c.Server.HealthCheckTimeout })},
+	{"TLS_CERT_FILE", "tls-cert-file", "PEM certificate chain; enables HTTPS with -tls-key-file", stringVar(func(c *Config) *string { return &c.Server.TLS.CertFile })},
+	{"TLS_KEY_FILE", "tls-key-file", "PEM private key for -tls-cert-file", stringVar(func(c *Config) *string { return &c.Server.TLS.KeyFile })},
+	{"TLS_RELOAD_INTERVAL", "tls-reload-interval", "how often certificate files are checked for changes", durationVar(func(c *Config) *time.Duration { return &c.Server.TLS.ReloadInterval })},
+
+	{"DB_HOST", "db-host", "database host", stringVar(func(c *Config) *string { return &c.Database.Host })},
+	{"DB_PORT", "db-port", "database port", stringVar(func(c *Config) *string { return &c.Database.Port })},
+	{"DB_NAME", "db-name", "database name", stringVar(func(c *Config) *string { return &c.Database.Name })},
+	{"DB_USER", "db-user", "database user", stringVar(func(c *Config) *string { return &c.Database.User })},
+	{"DB_PASS", "db-pass", "database password", stringVar(func(c *Config) *string { return &c.Database.Pass })},
+	{"DB_SSLMODE", "db-sslmode", "database sslmode", stringVar(func(c *Config) *string { return &c.Database.SSL })},
+	{"DB_MAX_OPEN_CONNS", "db-max-open-conns", "maximum open database connections", intVar(func(c *Config) *int { return &c.Database.MaxOpenConns })},
+	{"DB_MAX_IDLE_CONNS", "db-max-idle-conns", "maximum idle database connections", intVar(func(c *Config) *int { return &c.Database.MaxIdleConns })},
+	{"DB_CONN_MAX_IDLE_TIME", "db-conn-max-idle-time", "close database connections idle for longer than this", durationVar(func(c *Config) *time.Duration { return &c.Database.ConnMaxIdleTime })},
+	{"DB_CONN_MAX_LIFETIME", "db-conn-max-lifetime", "close database connections older than this", durationVar(func(c *Config) *time.Duration { return &c.Database.ConnMaxLifetime })},
+	{"DB_CONNECT_TIMEOUT", "db-connect-timeout", "how long startup waits for the database to become reachable", durationVar(func(c *Config) *time.Duration { return &c.Database.ConnectTimeout })},
+	{"DB_AUTO_MIGRATE", "auto-migrate", "apply pending migrations when the server starts", boolVar(func(c *Config) *bool { return &c.Database.AutoMigrate })},
+
+	{"JWT_KEY", "jwt-key", "key used to sign access tokens", stringVar(func(c *Config) *string { return &c.Auth.JwtKey })},
+	{"TOKEN_TTL", "token-ttl", "lifetime of login tokens and sessions", durationVar(func(c *Config) *time.Duration { return &c.Auth.TokenTTL })},
+
+	{"CORS_ALLOWED_ORIGINS", "cors-allowed-origins", "comma-separated origins allowed to call the API, or *", listVar(func(c *Config) *[]string { return &c.CORS.AllowedOrigins })},
+	{"CORS_ALLOWED_METHODS", "cors-allowed-methods", "comma-separated methods allowed in CORS requests", listVar(func(c *Config) *[]string { return &c.CORS.AllowedMethods })},
+	{"CORS_ALLOWED_HEADERS", "cors-allowed-headers", "comma-separated headers allowed in CORS requests", listVar(func(c *Config) *[]string { return &c.CORS.AllowedHeaders })},
+	{"CORS_ALLOW_CREDENTIALS", "cors-allow-credentials", "allow cookies and credentials in CORS requests", boolVar(func(c *Config) *bool { return &c.CORS.AllowCredentials })},
+	{"CORS_MAX_AGE", "cors-max-age", "how long browsers may cache preflight responses", durationVar(func(c *Config) *time.Duration { return &c.CORS.MaxAge })},
+
+	{"OIDC_ISSUER", "oidc-issuer", "OpenID Connect issuer URL; enables SSO login", stringVar(func(c *Config) *string { return &c.OIDC.Issuer })},
+	{"OIDC_CLIENT_ID", "oidc-client-id", "OpenID Connect client id", stringVar(func(c *Config) *string { return &c.OIDC.ClientID })},
+	{"OIDC_CLIENT_SECRET", "oidc-client-secret", "OpenID Connect client secret", stringVar(func(c *Config) *string { return &c.OIDC.ClientSecret })},
+	{"OIDC_REDIRECT_URL", "oidc-redirect-url", "OpenID Connect callback URL", stringVar(func(c *Config) *string { return &c.OIDC.RedirectURL })},
+
+	{"TRACING_EXPORTER", "tracing-exporter", "where to send traces: none, stdout or otlp", stringVar(func(c *Config) *string { return &c.Tracing.Exporter })},
+	{"TRACING_ENDPOINT", "tracing-endpoint", "OTLP/HTTP collector URL, e.g. http://localhost:4318", stringVar(func(c *Config) *string { return &c.Tracing.Endpoint })},
+	{"TRACING_SERVICE_NAME", "tracing-service-name", "service name reported with every span", stringVar(func(c *Config) *string { return &c.Tracing.ServiceName })},
+	{"TRACING_SAMPLE_RATIO", "tracing-sample-ratio", "share of new traces to record, from 0 to 1", floatVar(func(c *Config) *float64 { return &c.Tracing.SampleRatio })},
+
+	{"LOG_LEVEL", "log-level", "minimum log level: debug, info, warn or error", stringVar(func(c *Config) *string { return &c.Log.Level })},
+	{"LOG_FORMAT", "log-format", "log output format: json or text", stringVar(func(c *Config) *string { return &c.Log.Format })},
+
+	{"POLL_MAX_TITLE_LENGTH", "poll-max-title-length", "maximum characters in a poll title", intVar(func(c *Config) *int { return &c.Polls.MaxTitleLength })},
+	{"POLL_MAX_DESCRIPTION_LENGTH", "poll-max-description-length", "maximum characters in a poll description", intVar(func(c *Config) *int { return &c.Polls.MaxDescriptionLength })},
+	{"POLL_MAX_OPTION_LENGTH", "poll-max-option-length", "maximum characters in an option label", intVar(func(c *Config) *int { return &c.Polls.MaxOptionLength })},
+	{"POLL_MIN_OPTIONS", "poll-min-options", "fewest options a poll may have", intVar(func(c *Config) *int { return &c.Polls.MinOptions })},
+	{"POLL_MAX_OPTIONS", "poll-max-options", "most options a poll may have", intVar(func(c *Config) *int { return &c.Polls.MaxOptions })},
+	{"POLL_REQUIRE_IF_MATCH", "poll-require-if-match", "reject poll updates and deletes without an If-Match header", boolVar(func(c *Config) *bool { return &c.Polls.RequireIfMatch })},
+
+	{"STREAM_HEARTBEAT_INTERVAL", "stream-heartbeat-interval", "time between heartbeats on live results streams", durationVar(func(c *Config) *time.Duration { return &c.Stream.HeartbeatInterval })},
+	{"STREAM_MAX_DURATION", "stream-max-duration", "close live results streams after this long, 0 for never", durationVar(func(c *Config) *time.Duration { return &c.Stream.MaxDuration })},
+	{"STREAM_MAX_CONNECTIONS", "stream-max-connections", "live results streams open at once on this instance", intVar(func(c *Config) *int { return &c.Stream.MaxConnections })},
+	{"STREAM_MAX_PER_CLIENT", "stream-max-per-client", "live results streams one client IP may keep open", intVar(func(c *Config) *int { return &c.Stream.MaxPerClient })},
+	{"LIVE_PING_INTERVAL", "live-ping-interval", "time between pings on live session connections", durationVar(func(c *Config) *time.Duration { return &c.Live.PingInterval })},
+	{"LIVE_MAX_PARTICIPANTS", "live-max-participants", "participants one live session admits", intVar(func(c *Config) *int { return &c.Live.MaxParticipants })},
+	{"LIVE_PRESENTER_GRACE", "live-presenter-grace", "end a live session this long after its presenter disconnected", durationVar(func(c *Config) *time.Duration { return &c.Live.PresenterGrace })},
+	{"WEBHOOK_POLL_INTERVAL", "webhook-poll-interval", "time between checks for due webhook deliveries", durationVar(func(c *Config) *time.Duration { return &c.Webhooks.PollInterval })},
+	{"WEBHOOK_BATCH_SIZE", "webhook-batch-size", "webhook deliveries sent per check", intVar(func(c *Config) *int { return &c.Webhooks.BatchSize })},
+	{"WEBHOOK_TIMEOUT", "webhook-timeout", "time limit of one webhook request", durationVar(func(c *Config) *time.Duration { return &c.Webhooks.Timeout })},
+	{"WEBHOOK_MAX_ATTEMPTS", "webhook-max-attempts", "attempts before a webhook delivery fails for good", intVar(func(c *Config) *int { return &c.Webhooks.MaxAttempts })},
+	{"WEBHOOK_BACKOFF_BASE", "webhook-backoff-base", "delay before the first webhook retry, doubled on each further one", durationVar(func(c *Config) *time.Duration { return &c.Webhooks.BackoffBase })},
+	{"WEBHOOK_BACKOFF_MAX", "webhook-backoff-max", "longest delay between webhook retries", durationVar(func(c *Config) *time.Duration { return &c.Webhooks.BackoffMax })},
+	{"WEBHOOK_DISABLE_AFTER", "webhook-disable-after", "failed attempts in a row that disable a webhook", intVar(func(c *Config) *int { return &c.Webhooks.DisableAfter })},
+	{"WEBHOOK_ALLOW_PRIVATE", "webhook-allow-private", "let webhooks reach loopback and private addresses", boolVar(func(c *Config) *bool { return &c.Webhooks.AllowPrivate })},
+	{"OUTBOX_POLL_INTERVAL", "outbox-poll-interval", "time between checks for recorded events", durationVar(func(c *Config) *time.Duration { return &c.Outbox.PollInterval })},
+	{"OUTBOX_BATCH_SIZE", "outbox-batch-size", "recorded events handled per check", intVar(func(c *Config) *int { return &c.Outbox.BatchSize })},
+	{"OUTBOX_HANDLER_TIMEOUT", "outbox-handler-timeout", "time the subscribers of one event get to handle it", durationVar(func(c *Config) *time.Duration { return &c.Outbox.HandlerTimeout })},
+	{"OUTBOX_BACKOFF_BASE", "outbox-backoff-base", "delay before an event is handed out again, doubled on each further failure", durationVar(func(c *Config) *time.Duration { return &c.Outbox.BackoffBase })},
+	{"OUTBOX_BACKOFF_MAX", "outbox-backoff-max", "longest delay before an event is handed out again", durationVar(func(c *Config) *time.Duration { return &c.Outbox.BackoffMax })},
+	{"OUTBOX_MAX_ATTEMPTS", "outbox-max-attempts", "attempts before an event fails for good", intVar(func(c *Config) *int { return &c.Outbox.MaxAttempts })},
+	{"LOCKOUT_MAX_ACCOUNT_FAILURES", "lockout-max-account-failures", "failed logins for one account before it is locked", intVar(func(c *Config) *int { return &c.Lockout.MaxAccountFailures })},
+	{"LOCKOUT_MAX_IP_FAILURES", "lockout-max-ip-failures", "failed logins from one client IP before it is locked", intVar(func(c *Config) *int { return &c.Lockout.MaxIPFailures })},
+	{"LOCKOUT_BASE_DELAY", "lockout-base-delay", "first login lock, doubled on each further failure", durationVar(func(c *Config) *time.Duration { return &c.Lockout.BaseDelay })},
+	{"LOCKOUT_MAX_DELAY", "lockout-max-delay", "longest login lock", durationVar(func(c *Config) *time.Duration { return &c.Lockout.MaxDelay })},
+	{"LOCKOUT_WINDOW", "lockout-window", "time after which failed logins are forgotten", durationVar(func(c *Config) *time.Duration { return &c.Lockout.Window })},
+}
+
+func stringVar(field func(c *Config) *string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func intVar(field func(c *Config) *int) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func floatVar(field func(c *Config) *float64) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		*field(c) = f
+		return nil
+	}
+}
+
+func boolVar(field func(c *Config) *bool) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func durationVar(field func(c *Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration like 30s or 5m", value)
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+func listVar(field func(c *Config) *[]string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*field(c) = list
+		return nil
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// minJwtKeyLength is the HS256 key size recommended by RFC 7518.
+const minJwtKeyLength = 32
+
+var sslModes = map[string]bool{
+	"disable": true, "allow": true, "prefer": true,
+	"require": true, "verify-ca": true, "verify-full": true,
+}
+
+var logLevels = map[string]bool{
+	"debug": true, "info": true, "warn": true, "error": true,
+}
+
+// Validate checks every setting and returns all problems joined together.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.Server.Port), "server.port: %q is not a valid port", c.Server.Port)
+	check(c.Server.ReadTimeout > 0, "server.read_timeout must be positive")
+	check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout must be positive")
+	check(c.Server.WriteTimeout > 0, "server.write_timeout must be positive")
+	check(c.Server.IdleTimeout > 0, "server.idle_timeout must be positive")
+	check(c.Server.MaxHeaderBytes >= 4096, "server.max_header_bytes must be at least 4096")
+	check(c.Server.MaxBodyBytes > 0, "server.max_body_bytes must be positive")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	check(c.Server.HealthCheckTimeout > 0, "server.health_check_timeout must be positive")
+	check((c.Server.TLS.CertFile == "") == (c.Server.TLS.KeyFile == ""),
+		"server.tls.cert_file and server.tls.key_file must be set together")
+	check(c.Server.TLS.ReloadInterval > 0, "server.tls.reload_interval must be positive")
+
+	check(c.Database.Host != "", "database.host is required")
+	check(validPort(c.Database.Port), "database.port: %q is not a valid port", c.Database.Port)
+	check(c.Database.Name != "", "database.name is required")
+	check(c.Database.User != "", "database.user is required")
+	check(sslModes[c.Database.SSL], "database.sslmode: %q is not a valid sslmode", c.Database.SSL)
+	check(c.Database.MaxOpenConns > 0, "database.max_open_conns must be positive")
+	check(c.Database.MaxIdleConns >= 0 && c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"database.max_idle_conns must be between 0 and max_open_conns")
+	check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time must not be negative")
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime must not be negative")
+	check(c.Database.ConnectTimeout > 0, "database.connect_timeout must be positive")
+
+	check(c.Auth.JwtKey != "", "auth.jwt_key is required")
+	check(c.Auth.JwtKey == "" || len(c.Auth.JwtKey) >= minJwtKeyLength,
+		"auth.jwt_key must be at least %d bytes", minJwtKeyLength)
+	check(c.Auth.TokenTTL > 0, "auth.token_ttl must be positive")
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			check(!c.CORS.AllowCredentials, "cors.allowed_origins: * cannot be combined with allow_credentials")
+			continue
+		}
+		check(validOrigin(origin), "cors.allowed_origins: %q is not an origin like https://example.com", origin)
+	}
+	check(c.CORS.MaxAge >= 0, "cors.max_age must not be negative")
+
+	if c.OIDC.Issuer != "" {
+		check(validURL(c.OIDC.Issuer), "oidc.issuer: %q is not an absolute URL", c.OIDC.Issuer)
+		check(c.OIDC.ClientID != "", "oidc.client_id is required when oidc.issuer is set")
+		check(validURL(c.OIDC.RedirectURL), "oidc.redirect_url: %q is not an absolute URL", c.OIDC.RedirectURL)
+	}
+
+	check(c.Tracing.Exporter == "none" || c.Tracing.Exporter == "stdout" || c.Tracing.Exporter == "otlp",
+		"tracing.exporter: %q must be none, stdout or otlp", c.Tracing.Exporter)
+	check(c.Tracing.Endpoint == "" || validURL(c.Tracing.Endpoint), "tracing.endpoint: %q is not an absolute URL", c.Tracing.Endpoint)
+	check(c.Tracing.ServiceName != "", "tracing.service_name is required")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+
+	check(logLevels[c.Log.Level], "log.level: %q must be debug, info, warn or error", c.Log.Level)
+	check(c.Log.Format == "json" || c.Log.Format == "text", "log.format: %q must be json or text", c.Log.Format)
+
+	check(c.Polls.MaxTitleLength > 0, "polls.max_title_length must be positive")
+	check(c.Polls.MaxDescriptionLength > 0, "polls.max_description_length must be positive")
+	check(c.Polls.MaxOptionLength > 0, "polls.max_option_length must be positive")
+	check(c.Polls.MinOptions >= 2, "polls.min_options must be at least 2")
+	check(c.Polls.MaxOptions >= c.Polls.MinOptions, "polls.max_options must not be less than min_options")
+
+	check(c.Stream.HeartbeatInterval > 0, "stream.heartbeat_interval must be positive")
+	check(c.Stream.MaxDuration >= 0, "stream.max_duration must not be negative")
+	check(c.Stream.MaxConnections > 0, "stream.max_connections must be positive")
+	check(c.Stream.MaxPerClient > 0 && c.Stream.MaxPerClient <= c.Stream.MaxConnections,
+		"stream.max_per_client must be between 1 and max_connections")
+	check(c.Live.PingInterval > 0, "live.ping_interval must be positive")
+	check(c.Live.MaxParticipants > 0, "live.max_participants must be positive")
+	check(c.Live.PresenterGrace >= 0, "live.presenter_grace must not be negative")
+	check(c.Webhooks.PollInterval > 0, "webhooks.poll_interval must be positive")
+	check(c.Webhooks.BatchSize > 0, "webhooks.batch_size must be positive")
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout must be positive")
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.max_attempts must be positive")
+	check(c.Webhooks.BackoffBase > 0 && c.Webhooks.BackoffMax >= c.Webhooks.BackoffBase,
+		"webhooks.backoff_base must be positive and at most backoff_max")
+	check(c.Webhooks.DisableAfter > 0, "webhooks.disable_after must be positive")
+	check(c.Outbox.PollInterval > 0, "outbox.poll_interval must be positive")
+	check(c.Outbox.BatchSize > 0, "outbox.batch_size must be positive")
+	check(c.Outbox.HandlerTimeout > 0, "outbox.handler_timeout must be positive")
+	check(c.Outbox.BackoffBase > 0 && c.Outbox.BackoffMax >= c.Outbox.BackoffBase,
+		"outbox.backoff_base must be positive and at most backoff_max")
+	check(c.Outbox.MaxAttempts > 0, "outbox.max_attempts must be positive")
+	check(c.Lockout.MaxAccountFailures > 0, "lockout.max_account_failures must be positive")
+	check(c.Lockout.MaxIPFailures > 0, "lockout.max_ip_failures must be positive")
+	check(c.Lockout.BaseDelay > 0 && c.Lockout.MaxDelay >= c.Lockout.BaseDelay,
+		"lockout.base_delay must be positive and at most max_delay")
+	check(c.Lockout.Window > 0, "lockout.window must be positive")
+
+	return errors.Join(errs...)
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
+
+func validURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func validOrigin(raw string) bool {
+	u, err := url.Parse(raw)
+	return validURL(raw) && err == nil && u.Path == "" && u.RawQuery == ""
+}
@@ -1,31 +1,78 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
+	"log/slog"
 	"native-free-pollings/config"
+	"net/url"
 	"time"
 
 	_ "github.com/lib/pq"
 )
 
-func GetDatabaseConnection(conf config.Database) *sql.DB {
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		conf.User, conf.Pass, conf.Host, conf.Port, conf.Name, conf.SSL,
-	)
+const (
+	connectBaseDelay = 500 * time.Millisecond
+	connectMaxDelay  = 10 * time.Second
+)
+
+// DSN is the connection URL for conf, also used by connections that live
+// outside the pool such as LISTEN sessions.
+func DSN(conf config.Database) string {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(conf.User, conf.Pass),
+		Host:     conf.Host + ":" + conf.Port,
+		Path:     conf.Name,
+		RawQuery: url.Values{"sslmode": {conf.SSL}}.Encode(),
+	}
+	return dsn.String()
+}
 
-	db, err := sql.Open("postgres", dsn)
+func GetDatabaseConnection(conf config.Database) *sql.DB {
+	db, err := sql.Open("postgres", DSN(conf))
 	if err != nil {
 		log.Fatal("Failed open conection:", err)
 	}
 
-	fmt.Println("Database connected")
-
-	db.SetMaxIdleConns(10)
-	db.SetMaxOpenConns(100)
-	db.SetConnMaxIdleTime(3 * time.Minute)
-	db.SetConnMaxLifetime(60 * time.Minute)
+	db.SetMaxIdleConns(conf.MaxIdleConns)
+	db.SetMaxOpenConns(conf.MaxOpenConns)
+	db.SetConnMaxIdleTime(conf.ConnMaxIdleTime)
+	db.SetConnMaxLifetime(conf.ConnMaxLifetime)
 
 	return db
 }
+
+// Pinger is the part of *sql.DB that WaitForConnection needs.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// WaitForConnection pings the database until it answers, backing off
+// exponentially between attempts, and gives up after maxWait or when ctx is
+// cancelled. It lets the server start alongside a database that is still
+// booting.
+func WaitForConnection(ctx context.Context, db Pinger, maxWait time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, maxWait)
+	defer cancel()
+
+	delay := connectBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			slog.Info("Database connected")
+			return nil
+		}
+
+		slog.Warn("Database not reachable", "attempt", attempt, "retry_in", delay.String(), "error", err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("database not reachable after %d attempts: %w", attempt, err)
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, connectMaxDelay)
+	}
+}
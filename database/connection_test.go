@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePinger struct {
+	failures int
+	calls    int
+}
+
+func (p *fakePinger) PingContext(ctx context.Context) error {
+	p.calls++
+	if p.calls <= p.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestWaitForConnection(t *testing.T) {
+	t.Run("retries until reachable", func(t *testing.T) {
+		p := &fakePinger{failures: 1}
+
+		err := WaitForConnection(context.Background(), p, 5*time.Second)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, p.calls)
+	})
+
+	t.Run("gives up after max wait", func(t *testing.T) {
+		p := &fakePinger{failures: 1000}
+
+		err := WaitForConnection(context.Background(), p, 50*time.Millisecond)
+
+		assert.ErrorContains(t, err, "database not reachable")
+		assert.Equal(t, 1, p.calls)
+	})
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"native-free-pollings/migrations"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		versions []int64
+		wantErr  bool
+	}{
+		{
+			name: "sorted by version",
+			fsys: fstest.MapFS{
+				"000010_b.up.sql":   {Data: []byte("b up")},
+				"000010_b.down.sql": {Data: []byte("b down")},
+				"000002_a.up.sql":   {Data: []byte("a up")},
+				"000002_a.down.sql": {Data: []byte("a down")},
+				"README.md":         {Data: []byte("ignored")},
+			},
+			versions: []int64{2, 10},
+		},
+		{
+			name:    "missing down file",
+			fsys:    fstest.MapFS{"000001_a.up.sql": {Data: []byte("a up")}},
+			wantErr: true,
+		},
+		{
+			name: "conflicting names",
+			fsys: fstest.MapFS{
+				"000001_a.up.sql":   {Data: []byte("a up")},
+				"000001_b.down.sql": {Data: []byte("b down")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadMigrations(tt.fsys)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			var versions []int64
+			for _, mig := range got {
+				versions = append(versions, mig.Version)
+			}
+			assert.Equal(t, tt.versions, versions)
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	got, err := LoadMigrations(migrations.FS)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, got)
+	for i, mig := range got {
+		assert.Equal(t, int64(i+1), mig.Version, "migration versions must have no gaps")
+		assert.NotEmpty(t, mig.Down, "migration %d needs a down file", mig.Version)
+	}
+}
+
+func TestMigrator_Up(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	fsys := fstest.MapFS{
+		"000001_a.up.sql":   {Data: []byte("create table a()")},
+		"000001_a.down.sql": {Data: []byte("drop table a")},
+		"000002_b.up.sql":   {Data: []byte("create table b()")},
+		"000002_b.down.sql": {Data: []byte("drop table b")},
+	}
+	migrator, err := NewMigrator(db, fsys)
+	assert.NoError(t, err)
+
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WithArgs(migrationLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, applied_at FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(int64(1), time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec(`create table b\(\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations`).WithArgs(int64(2), "b").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WithArgs(migrationLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := migrator.Up(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, int64(2), applied[0].Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Down(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	fsys := fstest.MapFS{
+		"000001_a.up.sql":   {Data: []byte("create table a()")},
+		"000001_a.down.sql": {Data: []byte("drop table a")},
+		"000002_b.up.sql":   {Data: []byte("create table b()")},
+		"000002_b.down.sql": {Data: []byte("drop table b")},
+	}
+	migrator, err := NewMigrator(db, fsys)
+	assert.NoError(t, err)
+
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WithArgs(migrationLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, applied_at FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(int64(1), time.Now()).AddRow(int64(2), time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec(`drop table b`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM schema_migrations`).WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WithArgs(migrationLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rolled, err := migrator.Down(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, rolled, 1)
+	assert.Equal(t, int64(2), rolled[0].Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Pending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	fsys := fstest.MapFS{
+		"000001_a.up.sql":   {Data: []byte("create table a()")},
+		"000001_a.down.sql": {Data: []byte("drop table a")},
+		"000002_b.up.sql":   {Data: []byte("create table b()")},
+		"000002_b.down.sql": {Data: []byte("drop table b")},
+	}
+	migrator, err := NewMigrator(db, fsys)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(`SELECT version FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(int64(1)))
+
+	pending, err := migrator.Pending(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pending)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
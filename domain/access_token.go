@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"context"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/models"
+)
+
+type AccessTokenRepository interface {
+	Create(ctx context.Context, token *models.AccessToken) error
+	ListByUserID(ctx context.Context, userID int64) ([]models.AccessToken, error)
+	Delete(ctx context.Context, userID, id int64) error
+	GetByHash(ctx context.Context, hash string) (*models.AccessToken, error)
+	TouchLastUsed(ctx context.Context, id int64) error
+}
+
+type AccessTokenService interface {
+	Create(ctx context.Context, userID int64, req *dto.CreateAccessTokenRequest) (*dto.AccessTokenCreatedResponse, error)
+	List(ctx context.Context, userID int64) ([]dto.AccessTokenResponse, error)
+	Revoke(ctx context.Context, userID, id int64) error
+	Authenticate(ctx context.Context, token string) (*helper.AuthContext, error)
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"context"
+	"native-free-pollings/dto"
+	"native-free-pollings/models"
+)
+
+type AccountRepository interface {
+	Export(ctx context.Context, userID int64) (*models.AccountExport, error)
+	// Erase removes the user's personal data. When deletePolls is true, or
+	// the user has no polls, the users row is deleted outright; otherwise it
+	// is kept as an anonymous owner of the polls so their results survive.
+	Erase(ctx context.Context, userID int64, deletePolls bool) error
+}
+
+type AccountService interface {
+	Export(ctx context.Context, userID int64) (*dto.AccountExport, error)
+	// Delete erases the account; sessionID is the session asking for it.
+	Delete(ctx context.Context, userID, sessionID int64, req *dto.DeleteAccountRequest) error
+}
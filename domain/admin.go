@@ -0,0 +1,12 @@
+package domain
+
+import (
+	"context"
+	"native-free-pollings/authz"
+	"native-free-pollings/dto"
+)
+
+type AdminService interface {
+	ListUsers(ctx context.Context) ([]dto.AdminUserResponse, error)
+	UpdateUser(ctx context.Context, actor authz.Subject, id int64, req *dto.AdminUpdateUserRequest) (*dto.AdminUserResponse, error)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"native-free-pollings/dto"
 	"native-free-pollings/models"
+	"native-free-pollings/oidc"
 )
 
 type AuthRepository interface {
@@ -13,5 +14,8 @@ type AuthRepository interface {
 
 type AuthService interface {
 	Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, error)
+	LoginTwoFactor(ctx context.Context, req *dto.LoginTwoFactorRequest) (*dto.LoginResponse, error)
+	LoginOIDC(ctx context.Context, identity *oidc.Identity, client dto.ClientInfo) (*dto.LoginResponse, error)
 	Register(ctx context.Context, req *dto.RegisterRequest) (*dto.RegisterResponse, error)
+	UnlockAccount(ctx context.Context, email string) error
 }
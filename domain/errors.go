@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"errors"
+	"native-free-pollings/authz"
+)
+
+// Sentinel errors returned by services, possibly wrapped with more context.
+// Callers test for them with errors.Is; response.Error maps them to HTTP.
+var (
+	ErrPollNotFound   = errors.New("polling not found")
+	ErrOptionNotFound = errors.New("option not found")
+	ErrForbidden      = authz.ErrForbidden
+	ErrAlreadyVoted   = errors.New("already voted in this polling")
+	ErrOptionHasVotes = errors.New("option has votes")
+	// ErrVersionMismatch means the poll changed since the client read it.
+	ErrVersionMismatch = errors.New("polling version mismatch")
+)
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"native-free-pollings/models"
+	"time"
+)
+
+// Events recorded in the outbox with the poll change or vote that caused
+// them.
+const (
+	EventPollCreated = "poll.created"
+	EventPollUpdated = "poll.updated"
+	// EventPollClosed follows poll.updated when the update closed the poll.
+	EventPollClosed  = "poll.closed"
+	EventPollDeleted = "poll.deleted"
+	EventVoteCast    = "vote.cast"
+)
+
+// PollEvents lists every event, in no particular order.
+var PollEvents = []string{EventPollCreated, EventPollUpdated, EventPollClosed, EventPollDeleted, EventVoteCast}
+
+// Event is something that happened to a poll. ID is unique per event, so
+// consumers can drop one they have seen before. Data is stored as JSON;
+// events read back from the outbox carry it as json.RawMessage.
+type Event struct {
+	ID         string
+	Name       string
+	PollID     int64
+	OwnerID    int64
+	OccurredAt time.Time
+	Data       any
+}
+
+// OutboxRepository stores events in the transaction of the change that
+// caused them, so an event exists exactly when its change was committed.
+type OutboxRepository interface {
+	// Add records ev through db, which should be the change's transaction.
+	Add(ctx context.Context, db DB, ev Event) error
+	// ClaimDue returns up to limit due events, oldest first, and pushes
+	// their next attempt lease into the future so no other instance
+	// handles them meanwhile.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	// MarkConsumed records that consumer handled the event, so it is not
+	// given the event again when another consumer failed on it.
+	MarkConsumed(ctx context.Context, id int64, consumer string) error
+	// Complete removes an event every consumer has handled.
+	Complete(ctx context.Context, id int64) error
+	// Retry schedules the event again at retryAt.
+	Retry(ctx context.Context, id int64, retryAt time.Time, lastError string) error
+	// Fail stops handing out an event that ran out of attempts. It stays in
+	// the table with its last error until an operator requeues or drops it.
+	Fail(ctx context.Context, id int64, lastError string) error
+}
@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"context"
+	"native-free-pollings/models"
+	"native-free-pollings/oidc"
+)
+
+type IdentityRepository interface {
+	GetUser(ctx context.Context, issuer, subject string) (*models.User, error)
+	Link(ctx context.Context, identity *models.Identity) error
+	CreateUserWithIdentity(ctx context.Context, user *models.User, identity *models.Identity) error
+}
+
+type OIDCProvider interface {
+	AuthCodeURL(state, nonce, verifier string) string
+	Exchange(ctx context.Context, code, verifier, nonce string) (*oidc.Identity, error)
+}
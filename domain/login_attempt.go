@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type LoginAttemptTracker interface {
+	LockedUntil(ctx context.Context, email, ip string) (time.Time, error)
+	RecordFailure(ctx context.Context, email, ip string) (time.Time, error)
+	RecordSuccess(ctx context.Context, email, ip string) error
+	Unlock(ctx context.Context, email string) error
+}
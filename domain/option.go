@@ -10,4 +10,7 @@ type OptionRepository interface {
 	Update(ctx context.Context, db DB, option *models.PollOption) error
 	Delete(ctx context.Context, db DB, id int64) error
 	GetByPollID(ctx context.Context, db DB, id int64) ([]models.PollOption, error)
+	// Reorder gives the options of pollID the positions 1..n in the order of
+	// ids, which must list every option of the poll.
+	Reorder(ctx context.Context, db DB, pollID int64, ids []int64) error
 }
@@ -2,23 +2,51 @@ package domain
 
 import (
 	"context"
+	"native-free-pollings/authz"
 	"native-free-pollings/dto"
 	"native-free-pollings/models"
 )
 
+// ResultsChannel is the Postgres notification channel announcing that the
+// results of a poll changed. The payload is the poll ID.
+const ResultsChannel = "poll_results"
+
 type PollRepository interface {
 	Create(ctx context.Context, db DB, poll *models.Polling) error
+	// Update and Delete only apply while the stored version matches,
+	// returning sql.ErrNoRows otherwise.
 	Update(ctx context.Context, db DB, poll *models.Polling) error
-	Delete(ctx context.Context, db DB, id int64) error
+	Delete(ctx context.Context, db DB, id, version int64) error
+	// Touch bumps the version after a change to the poll's options.
+	Touch(ctx context.Context, db DB, id int64) error
+	// NotifyResults announces on ResultsChannel that the results of the
+	// poll changed. Inside a transaction it is only sent on commit.
+	NotifyResults(ctx context.Context, db DB, id int64) error
 	GetByID(ctx context.Context, db DB, id int64) (*models.Polling, error)
+	// GetByIDForUpdate locks the poll until the transaction db ends.
+	GetByIDForUpdate(ctx context.Context, db DB, id int64) (*models.Polling, error)
+	// GetByIDForShare holds the poll against changes, but not against other
+	// share locks, until the transaction db ends.
+	GetByIDForShare(ctx context.Context, db DB, id int64) (*models.Polling, error)
 	GetResultsByID(ctx context.Context, db DB, id int64) ([]models.VoteResult, error)
 }
 
 type PollService interface {
 	CreatePolling(ctx context.Context, rq *dto.CreatePollingRequest, creator dto.CreatorInfo) (*dto.PollingResponse, error)
-	UpdatePolling(ctx context.Context, rq *dto.UpdatePollingRequest, creator dto.CreatorInfo) (*dto.PollingResponse, error)
-	DeletePolling(ctx context.Context, pollID, userID int64) error
+	UpdatePolling(ctx context.Context, rq *dto.UpdatePollingRequest, actor authz.Subject) (*dto.PollingResponse, error)
+	// DeletePolling deletes the poll only while it is at version; zero skips
+	// the check.
+	DeletePolling(ctx context.Context, pollID, version int64, actor authz.Subject) error
 	GetDetailPolling(ctx context.Context, id int64) (*dto.PollingResponse, error)
 	VoteOptionPolling(ctx context.Context, userID, pollID, optionID int64, deviceHash string) error
 	GetPollingResult(ctx context.Context, pollID int64) (*dto.ResultPolling, error)
+
+	// Option changes run only while the poll is at version, like
+	// DeletePolling, and return the poll's version after the change.
+	AddOption(ctx context.Context, pollID, version int64, rq *dto.CreateOptionRequest, actor authz.Subject) (*dto.Option, int64, error)
+	RenameOption(ctx context.Context, pollID, version, optionID int64, rq *dto.RenameOptionRequest, actor authz.Subject) (*dto.Option, int64, error)
+	ReorderOptions(ctx context.Context, pollID, version int64, rq *dto.ReorderOptionsRequest, actor authz.Subject) ([]dto.Option, int64, error)
+	// DeleteOption refuses to remove an option with votes unless force is
+	// set, in which case its votes are deleted too.
+	DeleteOption(ctx context.Context, pollID, version, optionID int64, force bool, actor authz.Subject) (int64, error)
 }
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"context"
+	"native-free-pollings/dto"
+	"native-free-pollings/models"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	ListActive(ctx context.Context, userID int64) ([]models.Session, error)
+	Revoke(ctx context.Context, userID, id int64) error
+	// GetActive returns a session of userID that is neither revoked nor
+	// expired, or sql.ErrNoRows.
+	GetActive(ctx context.Context, userID, id int64) (*models.Session, error)
+	// Touch records activity on an active session and returns its owner and
+	// the owner's current role. It returns sql.ErrNoRows when the session is
+	// unknown, revoked, expired or belongs to a disabled account.
+	Touch(ctx context.Context, id int64) (int64, string, error)
+}
+
+type SessionService interface {
+	List(ctx context.Context, userID, currentID int64) ([]dto.SessionResponse, error)
+	Revoke(ctx context.Context, userID, id int64) error
+	// Validate checks the session behind a login token and returns the
+	// owner's current role, which wins over the one in the token.
+	Validate(ctx context.Context, userID, id int64) (string, error)
+	// ForgetUser drops what is cached about userID's sessions, so a changed
+	// role or disabled account is picked up on the next request.
+	ForgetUser(userID int64)
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"native-free-pollings/dto"
+	"native-free-pollings/models"
+)
+
+type TwoFactorRepository interface {
+	Get(ctx context.Context, userID int64) (*models.TwoFactor, error)
+	SaveSecret(ctx context.Context, userID int64, secret string) error
+	Enable(ctx context.Context, userID, step int64, recoveryHashes []string) error
+	Disable(ctx context.Context, userID int64) error
+	UseStep(ctx context.Context, userID, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID int64, hash string) (bool, error)
+}
+
+type TwoFactorService interface {
+	Setup(ctx context.Context, userID int64) (*dto.TwoFactorSetupResponse, error)
+	Confirm(ctx context.Context, userID int64, code string) (*dto.RecoveryCodesResponse, error)
+	// Disable takes the login session, since accounts without a password
+	// must have signed in recently instead.
+	Disable(ctx context.Context, userID, sessionID int64, password string) error
+}
@@ -10,6 +10,10 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id int64) (*models.User, error)
 	Update(ctx context.Context, user *models.User) error
 	UpdatePassword(ctx context.Context, id int64, passwordHashed string) error
+	GetPasswordHash(ctx context.Context, id int64) (string, error)
+	List(ctx context.Context) ([]models.User, error)
+	UpdateRole(ctx context.Context, id int64, role string) error
+	SetDisabled(ctx context.Context, id int64, disabled bool) error
 	FindPollingsByID(ctx context.Context, id int64) ([]models.PollingSummary, error)
 	FindPollingsVotedByID(ctx context.Context, id int64) ([]models.PollingSummary, error)
 }
@@ -10,6 +10,7 @@ type VoteRepository interface {
 	CreateUserVote(ctx context.Context, db DB, userID, voteID int64) error
 	GetByPollID(ctx context.Context, db DB, pollID int64) ([]models.Vote, error)
 	GetByOptionID(ctx context.Context, db DB, optionID int64) ([]models.Vote, error)
+	CountByOptionID(ctx context.Context, db DB, optionID int64) (int64, error)
 	HasUserVoted(ctx context.Context, db DB, pollID, userID int64) (bool, error)
 	HasDeviceVoted(ctx context.Context, db DB, deviceHash string, pollID int64) (bool, error)
 }
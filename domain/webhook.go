@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"native-free-pollings/authz"
+	"native-free-pollings/dto"
+	"native-free-pollings/models"
+	"time"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, hook *models.Webhook) error
+	ListByUserID(ctx context.Context, userID int64) ([]models.Webhook, error)
+	GetByID(ctx context.Context, userID, id int64) (*models.Webhook, error)
+	// Update saves url and events, and disabled_at together with a reset of
+	// the failure count.
+	Update(ctx context.Context, hook *models.Webhook) error
+	Delete(ctx context.Context, userID, id int64) error
+	// EnqueueEvent queues a delivery of the event for every enabled webhook
+	// that wants it: those subscribed to pollID, and those of ownerID
+	// subscribed to all of their polls. Webhooks that already have a
+	// delivery of eventID are skipped, so calling it again for the same
+	// event queues nothing twice. It returns how many deliveries it queued.
+	EnqueueEvent(ctx context.Context, ownerID, pollID int64, eventID, event string, payload []byte) (int64, error)
+	Enqueue(ctx context.Context, delivery *models.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, webhookID int64, limit int) ([]models.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, webhookID, id int64) (*models.WebhookDelivery, error)
+	// ClaimDue returns up to limit pending deliveries that are due, with the
+	// URL and secret of their webhook, and pushes their next attempt lease
+	// into the future so no other instance sends them meanwhile.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	// RecordSuccess logs the attempt, completes the delivery and resets the
+	// webhook's failure count.
+	RecordSuccess(ctx context.Context, delivery *models.WebhookDelivery, attempt *models.WebhookAttempt) error
+	// RecordFailure logs the attempt and schedules the delivery for retryAt,
+	// or fails it for good when retryAt is nil. The webhook is disabled when
+	// this was its disableAfter-th failure in a row; disabled reports that.
+	RecordFailure(ctx context.Context, delivery *models.WebhookDelivery, attempt *models.WebhookAttempt, retryAt *time.Time, disableAfter int) (disabled bool, err error)
+}
+
+// WebhookService manages a user's webhooks and queues deliveries of the
+// events they subscribed to.
+type WebhookService interface {
+	// HandleEvent queues deliveries of ev for its subscribers. It is an
+	// event bus consumer and may be called again for the same event.
+	HandleEvent(ctx context.Context, ev Event, data json.RawMessage) error
+
+	// Create subscribes actor's URL. A webhook for one poll needs the right
+	// to manage that poll.
+	Create(ctx context.Context, actor authz.Subject, req *dto.CreateWebhookRequest) (*dto.WebhookCreatedResponse, error)
+	List(ctx context.Context, userID int64) ([]dto.WebhookResponse, error)
+	Update(ctx context.Context, userID int64, req *dto.UpdateWebhookRequest) (*dto.WebhookResponse, error)
+	Delete(ctx context.Context, userID, id int64) error
+	ListDeliveries(ctx context.Context, userID, webhookID int64) ([]dto.WebhookDeliveryResponse, error)
+	// Redeliver queues the event of a past delivery again as a new delivery
+	// with the same event ID.
+	Redeliver(ctx context.Context, userID, webhookID, deliveryID int64) (*dto.WebhookDeliveryResponse, error)
+}
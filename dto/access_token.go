@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type CreateAccessTokenRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=polls:read polls:write votes:write profile"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"gte=0,lte=365"`
+}
+
+type AccessTokenResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type AccessTokenCreatedResponse struct {
+	AccessTokenResponse
+	Token string `json:"token"`
+}
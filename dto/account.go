@@ -0,0 +1,81 @@
+package dto
+
+import "time"
+
+type DeleteAccountRequest struct {
+	Password    string `json:"password" validate:"required"`
+	DeletePolls bool   `json:"delete_polls"`
+}
+
+type AccountExport struct {
+	ExportedAt    time.Time             `json:"exported_at"`
+	Profile       ExportProfile         `json:"profile"`
+	Polls         []ExportPoll          `json:"polls"`
+	Votes         []ExportVote          `json:"votes"`
+	Sessions      []ExportSession       `json:"sessions"`
+	AccessTokens  []AccessTokenResponse `json:"access_tokens"`
+	Identities    []ExportIdentity      `json:"identities"`
+	LoginAttempts []ExportLoginAttempt  `json:"login_attempts"`
+	Webhooks      []WebhookResponse     `json:"webhooks"`
+}
+
+type ExportProfile struct {
+	ID               int64      `json:"id"`
+	Name             string     `json:"name"`
+	Email            string     `json:"email"`
+	Role             string     `json:"role"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	DisabledAt       *time.Time `json:"disabled_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+type ExportPoll struct {
+	ID          int64          `json:"id"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Status      string         `json:"status"`
+	StartsAt    *time.Time     `json:"starts_at"`
+	EndsAt      *time.Time     `json:"ends_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	Options     []ExportOption `json:"options"`
+}
+
+type ExportOption struct {
+	ID       int64  `json:"id"`
+	Label    string `json:"label"`
+	Position int    `json:"position"`
+	Votes    int64  `json:"votes"`
+}
+
+type ExportVote struct {
+	PollID      int64     `json:"poll_id"`
+	PollTitle   string    `json:"poll_title"`
+	OptionID    int64     `json:"option_id"`
+	OptionLabel string    `json:"option_label"`
+	VotedAt     time.Time `json:"voted_at"`
+}
+
+type ExportSession struct {
+	ID         int64      `json:"id"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+type ExportIdentity struct {
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportLoginAttempt struct {
+	IP          string    `json:"ip"`
+	Success     bool      `json:"success"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+type AdminUserResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	DisabledAt *time.Time `json:"disabled_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type AdminUpdateUserRequest struct {
+	Role     *string `json:"role" validate:"omitempty,oneof=user moderator admin"`
+	Disabled *bool   `json:"disabled"`
+}
@@ -1,14 +1,18 @@
 package dto
 
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email     string `json:"email" validate:"required,email"`
+	Password  string `json:"password" validate:"required"`
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 type LoginResponse struct {
-	ID    int64  `json:"id"`
-	Name  string `json:"name"`
-	Token string `json:"token"`
+	ID                int64  `json:"id"`
+	Name              string `json:"name"`
+	Token             string `json:"token,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
 }
 
 type RegisterRequest struct {
@@ -22,3 +26,31 @@ type RegisterResponse struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
 }
+
+type UnlockAccountRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+	IP             string `json:"-"`
+	UserAgent      string `json:"-"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type TwoFactorConfirmRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -2,23 +2,42 @@ package dto
 
 import "time"
 
+// Poll rules such as polltitle and polloptions are registered in package
+// helper; their limits come from config.Polls.
 type CreatePollingRequest struct {
-	Title       string    `json:"title" validate:"required"`
-	Description string    `json:"description" validate:"required"`
-	Status      string    `json:"status" validate:"required"`
+	Title       string    `json:"title" validate:"required,notblank,polltitle"`
+	Description string    `json:"description" validate:"required,notblank,polldesc"`
+	Status      string    `json:"status" validate:"required,pollstatus"`
 	StartsAt    time.Time `json:"starts_at" validate:"required"`
-	EndsAt      time.Time `json:"ends_at" validate:"required"`
-	Options     []string  `json:"options" validate:"required"`
+	EndsAt      time.Time `json:"ends_at" validate:"required,gtfield=StartsAt"`
+	Options     []string  `json:"options" validate:"required,polloptions,uniquelabels,dive,notblank,optionlabel"`
 }
 
+// UpdatePollingRequest is a partial update: nil fields keep their current
+// value. Options are changed through their own endpoints, so a PATCH never
+// drops options or votes.
 type UpdatePollingRequest struct {
-	ID          int64     `json:"id" validate:"required"`
-	Title       string    `json:"title" validate:"required"`
-	Description string    `json:"description" validate:"required"`
-	Status      string    `json:"status" validate:"required"`
-	StartsAt    time.Time `json:"starts_at" validate:"required"`
-	EndsAt      time.Time `json:"ends_at" validate:"required"`
-	Options     []Option  `json:"options" validate:"required"`
+	ID int64 `json:"-" validate:"required"`
+	// Version comes from If-Match; zero skips the check.
+	Version     int64      `json:"-"`
+	Title       *string    `json:"title" validate:"omitempty,notblank,polltitle"`
+	Description *string    `json:"description" validate:"omitempty,notblank,polldesc"`
+	Status      *string    `json:"status" validate:"omitempty,pollstatus"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+}
+
+type CreateOptionRequest struct {
+	Label string `json:"label" validate:"required,notblank,optionlabel"`
+}
+
+type RenameOptionRequest struct {
+	Label string `json:"label" validate:"required,notblank,optionlabel"`
+}
+
+// ReorderOptionsRequest lists every option of the poll in its new order.
+type ReorderOptionsRequest struct {
+	OptionIDs []int64 `json:"option_ids" validate:"required,unique"`
 }
 
 type VoteRequest struct {
@@ -39,6 +58,7 @@ type PollingResponse struct {
 	EndsAt      time.Time `json:"ends_at"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int64     `json:"version"`
 	Options     []Option  `json:"polling_options"`
 
 	Creator CreatorInfo `json:"creator"`
@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type SessionResponse struct {
+	ID         int64     `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
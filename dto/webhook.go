@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url,max=2000"`
+	Events []string `json:"events" validate:"required,min=1,unique,dive,oneof=poll.created poll.updated poll.closed poll.deleted vote.cast"`
+	// PollID limits the webhook to one poll; without it the webhook gets the
+	// events of every poll the user created.
+	PollID *int64 `json:"poll_id" validate:"omitempty,gt=0"`
+}
+
+type UpdateWebhookRequest struct {
+	ID     int64    `json:"-"`
+	URL    *string  `json:"url" validate:"omitempty,url,max=2000"`
+	Events []string `json:"events" validate:"omitempty,min=1,unique,dive,oneof=poll.created poll.updated poll.closed poll.deleted vote.cast"`
+	// Active set to true re-enables a webhook that was disabled after
+	// failing too often; false disables it.
+	Active *bool `json:"active"`
+}
+
+type WebhookResponse struct {
+	ID                  int64      `json:"id"`
+	URL                 string     `json:"url"`
+	PollID              *int64     `json:"poll_id"`
+	Events              []string   `json:"events"`
+	Active              bool       `json:"active"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+type WebhookCreatedResponse struct {
+	WebhookResponse
+	// Secret signs the deliveries. It is only returned once.
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID            int64                    `json:"id"`
+	EventID       string                   `json:"event_id"`
+	Event         string                   `json:"event"`
+	Payload       json.RawMessage          `json:"payload" swaggertype:"object"`
+	Status        string                   `json:"status"`
+	Attempts      int                      `json:"attempts"`
+	NextAttemptAt *time.Time               `json:"next_attempt_at"`
+	DeliveredAt   *time.Time               `json:"delivered_at"`
+	CreatedAt     time.Time                `json:"created_at"`
+	AttemptLog    []WebhookAttemptResponse `json:"attempt_log"`
+}
+
+type WebhookAttemptResponse struct {
+	StatusCode  *int      `json:"status_code"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int       `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// PollDeletedData is the data of a poll.deleted event.
+type PollDeletedData struct {
+	PollID int64 `json:"poll_id"`
+}
+
+// VoteCastData is the data of a vote.cast event. It does not identify the
+// voter.
+type VoteCastData struct {
+	PollID   int64 `json:"poll_id"`
+	OptionID int64 `json:"option_id"`
+}
@@ -0,0 +1,69 @@
+// Package events hands the events recorded in the transactional outbox to
+// the in-process subscribers of a Bus. Every event reaches each of its
+// subscribers at least once: a subscriber that fails gets the event again
+// later, and one that succeeded is not called for it again, unless the
+// process dies between handling the event and recording that it did.
+// Subscribers must therefore tolerate seeing an event twice, for example
+// by keying their effects on the event ID.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"native-free-pollings/domain"
+	"sync"
+)
+
+// Handler handles one event. The event's Data is its raw JSON.
+type Handler func(ctx context.Context, ev domain.Event) error
+
+type subscription struct {
+	consumer string
+	handle   Handler
+}
+
+// Bus routes events to the subscribers registered for their name.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[string][]subscription
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[string][]subscription)}
+}
+
+// Subscribe registers fn for the event name under consumer, which names
+// the subscriber in the outbox's record of who handled what and therefore
+// must stay the same across releases. The event's data is decoded into T
+// before fn is called. Registering a consumer twice for the same event
+// panics, since the second one would never be called.
+func Subscribe[T any](b *Bus, consumer, name string, fn func(ctx context.Context, ev domain.Event, data T) error) {
+	b.Handle(consumer, name, func(ctx context.Context, ev domain.Event) error {
+		raw, _ := ev.Data.(json.RawMessage)
+		var data T
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return fmt.Errorf("decode %s data: %w", ev.Name, err)
+		}
+		return fn(ctx, ev, data)
+	})
+}
+
+// Handle is Subscribe for handlers that take the raw event.
+func (b *Bus) Handle(consumer, name string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, s := range b.subs[name] {
+		if s.consumer == consumer {
+			panic(fmt.Sprintf("events: %s already subscribed to %s", consumer, name))
+		}
+	}
+	b.subs[name] = append(b.subs[name], subscription{consumer: consumer, handle: h})
+}
+
+func (b *Bus) subscribers(name string) []subscription {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.subs[name]
+}
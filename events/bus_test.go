@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribe(t *testing.T) {
+	bus := NewBus()
+
+	var got dto.VoteCastData
+	Subscribe(bus, "tally", domain.EventVoteCast, func(ctx context.Context, ev domain.Event, data dto.VoteCastData) error {
+		got = data
+		return nil
+	})
+
+	subs := bus.subscribers(domain.EventVoteCast)
+	require.Len(t, subs, 1)
+	assert.Equal(t, "tally", subs[0].consumer)
+	assert.Empty(t, bus.subscribers(domain.EventPollCreated))
+
+	err := subs[0].handle(context.Background(), domain.Event{Name: domain.EventVoteCast, Data: json.RawMessage(`{"poll_id":1,"option_id":2}`)})
+	require.NoError(t, err)
+	assert.Equal(t, dto.VoteCastData{PollID: 1, OptionID: 2}, got)
+
+	err = subs[0].handle(context.Background(), domain.Event{Name: domain.EventVoteCast, Data: json.RawMessage(`[]`)})
+	assert.ErrorContains(t, err, "decode vote.cast data")
+}
+
+func TestSubscribe_Twice(t *testing.T) {
+	bus := NewBus()
+	handler := func(ctx context.Context, ev domain.Event, data json.RawMessage) error { return nil }
+
+	Subscribe(bus, "webhooks", domain.EventPollCreated, handler)
+	Subscribe(bus, "webhooks", domain.EventPollUpdated, handler)
+	Subscribe(bus, "audit", domain.EventPollCreated, handler)
+
+	assert.Panics(t, func() { Subscribe(bus, "webhooks", domain.EventPollCreated, handler) })
+	assert.Len(t, bus.subscribers(domain.EventPollCreated), 2)
+}
@@ -0,0 +1,146 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"native-free-pollings/config"
+	"native-free-pollings/domain"
+	"native-free-pollings/helper"
+	"native-free-pollings/metrics"
+	"native-free-pollings/models"
+	"time"
+)
+
+// Dispatcher hands due outbox events to the bus. Any number of instances
+// may run one; an event is claimed by one of them at a time.
+type Dispatcher struct {
+	repo domain.OutboxRepository
+	bus  *Bus
+	conf config.Outbox
+	now  func() time.Time
+}
+
+func NewDispatcher(repo domain.OutboxRepository, bus *Bus, conf config.Outbox) *Dispatcher {
+	return &Dispatcher{repo: repo, bus: bus, conf: conf, now: time.Now}
+}
+
+// Run dispatches until ctx is cancelled. It fits server.Workers.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.conf.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// A full batch suggests more are due, so the next one is claimed
+		// right away instead of after the interval.
+		for {
+			n, err := d.Dispatch(ctx)
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to dispatch outbox events", "error", err)
+			}
+			if err != nil || n < d.conf.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch claims one batch of due events and hands them to their
+// subscribers in the order they were recorded. It returns how many events
+// it claimed.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	// The lease outlasts handlers that run into their timeout, so another
+	// instance only picks an event up again if this one died handling it.
+	lease := time.Duration(d.conf.BatchSize)*d.conf.HandlerTimeout + time.Minute
+	claimed, err := d.repo.ClaimDue(ctx, d.conf.BatchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range claimed {
+		if ctx.Err() != nil {
+			// The rest are handed out again once their lease runs out.
+			break
+		}
+		d.dispatch(ctx, &claimed[i])
+	}
+
+	return len(claimed), nil
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, oe *models.OutboxEvent) {
+	ev := domain.Event{
+		ID:         oe.EventID,
+		Name:       oe.Name,
+		PollID:     oe.PollID,
+		OwnerID:    oe.OwnerID,
+		OccurredAt: oe.OccurredAt,
+		Data:       oe.Data,
+	}
+	consumed := make(map[string]bool, len(oe.Consumed))
+	for _, c := range oe.Consumed {
+		consumed[c] = true
+	}
+
+	handleCtx, cancel := context.WithTimeout(ctx, d.conf.HandlerTimeout)
+	defer cancel()
+	// Record with a fresh context: a handler that finished just before
+	// shutdown must not be called again for nothing.
+	recordCtx, cancelRecord := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancelRecord()
+
+	var errs []error
+	for _, sub := range d.bus.subscribers(ev.Name) {
+		if consumed[sub.consumer] {
+			continue
+		}
+		if err := sub.handle(handleCtx, ev); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sub.consumer, err))
+			continue
+		}
+		if err := d.repo.MarkConsumed(recordCtx, oe.ID, sub.consumer); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if ctx.Err() != nil && len(errs) > 0 {
+		// Cut off by shutdown, which is not the subscribers' fault. The
+		// lease runs out and the event is handed out again.
+		return
+	}
+
+	if len(errs) == 0 {
+		if err := d.repo.Complete(recordCtx, oe.ID); err != nil {
+			slog.ErrorContext(ctx, "Failed to complete outbox event", "event_id", ev.ID, "error", err)
+			return
+		}
+		metrics.OutboxEvents.Inc("handled")
+		return
+	}
+
+	err := errors.Join(errs...)
+	if oe.Attempts+1 >= d.conf.MaxAttempts {
+		metrics.OutboxEvents.Inc("failed")
+		slog.ErrorContext(ctx, "Outbox event failed for good", "event", ev.Name, "event_id", ev.ID,
+			"attempts", oe.Attempts+1, "error", err)
+		if err := d.repo.Fail(recordCtx, oe.ID, err.Error()); err != nil {
+			slog.ErrorContext(ctx, "Failed to mark outbox event failed", "event_id", ev.ID, "error", err)
+		}
+		return
+	}
+
+	retryAt := d.now().Add(helper.Backoff(oe.Attempts+1, d.conf.BackoffBase, d.conf.BackoffMax))
+	metrics.OutboxEvents.Inc("retrying")
+	slog.WarnContext(ctx, "Outbox event not handled", "event", ev.Name, "event_id", ev.ID,
+		"attempt", oe.Attempts+1, "retry_at", retryAt, "error", err)
+	if err := d.repo.Retry(recordCtx, oe.ID, retryAt, err.Error()); err != nil {
+		slog.ErrorContext(ctx, "Failed to reschedule outbox event", "event_id", ev.ID, "error", err)
+	}
+}
@@ -0,0 +1,156 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"native-free-pollings/config"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/mocks"
+	"native-free-pollings/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	now := time.Date(2025, 10, 26, 9, 0, 0, 0, time.UTC)
+	conf := config.Default().Outbox
+	recorded := models.OutboxEvent{
+		ID: 7, EventID: "evt_1", Name: domain.EventVoteCast, PollID: 1, OwnerID: 5,
+		Data: json.RawMessage(`{"poll_id":1,"option_id":2}`), Attempts: 2,
+	}
+
+	tests := []struct {
+		name        string
+		consumed    []string
+		lastAttempt bool
+		failing     string
+		setupMocks  func(repo *mocks.OutboxRepositoryMock)
+		wantCalled  []string
+	}{
+		{
+			name: "all handled",
+			setupMocks: func(repo *mocks.OutboxRepositoryMock) {
+				repo.On("MarkConsumed", mock.Anything, int64(7), "webhooks").Return(nil)
+				repo.On("MarkConsumed", mock.Anything, int64(7), "tally").Return(nil)
+				repo.On("Complete", mock.Anything, int64(7)).Return(nil)
+			},
+			wantCalled: []string{"webhooks", "tally"},
+		},
+		{
+			name:    "one consumer fails",
+			failing: "tally",
+			setupMocks: func(repo *mocks.OutboxRepositoryMock) {
+				repo.On("MarkConsumed", mock.Anything, int64(7), "webhooks").Return(nil)
+				retryAt := now.Add(helper.Backoff(3, conf.BackoffBase, conf.BackoffMax))
+				repo.On("Retry", mock.Anything, int64(7), retryAt, "tally: tally is down").Return(nil)
+			},
+			wantCalled: []string{"webhooks", "tally"},
+		},
+		{
+			name:        "last attempt fails for good",
+			lastAttempt: true,
+			failing:     "tally",
+			setupMocks: func(repo *mocks.OutboxRepositoryMock) {
+				repo.On("MarkConsumed", mock.Anything, int64(7), "webhooks").Return(nil)
+				repo.On("Fail", mock.Anything, int64(7), "tally: tally is down").Return(nil)
+			},
+			wantCalled: []string{"webhooks", "tally"},
+		},
+		{
+			name:     "consumed before are skipped",
+			consumed: []string{"webhooks"},
+			setupMocks: func(repo *mocks.OutboxRepositoryMock) {
+				repo.On("MarkConsumed", mock.Anything, int64(7), "tally").Return(nil)
+				repo.On("Complete", mock.Anything, int64(7)).Return(nil)
+			},
+			wantCalled: []string{"tally"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called []string
+			bus := NewBus()
+			for _, consumer := range []string{"webhooks", "tally"} {
+				Subscribe(bus, consumer, domain.EventVoteCast, func(ctx context.Context, ev domain.Event, data dto.VoteCastData) error {
+					called = append(called, consumer)
+					assert.Equal(t, "evt_1", ev.ID)
+					assert.Equal(t, int64(2), data.OptionID)
+					if consumer == tt.failing {
+						return errors.New(consumer + " is down")
+					}
+					return nil
+				})
+			}
+
+			ev := recorded
+			ev.Consumed = tt.consumed
+			if tt.lastAttempt {
+				ev.Attempts = conf.MaxAttempts - 1
+			}
+			repo := new(mocks.OutboxRepositoryMock)
+			repo.On("ClaimDue", mock.Anything, conf.BatchSize, mock.AnythingOfType("time.Duration")).
+				Return([]models.OutboxEvent{ev}, nil)
+			tt.setupMocks(repo)
+
+			d := NewDispatcher(repo, bus, conf)
+			d.now = func() time.Time { return now }
+
+			n, err := d.Dispatch(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, 1, n)
+			assert.Equal(t, tt.wantCalled, called)
+			repo.AssertExpectations(t)
+			if tt.failing != "" {
+				repo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything)
+			}
+			if !tt.lastAttempt {
+				repo.AssertNotCalled(t, "Fail", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestDispatcher_DispatchInOrder(t *testing.T) {
+	var order []string
+	bus := NewBus()
+	bus.Handle("log", domain.EventPollUpdated, func(ctx context.Context, ev domain.Event) error {
+		order = append(order, ev.ID)
+		return nil
+	})
+
+	repo := new(mocks.OutboxRepositoryMock)
+	repo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return([]models.OutboxEvent{
+		{ID: 1, EventID: "evt_1", Name: domain.EventPollUpdated},
+		{ID: 2, EventID: "evt_2", Name: domain.EventPollUpdated},
+		// Nobody subscribed; it is completed all the same.
+		{ID: 3, EventID: "evt_3", Name: domain.EventPollCreated},
+	}, nil)
+	repo.On("MarkConsumed", mock.Anything, mock.Anything, "log").Return(nil)
+	repo.On("Complete", mock.Anything, mock.Anything).Return(nil)
+
+	n, err := NewDispatcher(repo, bus, config.Default().Outbox).Dispatch(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []string{"evt_1", "evt_2"}, order)
+	repo.AssertNumberOfCalls(t, "Complete", 3)
+}
+
+func TestDispatcher_ClaimFails(t *testing.T) {
+	repo := new(mocks.OutboxRepositoryMock)
+	repo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+
+	n, err := NewDispatcher(repo, NewBus(), config.Default().Outbox).Dispatch(context.Background())
+
+	assert.Error(t, err)
+	assert.Zero(t, n)
+}
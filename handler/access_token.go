@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/response"
+	"net/http"
+)
+
+type AccessTokenHandler struct {
+	Service domain.AccessTokenService
+}
+
+func NewAccessTokenHandler(service domain.AccessTokenService) *AccessTokenHandler {
+	return &AccessTokenHandler{Service: service}
+}
+
+// Create Access Token godoc
+// @Summary      create personal access token
+// @Description  Creates a named, scoped personal access token for automation. The token value is only returned once.
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Security BearerAuth
+// @Param        request  body     dto.CreateAccessTokenRequest  true "Token payload"
+// @Success      201      {object}  response.Envelope{data=dto.AccessTokenCreatedResponse}
+// @Router       /users/me/tokens [post]
+func (h *AccessTokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	var req dto.CreateAccessTokenRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if errs, err := helper.BindAndValidate(&req); err != nil {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	resp, err := h.Service.Create(r.Context(), auth.UserID, &req)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, "created token successfully", resp)
+}
+
+// List Access Tokens godoc
+// @Summary      list personal access tokens
+// @Description  Lists the personal access tokens of the logged-in user without their secret values.
+// @Tags         User
+// @Produce      json
+// @Security BearerAuth
+// @Success      200      {object}  response.Envelope{data=[]dto.AccessTokenResponse}
+// @Router       /users/me/tokens [get]
+func (h *AccessTokenHandler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	resp, err := h.Service.List(r.Context(), auth.UserID)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "get tokens successfully", resp)
+}
+
+// Revoke Access Token godoc
+// @Summary      revoke personal access token
+// @Description  Deletes a personal access token so it can no longer be used.
+// @Tags         User
+// @Produce      json
+// @Security BearerAuth
+// @Param id path int true "Token ID"
+// @Success      200      {object}  response.Envelope "Success message"
+// @Router       /users/me/tokens/{id} [delete]
+func (h *AccessTokenHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	id, ok := pathID(w, r, "token")
+	if !ok {
+		return
+	}
+
+	if err := h.Service.Revoke(r.Context(), auth.UserID, id); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "revoked token successfully", nil)
+}
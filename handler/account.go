@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/response"
+	"net/http"
+)
+
+type AccountHandler struct {
+	Service domain.AccountService
+}
+
+func NewAccountHandler(service domain.AccountService) *AccountHandler {
+	return &AccountHandler{Service: service}
+}
+
+// Export Account godoc
+// @Summary      export personal data
+// @Description  Returns everything stored about the logged-in user: profile, created polls with options and results, votes, sessions, access tokens, linked SSO identities, login history and webhooks (without secrets). Use format=zip for an archive with one JSON file per section.
+// @Tags         User
+// @Produce      json
+// @Produce      application/zip
+// @Security BearerAuth
+// @Param        format  query  string  false  "json (default) or zip"
+// @Success      200      {object}  dto.AccountExport
+// @Router       /users/me/export [get]
+func (h *AccountHandler) Export(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "zip" {
+		response.WriteProblem(w, r, helper.CodeBadRequest, "format must be json or zip")
+		return
+	}
+
+	export, err := h.Service.Export(r.Context(), auth.UserID)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("account-%d-%s", auth.UserID, export.ExportedAt.Format("20060102"))
+
+	if format == "zip" {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.zip"`)
+		w.WriteHeader(http.StatusOK)
+		_ = writeExportZip(w, export)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.json"`)
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(export)
+}
+
+func writeExportZip(w http.ResponseWriter, export *dto.AccountExport) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"polls.json", export.Polls},
+		{"votes.json", export.Votes},
+		{"sessions.json", export.Sessions},
+		{"access_tokens.json", export.AccessTokens},
+		{"identities.json", export.Identities},
+		{"login_attempts.json", export.LoginAttempts},
+		{"webhooks.json", export.Webhooks},
+	}
+
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// Delete Account godoc
+// @Summary      delete account
+// @Description  Permanently removes the logged-in user's personal data after password confirmation. SSO-only accounts confirm with their email and must have signed in within the last 5 minutes, otherwise REAUTH_REQUIRED is returned. Votes on other people's polls keep counting but are no longer linked to the account. The user's own polls are kept under an anonymous "Deleted user" owner unless delete_polls is true, which deletes them together with every vote on them.
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Security BearerAuth
+// @Param        request  body     dto.DeleteAccountRequest  true "Password confirmation"
+// @Success      200      {object}  response.Envelope "Success message"
+// @Router       /users/me [delete]
+func (h *AccountHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	var req dto.DeleteAccountRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if errs, err := helper.BindAndValidate(&req); err != nil {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	if err := h.Service.Delete(r.Context(), auth.UserID, auth.SessionID, &req); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "deleted account successfully", nil)
+}
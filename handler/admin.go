@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"native-free-pollings/authz"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/response"
+	"net/http"
+)
+
+type AdminHandler struct {
+	Service domain.AdminService
+}
+
+func NewAdminHandler(service domain.AdminService) *AdminHandler {
+	return &AdminHandler{Service: service}
+}
+
+// List Users godoc
+// @Summary      list users
+// @Description  Lists every account with its role and status. Requires the admin role.
+// @Tags         Admin
+// @Produce      json
+// @Security BearerAuth
+// @Success      200      {object}  response.Envelope{data=[]dto.AdminUserResponse}
+// @Router       /admin/users [get]
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.Service.ListUsers(r.Context())
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "get users successfully", resp)
+}
+
+// Update User godoc
+// @Summary      update user role or status
+// @Description  Changes the role of an account and/or disables it. Disabled accounts cannot log in and their access tokens stop working. Requires the admin role.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param        request  body     dto.AdminUpdateUserRequest  true "Fields to change"
+// @Success      200      {object}  response.Envelope{data=dto.AdminUserResponse}
+// @Router       /admin/users/{id} [patch]
+func (h *AdminHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+
+	id, ok := pathID(w, r, "user")
+	if !ok {
+		return
+	}
+
+	var req dto.AdminUpdateUserRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if errs, err := helper.BindAndValidate(&req); err != nil {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	resp, err := h.Service.UpdateUser(r.Context(), authz.SubjectFromAuth(auth), id, &req)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "updated user successfully", resp)
+}
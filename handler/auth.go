@@ -1,10 +1,10 @@
 package handler
 
 import (
-	"encoding/json"
 	"native-free-pollings/domain"
 	"native-free-pollings/dto"
 	"native-free-pollings/helper"
+	"native-free-pollings/response"
 	"net/http"
 )
 
@@ -23,50 +23,64 @@ func NewAuthHandler(service domain.AuthService) *AuthHandler {
 // @Accept       json
 // @Produce      json
 // @Param        request  body      dto.LoginRequest  true  "Login credentials"
-// @Success      201      {object}  dto.LoginResponse
+// @Success      201      {object}  response.Envelope{data=dto.LoginResponse}
+// @Failure      429      {object}  response.Problem "Too many failed attempts"
 // @Router       /login [post]
 func (a *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "NOT_ALLOWED",
-			"message": "method not allowed",
-		})
+	var req dto.LoginRequest
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
-	var req dto.LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_REQUEST",
-			"message": "invalid request payload",
-		})
+	if errs, err := helper.BindAndValidate(&req); err != nil {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	req.IP = helper.ClientIP(r)
+	req.UserAgent = r.UserAgent()
+
+	resp, err := a.Service.Login(r.Context(), &req)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, "login successfully", resp)
+}
+
+// Login Two Factor godoc
+// @Summary      Complete two-factor login
+// @Description  Exchanges the challenge token returned by /login and a TOTP or recovery code for a JWT token.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.LoginTwoFactorRequest  true  "Challenge token and code"
+// @Success      201      {object}  response.Envelope{data=dto.LoginResponse}
+// @Failure      400      {object}  response.Problem "INVALID_VERIFICATION_CODE for a wrong code"
+// @Failure      429      {object}  response.Problem "Too many failed attempts"
+// @Router       /login/2fa [post]
+func (a *AuthHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req dto.LoginTwoFactorRequest
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	if errs, err := helper.BindAndValidate(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]any{
-			"code":    "VALIDATION_ERROR",
-			"message": "payload validation failed",
-			"details": errs,
-		})
+		response.ValidationError(w, r, errs)
 		return
 	}
 
-	resp, err := a.Service.Login(r.Context(), &req)
+	req.IP = helper.ClientIP(r)
+	req.UserAgent = r.UserAgent()
+
+	resp, err := a.Service.LoginTwoFactor(r.Context(), &req)
 	if err != nil {
-		err.(*helper.AppError).WriteError(w)
+		response.Error(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(resp)
+	response.JSON(w, http.StatusCreated, "login successfully", resp)
 }
 
 // Register godoc
@@ -76,48 +90,57 @@ func (a *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 // @Accept       json
 // @Produce      json
 // @Param        request  body      dto.RegisterRequest  true  "Register credentials"
-// @Success      201      {object}  dto.RegisterResponse
+// @Success      201      {object}  response.Envelope{data=dto.RegisterResponse}
 // @Router       /register [post]
 func (a *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "NOT_ALLOWED",
-			"message": "method not allowed",
-		})
-		return
-	}
-
 	var req dto.RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_REQUEST",
-			"message": "invalid request payload",
-		})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	if errs, err := helper.BindAndValidate(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]any{
-			"code":    "VALIDATION_ERROR",
-			"message": "payload validation failed",
-			"details": errs,
-		})
+		response.ValidationError(w, r, errs)
 		return
 	}
 
 	resp, err := a.Service.Register(r.Context(), &req)
 	if err != nil {
-		err.(*helper.AppError).WriteError(w)
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, "registered successfully", resp)
+}
+
+// Unlock Account godoc
+// @Summary      unlock account
+// @Description  Clears the failed-login lockout of an account. Admin only.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security BearerAuth
+// @Param        request  body      dto.UnlockAccountRequest  true  "Account to unlock"
+// @Success      200      {object}  response.Envelope "Success message"
+// @Router       /admin/unlock [post]
+func (a *AuthHandler) UnlockAccount(w http.ResponseWriter, r *http.Request) {
+	if auth, ok := helper.GetAuthContext(r.Context()); ok && !requireSession(w, r, auth) {
+		return
+	}
+
+	var req dto.UnlockAccountRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if errs, err := helper.BindAndValidate(&req); err != nil {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	if err := a.Service.UnlockAccount(r.Context(), req.Email); err != nil {
+		response.Error(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(resp)
+	response.JSON(w, http.StatusOK, "account unlocked successfully", nil)
 }
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"native-free-pollings/dto"
+	"native-free-pollings/helper"
 	"native-free-pollings/mocks"
 	"net/http"
 	"net/http/httptest"
@@ -21,16 +22,6 @@ func TestHandlerRegister(t *testing.T) {
 		wantCode   int
 		wantBody   string
 	}{
-		{
-			name:   "method not allowed",
-			method: http.MethodGet,
-			body:   ``,
-			setupMocks: func(svc *mocks.AuthServiceMock) {
-				// tidak ada expectation
-			},
-			wantCode: http.StatusMethodNotAllowed,
-			wantBody: "method not allowed\n",
-		},
 		{
 			name:   "invalid request payload",
 			method: http.MethodPost,
@@ -39,7 +30,7 @@ func TestHandlerRegister(t *testing.T) {
 				// tidak ada expectation
 			},
 			wantCode: http.StatusBadRequest,
-			wantBody: "invalid request payload\n",
+			wantBody: `field \"password\" must be a string`,
 		},
 		{
 			name:   "service returns error",
@@ -50,10 +41,10 @@ func TestHandlerRegister(t *testing.T) {
 					Email: "a@mail.com",
 					Pass:  "123",
 					Name:  "John",
-				}).Return(nil, assert.AnError)
+				}).Return(nil, helper.NewAppError("BAD_REQUEST", assert.AnError.Error(), assert.AnError))
 			},
 			wantCode: http.StatusBadRequest,
-			wantBody: assert.AnError.Error() + "\n",
+			wantBody: assert.AnError.Error(),
 		},
 		{
 			name:   "success",
@@ -83,6 +74,7 @@ func TestHandlerRegister(t *testing.T) {
 
 			req := httptest.NewRequest(tt.method, "/register", strings.NewReader(tt.body))
 			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Content-Type", "application/json")
 			rr := httptest.NewRecorder()
 
 			h.Register(rr, req)
@@ -103,21 +95,13 @@ func TestHandlerLogin(t *testing.T) {
 		wantCode   int
 		wantBody   string
 	}{
-		{
-			name:       "method not allowed",
-			body:       ``,
-			method:     http.MethodGet,
-			setupMocks: func(svc *mocks.AuthServiceMock) {},
-			wantCode:   http.StatusMethodNotAllowed,
-			wantBody:   "method not allowed\n",
-		},
 		{
 			name:       "invalid request payload",
 			body:       ``,
 			method:     http.MethodPost,
 			setupMocks: func(svc *mocks.AuthServiceMock) {},
 			wantCode:   http.StatusBadRequest,
-			wantBody:   "invalid request payload\n",
+			wantBody:   "request body must not be empty",
 		},
 		{
 			name:   "service returns error",
@@ -127,10 +111,11 @@ func TestHandlerLogin(t *testing.T) {
 				svc.On("Login", mock.Anything, &dto.LoginRequest{
 					Email:    "a@mail.com",
 					Password: "123",
-				}).Return(nil, assert.AnError)
+					IP:       "192.0.2.1",
+				}).Return(nil, helper.NewAppError("BAD_REQUEST", assert.AnError.Error(), assert.AnError))
 			},
 			wantCode: http.StatusBadRequest,
-			wantBody: assert.AnError.Error() + "\n",
+			wantBody: assert.AnError.Error(),
 		},
 		{
 			name:   "success",
@@ -140,6 +125,7 @@ func TestHandlerLogin(t *testing.T) {
 				svc.On("Login", mock.Anything, &dto.LoginRequest{
 					Email:    "a@mail.com",
 					Password: "123",
+					IP:       "192.0.2.1",
 				}).Return(&dto.LoginResponse{
 					ID:    1,
 					Name:  "John",
@@ -160,6 +146,7 @@ func TestHandlerLogin(t *testing.T) {
 
 			req := httptest.NewRequest(tt.method, "/login", strings.NewReader(tt.body))
 			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Content-Type", "application/json")
 			rr := httptest.NewRecorder()
 
 			h.Login(rr, req)
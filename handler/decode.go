@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"native-free-pollings/helper"
+	"native-free-pollings/response"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// decodeJSON reads exactly one JSON value from the body into dst. Bodies
+// must be sent as application/json, may not contain fields dst does not
+// know and are capped by middleware.BodyLimit. On failure it answers the
+// request and returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		response.WriteProblem(w, r, helper.CodeUnsupportedMedia, "Content-Type must be application/json")
+		return false
+	}
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	err = dec.Decode(dst)
+	if err == nil && dec.Decode(&struct{}{}) != io.EOF {
+		err = errMultipleValues
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.WriteProblem(w, r, helper.CodePayloadTooLarge,
+				fmt.Sprintf("request body must not exceed %d bytes", tooLarge.Limit))
+			return false
+		}
+		response.WriteProblem(w, r, helper.CodeInvalidRequest, decodeErrorDetail(err))
+		return false
+	}
+	return true
+}
+
+var errMultipleValues = errors.New("request body must contain a single JSON value")
+
+// decodeErrorDetail explains a decode error in terms of the request body,
+// without the Go type names encoding/json puts in its messages.
+func decodeErrorDetail(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return fmt.Sprintf("request body must be %s (offset %d)", jsonKind(typeErr), typeErr.Offset)
+		}
+		return fmt.Sprintf("field %q must be %s (offset %d)", typeErr.Field, jsonKind(typeErr), typeErr.Offset)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "malformed JSON: unexpected end of body"
+	case errors.Is(err, io.EOF):
+		return "request body must not be empty"
+	case errors.Is(err, errMultipleValues):
+		return err.Error()
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for unknown fields.
+		return "unknown field " + strings.TrimPrefix(err.Error(), "json: unknown field ")
+	}
+	return "invalid request payload"
+}
+
+// jsonKind names the JSON type the target of typeErr accepts.
+func jsonKind(typeErr *json.UnmarshalTypeError) string {
+	switch typeErr.Type.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	type payload struct {
+		Title   string   `json:"title"`
+		Options []string `json:"options"`
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		limit       int64
+		wantOK      bool
+		wantCode    int
+		wantDetail  string
+	}{
+		{name: "valid", contentType: "application/json", body: `{"title":"a","options":["x"]}`, wantOK: true},
+		{name: "charset parameter", contentType: "application/json; charset=utf-8", body: `{"title":"a"}`, wantOK: true},
+		{name: "missing content type", body: `{"title":"a"}`, wantCode: http.StatusUnsupportedMediaType, wantDetail: "Content-Type must be application/json"},
+		{name: "wrong content type", contentType: "text/plain", body: `{"title":"a"}`, wantCode: http.StatusUnsupportedMediaType, wantDetail: "Content-Type must be application/json"},
+		{name: "too large", contentType: "application/json", body: `{"title":"` + strings.Repeat("a", 64) + `"}`, limit: 16, wantCode: http.StatusRequestEntityTooLarge, wantDetail: "request body must not exceed 16 bytes"},
+		{name: "unknown field", contentType: "application/json", body: `{"title":"a","status":"active"}`, wantCode: http.StatusBadRequest, wantDetail: `unknown field "status"`},
+		{name: "multiple values", contentType: "application/json", body: `{"title":"a"}{"title":"b"}`, wantCode: http.StatusBadRequest, wantDetail: "request body must contain a single JSON value"},
+		{name: "trailing garbage", contentType: "application/json", body: `{"title":"a"} x`, wantCode: http.StatusBadRequest, wantDetail: "request body must contain a single JSON value"},
+		{name: "syntax error", contentType: "application/json", body: `{"title":}`, wantCode: http.StatusBadRequest, wantDetail: "malformed JSON at offset 10"},
+		{name: "truncated", contentType: "application/json", body: `{"title":"a"`, wantCode: http.StatusBadRequest, wantDetail: "malformed JSON: unexpected end of body"},
+		{name: "wrong field type", contentType: "application/json", body: `{"options":"x"}`, wantCode: http.StatusBadRequest, wantDetail: `field "options" must be an array (offset 14)`},
+		{name: "wrong body type", contentType: "application/json", body: `[1]`, wantCode: http.StatusBadRequest, wantDetail: "request body must be an object (offset 1)"},
+		{name: "empty body", contentType: "application/json", body: ``, wantCode: http.StatusBadRequest, wantDetail: "request body must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/pollings", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rr := httptest.NewRecorder()
+			if tt.limit > 0 {
+				req.Body = http.MaxBytesReader(rr, req.Body, tt.limit)
+			}
+
+			var dst payload
+			ok := decodeJSON(rr, req, &dst)
+
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, "a", dst.Title)
+				return
+			}
+			assert.Equal(t, tt.wantCode, rr.Code)
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			assert.Equal(t, tt.wantDetail, body["detail"])
+		})
+	}
+}
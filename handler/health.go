@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"native-free-pollings/health"
+	"net/http"
+)
+
+type HealthHandler struct {
+	Registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{Registry: registry}
+}
+
+// Liveness godoc
+// @Summary      liveness probe
+// @Description  Answers 200 as long as the process is able to serve requests. It checks no dependencies, so a database outage does not get the instance restarted.
+// @Tags         Health
+// @Produce      json
+// @Success      200      {object}  map[string]string
+// @Router       /healthz [get]
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"status": health.StatusOK,
+	})
+}
+
+// Readiness godoc
+// @Summary      readiness probe
+// @Description  Runs every registered check (database ping, pending migrations, background workers) and reports each one. Answers 503 when any check fails.
+// @Tags         Health
+// @Produce      json
+// @Success      200      {object}  health.Report
+// @Failure      503      {object}  health.Report
+// @Router       /readyz [get]
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.Registry.Run(r.Context())
+
+	status := http.StatusOK
+	if !report.OK() {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
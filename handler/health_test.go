@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"native-free-pollings/health"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthHandler_Readiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		dbErr      error
+		wantStatus int
+		wantReport string
+	}{
+		{name: "ready", dbErr: nil, wantStatus: http.StatusOK, wantReport: health.StatusOK},
+		{name: "database down", dbErr: errors.New("connection refused"), wantStatus: http.StatusServiceUnavailable, wantReport: health.StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := health.NewRegistry(time.Second)
+			reg.Register("database", health.CheckerFunc(func(context.Context) error { return tt.dbErr }))
+			h := NewHealthHandler(reg)
+
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			rec := httptest.NewRecorder()
+			h.Readiness(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			var report health.Report
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+			assert.Equal(t, tt.wantReport, report.Status)
+			assert.Contains(t, report.Checks, "database")
+		})
+	}
+}
+
+func TestHealthHandler_Liveness(t *testing.T) {
+	h := NewHealthHandler(health.NewRegistry(time.Second))
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+	h.Liveness(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+}
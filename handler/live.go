@@ -0,0 +1,400 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"native-free-pollings/authz"
+	"native-free-pollings/config"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/middleware"
+	"native-free-pollings/realtime"
+	"native-free-pollings/response"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// liveMaxMessage caps a client message; commands are tiny.
+const liveMaxMessage = 4096
+
+type LiveHandler struct {
+	Service      domain.PollService
+	Sessions     *realtime.LiveSessions
+	Authenticate middleware.Authenticator
+	Conf         config.Live
+}
+
+func NewLiveHandler(svc domain.PollService, sessions *realtime.LiveSessions, authenticate middleware.Authenticator, conf config.Live) *LiveHandler {
+	return &LiveHandler{Service: svc, Sessions: sessions, Authenticate: authenticate, Conf: conf}
+}
+
+// liveCommand is a message a client sends on a live session connection.
+type liveCommand struct {
+	Type       string `json:"type"`
+	Token      string `json:"token"`
+	Code       string `json:"code"`
+	PollID     int64  `json:"poll_id"`
+	OptionID   int64  `json:"option_id"`
+	DeviceHash string `json:"device_hash"`
+}
+
+// Live Session godoc
+// @Summary      live poll session
+// @Description  Upgrades to a WebSocket carrying JSON messages. Send {"type":"auth","token":"..."} (or connect with an Authorization header) to identify yourself. A presenter sends "start" to open a session and gets its code, then "show" with poll_id, "open", "close" and "end". Participants send "join" with the code and "vote" with option_id and device_hash. The server sends "session", "state" (the current poll), "results", "voted", "ended" and "error" messages.
+// @Tags         Polling
+// @Param Authorization header string false "Bearer token"
+// @Success      101      "Switching protocols"
+// @Failure      400      {object}  response.Problem "Not a WebSocket handshake"
+// @Router       /live [get]
+func (h *LiveHandler) Live(w http.ResponseWriter, r *http.Request) {
+	ws, err := realtime.Upgrade(w, r, liveMaxMessage)
+	if err != nil {
+		if errors.Is(err, realtime.ErrBadHandshake) {
+			response.WriteProblem(w, r, helper.CodeBadRequest, err.Error())
+			return
+		}
+		slog.WarnContext(r.Context(), "Failed to upgrade live connection", "error", err)
+		return
+	}
+	ws.KeepAlive(2 * h.Conf.PingInterval)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	c := &liveConn{h: h, ws: ws}
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		c.token = strings.TrimPrefix(header, "Bearer ")
+	}
+	defer c.leave()
+
+	go c.keepAlive(ctx)
+
+	for {
+		data, err := ws.ReadMessage()
+		if err != nil {
+			// A peer that closed got its close frame answered already; this
+			// only releases the connection.
+			_ = ws.Close(realtime.CloseNormal, "")
+			return
+		}
+
+		var cmd liveCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			c.sendError(ctx, helper.NewAppError(helper.CodeInvalidRequest, "message must be a JSON command", err))
+			continue
+		}
+		if err := c.handle(ctx, cmd); err != nil {
+			c.sendError(ctx, err)
+		}
+	}
+}
+
+// liveConn is the state of one connection. Only the reading goroutine
+// touches token and member.
+type liveConn struct {
+	h      *LiveHandler
+	ws     *realtime.Conn
+	token  string
+	member *realtime.Member
+	sess   *realtime.LiveSession
+}
+
+func (c *liveConn) handle(ctx context.Context, cmd liveCommand) error {
+	switch cmd.Type {
+	case "auth":
+		c.token = cmd.Token
+		auth, err := c.identify(ctx)
+		if err != nil {
+			c.token = ""
+			return err
+		}
+		return c.send(realtime.LiveMessage{Type: "authenticated", Role: auth.Role})
+	case "start":
+		return c.start(ctx)
+	case "join":
+		return c.join(ctx, cmd.Code)
+	case "show":
+		return c.show(ctx, cmd.PollID)
+	case "open":
+		return c.setStatus(ctx, "active")
+	case "close":
+		return c.setStatus(ctx, "closed")
+	case "vote":
+		return c.vote(ctx, cmd.OptionID, cmd.DeviceHash)
+	case "end":
+		if _, err := c.presenter(ctx); err != nil {
+			return err
+		}
+		c.sess.End()
+		return nil
+	default:
+		return helper.NewAppError(helper.CodeInvalidRequest, "unknown message type "+cmd.Type, nil)
+	}
+}
+
+// identify checks the connection's token again, so an expired or revoked
+// login stops working on open connections too.
+func (c *liveConn) identify(ctx context.Context) (*helper.AuthContext, error) {
+	if c.token == "" {
+		return nil, helper.NewAppError(helper.CodeInvalidToken, "send an auth message first", nil)
+	}
+	return c.h.Authenticate(ctx, c.token)
+}
+
+func (c *liveConn) start(ctx context.Context) error {
+	auth, err := c.identify(ctx)
+	if err != nil {
+		return err
+	}
+	if !auth.HasScope(helper.ScopePollsWrite) {
+		return helper.NewAppError(helper.CodeInsufficientScope, "token is missing scope "+helper.ScopePollsWrite, nil)
+	}
+
+	sess, err := c.h.Sessions.Start(auth.UserID)
+	if err != nil {
+		return liveError(err)
+	}
+	return c.enter(ctx, sess, true)
+}
+
+func (c *liveConn) join(ctx context.Context, code string) error {
+	sess, err := c.h.Sessions.Find(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return liveError(err)
+	}
+
+	// The presenter may follow their own session from several devices,
+	// each of them able to control it.
+	presenter := false
+	if c.token != "" {
+		auth, err := c.identify(ctx)
+		if err != nil {
+			return err
+		}
+		presenter = auth.UserID == sess.PresenterID && auth.HasScope(helper.ScopePollsWrite)
+	}
+	return c.enter(ctx, sess, presenter)
+}
+
+// enter makes the connection a member of sess, leaving any session it was
+// in before.
+func (c *liveConn) enter(ctx context.Context, sess *realtime.LiveSession, presenter bool) error {
+	c.leave()
+
+	member, err := sess.Join(presenter)
+	if err != nil {
+		return liveError(err)
+	}
+	c.member, c.sess = member, sess
+
+	role := "participant"
+	if presenter {
+		role = "presenter"
+	}
+	if err := c.send(realtime.LiveMessage{Type: "session", Code: sess.Code, Role: role}); err != nil {
+		return err
+	}
+	go c.forward(ctx, member)
+	return nil
+}
+
+func (c *liveConn) leave() {
+	if c.member != nil {
+		c.member.Leave()
+		c.member, c.sess = nil, nil
+	}
+}
+
+// forward writes what the session broadcasts until the membership ends.
+func (c *liveConn) forward(ctx context.Context, m *realtime.Member) {
+	for {
+		select {
+		case msg := <-m.Messages():
+			if err := c.ws.WriteText(msg); err != nil {
+				return
+			}
+		case <-m.Done():
+			for {
+				select {
+				case msg := <-m.Messages():
+					if err := c.ws.WriteText(msg); err != nil {
+						return
+					}
+				default:
+					if errors.Is(m.Err(), realtime.ErrTooSlow) {
+						_ = c.ws.Close(realtime.CloseTryAgainLater, "too slow")
+					}
+					return
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// inSession reports whether the connection is still a member of a session;
+// it is not after the session ended or dropped it.
+func (c *liveConn) inSession() bool {
+	if c.member == nil {
+		return false
+	}
+	select {
+	case <-c.member.Done():
+		return false
+	default:
+		return true
+	}
+}
+
+// presenter returns the identity behind a presenter command.
+func (c *liveConn) presenter(ctx context.Context) (*helper.AuthContext, error) {
+	if !c.inSession() || !c.member.Presenter {
+		return nil, helper.NewAppError(helper.CodeForbidden, "only the presenter can control the session", nil)
+	}
+	return c.identify(ctx)
+}
+
+func (c *liveConn) show(ctx context.Context, pollID int64) error {
+	if _, err := c.presenter(ctx); err != nil {
+		return err
+	}
+	if pollID <= 0 {
+		return helper.NewAppError(helper.CodeInvalidID, "invalid id polling", nil)
+	}
+
+	poll, err := c.h.Service.GetDetailPolling(ctx, pollID)
+	if err != nil {
+		return err
+	}
+	if err := c.sess.Show(pollID, poll); err != nil {
+		return liveError(err)
+	}
+	return c.refreshResults(ctx, pollID)
+}
+
+// refreshResults sends the current tallies; later votes arrive through the
+// hub.
+func (c *liveConn) refreshResults(ctx context.Context, pollID int64) error {
+	results, err := c.h.Service.GetPollingResult(ctx, pollID)
+	if err != nil {
+		return err
+	}
+	ev, err := realtime.NewEvent(pollID, results)
+	if err != nil {
+		return err
+	}
+	c.sess.SetResults(ev)
+	return nil
+}
+
+// setStatus opens or closes voting on the poll on screen. The service
+// decides whether the presenter may change it, as for PATCH /pollings/{id}.
+func (c *liveConn) setStatus(ctx context.Context, status string) error {
+	auth, err := c.presenter(ctx)
+	if err != nil {
+		return err
+	}
+	pollID := c.sess.PollID()
+	if pollID == 0 {
+		return helper.NewAppError(helper.CodeInvalidInput, "show a polling first", nil)
+	}
+
+	poll, err := c.h.Service.UpdatePolling(ctx, &dto.UpdatePollingRequest{ID: pollID, Status: &status}, authz.SubjectFromAuth(auth))
+	if err != nil {
+		return err
+	}
+	if err := c.sess.Show(pollID, poll); err != nil {
+		return liveError(err)
+	}
+	return nil
+}
+
+func (c *liveConn) vote(ctx context.Context, optionID int64, deviceHash string) error {
+	if !c.inSession() {
+		return helper.NewAppError(helper.CodeInvalidInput, "join a session first", nil)
+	}
+	pollID := c.sess.PollID()
+	if pollID == 0 {
+		return helper.NewAppError(helper.CodeInvalidInput, "no polling is shown yet", nil)
+	}
+
+	// Voting without a token is anonymous, as on POST /pollings/{id}/votes.
+	var userID int64
+	if c.token != "" {
+		auth, err := c.identify(ctx)
+		if err != nil {
+			return err
+		}
+		if !auth.HasScope(helper.ScopeVotesWrite) {
+			return helper.NewAppError(helper.CodeInsufficientScope, "token is missing scope "+helper.ScopeVotesWrite, nil)
+		}
+		userID = auth.UserID
+	}
+
+	if err := c.h.Service.VoteOptionPolling(ctx, userID, pollID, optionID, deviceHash); err != nil {
+		return err
+	}
+	return c.send(realtime.LiveMessage{Type: "voted", OptionID: optionID})
+}
+
+func (c *liveConn) send(msg realtime.LiveMessage) error {
+	if err := c.ws.WriteText(msg.Encode()); err != nil {
+		return errConnGone
+	}
+	return nil
+}
+
+// errConnGone means the reply could not be written; the read loop notices
+// the broken connection on its own.
+var errConnGone = errors.New("live connection gone")
+
+func (c *liveConn) sendError(ctx context.Context, err error) {
+	if errors.Is(err, errConnGone) {
+		return
+	}
+	code, detail := response.Describe(err)
+	if response.Status(code) >= http.StatusInternalServerError {
+		slog.ErrorContext(ctx, "Live session command failed", "error", err)
+	}
+	_ = c.send(realtime.LiveMessage{Type: "error", ErrorCode: string(code), Detail: detail})
+}
+
+// keepAlive pings the client until the connection ends or the server shuts
+// down.
+func (c *liveConn) keepAlive(ctx context.Context) {
+	ticker := time.NewTicker(c.h.Conf.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.h.Sessions.Done():
+			_ = c.ws.Close(realtime.CloseGoingAway, "server shutting down")
+			return
+		case <-ticker.C:
+			if err := c.ws.Ping(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// liveError gives the session errors their API codes.
+func liveError(err error) error {
+	switch {
+	case errors.Is(err, realtime.ErrSessionNotFound):
+		return helper.NewAppError(helper.CodeNotFound, "live session not found", err)
+	case errors.Is(err, realtime.ErrSessionFull):
+		return helper.NewAppError(helper.CodeSessionFull, err.Error(), err)
+	case errors.Is(err, realtime.ErrTooManyStreams):
+		return helper.NewAppError(helper.CodeTooManyStreams, err.Error(), err)
+	default:
+		return err
+	}
+}
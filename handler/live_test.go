@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"native-free-pollings/config"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/mocks"
+	"native-free-pollings/realtime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func fakeAuthenticator(ctx context.Context, token string) (*helper.AuthContext, error) {
+	switch token {
+	case "presenter":
+		return &helper.AuthContext{UserID: 1, SessionID: 1, Role: "user"}, nil
+	case "voter":
+		return &helper.AuthContext{UserID: 2, SessionID: 2, Role: "user"}, nil
+	case "read-only":
+		return &helper.AuthContext{UserID: 1, TokenID: 9, Scopes: []string{helper.ScopePollsRead}}, nil
+	default:
+		return nil, helper.NewAppError(helper.CodeInvalidToken, "invalid token", nil)
+	}
+}
+
+func newLiveServer(t *testing.T, svc *mocks.PollServiceMock) *httptest.Server {
+	sessions := realtime.NewLiveSessions(realtime.NewHub(10, 10), 10, time.Minute)
+	t.Cleanup(sessions.Close)
+	h := NewLiveHandler(svc, sessions, fakeAuthenticator, config.Live{PingInterval: time.Minute})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /live", h.Live)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dialLive(t *testing.T, srv *httptest.Server) *websocket.Conn {
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/live", "", srv.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { ws.Close() })
+	require.NoError(t, ws.SetDeadline(time.Now().Add(5*time.Second)))
+	return ws
+}
+
+func sendLive(t *testing.T, ws *websocket.Conn, msg string) {
+	t.Helper()
+	require.NoError(t, websocket.Message.Send(ws, msg))
+}
+
+func readLive(t *testing.T, ws *websocket.Conn) realtime.LiveMessage {
+	t.Helper()
+	var data string
+	require.NoError(t, websocket.Message.Receive(ws, &data))
+	var msg realtime.LiveMessage
+	require.NoError(t, json.Unmarshal([]byte(data), &msg))
+	return msg
+}
+
+func TestLive_Session(t *testing.T) {
+	poll := &dto.PollingResponse{ID: 5, Title: "Languages", Status: "draft", Version: 1}
+	opened := &dto.PollingResponse{ID: 5, Title: "Languages", Status: "active", Version: 2}
+	results := &dto.ResultPolling{PollID: 5, TotalVotes: 0}
+
+	svc := new(mocks.PollServiceMock)
+	svc.On("GetDetailPolling", mock.Anything, int64(5)).Return(poll, nil)
+	svc.On("GetPollingResult", mock.Anything, int64(5)).Return(results, nil)
+	svc.On("UpdatePolling", mock.Anything, mock.MatchedBy(func(rq *dto.UpdatePollingRequest) bool {
+		return rq.ID == 5 && rq.Status != nil && *rq.Status == "active"
+	}), mock.Anything).Return(opened, nil)
+	svc.On("VoteOptionPolling", mock.Anything, int64(2), int64(5), int64(11), "device").Return(nil)
+	srv := newLiveServer(t, svc)
+
+	presenter := dialLive(t, srv)
+	sendLive(t, presenter, `{"type":"auth","token":"presenter"}`)
+	assert.Equal(t, "authenticated", readLive(t, presenter).Type)
+	sendLive(t, presenter, `{"type":"start"}`)
+	session := readLive(t, presenter)
+	assert.Equal(t, "session", session.Type)
+	assert.Equal(t, "presenter", session.Role)
+
+	voter := dialLive(t, srv)
+	sendLive(t, voter, `{"type":"auth","token":"voter"}`)
+	readLive(t, voter)
+	sendLive(t, voter, `{"type":"join","code":"`+strings.ToLower(session.Code)+`"}`)
+	joined := readLive(t, voter)
+	assert.Equal(t, "participant", joined.Role)
+	assert.Equal(t, session.Code, joined.Code)
+
+	sendLive(t, presenter, `{"type":"show","poll_id":5}`)
+	for _, ws := range []*websocket.Conn{presenter, voter} {
+		state := readLive(t, ws)
+		assert.Equal(t, "state", state.Type)
+		assert.Equal(t, "draft", state.Poll.(map[string]any)["status"])
+		assert.Equal(t, "results", readLive(t, ws).Type)
+	}
+
+	sendLive(t, voter, `{"type":"open"}`)
+	denied := readLive(t, voter)
+	assert.Equal(t, "error", denied.Type)
+	assert.Equal(t, string(helper.CodeForbidden), denied.ErrorCode)
+
+	sendLive(t, presenter, `{"type":"open"}`)
+	for _, ws := range []*websocket.Conn{presenter, voter} {
+		assert.Equal(t, "active", readLive(t, ws).Poll.(map[string]any)["status"])
+	}
+
+	sendLive(t, voter, `{"type":"vote","option_id":11,"device_hash":"device"}`)
+	voted := readLive(t, voter)
+	assert.Equal(t, "voted", voted.Type)
+	assert.Equal(t, int64(11), voted.OptionID)
+
+	sendLive(t, presenter, `{"type":"end"}`)
+	assert.Equal(t, "ended", readLive(t, presenter).Type)
+	assert.Equal(t, "ended", readLive(t, voter).Type)
+
+	sendLive(t, voter, `{"type":"vote","option_id":11,"device_hash":"device"}`)
+	assert.Equal(t, "join a session first", readLive(t, voter).Detail)
+	svc.AssertExpectations(t)
+}
+
+func TestLive_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		messages  []string
+		setup     func(svc *mocks.PollServiceMock)
+		wantCode  helper.ErrorCode
+		wantError string
+	}{
+		{
+			name:      "not JSON",
+			messages:  []string{`hello`},
+			wantCode:  helper.CodeInvalidRequest,
+			wantError: "message must be a JSON command",
+		},
+		{
+			name:      "unknown type",
+			messages:  []string{`{"type":"dance"}`},
+			wantCode:  helper.CodeInvalidRequest,
+			wantError: "unknown message type dance",
+		},
+		{
+			name:      "bad token",
+			messages:  []string{`{"type":"auth","token":"forged"}`},
+			wantCode:  helper.CodeInvalidToken,
+			wantError: "invalid token",
+		},
+		{
+			name:      "start without auth",
+			messages:  []string{`{"type":"start"}`},
+			wantCode:  helper.CodeInvalidToken,
+			wantError: "send an auth message first",
+		},
+		{
+			name:      "start without polls:write",
+			messages:  []string{`{"type":"auth","token":"read-only"}`, `{"type":"start"}`},
+			wantCode:  helper.CodeInsufficientScope,
+			wantError: "token is missing scope polls:write",
+		},
+		{
+			name:      "unknown session",
+			messages:  []string{`{"type":"join","code":"AAAAAAAA"}`},
+			wantCode:  helper.CodeNotFound,
+			wantError: "live session not found",
+		},
+		{
+			name:     "polling not found",
+			messages: []string{`{"type":"auth","token":"presenter"}`, `{"type":"start"}`, `{"type":"show","poll_id":9}`},
+			setup: func(svc *mocks.PollServiceMock) {
+				svc.On("GetDetailPolling", mock.Anything, int64(9)).Return(nil, domain.ErrPollNotFound)
+			},
+			wantCode:  helper.CodeNotFound,
+			wantError: "polling not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(mocks.PollServiceMock)
+			if tt.setup != nil {
+				tt.setup(svc)
+			}
+			ws := dialLive(t, newLiveServer(t, svc))
+
+			var last realtime.LiveMessage
+			for _, msg := range tt.messages {
+				sendLive(t, ws, msg)
+				last = readLive(t, ws)
+			}
+
+			assert.Equal(t, "error", last.Type)
+			assert.Equal(t, string(tt.wantCode), last.ErrorCode)
+			assert.Equal(t, tt.wantError, last.Detail)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLive_NotWebSocket(t *testing.T) {
+	srv := newLiveServer(t, new(mocks.PollServiceMock))
+
+	resp, err := http.Get(srv.URL + "/live")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
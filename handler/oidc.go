@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/oidc"
+	"native-free-pollings/response"
+	"net/http"
+	"time"
+)
+
+const (
+	oidcFlowCookie = "oidc_flow"
+	oidcFlowTTL    = 10 * time.Minute
+)
+
+type OIDCHandler struct {
+	Service  domain.AuthService
+	Provider domain.OIDCProvider
+	JwtKey   []byte
+}
+
+func NewOIDCHandler(service domain.AuthService, provider domain.OIDCProvider, jwtKey []byte) *OIDCHandler {
+	return &OIDCHandler{Service: service, Provider: provider, JwtKey: jwtKey}
+}
+
+// OIDC Login godoc
+// @Summary      Start SSO login
+// @Description  Redirects the browser to the OpenID Connect provider. State, nonce and the PKCE verifier are kept in a short-lived signed cookie.
+// @Tags         Auth
+// @Success      302
+// @Router       /auth/oidc/login [get]
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var flow helper.OIDCFlow
+	var err error
+	for _, v := range []*string{&flow.State, &flow.Nonce, &flow.Verifier} {
+		if *v, err = oidc.RandomString(); err != nil {
+			response.Error(w, r, helper.NewAppError(helper.CodeInternalError, "failed to start login", err))
+			return
+		}
+	}
+
+	sealed, err := helper.SealOIDCFlow(flow, time.Now().Add(oidcFlowTTL), h.JwtKey)
+	if err != nil {
+		response.Error(w, r, helper.NewAppError(helper.CodeTokenFailed, "failed to start login", err))
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcFlowCookie,
+		Value:    sealed,
+		Path:     "/auth/oidc",
+		MaxAge:   int(oidcFlowTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, h.Provider.AuthCodeURL(flow.State, flow.Nonce, flow.Verifier), http.StatusFound)
+}
+
+// OIDC Callback godoc
+// @Summary      Finish SSO login
+// @Description  Handles the provider redirect, verifies the ID token and returns the same JWT token as /login. Accounts are linked by verified email or created on first login.
+// @Tags         Auth
+// @Produce      json
+// @Param        code   query  string  true  "Authorization code"
+// @Param        state  query  string  true  "State"
+// @Success      201      {object}  response.Envelope{data=dto.LoginResponse}
+// @Failure      401      {object}  response.Problem "Login failed"
+// @Router       /auth/oidc/callback [get]
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	// The flow cookie is single use whatever the outcome.
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcFlowCookie,
+		Path:     "/auth/oidc",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	query := r.URL.Query()
+	if e := query.Get("error"); e != "" {
+		response.Error(w, r, helper.NewAppError(helper.CodeAuthFailed, "identity provider returned "+e, nil))
+		return
+	}
+
+	cookie, err := r.Cookie(oidcFlowCookie)
+	if err != nil {
+		response.Error(w, r, helper.NewAppError(helper.CodeAuthFailed, "login session expired, start again", err))
+		return
+	}
+
+	flow, err := helper.OpenOIDCFlow(cookie.Value, h.JwtKey)
+	if err != nil {
+		response.Error(w, r, helper.NewAppError(helper.CodeAuthFailed, "login session expired, start again", err))
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(flow.State)) != 1 {
+		response.Error(w, r, helper.NewAppError(helper.CodeAuthFailed, "invalid state", nil))
+		return
+	}
+
+	identity, err := h.Provider.Exchange(r.Context(), query.Get("code"), flow.Verifier, flow.Nonce)
+	if err != nil {
+		response.Error(w, r, helper.NewAppError(helper.CodeAuthFailed, "failed to verify identity", err))
+		return
+	}
+
+	resp, err := h.Service.LoginOIDC(r.Context(), identity, dto.ClientInfo{IP: helper.ClientIP(r), UserAgent: r.UserAgent()})
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, "login successfully", resp)
+}
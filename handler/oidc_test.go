@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"native-free-pollings/dto"
+	"native-free-pollings/mocks"
+	"native-free-pollings/oidc"
+	"native-free-pollings/oidc/oidctest"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOIDCHandler_Flow(t *testing.T) {
+	provider := oidctest.NewProvider("polling-app")
+	defer provider.Close()
+
+	client, err := oidc.NewClient(context.Background(), oidc.Config{
+		Issuer:      provider.Issuer,
+		ClientID:    provider.ClientID,
+		RedirectURL: "http://app.test/auth/oidc/callback",
+	}, nil)
+	assert.NoError(t, err)
+
+	svc := new(mocks.AuthServiceMock)
+	svc.On("LoginOIDC", mock.Anything, &oidc.Identity{
+		Issuer:        provider.Issuer,
+		Subject:       "user-1",
+		Email:         "user@example.com",
+		EmailVerified: true,
+		Name:          "Test User",
+	}, mock.AnythingOfType("dto.ClientInfo")).Return(&dto.LoginResponse{ID: 1, Name: "Test User", Token: "jwt"}, nil)
+
+	h := NewOIDCHandler(svc, client, []byte("test-secret"))
+
+	// Step 1: the app redirects to the provider and sets the flow cookie.
+	rec := httptest.NewRecorder()
+	h.Login(rec, httptest.NewRequest(http.MethodGet, "/auth/oidc/login", nil))
+	assert.Equal(t, http.StatusFound, rec.Code)
+	cookies := rec.Result().Cookies()
+	assert.Len(t, cookies, 1)
+
+	// Step 2: the provider approves and redirects back with code and state.
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := noRedirect.Get(rec.Header().Get("Location"))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	callback, _ := url.Parse(resp.Header.Get("Location"))
+
+	tests := []struct {
+		name     string
+		query    string
+		cookie   bool
+		wantCode int
+	}{
+		{name: "missing cookie", query: callback.RawQuery, cookie: false, wantCode: http.StatusUnauthorized},
+		{name: "state mismatch", query: "code=" + callback.Query().Get("code") + "&state=forged", cookie: true, wantCode: http.StatusUnauthorized},
+		{name: "provider error", query: "error=access_denied", cookie: true, wantCode: http.StatusUnauthorized},
+		{name: "success", query: callback.RawQuery, cookie: true, wantCode: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/auth/oidc/callback?"+tt.query, nil)
+			if tt.cookie {
+				req.AddCookie(cookies[0])
+			}
+			rec := httptest.NewRecorder()
+
+			h.Callback(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+
+	svc.AssertExpectations(t)
+}
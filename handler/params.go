@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"native-free-pollings/helper"
+	"native-free-pollings/response"
+	"net/http"
+	"strconv"
+)
+
+// pathID parses the numeric {id} wildcard of the matched route. When it is
+// not a number it answers 400 and returns false; resource names the kind of
+// ID in the error message.
+func pathID(w http.ResponseWriter, r *http.Request, resource string) (int64, bool) {
+	return pathValueID(w, r, "id", resource)
+}
+
+// pathValueID is pathID for a wildcard with another name, such as
+// {optionID} in routes that carry two IDs.
+func pathValueID(w http.ResponseWriter, r *http.Request, name, resource string) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil {
+		response.WriteProblem(w, r, helper.CodeInvalidID, "invalid id "+resource)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"native-free-pollings/authz"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/response"
+	"net/http"
+	"strconv"
+)
+
+// Add Option godoc
+// @Summary      add option
+// @Description  Adds an option after the last one. Only the creator, a moderator or an admin can change options.
+// @Tags         Polling
+// @Accept       json
+// @Produce      json
+// @Security BearerAuth
+// @Param id path int true "Poll ID"
+// @Param If-Match header string false "ETag of the poll being changed"
+// @Param        request  body     dto.CreateOptionRequest  true "Option payload"
+// @Success      201      {object}  response.Envelope{data=dto.Option}
+// @Failure      412      {object}  response.Problem
+// @Failure      428      {object}  response.Problem
+// @Router       /pollings/{id}/options [post]
+func (p *Polling) AddOption(w http.ResponseWriter, r *http.Request) {
+	auth, ok := optionWriter(w, r)
+	if !ok {
+		return
+	}
+
+	pollID, ok := pathID(w, r, "polling")
+	if !ok {
+		return
+	}
+	version, ok := p.ifMatchVersion(w, r, pollID)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateOptionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if errs, err := helper.BindAndValidate(&req); err != nil {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	resp, newVersion, err := p.Service.AddOption(r.Context(), pollID, version, &req, authz.SubjectFromAuth(auth))
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", pollETag(newVersion))
+
+	response.JSON(w, http.StatusCreated, "added option successfully", resp)
+}
+
+// Rename Option godoc
+// @Summary      rename option
+// @Description  Changes the label of an option. Its votes are kept.
+// @Tags         Polling
+// @Accept       json
+// @Produce      json
+// @Security BearerAuth
+// @Param id path int true "Poll ID"
+// @Param optionID path int true "Option ID"
+// @Param If-Match header string false "ETag of the poll being changed"
+// @Param        request  body     dto.RenameOptionRequest  true "New label"
+// @Success      200      {object}  response.Envelope{data=dto.Option}
+// @Failure      412      {object}  response.Problem
+// @Failure      428      {object}  response.Problem
+// @Router       /pollings/{id}/options/{optionID} [patch]
+func (p *Polling) RenameOption(w http.ResponseWriter, r *http.Request) {
+	auth, ok := optionWriter(w, r)
+	if !ok {
+		return
+	}
+
+	pollID, ok := pathID(w, r, "polling")
+	if !ok {
+		return
+	}
+	version, ok := p.ifMatchVersion(w, r, pollID)
+	if !ok {
+		return
+	}
+	optionID, ok := pathValueID(w, r, "optionID", "option")
+	if !ok {
+		return
+	}
+
+	var req dto.RenameOptionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if errs, err := helper.BindAndValidate(&req); err != nil {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	resp, newVersion, err := p.Service.RenameOption(r.Context(), pollID, version, optionID, &req, authz.SubjectFromAuth(auth))
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", pollETag(newVersion))
+
+	response.JSON(w, http.StatusOK, "renamed option successfully", resp)
+}
+
+// Reorder Options godoc
+// @Summary      reorder options
+// @Description  Sets the order of all options of a poll.
+// @Tags         Polling
+// @Accept       json
+// @Produce      json
+// @Security BearerAuth
+// @Param id path int true "Poll ID"
+// @Param If-Match header string false "ETag of the poll being changed"
+// @Param        request  body     dto.ReorderOptionsRequest  true "Every option ID in the new order"
+// @Success      200      {object}  response.Envelope{data=[]dto.Option}
+// @Failure      412      {object}  response.Problem
+// @Failure      428      {object}  response.Problem
+// @Router       /pollings/{id}/options/order [put]
+func (p *Polling) ReorderOptions(w http.ResponseWriter, r *http.Request) {
+	auth, ok := optionWriter(w, r)
+	if !ok {
+		return
+	}
+
+	pollID, ok := pathID(w, r, "polling")
+	if !ok {
+		return
+	}
+	version, ok := p.ifMatchVersion(w, r, pollID)
+	if !ok {
+		return
+	}
+
+	var req dto.ReorderOptionsRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if errs, err := helper.BindAndValidate(&req); err != nil {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	resp, newVersion, err := p.Service.ReorderOptions(r.Context(), pollID, version, &req, authz.SubjectFromAuth(auth))
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", pollETag(newVersion))
+
+	response.JSON(w, http.StatusOK, "reordered options successfully", resp)
+}
+
+// Delete Option godoc
+// @Summary      delete option
+// @Description  Removes an option. An option that has votes is only removed, together with its votes, when force is true.
+// @Tags         Polling
+// @Accept       json
+// @Produce      json
+// @Security BearerAuth
+// @Param id path int true "Poll ID"
+// @Param optionID path int true "Option ID"
+// @Param force query bool false "Also delete the option's votes"
+// @Param If-Match header string false "ETag of the poll being changed"
+// @Success      200      {object}  response.Envelope "Success message"
+// @Failure      409      {object}  response.Problem "Option has votes"
+// @Failure      412      {object}  response.Problem
+// @Failure      428      {object}  response.Problem
+// @Router       /pollings/{id}/options/{optionID} [delete]
+func (p *Polling) DeleteOption(w http.ResponseWriter, r *http.Request) {
+	auth, ok := optionWriter(w, r)
+	if !ok {
+		return
+	}
+
+	pollID, ok := pathID(w, r, "polling")
+	if !ok {
+		return
+	}
+	version, ok := p.ifMatchVersion(w, r, pollID)
+	if !ok {
+		return
+	}
+	optionID, ok := pathValueID(w, r, "optionID", "option")
+	if !ok {
+		return
+	}
+
+	force := false
+	if raw := r.URL.Query().Get("force"); raw != "" {
+		var err error
+		if force, err = strconv.ParseBool(raw); err != nil {
+			response.WriteProblem(w, r, helper.CodeInvalidInput, "force must be true or false")
+			return
+		}
+	}
+
+	newVersion, err := p.Service.DeleteOption(r.Context(), pollID, version, optionID, force, authz.SubjectFromAuth(auth))
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", pollETag(newVersion))
+
+	response.JSON(w, http.StatusOK, "deleted option successfully", nil)
+}
+
+// optionWriter returns the caller of an option endpoint, who needs the
+// polls:write scope like for any other change to a poll.
+func optionWriter(w http.ResponseWriter, r *http.Request) (*helper.AuthContext, bool) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user information")
+		return nil, false
+	}
+	if !requireScope(w, r, auth, helper.ScopePollsWrite) {
+		return nil, false
+	}
+	return auth, true
+}
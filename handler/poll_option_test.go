@@ -0,0 +1,300 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newOptionRequest(method, target, body string, auth any) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.SetPathValue("id", "1")
+	return req.WithContext(context.WithValue(req.Context(), helper.AuthKey, auth))
+}
+
+func TestHandlerAddOption(t *testing.T) {
+	owner := &helper.AuthContext{UserID: 1}
+
+	tests := []struct {
+		name       string
+		auth       any
+		body       string
+		setupMocks func(svc *mocks.PollServiceMock)
+		wantCode   int
+		wantBody   string
+	}{
+		{
+			name:       "failed get user information",
+			auth:       "",
+			body:       `{"label": "Zig"}`,
+			setupMocks: func(svc *mocks.PollServiceMock) {},
+			wantCode:   http.StatusUnauthorized,
+			wantBody:   "invalid user information",
+		},
+		{
+			name:       "blank label",
+			auth:       owner,
+			body:       `{"label": "  "}`,
+			setupMocks: func(svc *mocks.PollServiceMock) {},
+			wantCode:   http.StatusBadRequest,
+			wantBody:   "must not be blank",
+		},
+		{
+			name: "polling not found",
+			auth: owner,
+			body: `{"label": "Zig"}`,
+			setupMocks: func(svc *mocks.PollServiceMock) {
+				svc.On("AddOption", mock.Anything, int64(1), int64(0), &dto.CreateOptionRequest{Label: "Zig"}, mock.AnythingOfType("authz.Subject")).
+					Return(nil, int64(0), domain.ErrPollNotFound)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: "polling not found",
+		},
+		{
+			name: "success",
+			auth: owner,
+			body: `{"label": "Zig"}`,
+			setupMocks: func(svc *mocks.PollServiceMock) {
+				svc.On("AddOption", mock.Anything, int64(1), int64(0), &dto.CreateOptionRequest{Label: "Zig"}, mock.AnythingOfType("authz.Subject")).
+					Return(&dto.Option{ID: 3, Label: "Zig", Position: 3}, int64(5), nil)
+			},
+			wantCode: http.StatusCreated,
+			wantBody: `"position":3`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(mocks.PollServiceMock)
+			tt.setupMocks(svc)
+
+			rr := httptest.NewRecorder()
+			(&Polling{Service: svc}).AddOption(rr, newOptionRequest(http.MethodPost, "/pollings/1/options", tt.body, tt.auth))
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantBody)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerRenameOption(t *testing.T) {
+	tests := []struct {
+		name       string
+		optionID   string
+		setupMocks func(svc *mocks.PollServiceMock)
+		wantCode   int
+		wantBody   string
+	}{
+		{
+			name:       "invalid option id",
+			optionID:   "abc",
+			setupMocks: func(svc *mocks.PollServiceMock) {},
+			wantCode:   http.StatusBadRequest,
+			wantBody:   "invalid id option",
+		},
+		{
+			name:     "option not found",
+			optionID: "9",
+			setupMocks: func(svc *mocks.PollServiceMock) {
+				svc.On("RenameOption", mock.Anything, int64(1), int64(0), int64(9), &dto.RenameOptionRequest{Label: "Zig"}, mock.AnythingOfType("authz.Subject")).
+					Return(nil, int64(0), domain.ErrOptionNotFound)
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: "option not found",
+		},
+		{
+			name:     "success",
+			optionID: "2",
+			setupMocks: func(svc *mocks.PollServiceMock) {
+				svc.On("RenameOption", mock.Anything, int64(1), int64(0), int64(2), &dto.RenameOptionRequest{Label: "Zig"}, mock.AnythingOfType("authz.Subject")).
+					Return(&dto.Option{ID: 2, Label: "Zig", Position: 2}, int64(5), nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: "renamed option successfully",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(mocks.PollServiceMock)
+			tt.setupMocks(svc)
+
+			req := newOptionRequest(http.MethodPatch, "/pollings/1/options/"+tt.optionID, `{"label": "Zig"}`, &helper.AuthContext{UserID: 1})
+			req.SetPathValue("optionID", tt.optionID)
+			rr := httptest.NewRecorder()
+			(&Polling{Service: svc}).RenameOption(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantBody)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerReorderOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		setupMocks func(svc *mocks.PollServiceMock)
+		wantCode   int
+		wantBody   string
+	}{
+		{
+			name:       "duplicate ids",
+			body:       `{"option_ids": [1, 1]}`,
+			setupMocks: func(svc *mocks.PollServiceMock) {},
+			wantCode:   http.StatusBadRequest,
+			wantBody:   "OptionIDs",
+		},
+		{
+			name: "success",
+			body: `{"option_ids": [2, 1]}`,
+			setupMocks: func(svc *mocks.PollServiceMock) {
+				svc.On("ReorderOptions", mock.Anything, int64(1), int64(0), &dto.ReorderOptionsRequest{OptionIDs: []int64{2, 1}}, mock.AnythingOfType("authz.Subject")).
+					Return([]dto.Option{{ID: 2, Position: 1}, {ID: 1, Position: 2}}, int64(5), nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: "reordered options successfully",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(mocks.PollServiceMock)
+			tt.setupMocks(svc)
+
+			rr := httptest.NewRecorder()
+			(&Polling{Service: svc}).ReorderOptions(rr, newOptionRequest(http.MethodPut, "/pollings/1/options/order", tt.body, &helper.AuthContext{UserID: 1}))
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantBody)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerDeleteOption(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		setupMocks func(svc *mocks.PollServiceMock)
+		wantCode   int
+		wantBody   string
+	}{
+		{
+			name:       "invalid force",
+			query:      "?force=yes-please",
+			setupMocks: func(svc *mocks.PollServiceMock) {},
+			wantCode:   http.StatusBadRequest,
+			wantBody:   "force must be true or false",
+		},
+		{
+			name: "option has votes",
+			setupMocks: func(svc *mocks.PollServiceMock) {
+				svc.On("DeleteOption", mock.Anything, int64(1), int64(0), int64(2), false, mock.AnythingOfType("authz.Subject")).
+					Return(int64(0), fmt.Errorf("option 2 has 3 votes: %w", domain.ErrOptionHasVotes))
+			},
+			wantCode: http.StatusConflict,
+			wantBody: "OPTION_HAS_VOTES",
+		},
+		{
+			name:  "forced",
+			query: "?force=true",
+			setupMocks: func(svc *mocks.PollServiceMock) {
+				svc.On("DeleteOption", mock.Anything, int64(1), int64(0), int64(2), true, mock.AnythingOfType("authz.Subject")).Return(int64(5), nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: "deleted option successfully",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(mocks.PollServiceMock)
+			tt.setupMocks(svc)
+
+			req := newOptionRequest(http.MethodDelete, "/pollings/1/options/2"+tt.query, "", &helper.AuthContext{UserID: 1})
+			req.SetPathValue("optionID", "2")
+			rr := httptest.NewRecorder()
+			(&Polling{Service: svc}).DeleteOption(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantBody)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerOptionPreconditions(t *testing.T) {
+	tests := []struct {
+		name           string
+		ifMatch        string
+		requireIfMatch bool
+		setupMocks     func(svc *mocks.PollServiceMock)
+		wantCode       int
+		wantETag       string
+	}{
+		{
+			name:           "missing required If-Match",
+			requireIfMatch: true,
+			setupMocks:     func(svc *mocks.PollServiceMock) {},
+			wantCode:       http.StatusPreconditionRequired,
+		},
+		{
+			name:       "malformed If-Match",
+			ifMatch:    "4",
+			setupMocks: func(svc *mocks.PollServiceMock) {},
+			wantCode:   http.StatusPreconditionFailed,
+		},
+		{
+			name:    "stale version",
+			ifMatch: `"3"`,
+			setupMocks: func(svc *mocks.PollServiceMock) {
+				svc.On("AddOption", mock.Anything, int64(1), int64(3), &dto.CreateOptionRequest{Label: "Zig"}, mock.AnythingOfType("authz.Subject")).
+					Return(nil, int64(0), fmt.Errorf("polling 1 is at version 4, not 3: %w", domain.ErrVersionMismatch))
+			},
+			wantCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "matching version",
+			ifMatch:        `"4"`,
+			requireIfMatch: true,
+			setupMocks: func(svc *mocks.PollServiceMock) {
+				svc.On("AddOption", mock.Anything, int64(1), int64(4), &dto.CreateOptionRequest{Label: "Zig"}, mock.AnythingOfType("authz.Subject")).
+					Return(&dto.Option{ID: 3, Label: "Zig", Position: 3}, int64(5), nil)
+			},
+			wantCode: http.StatusCreated,
+			wantETag: `"5"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(mocks.PollServiceMock)
+			tt.setupMocks(svc)
+
+			req := newOptionRequest(http.MethodPost, "/pollings/1/options", `{"label": "Zig"}`, &helper.AuthContext{UserID: 1})
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			rr := httptest.NewRecorder()
+			(&Polling{Service: svc, RequireIfMatch: tt.requireIfMatch}).AddOption(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Equal(t, tt.wantETag, rr.Header().Get("ETag"))
+			svc.AssertExpectations(t)
+		})
+	}
+}
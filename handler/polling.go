@@ -1,21 +1,22 @@
 package handler
 
 import (
-	"encoding/json"
+	"native-free-pollings/authz"
 	"native-free-pollings/domain"
 	"native-free-pollings/dto"
 	"native-free-pollings/helper"
+	"native-free-pollings/response"
 	"net/http"
-	"strconv"
-	"strings"
 )
 
 type Polling struct {
 	Service domain.PollService
+	// RequireIfMatch rejects updates and deletes without If-Match.
+	RequireIfMatch bool
 }
 
-func NewPolling(svc domain.PollService) *Polling {
-	return &Polling{Service: svc}
+func NewPolling(svc domain.PollService, requireIfMatch bool) *Polling {
+	return &Polling{Service: svc, RequireIfMatch: requireIfMatch}
 }
 
 // Create Polling godoc
@@ -26,27 +27,15 @@ func NewPolling(svc domain.PollService) *Polling {
 // @Produce      json
 // @Security BearerAuth
 // @Param        request  body     dto.CreatePollingRequest  true "Poll create payload"
-// @Success      201      {object}  dto.PollingResponse
+// @Success      201      {object}  response.Envelope{data=dto.PollingResponse}
 // @Router       /pollings [post]
 func (p *Polling) CreatePolling(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "NOT_ALLOWED",
-			"message": "method not allowed",
-		})
-		return
-	}
-
 	auth, ok := helper.GetAuthContext(r.Context())
 	if !ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_TOKEN",
-			"message": "invalid user information",
-		})
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user information")
+		return
+	}
+	if !requireScope(w, r, auth, helper.ScopePollsWrite) {
 		return
 	}
 	creator := dto.CreatorInfo{
@@ -56,221 +45,149 @@ func (p *Polling) CreatePolling(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req dto.CreatePollingRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_REQUEST",
-			"message": "invalid request payload",
-		})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	if errs, err := helper.BindAndValidate(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]any{
-			"code":    "VALIDATION_ERROR",
-			"message": "payload validation failed",
-			"details": errs,
-		})
+		response.ValidationError(w, r, errs)
 		return
 	}
 
 	resp, err := p.Service.CreatePolling(r.Context(), &req, creator)
 	if err != nil {
-		err.(*helper.AppError).WriteError(w)
+		response.Error(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"message": "created polling successfully",
-		"data":    resp,
-	})
+	response.JSON(w, http.StatusCreated, "created polling successfully", resp)
 }
 
 // Update Polling godoc
 // @Summary      update polling
-// @Description  Updates an existing poll. Only the creator can modify title, options, or timestamps.
+// @Description  Partially updates a poll: omitted fields keep their value. Options are managed through /pollings/{id}/options. Only the creator, a moderator or an admin can update it. Send the poll's ETag in If-Match to avoid overwriting someone else's change.
 // @Tags         Polling
 // @Accept       json
 // @Produce      json
 // @Security BearerAuth
 // @Param id path int true "Poll ID"
+// @Param If-Match header string false "ETag of the poll being updated"
 // @Param        request  body     dto.UpdatePollingRequest  true "Poll update payload"
-// @Success      200      {object}  dto.PollingResponse
+// @Success      200      {object}  response.Envelope{data=dto.PollingResponse}
+// @Failure      412      {object}  response.Problem
+// @Failure      428      {object}  response.Problem
 // @Router       /pollings/{id} [patch]
 func (p *Polling) UpdatePolling(w http.ResponseWriter, r *http.Request) {
 	auth, ok := helper.GetAuthContext(r.Context())
 	if !ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_TOKEN",
-			"message": "invalid user information",
-		})
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user information")
 		return
 	}
-	creator := dto.CreatorInfo{
-		ID:    auth.UserID,
-		Name:  auth.UserName,
-		Email: auth.UserEmail,
+	if !requireScope(w, r, auth, helper.ScopePollsWrite) {
+		return
+	}
+
+	pollID, ok := pathID(w, r, "polling")
+	if !ok {
+		return
+	}
+	version, ok := p.ifMatchVersion(w, r, pollID)
+	if !ok {
+		return
 	}
 
 	var req dto.UpdatePollingRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_REQUEST",
-			"message": "invalid request payload",
-		})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
+	// The path decides which poll is updated, whatever the body says.
+	req.ID = pollID
+	req.Version = version
 
 	if errs, err := helper.BindAndValidate(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]any{
-			"code":    "VALIDATION_ERROR",
-			"message": "payload validation failed",
-			"details": errs,
-		})
+		response.ValidationError(w, r, errs)
 		return
 	}
 
-	resp, err := p.Service.UpdatePolling(r.Context(), &req, creator)
+	resp, err := p.Service.UpdatePolling(r.Context(), &req, authz.SubjectFromAuth(auth))
 	if err != nil {
-		err.(*helper.AppError).WriteError(w)
+		response.Error(w, r, err)
 		return
 	}
+	w.Header().Set("ETag", pollETag(resp.Version))
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"message": "updated polling successfully",
-		"data":    resp,
-	})
+	response.JSON(w, http.StatusOK, "updated polling successfully", resp)
 }
 
 // Delete Polling godoc
 // @Summary      delete polling
-// @Description  Deletes a poll. Only the creator is authorized to remove it.
+// @Description  Deletes a poll. Only the creator, a moderator or an admin is authorized to remove it. Send the poll's ETag in If-Match to delete only the version you have seen.
 // @Tags         Polling
 // @Accept       json
 // @Produce      json
 // @Security BearerAuth
 // @Param id path int true "Poll ID"
-// @Success      200      {object}  map[string]string "Success message"
+// @Param If-Match header string false "ETag of the poll being deleted"
+// @Success      200      {object}  response.Envelope "Success message"
+// @Failure      412      {object}  response.Problem
+// @Failure      428      {object}  response.Problem
 // @Router       /pollings/{id} [delete]
 func (p *Polling) DeletePolling(w http.ResponseWriter, r *http.Request) {
 	auth, ok := helper.GetAuthContext(r.Context())
 	if !ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_TOKEN",
-			"message": "invalid user information",
-		})
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user information")
 		return
 	}
-	creator := dto.CreatorInfo{
-		ID: auth.UserID,
+	if !requireScope(w, r, auth, helper.ScopePollsWrite) {
+		return
 	}
 
-	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) < 3 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_PATH",
-			"message": "invalid path id polling",
-		})
+	pollID, ok := pathID(w, r, "polling")
+	if !ok {
 		return
 	}
 
-	idStr := parts[2]
-	pollID, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_ID",
-			"message": "invalid id polling",
-		})
+	version, ok := p.ifMatchVersion(w, r, pollID)
+	if !ok {
 		return
 	}
 
-	err = p.Service.DeletePolling(r.Context(), pollID, creator.ID)
+	err := p.Service.DeletePolling(r.Context(), pollID, version, authz.SubjectFromAuth(auth))
 	if err != nil {
-		err.(*helper.AppError).WriteError(w)
+		response.Error(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"message": "deleted polling successfully",
-	})
+	response.JSON(w, http.StatusOK, "deleted polling successfully", nil)
 }
 
 // Get Polling godoc
 // @Summary      get polling
-// @Description  Fetches detailed information about a specific poll.
+// @Description  Fetches detailed information about a specific poll. The ETag header carries its version; send it in If-None-Match to get 304 when nothing changed.
 // @Tags         Polling
 // @Accept       json
 // @Produce      json
 // @Param id path int true "Poll ID"
-// @Success      200      {object}  dto.PollingResponse
+// @Param If-None-Match header string false "ETag from an earlier response"
+// @Success      200      {object}  response.Envelope{data=dto.PollingResponse}
+// @Success      304      "Not modified"
 // @Router       /pollings/{id} [get]
 func (p *Polling) GetDetailPolling(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "NOT_ALLOWED",
-			"message": "method not allowed",
-		})
-		return
-	}
-
-	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) < 3 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_PATH",
-			"message": "invalid path id polling",
-		})
+	pollID, ok := pathID(w, r, "polling")
+	if !ok {
 		return
 	}
 
-	idStr := parts[2]
-	pollID, err := strconv.ParseInt(idStr, 10, 64)
+	resp, err := p.Service.GetDetailPolling(r.Context(), pollID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_ID",
-			"message": "invalid id polling",
-		})
+		response.Error(w, r, err)
 		return
 	}
-
-	resp, err := p.Service.GetDetailPolling(r.Context(), pollID)
-	if err != nil {
-		err.(*helper.AppError).WriteError(w)
+	if response.NotModified(w, r, pollETag(resp.Version)) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"message": "get detail polling successfully",
-		"data":    resp,
-	})
+	response.JSON(w, http.StatusOK, "get detail polling successfully", resp)
 }
 
 // Vote Option Polling godoc
@@ -281,127 +198,65 @@ func (p *Polling) GetDetailPolling(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Param id path int true "Poll ID"
 // @Param        request  body     dto.VoteRequest  true "Poll vote payload"
-// @Success      200      {object}  map[string]string "Success message"
+// @Success      200      {object}  response.Envelope "Success message"
 // @Router       /pollings/{id}/votes [post]
 func (p *Polling) VoteOptionPolling(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "NOT_ALLOWED",
-			"message": "method not allowed",
-		})
-		return
-	}
-	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) < 4 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_PATH",
-			"message": "invalid path id polling",
-		})
-		return
-	}
-
-	idStr := parts[2]
-	pollID, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_ID",
-			"message": "invalid id polling",
-		})
+	pollID, ok := pathID(w, r, "polling")
+	if !ok {
 		return
 	}
 
 	var req dto.VoteRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_REQUEST",
-			"message": "invalid request payload",
-		})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	auth, ok := helper.GetAuthContext(r.Context())
 	if ok {
+		if !requireScope(w, r, auth, helper.ScopeVotesWrite) {
+			return
+		}
 		err := p.Service.VoteOptionPolling(r.Context(), auth.UserID, pollID, req.OptionID, req.DeviceHash)
 		if err != nil {
-			err.(*helper.AppError).WriteError(w)
+			response.Error(w, r, err)
 			return
 		}
 	} else {
 		err := p.Service.VoteOptionPolling(r.Context(), 0, pollID, req.OptionID, req.DeviceHash)
 		if err != nil {
-			err.(*helper.AppError).WriteError(w)
+			response.Error(w, r, err)
 			return
 		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"message": "vote successfully",
-	})
+	response.JSON(w, http.StatusOK, "vote successfully", nil)
 }
 
 // Get Polling Result godoc
 // @Summary      get polling result
-// @Description  Returns the voting results for a specific poll.
+// @Description  Returns the voting results for a specific poll. Send the ETag of an earlier response in If-None-Match to get 304 while the counts are unchanged.
 // @Tags         Polling
 // @Accept       json
 // @Produce      json
 // @Param id path int true "Poll ID"
-// @Success      200      {object}  dto.ResultPolling
+// @Param If-None-Match header string false "ETag from an earlier response"
+// @Success      200      {object}  response.Envelope{data=dto.ResultPolling}
+// @Success      304      "Not modified"
 // @Router       /pollings/{id}/results [get]
 func (p *Polling) GetPollingResult(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "NOT_ALLOWED",
-			"message": "method not allowed",
-		})
-		return
-	}
-
-	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) < 4 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_PATH",
-			"message": "invalid path id polling",
-		})
+	pollID, ok := pathID(w, r, "polling")
+	if !ok {
 		return
 	}
 
-	idStr := parts[2]
-	pollID, err := strconv.ParseInt(idStr, 10, 64)
+	resp, err := p.Service.GetPollingResult(r.Context(), pollID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_ID",
-			"message": "invalid id polling",
-		})
+		response.Error(w, r, err)
 		return
 	}
-
-	resp, err := p.Service.GetPollingResult(r.Context(), pollID)
-	if err != nil {
-		err.(*helper.AppError).WriteError(w)
+	if response.NotModified(w, r, response.ContentETag(resp)) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"message": "get polling result successfully",
-		"data":    resp,
-	})
+	response.JSON(w, http.StatusOK, "get polling result successfully", resp)
 }
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"native-free-pollings/authz"
 	"native-free-pollings/dto"
 	"native-free-pollings/helper"
 	"native-free-pollings/mocks"
@@ -37,7 +38,7 @@ func TestHandlerCreatePolling(t *testing.T) {
 			body:       `{invalid json}`,
 			setupMocks: func(svc *mocks.PollServiceMock) {},
 			wantCode:   http.StatusBadRequest,
-			wantBody:   "invalid request payload",
+			wantBody:   "malformed JSON at offset 2",
 		},
 		{
 			name:       "error validate request",
@@ -50,7 +51,7 @@ func TestHandlerCreatePolling(t *testing.T) {
 		{
 			name:    "CreatePolling service error",
 			creator: &helper.AuthContext{UserID: 1, UserName: "test user", UserEmail: "test@example.com"},
-			body:    `{"title": "title polling", "description": "description polling", "status": "active", "starts_at": "2025-10-25T21:52:00+07:00", "ends_at": "2025-10-25T21:52:00+07:00", "options": ["go", "kotlin"]}`,
+			body:    `{"title": "title polling", "description": "description polling", "status": "active", "starts_at": "2025-10-25T21:52:00+07:00", "ends_at": "2025-10-30T21:52:00+07:00", "options": ["go", "kotlin"]}`,
 			setupMocks: func(svc *mocks.PollServiceMock) {
 				svc.On("CreatePolling", mock.Anything, mock.AnythingOfType("*dto.CreatePollingRequest"), mock.AnythingOfType("dto.CreatorInfo")).
 					Return(nil, helper.NewAppError("BAD_REQUEST", assert.AnError.Error(), assert.AnError))
@@ -61,7 +62,7 @@ func TestHandlerCreatePolling(t *testing.T) {
 		{
 			name:    "success",
 			creator: &helper.AuthContext{UserID: 1, UserName: "test user", UserEmail: "test@example.com"},
-			body:    `{"title": "title polling", "description": "description polling", "status": "active", "starts_at": "2025-10-25T21:52:00+07:00", "ends_at": "2025-10-25T21:52:00+07:00", "options": ["go", "kotlin"]}`,
+			body:    `{"title": "title polling", "description": "description polling", "status": "active", "starts_at": "2025-10-25T21:52:00+07:00", "ends_at": "2025-10-30T21:52:00+07:00", "options": ["go", "kotlin"]}`,
 			setupMocks: func(svc *mocks.PollServiceMock) {
 				svc.On("CreatePolling", mock.Anything, mock.AnythingOfType("*dto.CreatePollingRequest"), mock.AnythingOfType("dto.CreatorInfo")).
 					Return(&dto.PollingResponse{ID: 1}, nil)
@@ -76,7 +77,8 @@ func TestHandlerCreatePolling(t *testing.T) {
 			svc := new(mocks.PollServiceMock)
 			tt.setupMocks(svc)
 
-			req := httptest.NewRequest("CREATE", "/pollings/1", strings.NewReader(tt.body))
+			req := httptest.NewRequest("POST", "/pollings", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
 			rr := httptest.NewRecorder()
 
 			ctx := context.WithValue(req.Context(), helper.AuthKey, tt.creator)
@@ -115,36 +117,30 @@ func TestHandlerUpdatePolling(t *testing.T) {
 			body:       `{invalid json}`,
 			setupMocks: func(svc *mocks.PollServiceMock) {},
 			wantCode:   http.StatusBadRequest,
-			wantBody:   "invalid request payload",
+			wantBody:   "malformed JSON at offset 2",
 		},
 		{
 			name:       "error validate request",
 			creator:    &helper.AuthContext{UserID: 1, UserName: "test user", UserEmail: "test@example.com"},
-			body:       `{}`,
+			body:       `{"title": " ", "status": "open"}`,
 			setupMocks: func(svc *mocks.PollServiceMock) {},
 			wantCode:   http.StatusBadRequest,
 			wantBody:   "payload validation failed",
 		},
+		{
+			name:       "options are not part of the update",
+			creator:    &helper.AuthContext{UserID: 1, UserName: "test user", UserEmail: "test@example.com"},
+			body:       `{"options": []}`,
+			setupMocks: func(svc *mocks.PollServiceMock) {},
+			wantCode:   http.StatusBadRequest,
+			wantBody:   `unknown field \"options\"`,
+		},
 		{
 			name:    "UpdatePolling service error",
 			creator: &helper.AuthContext{UserID: 1, UserName: "test user", UserEmail: "test@example.com"},
-			body: `{
-						"id": 1,
-						"title": "Favorite Programming Language",
-						"description": "Vote for the language you love most",
-						"status": "active",
-						"starts_at": "2025-10-26T09:00:00+07:00",
-						"ends_at": "2025-10-30T17:00:00+07:00",
-						"options": [
-							{
-								"id": 1,
-								"label": "Go",
-								"position": 1
-							}
-						]
-					}`,
+			body:    `{"title": "Favorite Programming Language"}`,
 			setupMocks: func(svc *mocks.PollServiceMock) {
-				svc.On("UpdatePolling", mock.Anything, mock.AnythingOfType("*dto.UpdatePollingRequest"), mock.AnythingOfType("dto.CreatorInfo")).
+				svc.On("UpdatePolling", mock.Anything, mock.AnythingOfType("*dto.UpdatePollingRequest"), mock.AnythingOfType("authz.Subject")).
 					Return(nil, helper.NewAppError("BAD_REQUEST", assert.AnError.Error(), assert.AnError))
 			},
 			wantCode: http.StatusBadRequest,
@@ -153,23 +149,9 @@ func TestHandlerUpdatePolling(t *testing.T) {
 		{
 			name:    "success",
 			creator: &helper.AuthContext{UserID: 1, UserName: "test user", UserEmail: "test@example.com"},
-			body: `{
-						"id": 1,
-						"title": "Favorite Programming Language",
-						"description": "Vote for the language you love most",
-						"status": "active",
-						"starts_at": "2025-10-26T09:00:00+07:00",
-						"ends_at": "2025-10-30T17:00:00+07:00",
-						"options": [
-							{
-							"id": 1,
-							"label": "Go",
-							"position": 1
-							}
-						]
-					}`,
+			body:    `{"title": "Favorite Programming Language"}`,
 			setupMocks: func(svc *mocks.PollServiceMock) {
-				svc.On("UpdatePolling", mock.Anything, mock.AnythingOfType("*dto.UpdatePollingRequest"), mock.AnythingOfType("dto.CreatorInfo")).
+				svc.On("UpdatePolling", mock.Anything, mock.AnythingOfType("*dto.UpdatePollingRequest"), mock.AnythingOfType("authz.Subject")).
 					Return(&dto.PollingResponse{ID: 1}, nil)
 			},
 			wantCode: http.StatusOK,
@@ -183,6 +165,8 @@ func TestHandlerUpdatePolling(t *testing.T) {
 			tt.setupMocks(svc)
 
 			req := httptest.NewRequest("PATCH", "/pollings/1", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.SetPathValue("id", "1")
 			rr := httptest.NewRecorder()
 
 			ctx := context.WithValue(req.Context(), helper.AuthKey, tt.creator)
@@ -202,7 +186,8 @@ func TestHandlerDeletePolling(t *testing.T) {
 	tests := []struct {
 		name       string
 		creator    any
-		path       string
+		id         string
+		ifMatch    string
 		setupMocks func(svc *mocks.PollServiceMock)
 		wantCode   int
 		wantBody   string
@@ -210,23 +195,15 @@ func TestHandlerDeletePolling(t *testing.T) {
 		{
 			name:       "failed get information creator",
 			creator:    "",
-			path:       "/pollings",
+			id:         "abc",
 			setupMocks: func(svc *mocks.PollServiceMock) {},
 			wantCode:   http.StatusUnauthorized,
 			wantBody:   "invalid user information",
 		},
-		{
-			name:       "invalid path",
-			creator:    &helper.AuthContext{UserID: 1, UserName: "test user", UserEmail: "test@example.com"},
-			path:       "/pollings",
-			setupMocks: func(svc *mocks.PollServiceMock) {},
-			wantCode:   http.StatusBadRequest,
-			wantBody:   "invalid path id polling",
-		},
 		{
 			name:       "invalid id polling",
 			creator:    &helper.AuthContext{UserID: 1, UserName: "test user", UserEmail: "test@example.com"},
-			path:       "/polling/",
+			id:         "abc",
 			setupMocks: func(svc *mocks.PollServiceMock) {},
 			wantCode:   http.StatusBadRequest,
 			wantBody:   "invalid id polling",
@@ -234,20 +211,41 @@ func TestHandlerDeletePolling(t *testing.T) {
 		{
 			name:    "DeletePolling return error",
 			creator: &helper.AuthContext{UserID: 1, UserName: "test user", UserEmail: "test@example.com"},
-			path:    "/polling/1",
+			id:      "1",
 			setupMocks: func(svc *mocks.PollServiceMock) {
-				svc.On("DeletePolling", mock.Anything, int64(1), int64(1)).
+				svc.On("DeletePolling", mock.Anything, int64(1), int64(0), authz.Subject{UserID: 1}).
 					Return(helper.NewAppError("NOT_FOUND", assert.AnError.Error(), assert.AnError))
 			},
 			wantCode: http.StatusNotFound,
 			wantBody: assert.AnError.Error(),
 		},
+		{
+			name:       "weak if-match never matches",
+			creator:    &helper.AuthContext{UserID: 1, UserName: "test user", UserEmail: "test@example.com"},
+			id:         "1",
+			ifMatch:    `W/"3"`,
+			setupMocks: func(svc *mocks.PollServiceMock) {},
+			wantCode:   http.StatusPreconditionFailed,
+			wantBody:   "If-Match does not match",
+		},
+		{
+			name:    "if-match passes the version",
+			creator: &helper.AuthContext{UserID: 1, UserName: "test user", UserEmail: "test@example.com"},
+			id:      "1",
+			ifMatch: `"3"`,
+			setupMocks: func(svc *mocks.PollServiceMock) {
+				svc.On("DeletePolling", mock.Anything, int64(1), int64(3), authz.Subject{UserID: 1}).
+					Return(nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: "deleted polling successfully",
+		},
 		{
 			name:    "success",
 			creator: &helper.AuthContext{UserID: 1, UserName: "test user", UserEmail: "test@example.com"},
-			path:    "/polling/1",
+			id:      "1",
 			setupMocks: func(svc *mocks.PollServiceMock) {
-				svc.On("DeletePolling", mock.Anything, int64(1), int64(1)).
+				svc.On("DeletePolling", mock.Anything, int64(1), int64(0), authz.Subject{UserID: 1}).
 					Return(nil)
 			},
 			wantCode: http.StatusOK,
@@ -260,7 +258,11 @@ func TestHandlerDeletePolling(t *testing.T) {
 			svc := new(mocks.PollServiceMock)
 			tt.setupMocks(svc)
 
-			req := httptest.NewRequest("DELETE", tt.path, nil)
+			req := httptest.NewRequest("DELETE", "/pollings/"+tt.id, nil)
+			req.SetPathValue("id", tt.id)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
 			rr := httptest.NewRecorder()
 
 			ctx := context.WithValue(req.Context(), helper.AuthKey, tt.creator)
@@ -278,29 +280,23 @@ func TestHandlerDeletePolling(t *testing.T) {
 
 func TestHandlerGetDetailPolling(t *testing.T) {
 	tests := []struct {
-		name       string
-		path       string
-		setupMocks func(svc *mocks.PollServiceMock)
-		wantCode   int
-		wantBody   string
+		name        string
+		id          string
+		ifNoneMatch string
+		setupMocks  func(svc *mocks.PollServiceMock)
+		wantCode    int
+		wantBody    string
 	}{
-		{
-			name:       "invalid path",
-			path:       "/pollings",
-			setupMocks: func(svc *mocks.PollServiceMock) {},
-			wantCode:   http.StatusBadRequest,
-			wantBody:   "invalid path id polling",
-		},
 		{
 			name:       "invalid id polling",
-			path:       "/polling/",
+			id:         "abc",
 			setupMocks: func(svc *mocks.PollServiceMock) {},
 			wantCode:   http.StatusBadRequest,
 			wantBody:   "invalid id polling",
 		},
 		{
 			name: "GetDetailPolling return error",
-			path: "/polling/1",
+			id:   "1",
 			setupMocks: func(svc *mocks.PollServiceMock) {
 				svc.On("GetDetailPolling", mock.Anything, int64(1)).
 					Return(nil, helper.NewAppError("NOT_FOUND", assert.AnError.Error(), assert.AnError))
@@ -310,14 +306,24 @@ func TestHandlerGetDetailPolling(t *testing.T) {
 		},
 		{
 			name: "success",
-			path: "/polling/1",
+			id:   "1",
 			setupMocks: func(svc *mocks.PollServiceMock) {
 				svc.On("GetDetailPolling", mock.Anything, int64(1)).
-					Return(&dto.PollingResponse{}, nil)
+					Return(&dto.PollingResponse{Version: 2}, nil)
 			},
 			wantCode: http.StatusOK,
 			wantBody: "get detail polling successfully",
 		},
+		{
+			name:        "not modified",
+			id:          "1",
+			ifNoneMatch: `"2"`,
+			setupMocks: func(svc *mocks.PollServiceMock) {
+				svc.On("GetDetailPolling", mock.Anything, int64(1)).
+					Return(&dto.PollingResponse{Version: 2}, nil)
+			},
+			wantCode: http.StatusNotModified,
+		},
 	}
 
 	for _, tt := range tests {
@@ -325,7 +331,11 @@ func TestHandlerGetDetailPolling(t *testing.T) {
 			svc := new(mocks.PollServiceMock)
 			tt.setupMocks(svc)
 
-			req := httptest.NewRequest("GET", tt.path, nil)
+			req := httptest.NewRequest("GET", "/pollings/"+tt.id, nil)
+			req.SetPathValue("id", tt.id)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
 			rr := httptest.NewRecorder()
 
 			h := &Polling{Service: svc}
@@ -333,6 +343,10 @@ func TestHandlerGetDetailPolling(t *testing.T) {
 
 			assert.Equal(t, tt.wantCode, rr.Code)
 			assert.Contains(t, rr.Body.String(), tt.wantBody)
+			if tt.wantCode == http.StatusNotModified {
+				assert.Empty(t, rr.Body.String())
+				assert.Equal(t, tt.ifNoneMatch, rr.Header().Get("ETag"))
+			}
 			svc.AssertExpectations(t)
 		})
 	}
@@ -342,34 +356,16 @@ func TestHandlerVoteOptionPolling(t *testing.T) {
 	tests := []struct {
 		name       string
 		method     string
-		path       string
+		id         string
 		body       string
 		setupMocks func(svc *mocks.PollServiceMock)
 		wantCode   int
 		wantBody   string
 	}{
-		{
-			name:       "method invalid",
-			method:     "GET",
-			path:       "/pollings",
-			body:       `{}`,
-			setupMocks: func(svc *mocks.PollServiceMock) {},
-			wantCode:   http.StatusMethodNotAllowed,
-			wantBody:   "method not allowed",
-		},
-		{
-			name:       "invalid path",
-			method:     "POST",
-			path:       "/pollings",
-			body:       `{}`,
-			setupMocks: func(svc *mocks.PollServiceMock) {},
-			wantCode:   http.StatusBadRequest,
-			wantBody:   "invalid path id polling",
-		},
 		{
 			name:       "invalid id polling",
 			method:     "POST",
-			path:       "/pollings//vote",
+			id:         "abc",
 			body:       `{}`,
 			setupMocks: func(svc *mocks.PollServiceMock) {},
 			wantCode:   http.StatusBadRequest,
@@ -378,7 +374,7 @@ func TestHandlerVoteOptionPolling(t *testing.T) {
 		{
 			name:       "invalid request body",
 			method:     "POST",
-			path:       "/pollings//vote",
+			id:         "abc",
 			body:       ``,
 			setupMocks: func(svc *mocks.PollServiceMock) {},
 			wantCode:   http.StatusBadRequest,
@@ -387,7 +383,7 @@ func TestHandlerVoteOptionPolling(t *testing.T) {
 		{
 			name:   "VoteOptionPolling return error",
 			method: "POST",
-			path:   "/pollings/1/vote",
+			id:     "1",
 			body:   `{"option_id": 1, "device_hash": "test device hash"}`,
 			setupMocks: func(svc *mocks.PollServiceMock) {
 				svc.On("VoteOptionPolling", mock.Anything, int64(0), int64(1), int64(1), "test device hash").
@@ -399,7 +395,7 @@ func TestHandlerVoteOptionPolling(t *testing.T) {
 		{
 			name:   "success",
 			method: "POST",
-			path:   "/polling/1/vote",
+			id:     "1",
 			body:   `{"option_id": 1, "device_hash": "test device hash"}`,
 			setupMocks: func(svc *mocks.PollServiceMock) {
 				svc.On("VoteOptionPolling", mock.Anything, int64(0), int64(1), int64(1), "test device hash").
@@ -415,7 +411,9 @@ func TestHandlerVoteOptionPolling(t *testing.T) {
 			svc := new(mocks.PollServiceMock)
 			tt.setupMocks(svc)
 
-			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req := httptest.NewRequest(tt.method, "/pollings/"+tt.id, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.SetPathValue("id", tt.id)
 			rr := httptest.NewRecorder()
 
 			ctx := context.WithValue(req.Context(), helper.AuthKey, "")
@@ -435,31 +433,15 @@ func TestHandlerGetPollingResult(t *testing.T) {
 	tests := []struct {
 		name       string
 		method     string
-		path       string
+		id         string
 		setupMocks func(svc *mocks.PollServiceMock)
 		wantCode   int
 		wantBody   string
 	}{
-		{
-			name:       "method invalid",
-			method:     http.MethodPost,
-			path:       "/",
-			setupMocks: func(svc *mocks.PollServiceMock) {},
-			wantCode:   http.StatusMethodNotAllowed,
-			wantBody:   "method not allowed",
-		},
-		{
-			name:       "invalid path",
-			method:     http.MethodGet,
-			path:       "/pollings",
-			setupMocks: func(svc *mocks.PollServiceMock) {},
-			wantCode:   http.StatusBadRequest,
-			wantBody:   "invalid path id polling",
-		},
 		{
 			name:       "invalid id polling",
 			method:     http.MethodGet,
-			path:       "/polling//result",
+			id:         "abc",
 			setupMocks: func(svc *mocks.PollServiceMock) {},
 			wantCode:   http.StatusBadRequest,
 			wantBody:   "invalid id polling",
@@ -467,7 +449,7 @@ func TestHandlerGetPollingResult(t *testing.T) {
 		{
 			name:   "GetPollingResult return error",
 			method: http.MethodGet,
-			path:   "/pollings/1/result",
+			id:     "1",
 			setupMocks: func(svc *mocks.PollServiceMock) {
 				svc.On("GetPollingResult", mock.Anything, int64(1)).
 					Return(nil, helper.NewAppError("NOT_FOUND", assert.AnError.Error(), assert.AnError))
@@ -478,7 +460,7 @@ func TestHandlerGetPollingResult(t *testing.T) {
 		{
 			name:   "success",
 			method: http.MethodGet,
-			path:   "/pollings/1/result",
+			id:     "1",
 			setupMocks: func(svc *mocks.PollServiceMock) {
 				svc.On("GetPollingResult", mock.Anything, int64(1)).
 					Return(&dto.ResultPolling{}, nil)
@@ -493,7 +475,8 @@ func TestHandlerGetPollingResult(t *testing.T) {
 			svc := new(mocks.PollServiceMock)
 			tt.setupMocks(svc)
 
-			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req := httptest.NewRequest(tt.method, "/pollings/"+tt.id, nil)
+			req.SetPathValue("id", tt.id)
 			rr := httptest.NewRecorder()
 
 			h := &Polling{Service: svc}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"native-free-pollings/helper"
+	"native-free-pollings/response"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pollETag is the strong ETag of a poll at version.
+func pollETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatchVersion reads the poll version a write is conditioned on from
+// If-Match. Zero means no condition: the header is absent (and not
+// required) or "*". The header may list several ETags (RFC 9110 section
+// 13.1.1); with more than one, the current version is looked up and used
+// if listed. Weak ETags never match. When nothing can match it answers 412,
+// and a missing required header 428; both return false.
+func (p *Polling) ifMatchVersion(w http.ResponseWriter, r *http.Request, pollID int64) (int64, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		if p.RequireIfMatch {
+			response.WriteProblem(w, r, helper.CodePreconditionRequired, "If-Match header is required; send the ETag of the polling")
+			return 0, false
+		}
+		return 0, true
+	}
+	if header == "*" {
+		return 0, true
+	}
+
+	var versions []int64
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		// If-Match uses the strong comparison, so a weak ETag never matches.
+		version, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(tag, `"`), `"`), 10, 64)
+		if err == nil && version > 0 && tag == pollETag(version) {
+			versions = append(versions, version)
+		}
+	}
+
+	switch len(versions) {
+	case 0:
+		response.WriteProblem(w, r, helper.CodePreconditionFailed, "If-Match does not match the current ETag of the polling")
+		return 0, false
+	case 1:
+		// The write itself checks the version, no need to read it first.
+		return versions[0], true
+	}
+
+	poll, err := p.Service.GetDetailPolling(r.Context(), pollID)
+	if err != nil {
+		response.Error(w, r, err)
+		return 0, false
+	}
+	if !slices.Contains(versions, poll.Version) {
+		response.WriteProblem(w, r, helper.CodePreconditionFailed, "If-Match does not match the current ETag of the polling")
+		return 0, false
+	}
+	// Still passed on, so a write that slips in before ours is caught.
+	return poll.Version, true
+}
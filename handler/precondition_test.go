@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		require     bool
+		ifMatch     string
+		wantVersion int64
+		wantOK      bool
+		lookupErr   error
+		wantCode    int
+	}{
+		{name: "absent and optional", wantOK: true},
+		{name: "absent and required", require: true, wantCode: http.StatusPreconditionRequired},
+		{name: "any version", require: true, ifMatch: "*", wantOK: true},
+		{name: "strong etag", ifMatch: `"7"`, wantVersion: 7, wantOK: true},
+		{name: "weak etag", ifMatch: `W/"7"`, wantCode: http.StatusPreconditionFailed},
+		{name: "unquoted", ifMatch: "7", wantCode: http.StatusPreconditionFailed},
+		{name: "not a version", ifMatch: `"abc"`, wantCode: http.StatusPreconditionFailed},
+		{name: "one strong etag among weak ones", ifMatch: `W/"6", "7"`, wantVersion: 7, wantOK: true},
+		{name: "list holding the current etag", ifMatch: `"6", "9"`, wantVersion: 9, wantOK: true},
+		{name: "list without the current etag", ifMatch: `"6","7"`, wantCode: http.StatusPreconditionFailed},
+		{name: "list of a missing polling", ifMatch: `"6", "7"`, lookupErr: domain.ErrPollNotFound, wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PATCH", "/pollings/1", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			rr := httptest.NewRecorder()
+
+			svc := new(mocks.PollServiceMock)
+			if tt.lookupErr != nil {
+				svc.On("GetDetailPolling", mock.Anything, int64(1)).Return(nil, tt.lookupErr)
+			} else {
+				svc.On("GetDetailPolling", mock.Anything, int64(1)).Return(&dto.PollingResponse{ID: 1, Version: 9}, nil)
+			}
+
+			h := &Polling{Service: svc, RequireIfMatch: tt.require}
+			version, ok := h.ifMatchVersion(rr, req, 1)
+
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantVersion, version)
+			if !tt.wantOK {
+				assert.Equal(t, tt.wantCode, rr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"native-free-pollings/config"
+	"native-free-pollings/domain"
+	"native-free-pollings/helper"
+	"native-free-pollings/realtime"
+	"native-free-pollings/response"
+	"net/http"
+	"time"
+)
+
+// streamRetry is the reconnect delay suggested to EventSource clients.
+const streamRetry = 3 * time.Second
+
+type ResultsStreamHandler struct {
+	Service domain.PollService
+	Hub     *realtime.Hub
+	Conf    config.Stream
+}
+
+func NewResultsStreamHandler(svc domain.PollService, hub *realtime.Hub, conf config.Stream) *ResultsStreamHandler {
+	return &ResultsStreamHandler{Service: svc, Hub: hub, Conf: conf}
+}
+
+// Stream Polling Result godoc
+// @Summary      stream polling result
+// @Description  Streams the results of a poll as Server-Sent Events. Each "results" event carries the full tallies, in the same shape as GET /pollings/{id}/results, and is sent when votes are committed or options change. Send the last event ID in Last-Event-ID when reconnecting to skip a snapshot you already have. Comment lines are sent as heartbeats.
+// @Tags         Polling
+// @Produce      text/event-stream
+// @Param id path int true "Poll ID"
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success      200      {object}  dto.ResultPolling "Event stream"
+// @Failure      429      {object}  response.Problem "Too many streams"
+// @Router       /pollings/{id}/results/stream [get]
+func (h *ResultsStreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	pollID, ok := pathID(w, r, "polling")
+	if !ok {
+		return
+	}
+
+	// Subscribing before reading the results means no vote committed in
+	// between is missed.
+	sub, err := h.Hub.Subscribe(pollID, helper.ClientIP(r))
+	if err != nil {
+		if errors.Is(err, realtime.ErrTooManyStreams) {
+			response.WriteProblem(w, r, helper.CodeTooManyStreams, err.Error())
+			return
+		}
+		response.Error(w, r, err)
+		return
+	}
+	defer sub.Close()
+
+	results, err := h.Service.GetPollingResult(r.Context(), pollID)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+	current, err := realtime.NewEvent(pollID, results)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// The server write timeout is meant for ordinary responses; streams are
+	// bounded by MaxDuration instead.
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds()); err != nil {
+		return
+	}
+	if current.ID != lastID {
+		if err := writeEvent(w, current); err != nil {
+			return
+		}
+		lastID = current.ID
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.Conf.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	var expired <-chan time.Time
+	if h.Conf.MaxDuration > 0 {
+		timer := time.NewTimer(h.Conf.MaxDuration)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sub.Done():
+			return
+		case <-expired:
+			return
+		case ev := <-sub.Events():
+			if ev.ID == lastID {
+				continue
+			}
+			if err := writeEvent(w, ev); err != nil {
+				return
+			}
+			lastID = ev.ID
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, ev realtime.Event) error {
+	_, err := fmt.Fprintf(w, "id: %s\nevent: results\ndata: %s\n\n", ev.ID, ev.Data)
+	return err
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"native-free-pollings/config"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/mocks"
+	"native-free-pollings/realtime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamServer(t *testing.T, svc *mocks.PollServiceMock, hub *realtime.Hub) *httptest.Server {
+	h := NewResultsStreamHandler(svc, hub, config.Stream{HeartbeatInterval: 20 * time.Millisecond, MaxDuration: time.Minute})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /pollings/{id}/results/stream", h.Stream)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func openStream(t *testing.T, url, lastEventID string) (*http.Response, *bufio.Reader) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp, bufio.NewReader(resp.Body)
+}
+
+// nextFrame reads one SSE frame, up to the blank line that ends it.
+func nextFrame(t *testing.T, r *bufio.Reader) string {
+	var frame strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		if line == "\n" {
+			return frame.String()
+		}
+		frame.WriteString(line)
+	}
+}
+
+func TestResultsStream(t *testing.T) {
+	initial := &dto.ResultPolling{PollID: 1, TotalVotes: 1, Result: []dto.Vote{{OptionID: 1, OptionLabel: "Go", Votes: 1}}}
+	updated := &dto.ResultPolling{PollID: 1, TotalVotes: 2, Result: []dto.Vote{{OptionID: 1, OptionLabel: "Go", Votes: 2}}}
+
+	svc := new(mocks.PollServiceMock)
+	svc.On("GetPollingResult", mock.Anything, int64(1)).Return(initial, nil)
+	hub := realtime.NewHub(10, 10)
+	srv := newStreamServer(t, svc, hub)
+
+	resp, body := openStream(t, srv.URL+"/pollings/1/results/stream", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	assert.Equal(t, "retry: 3000\n", nextFrame(t, body))
+	first, err := realtime.NewEvent(1, initial)
+	require.NoError(t, err)
+	assert.Equal(t, "id: "+first.ID+"\nevent: results\ndata: "+string(first.Data)+"\n", nextFrame(t, body))
+
+	next, err := realtime.NewEvent(1, updated)
+	require.NoError(t, err)
+	hub.Publish(next)
+	frame := nextFrame(t, body)
+	for frame == ": heartbeat\n" {
+		frame = nextFrame(t, body)
+	}
+	assert.Equal(t, "id: "+next.ID+"\nevent: results\ndata: "+string(next.Data)+"\n", frame)
+
+	// Shutdown ends the stream cleanly.
+	hub.Close()
+	_, err = io.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return hub.Open() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestResultsStream_ResumeSkipsKnownSnapshot(t *testing.T) {
+	results := &dto.ResultPolling{PollID: 1, TotalVotes: 1}
+	svc := new(mocks.PollServiceMock)
+	svc.On("GetPollingResult", mock.Anything, int64(1)).Return(results, nil)
+	srv := newStreamServer(t, svc, realtime.NewHub(10, 10))
+
+	known, err := realtime.NewEvent(1, results)
+	require.NoError(t, err)
+	_, body := openStream(t, srv.URL+"/pollings/1/results/stream", known.ID)
+
+	assert.Equal(t, "retry: 3000\n", nextFrame(t, body))
+	assert.Equal(t, ": heartbeat\n", nextFrame(t, body))
+}
+
+func TestResultsStream_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		setup      func(svc *mocks.PollServiceMock, hub *realtime.Hub)
+		wantCode   int
+		wantDetail string
+	}{
+		{
+			name:       "invalid id",
+			path:       "/pollings/abc/results/stream",
+			setup:      func(svc *mocks.PollServiceMock, hub *realtime.Hub) {},
+			wantCode:   http.StatusBadRequest,
+			wantDetail: "invalid id polling",
+		},
+		{
+			name: "polling not found",
+			path: "/pollings/1/results/stream",
+			setup: func(svc *mocks.PollServiceMock, hub *realtime.Hub) {
+				svc.On("GetPollingResult", mock.Anything, int64(1)).Return(nil, domain.ErrPollNotFound)
+			},
+			wantCode:   http.StatusNotFound,
+			wantDetail: "polling not found",
+		},
+		{
+			name: "too many streams",
+			path: "/pollings/1/results/stream",
+			setup: func(svc *mocks.PollServiceMock, hub *realtime.Hub) {
+				_, err := hub.Subscribe(2, "127.0.0.1")
+				require.NoError(t, err)
+			},
+			wantCode:   http.StatusTooManyRequests,
+			wantDetail: "at most 1 streams per client",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(mocks.PollServiceMock)
+			hub := realtime.NewHub(10, 1)
+			tt.setup(svc, hub)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.RemoteAddr = "127.0.0.1:1234"
+			rr := httptest.NewRecorder()
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /pollings/{id}/results/stream", NewResultsStreamHandler(svc, hub, config.Stream{HeartbeatInterval: time.Second}).Stream)
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantDetail)
+			svc.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"native-free-pollings/helper"
+	"native-free-pollings/response"
+	"net/http"
+)
+
+// requireScope answers 403 and returns false when the request was made with a
+// personal access token that was not granted scope.
+func requireScope(w http.ResponseWriter, r *http.Request, auth *helper.AuthContext, scope string) bool {
+	if auth.HasScope(scope) {
+		return true
+	}
+
+	response.WriteProblem(w, r, helper.CodeInsufficientScope, "token is missing scope "+scope)
+	return false
+}
+
+// requireSession rejects personal access tokens on endpoints that manage
+// credentials, so a leaked automation token cannot escalate itself.
+func requireSession(w http.ResponseWriter, r *http.Request, auth *helper.AuthContext) bool {
+	if auth.IsSession() {
+		return true
+	}
+
+	response.WriteProblem(w, r, helper.CodeInsufficientScope, "this action requires a login session")
+	return false
+}
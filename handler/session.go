@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"native-free-pollings/domain"
+	"native-free-pollings/helper"
+	"native-free-pollings/response"
+	"net/http"
+)
+
+type SessionHandler struct {
+	Service domain.SessionService
+}
+
+func NewSessionHandler(service domain.SessionService) *SessionHandler {
+	return &SessionHandler{Service: service}
+}
+
+// List Sessions godoc
+// @Summary      list active sessions
+// @Description  Lists the devices the user is logged in on, with user agent, IP, and creation and last-seen times. The session making the request is marked as current.
+// @Tags         User
+// @Produce      json
+// @Security BearerAuth
+// @Success      200      {object}  response.Envelope{data=[]dto.SessionResponse}
+// @Router       /users/me/sessions [get]
+func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	resp, err := h.Service.List(r.Context(), auth.UserID, auth.SessionID)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "get sessions successfully", resp)
+}
+
+// Revoke Session godoc
+// @Summary      revoke session
+// @Description  Logs out one session. Its token is rejected from then on.
+// @Tags         User
+// @Produce      json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success      200      {object}  response.Envelope "Success message"
+// @Router       /users/me/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	id, ok := pathID(w, r, "session")
+	if !ok {
+		return
+	}
+
+	if err := h.Service.Revoke(r.Context(), auth.UserID, id); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "revoked session successfully", nil)
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/response"
+	"net/http"
+)
+
+type TwoFactorHandler struct {
+	Service domain.TwoFactorService
+}
+
+func NewTwoFactorHandler(service domain.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{Service: service}
+}
+
+// Setup Two Factor godoc
+// @Summary      start two-factor enrolment
+// @Description  Generates a new TOTP secret and otpauth URI for the logged-in user. 2FA stays disabled until confirmed.
+// @Tags         User
+// @Produce      json
+// @Security BearerAuth
+// @Success      200      {object}  response.Envelope{data=dto.TwoFactorSetupResponse}
+// @Router       /users/me/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	resp, err := h.Service.Setup(r.Context(), auth.UserID)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "scan the uri with an authenticator app and confirm with a code", resp)
+}
+
+// Confirm Two Factor godoc
+// @Summary      confirm two-factor enrolment
+// @Description  Verifies a code from the authenticator app, enables 2FA and returns one-time recovery codes.
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Security BearerAuth
+// @Param        request  body     dto.TwoFactorConfirmRequest  true "TOTP code"
+// @Success      200      {object}  response.Envelope{data=dto.RecoveryCodesResponse}
+// @Router       /users/me/2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	var req dto.TwoFactorConfirmRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if errs, err := helper.BindAndValidate(&req); err != nil {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	resp, err := h.Service.Confirm(r.Context(), auth.UserID, req.Code)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "two-factor authentication enabled", resp)
+}
+
+// Disable Two Factor godoc
+// @Summary      disable two-factor authentication
+// @Description  Disables 2FA for the logged-in user after re-confirming the password. Accounts created through SSO send their email address instead and must have signed in within the last 5 minutes.
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Security BearerAuth
+// @Param        request  body     dto.TwoFactorDisableRequest  true "Current password"
+// @Success      200      {object}  response.Envelope "Success message"
+// @Failure      401      {object}  response.Problem "REAUTH_REQUIRED when an SSO login is too old"
+// @Router       /users/me/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	var req dto.TwoFactorDisableRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if errs, err := helper.BindAndValidate(&req); err != nil {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	if err := h.Service.Disable(r.Context(), auth.UserID, auth.SessionID, req.Password); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "two-factor authentication disabled", nil)
+}
@@ -1,11 +1,11 @@
 package handler
 
 import (
-	"encoding/json"
 	"native-free-pollings/domain"
 	"native-free-pollings/dto"
 	"native-free-pollings/helper"
 	"native-free-pollings/models"
+	"native-free-pollings/response"
 	"net/http"
 )
 
@@ -24,39 +24,25 @@ func NewUserHandler(service domain.UserService) *UserHandler {
 // @Accept       json
 // @Produce      json
 // @Security BearerAuth
-// @Success      200      {object}  dto.ProfileResponse
+// @Success      200      {object}  response.Envelope{data=dto.ProfileResponse}
 // @Router       /users/me [get]
 func (u *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "NOT_ALLOWED",
-			"message": "method not allowed",
-		})
-		return
-	}
-
 	auth, ok := helper.GetAuthContext(r.Context())
 	if !ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_TOKEN",
-			"message": "invalid user id",
-		})
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireScope(w, r, auth, helper.ScopeProfile) {
 		return
 	}
 
 	resp, err := u.Service.GetProfile(r.Context(), auth.UserID)
 	if err != nil {
-		err.(*helper.AppError).WriteError(w)
+		response.Error(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(resp)
+	response.JSON(w, http.StatusOK, "get profile successfully", resp)
 }
 
 // Update Profile godoc
@@ -67,48 +53,25 @@ func (u *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security BearerAuth
 // @Param        request  body     dto.UpdateProfileRequest  true "profile update payload"
-// @Success      200      {object}  dto.ProfileResponse
+// @Success      200      {object}  response.Envelope{data=dto.ProfileResponse}
 // @Router       /users/me [patch]
 func (u *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPatch {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "NOT_ALLOWED",
-			"message": "method not allowed",
-		})
-		return
-	}
-
 	auth, ok := helper.GetAuthContext(r.Context())
 	if !ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_TOKEN",
-			"message": "invalid user id",
-		})
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireScope(w, r, auth, helper.ScopeProfile) {
 		return
 	}
 
 	var req dto.UpdateProfileRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_REQUEST",
-			"message": "invalid request payload",
-		})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	if req.Email == "" && req.Name == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "BAD_REQUEST",
-			"message": "name and email cannot both be empty",
-		})
+		response.WriteProblem(w, r, helper.CodeBadRequest, "name and email cannot both be empty")
 		return
 	}
 
@@ -120,16 +83,11 @@ func (u *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := u.Service.UpdateProfile(r.Context(), user)
 	if err != nil {
-		err.(*helper.AppError).WriteError(w)
+		response.Error(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"message": "profile updated successfully",
-		"data":    resp,
-	})
+	response.JSON(w, http.StatusOK, "profile updated successfully", resp)
 }
 
 // Update Profile godoc
@@ -140,51 +98,29 @@ func (u *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security BearerAuth
 // @Param        request  body     dto.ChangePasswordRequest  true "change password payload"
-// @Success      200      {object}  map[string]string "Success message"
+// @Success      200      {object}  response.Envelope "Success message"
 // @Router       /users/me/change-password [patch]
 func (u *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPatch {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "NOT_ALLOWED",
-			"message": "method not allowed",
-		})
-		return
-	}
-
 	auth, ok := helper.GetAuthContext(r.Context())
 	if !ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_TOKEN",
-			"message": "invalid user id",
-		})
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
 		return
 	}
 
 	var req dto.ChangePasswordRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_REQUEST",
-			"message": "invalid request payload",
-		})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	if err := u.Service.ChangePassword(r.Context(), auth.UserID, req.Password); err != nil {
-		err.(*helper.AppError).WriteError(w)
+		response.Error(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]string{
-		"message": "password updated successfully",
-	})
+	response.JSON(w, http.StatusOK, "password updated successfully", nil)
 }
 
 // Get list polls created godoc
@@ -194,42 +130,25 @@ func (u *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 // @Accept       json
 // @Produce      json
 // @Security BearerAuth
-// @Success      200      {array}  dto.PollingSummaryForCreator
+// @Success      200      {object}  response.Envelope{data=[]dto.PollingSummaryForCreator}
 // @Router       /users/me/pollings/created [get]
 func (u *UserHandler) GetUserCreatedPollings(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "NOT_ALLOWED",
-			"message": "method not allowed",
-		})
-		return
-	}
-
 	auth, ok := helper.GetAuthContext(r.Context())
 	if !ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_TOKEN",
-			"message": "invalid user id",
-		})
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireScope(w, r, auth, helper.ScopePollsRead) {
 		return
 	}
 
 	resp, err := u.Service.GetUserCreatedPollings(r.Context(), auth.UserID)
 	if err != nil {
-		err.(*helper.AppError).WriteError(w)
+		response.Error(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"message": "get pollings successfully",
-		"data":    resp,
-	})
+	response.JSON(w, http.StatusOK, "get pollings successfully", resp)
 }
 
 // Get list polls voted godoc
@@ -239,40 +158,23 @@ func (u *UserHandler) GetUserCreatedPollings(w http.ResponseWriter, r *http.Requ
 // @Accept       json
 // @Produce      json
 // @Security BearerAuth
-// @Success      200      {object}  dto.PollingSummaryForVoter
+// @Success      200      {object}  response.Envelope{data=dto.PollingSummaryForVoter}
 // @Router       /users/me/pollings/voted [get]
 func (u *UserHandler) GetUserVotedPollings(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "NOT_ALLOWED",
-			"message": "method not allowed",
-		})
-		return
-	}
-
 	auth, ok := helper.GetAuthContext(r.Context())
 	if !ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "INVALID_TOKEN",
-			"message": "invalid user id",
-		})
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireScope(w, r, auth, helper.ScopePollsRead) {
 		return
 	}
 
 	resp, err := u.Service.GetUserVotedPollings(r.Context(), auth.UserID)
 	if err != nil {
-		err.(*helper.AppError).WriteError(w)
+		response.Error(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"message": "get pollings successfully",
-		"data":    resp,
-	})
+	response.JSON(w, http.StatusOK, "get pollings successfully", resp)
 }
@@ -24,13 +24,6 @@ func TestHandlerGetProfile(t *testing.T) {
 		wantCode   int
 		wantBody   string
 	}{
-		{
-			name:       "method no allowed",
-			method:     http.MethodPost,
-			setupMocks: func(svc *mocks.UserServiceMock) {},
-			wantCode:   http.StatusMethodNotAllowed,
-			wantBody:   "method not allowed",
-		},
 		{
 			name:       "invalid user id",
 			method:     http.MethodGet,
@@ -75,9 +68,7 @@ func TestHandlerGetProfile(t *testing.T) {
 			h := &UserHandler{Service: svc}
 
 			req := httptest.NewRequest(tt.method, "/profile", nil)
-			if tt.id != nil {
-				req = req.WithContext(context.WithValue(req.Context(), helper.UserIDKey, tt.id))
-			}
+			req = withUser(req, tt.id)
 			rr := httptest.NewRecorder()
 
 			h.GetProfile(rr, req)
@@ -99,13 +90,6 @@ func TestHandlerUpdateProfile(t *testing.T) {
 		wantCode   int
 		wantBody   string
 	}{
-		{
-			name:       "method no allowed",
-			method:     http.MethodGet,
-			setupMocks: func(svc *mocks.UserServiceMock) {},
-			wantCode:   http.StatusMethodNotAllowed,
-			wantBody:   "method not allowed",
-		},
 		{
 			name:       "invalid user id",
 			method:     http.MethodPost,
@@ -121,7 +105,7 @@ func TestHandlerUpdateProfile(t *testing.T) {
 			body:       `{"email": "a@mail.com", "name":}`,
 			setupMocks: func(svc *mocks.UserServiceMock) {},
 			wantCode:   http.StatusBadRequest,
-			wantBody:   "invalid request payload",
+			wantBody:   "malformed JSON at offset 32",
 		},
 		{
 			name:       "bad request",
@@ -170,9 +154,8 @@ func TestHandlerUpdateProfile(t *testing.T) {
 			h := &UserHandler{Service: svc}
 
 			req := httptest.NewRequest(tt.method, "/profile", strings.NewReader(tt.body))
-			if tt.id != nil {
-				req = req.WithContext(context.WithValue(req.Context(), helper.UserIDKey, tt.id))
-			}
+			req.Header.Set("Content-Type", "application/json")
+			req = withUser(req, tt.id)
 			req.Header.Set("Content-Type", "application/json")
 			rr := httptest.NewRecorder()
 
@@ -195,13 +178,6 @@ func TestHandlerChangePassword(t *testing.T) {
 		wantCode   int
 		wantBody   string
 	}{
-		{
-			name:       "method no allowed",
-			method:     http.MethodGet,
-			setupMocks: func(svc *mocks.UserServiceMock) {},
-			wantCode:   http.StatusMethodNotAllowed,
-			wantBody:   "method not allowed",
-		},
 		{
 			name:       "invalid user id",
 			method:     http.MethodPost,
@@ -217,7 +193,7 @@ func TestHandlerChangePassword(t *testing.T) {
 			body:       `{"password":}`,
 			setupMocks: func(svc *mocks.UserServiceMock) {},
 			wantCode:   http.StatusBadRequest,
-			wantBody:   "invalid request payload",
+			wantBody:   "malformed JSON at offset 13",
 		},
 		{
 			name:   "service return error",
@@ -253,9 +229,8 @@ func TestHandlerChangePassword(t *testing.T) {
 			h := &UserHandler{Service: svc}
 
 			req := httptest.NewRequest(tt.method, "/users/me/password", strings.NewReader(tt.body))
-			if tt.id != nil {
-				req = req.WithContext(context.WithValue(req.Context(), helper.UserIDKey, tt.id))
-			}
+			req.Header.Set("Content-Type", "application/json")
+			req = withUser(req, tt.id)
 			req.Header.Set("Content-Type", "application/json")
 			rr := httptest.NewRecorder()
 
@@ -277,14 +252,6 @@ func TestHandlerGetUserCreatedPollings(t *testing.T) {
 		wantCode   int
 		wantBody   string
 	}{
-		{
-			name:       "invalid method",
-			method:     http.MethodPost,
-			id:         "",
-			setupMocks: func(svc *mocks.UserServiceMock) {},
-			wantCode:   http.StatusMethodNotAllowed,
-			wantBody:   "method not allowed",
-		},
 		{
 			name:       "invalid user id",
 			method:     http.MethodGet,
@@ -327,8 +294,7 @@ func TestHandlerGetUserCreatedPollings(t *testing.T) {
 			req := httptest.NewRequest(tt.method, "/users/pollings/creator", nil)
 			rr := httptest.NewRecorder()
 
-			ctx := context.WithValue(req.Context(), helper.UserIDKey, tt.id)
-			req = req.WithContext(ctx)
+			req = withUser(req, tt.id)
 
 			h := &UserHandler{Service: svc}
 			h.GetUserCreatedPollings(rr, req)
@@ -349,14 +315,6 @@ func TestHandlerGetUserVotedPollings(t *testing.T) {
 		wantCode   int
 		wantBody   string
 	}{
-		{
-			name:       "invalid method",
-			method:     http.MethodPost,
-			id:         "",
-			setupMocks: func(svc *mocks.UserServiceMock) {},
-			wantCode:   http.StatusMethodNotAllowed,
-			wantBody:   "method not allowed",
-		},
 		{
 			name:       "invalid user id",
 			method:     http.MethodGet,
@@ -399,8 +357,7 @@ func TestHandlerGetUserVotedPollings(t *testing.T) {
 			req := httptest.NewRequest(tt.method, "/users/pollings/creator", nil)
 			rr := httptest.NewRecorder()
 
-			ctx := context.WithValue(req.Context(), helper.UserIDKey, tt.id)
-			req = req.WithContext(ctx)
+			req = withUser(req, tt.id)
 
 			h := &UserHandler{Service: svc}
 			h.GetUserVotedPollings(rr, req)
@@ -411,3 +368,14 @@ func TestHandlerGetUserVotedPollings(t *testing.T) {
 		})
 	}
 }
+
+// withUser authenticates req as the user with the given ID when id is an
+// int64 and leaves it anonymous otherwise.
+func withUser(req *http.Request, id any) *http.Request {
+	userID, ok := id.(int64)
+	if !ok {
+		return req
+	}
+	auth := &helper.AuthContext{UserID: userID, SessionID: 1}
+	return req.WithContext(context.WithValue(req.Context(), helper.AuthKey, auth))
+}
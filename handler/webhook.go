@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"native-free-pollings/authz"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/response"
+	"net/http"
+)
+
+type WebhookHandler struct {
+	Service domain.WebhookService
+}
+
+func NewWebhookHandler(service domain.WebhookService) *WebhookHandler {
+	return &WebhookHandler{Service: service}
+}
+
+// Create Webhook godoc
+// @Summary      create webhook
+// @Description  Subscribes a URL to poll events, either of one poll the user manages or of every poll the user creates. The signing secret is only returned once.
+// @Tags         Webhook
+// @Accept       json
+// @Produce      json
+// @Security BearerAuth
+// @Param        request  body     dto.CreateWebhookRequest  true "Webhook payload"
+// @Success      201      {object}  response.Envelope{data=dto.WebhookCreatedResponse}
+// @Failure      403      {object}  response.Problem
+// @Failure      404      {object}  response.Problem
+// @Router       /users/me/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	var req dto.CreateWebhookRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if errs, err := helper.BindAndValidate(&req); err != nil {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	resp, err := h.Service.Create(r.Context(), authz.SubjectFromAuth(auth), &req)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, "created webhook successfully", resp)
+}
+
+// List Webhooks godoc
+// @Summary      list webhooks
+// @Description  Lists the webhooks of the logged-in user without their secrets.
+// @Tags         Webhook
+// @Produce      json
+// @Security BearerAuth
+// @Success      200      {object}  response.Envelope{data=[]dto.WebhookResponse}
+// @Router       /users/me/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	resp, err := h.Service.List(r.Context(), auth.UserID)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "get webhooks successfully", resp)
+}
+
+// Update Webhook godoc
+// @Summary      update webhook
+// @Description  Changes the URL or events of a webhook. active=true re-enables a webhook that was disabled after failing repeatedly and resets its failure count.
+// @Tags         Webhook
+// @Accept       json
+// @Produce      json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param        request  body     dto.UpdateWebhookRequest  true "Fields to change"
+// @Success      200      {object}  response.Envelope{data=dto.WebhookResponse}
+// @Failure      404      {object}  response.Problem
+// @Router       /users/me/webhooks/{id} [patch]
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	id, ok := pathID(w, r, "webhook")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWebhookRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	req.ID = id
+
+	if errs, err := helper.BindAndValidate(&req); err != nil {
+		response.ValidationError(w, r, errs)
+		return
+	}
+
+	resp, err := h.Service.Update(r.Context(), auth.UserID, &req)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "updated webhook successfully", resp)
+}
+
+// Delete Webhook godoc
+// @Summary      delete webhook
+// @Description  Deletes a webhook together with its delivery log.
+// @Tags         Webhook
+// @Produce      json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success      200      {object}  response.Envelope "Success message"
+// @Failure      404      {object}  response.Problem
+// @Router       /users/me/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	id, ok := pathID(w, r, "webhook")
+	if !ok {
+		return
+	}
+
+	if err := h.Service.Delete(r.Context(), auth.UserID, id); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "deleted webhook successfully", nil)
+}
+
+// List Webhook Deliveries godoc
+// @Summary      list webhook deliveries
+// @Description  Lists the most recent deliveries of a webhook, newest first, with the log of every attempt.
+// @Tags         Webhook
+// @Produce      json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success      200      {object}  response.Envelope{data=[]dto.WebhookDeliveryResponse}
+// @Failure      404      {object}  response.Problem
+// @Router       /users/me/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	id, ok := pathID(w, r, "webhook")
+	if !ok {
+		return
+	}
+
+	resp, err := h.Service.ListDeliveries(r.Context(), auth.UserID, id)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "get deliveries successfully", resp)
+}
+
+// Redeliver Webhook Delivery godoc
+// @Summary      redeliver webhook event
+// @Description  Queues the event of a past delivery again. The new delivery keeps the event ID so receivers can recognize a repeat.
+// @Tags         Webhook
+// @Produce      json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param deliveryID path int true "Delivery ID"
+// @Success      202      {object}  response.Envelope{data=dto.WebhookDeliveryResponse}
+// @Failure      404      {object}  response.Problem
+// @Router       /users/me/webhooks/{id}/deliveries/{deliveryID}/redeliver [post]
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	auth, ok := helper.GetAuthContext(r.Context())
+	if !ok {
+		response.WriteProblem(w, r, helper.CodeInvalidToken, "invalid user id")
+		return
+	}
+	if !requireSession(w, r, auth) {
+		return
+	}
+
+	id, ok := pathID(w, r, "webhook")
+	if !ok {
+		return
+	}
+	deliveryID, ok := pathValueID(w, r, "deliveryID", "delivery")
+	if !ok {
+		return
+	}
+
+	resp, err := h.Service.Redeliver(r.Context(), auth.UserID, id, deliveryID)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, "queued delivery successfully", resp)
+}
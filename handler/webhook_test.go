@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"context"
+	"native-free-pollings/authz"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandlerCreateWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		auth       *helper.AuthContext
+		body       string
+		setupMocks func(svc *mocks.WebhookServiceMock)
+		wantCode   int
+		wantBody   string
+	}{
+		{
+			name:       "access token",
+			auth:       &helper.AuthContext{UserID: 1, TokenID: 3, Scopes: []string{helper.ScopePollsWrite}},
+			body:       `{"url":"https://hooks.example.com/in","events":["vote.cast"]}`,
+			setupMocks: func(svc *mocks.WebhookServiceMock) {},
+			wantCode:   http.StatusForbidden,
+			wantBody:   "requires a login session",
+		},
+		{
+			name:       "unknown event",
+			auth:       &helper.AuthContext{UserID: 1, SessionID: 1},
+			body:       `{"url":"https://hooks.example.com/in","events":["poll.archived"]}`,
+			setupMocks: func(svc *mocks.WebhookServiceMock) {},
+			wantCode:   http.StatusBadRequest,
+			wantBody:   `"field":"Events[0]"`,
+		},
+		{
+			name:       "no events",
+			auth:       &helper.AuthContext{UserID: 1, SessionID: 1},
+			body:       `{"url":"https://hooks.example.com/in","events":[]}`,
+			setupMocks: func(svc *mocks.WebhookServiceMock) {},
+			wantCode:   http.StatusBadRequest,
+			wantBody:   `"field":"Events"`,
+		},
+		{
+			name: "poll of another user",
+			auth: &helper.AuthContext{UserID: 1, SessionID: 1},
+			body: `{"url":"https://hooks.example.com/in","events":["vote.cast"],"poll_id":3}`,
+			setupMocks: func(svc *mocks.WebhookServiceMock) {
+				svc.On("Create", mock.Anything, authz.Subject{UserID: 1}, mock.AnythingOfType("*dto.CreateWebhookRequest")).
+					Return(nil, domain.ErrForbidden)
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "success",
+			auth: &helper.AuthContext{UserID: 1, SessionID: 1},
+			body: `{"url":"https://hooks.example.com/in","events":["vote.cast","poll.closed"]}`,
+			setupMocks: func(svc *mocks.WebhookServiceMock) {
+				svc.On("Create", mock.Anything, authz.Subject{UserID: 1}, &dto.CreateWebhookRequest{
+					URL:    "https://hooks.example.com/in",
+					Events: []string{"vote.cast", "poll.closed"},
+				}).Return(&dto.WebhookCreatedResponse{WebhookResponse: dto.WebhookResponse{ID: 9, Active: true}, Secret: "whsec_abc"}, nil)
+			},
+			wantCode: http.StatusCreated,
+			wantBody: `"secret":"whsec_abc"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(mocks.WebhookServiceMock)
+			tt.setupMocks(svc)
+
+			h := NewWebhookHandler(svc)
+
+			req := httptest.NewRequest(http.MethodPost, "/users/me/webhooks", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), helper.AuthKey, tt.auth))
+			rr := httptest.NewRecorder()
+
+			h.CreateWebhook(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantBody)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerUpdateWebhook(t *testing.T) {
+	active := true
+
+	svc := new(mocks.WebhookServiceMock)
+	svc.On("Update", mock.Anything, int64(1), &dto.UpdateWebhookRequest{ID: 4, Active: &active}).
+		Return(&dto.WebhookResponse{ID: 4, Active: true}, nil)
+
+	h := NewWebhookHandler(svc)
+
+	req := httptest.NewRequest(http.MethodPatch, "/users/me/webhooks/4", strings.NewReader(`{"active":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.SetPathValue("id", "4")
+	req = withUser(req, int64(1))
+	rr := httptest.NewRecorder()
+
+	h.UpdateWebhook(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"active":true`)
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerRedeliver(t *testing.T) {
+	tests := []struct {
+		name       string
+		deliveryID string
+		setupMocks func(svc *mocks.WebhookServiceMock)
+		wantCode   int
+		wantBody   string
+	}{
+		{
+			name:       "invalid delivery id",
+			deliveryID: "latest",
+			setupMocks: func(svc *mocks.WebhookServiceMock) {},
+			wantCode:   http.StatusBadRequest,
+			wantBody:   "invalid id delivery",
+		},
+		{
+			name:       "not found",
+			deliveryID: "11",
+			setupMocks: func(svc *mocks.WebhookServiceMock) {
+				svc.On("Redeliver", mock.Anything, int64(1), int64(4), int64(11)).
+					Return(nil, helper.NewAppError("NOT_FOUND", "delivery not found", nil))
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:       "success",
+			deliveryID: "11",
+			setupMocks: func(svc *mocks.WebhookServiceMock) {
+				svc.On("Redeliver", mock.Anything, int64(1), int64(4), int64(11)).
+					Return(&dto.WebhookDeliveryResponse{ID: 12, EventID: "evt_1", Status: "pending"}, nil)
+			},
+			wantCode: http.StatusAccepted,
+			wantBody: `"event_id":"evt_1"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(mocks.WebhookServiceMock)
+			tt.setupMocks(svc)
+
+			h := NewWebhookHandler(svc)
+
+			req := httptest.NewRequest(http.MethodPost, "/users/me/webhooks/4/deliveries/"+tt.deliveryID+"/redeliver", nil)
+			req.SetPathValue("id", "4")
+			req.SetPathValue("deliveryID", tt.deliveryID)
+			req = withUser(req, int64(1))
+			rr := httptest.NewRecorder()
+
+			h.Redeliver(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.wantBody)
+			svc.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Package health runs named readiness checks and reports their results.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a plain function to Checker.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error { return f(ctx) }
+
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+func (r Report) OK() bool { return r.Status == StatusOK }
+
+// Registry holds the checks behind the readiness endpoint. Checks run
+// concurrently and each one is cut off after the registry's timeout.
+type Registry struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks map[string]Checker
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout, checks: make(map[string]Checker)}
+}
+
+// Register adds a check, replacing any previous one with the same name.
+func (r *Registry) Register(name string, c Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks[name] = c
+}
+
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := make(map[string]Checker, len(r.checks))
+	for name, c := range r.checks {
+		checks[name] = c
+	}
+	r.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := r.runOne(ctx, c)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (r *Registry) runOne(ctx context.Context, c Checker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- c.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// A check that ignores its context must not hold up the probe.
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Status: StatusOK, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
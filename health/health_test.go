@@ -0,0 +1,76 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Run(t *testing.T) {
+	tests := []struct {
+		name       string
+		checks     map[string]Checker
+		wantStatus string
+		wantChecks map[string]string
+	}{
+		{
+			name:       "no checks is ready",
+			checks:     map[string]Checker{},
+			wantStatus: StatusOK,
+			wantChecks: map[string]string{},
+		},
+		{
+			name: "all passing",
+			checks: map[string]Checker{
+				"database":   CheckerFunc(func(context.Context) error { return nil }),
+				"migrations": CheckerFunc(func(context.Context) error { return nil }),
+			},
+			wantStatus: StatusOK,
+			wantChecks: map[string]string{"database": StatusOK, "migrations": StatusOK},
+		},
+		{
+			name: "one failing",
+			checks: map[string]Checker{
+				"database":   CheckerFunc(func(context.Context) error { return errors.New("connection refused") }),
+				"migrations": CheckerFunc(func(context.Context) error { return nil }),
+			},
+			wantStatus: StatusFail,
+			wantChecks: map[string]string{"database": StatusFail, "migrations": StatusOK},
+		},
+		{
+			name: "slow check times out",
+			checks: map[string]Checker{
+				"database": CheckerFunc(func(context.Context) error {
+					time.Sleep(time.Second)
+					return nil
+				}),
+			},
+			wantStatus: StatusFail,
+			wantChecks: map[string]string{"database": StatusFail},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := NewRegistry(20 * time.Millisecond)
+			for name, c := range tt.checks {
+				reg.Register(name, c)
+			}
+
+			report := reg.Run(context.Background())
+
+			assert.Equal(t, tt.wantStatus, report.Status)
+			got := map[string]string{}
+			for name, result := range report.Checks {
+				got[name] = result.Status
+				if result.Status == StatusFail {
+					assert.NotEmpty(t, result.Error)
+				}
+			}
+			assert.Equal(t, tt.wantChecks, got)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package helper
+
+import "time"
+
+// Backoff returns the delay before retrying after the given number of
+// failed attempts: base, then doubling, capped at max.
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return min(delay, max)
+}
@@ -0,0 +1,18 @@
+package helper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	base, max := 30*time.Second, 10*time.Minute
+
+	assert.Equal(t, 30*time.Second, Backoff(1, base, max))
+	assert.Equal(t, time.Minute, Backoff(2, base, max))
+	assert.Equal(t, 8*time.Minute, Backoff(5, base, max))
+	assert.Equal(t, max, Backoff(6, base, max))
+	assert.Equal(t, max, Backoff(60, base, max))
+}
@@ -0,0 +1,17 @@
+package helper
+
+import (
+	"net"
+	"net/http"
+)
+
+// ClientIP returns the address of the peer that opened the connection.
+// Forwarding headers are ignored on purpose so clients cannot pick their own
+// identity for throttling.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -5,14 +5,19 @@ import "context"
 type ctxKey string
 
 const (
-	UserIDKey ctxKey = "userID"
-	AuthKey   ctxKey = "auth"
+	AuthKey ctxKey = "auth"
+	// RequestIDKey holds the ID set by middleware.RequestID.
+	RequestIDKey ctxKey = "requestID"
 )
 
 type AuthContext struct {
 	UserID    int64
 	UserEmail string
 	UserName  string
+	Role      string
+	SessionID int64
+	TokenID   int64
+	Scopes    []string
 }
 
 func GetAuthContext(ctx context.Context) (*AuthContext, bool) {
@@ -20,3 +25,10 @@ func GetAuthContext(ctx context.Context) (*AuthContext, bool) {
 	auth, ok := v.(*AuthContext)
 	return auth, ok
 }
+
+// GetRequestID returns the ID of the request ctx belongs to, or "" outside
+// of a request.
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIDKey).(string)
+	return id
+}
@@ -1,15 +1,54 @@
 package helper
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/http"
+	"time"
+)
+
+// ErrorCode identifies the kind of an AppError. Every code is registered
+// with its HTTP status in package response; clients receive it as the
+// "code" member of the problem details.
+type ErrorCode string
+
+const (
+	CodeBadRequest           ErrorCode = "BAD_REQUEST"
+	CodeInvalidInput         ErrorCode = "INVALID_INPUT"
+	CodeInvalidRequest       ErrorCode = "INVALID_REQUEST"
+	CodeInvalidID            ErrorCode = "INVALID_ID"
+	CodeValidationError      ErrorCode = "VALIDATION_ERROR"
+	CodePayloadTooLarge      ErrorCode = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMedia     ErrorCode = "UNSUPPORTED_MEDIA_TYPE"
+	CodeLoginFailed          ErrorCode = "LOGIN_FAILED"
+	CodeInvalidCode          ErrorCode = "INVALID_VERIFICATION_CODE"
+	CodeAuthFailed           ErrorCode = "AUTH_FAILED"
+	CodeReauthRequired       ErrorCode = "REAUTH_REQUIRED"
+	CodeInvalidToken         ErrorCode = "INVALID_TOKEN"
+	CodeExpiredToken         ErrorCode = "EXPIRED_TOKEN"
+	CodeTokenNotValidYet     ErrorCode = "NOT_VALID"
+	CodeForbidden            ErrorCode = "FORBIDDEN_ERROR"
+	CodeAccountDisabled      ErrorCode = "ACCOUNT_DISABLED"
+	CodeInsufficientScope    ErrorCode = "INSUFFICIENT_SCOPE"
+	CodeNotFound             ErrorCode = "NOT_FOUND"
+	CodeNotAllowed           ErrorCode = "NOT_ALLOWED"
+	CodeEmailExist           ErrorCode = "EMAIL_EXIST"
+	CodeAlreadyVoted         ErrorCode = "ALREADY_VOTED"
+	CodeOptionHasVotes       ErrorCode = "OPTION_HAS_VOTES"
+	CodePreconditionFailed   ErrorCode = "PRECONDITION_FAILED"
+	CodePreconditionRequired ErrorCode = "PRECONDITION_REQUIRED"
+	CodeTooManyAttempts      ErrorCode = "TOO_MANY_ATTEMPTS"
+	CodeTooManyStreams       ErrorCode = "TOO_MANY_STREAMS"
+	CodeSessionFull          ErrorCode = "SESSION_FULL"
+	CodeInternalError        ErrorCode = "INTERNAL_ERROR"
+	CodeDBError              ErrorCode = "DB_ERROR"
+	CodeHashFailed           ErrorCode = "HASH_FAILED"
+	CodeTokenFailed          ErrorCode = "TOKEN_FAILED"
 )
 
 type AppError struct {
-	Code    string
-	Message string
-	Err     error
+	Code       ErrorCode
+	Message    string
+	Err        error
+	RetryAfter time.Duration
 }
 
 func (e *AppError) Error() string {
@@ -19,38 +58,11 @@ func (e *AppError) Error() string {
 	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
 }
 
-func NewAppError(code, message string, err error) *AppError {
-	return &AppError{Code: code, Message: message, Err: err}
+// Unwrap lets errors.Is and errors.As reach the cause.
+func (e *AppError) Unwrap() error {
+	return e.Err
 }
 
-func (e *AppError) WriteError(w http.ResponseWriter) {
-	status := http.StatusInternalServerError
-	switch e.Code {
-	case "INVALID_INPUT":
-		status = http.StatusBadRequest
-	case "EMAIL_EXIST":
-		status = http.StatusConflict
-	case "AUTH_FAILED":
-		status = http.StatusUnauthorized
-	case "LOGIN_FAILED":
-		status = http.StatusBadRequest
-	case "TOKEN_FAILED":
-		status = http.StatusInternalServerError
-	case "NOT_FOUND":
-		status = http.StatusNotFound
-	case "BAD_REQUEST":
-		status = http.StatusBadRequest
-	case "INTERNAL_ERROR":
-		status = http.StatusInternalServerError
-	case "FORBIDDEN_ERROR":
-		status = http.StatusForbidden
-	case "ALREADY_VOTED":
-		status = http.StatusConflict
-	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(map[string]string{
-		"code":    e.Code,
-		"message": e.Message,
-	})
+func NewAppError(code ErrorCode, message string, err error) *AppError {
+	return &AppError{Code: code, Message: message, Err: err}
 }
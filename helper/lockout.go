@@ -0,0 +1,32 @@
+package helper
+
+import "time"
+
+// LockoutPolicy has the fields of config.Lockout, so the configured one
+// converts directly.
+type LockoutPolicy struct {
+	MaxAccountFailures int
+	MaxIPFailures      int
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+	Window             time.Duration
+}
+
+// LockDuration returns how long a key must stay locked after the given number
+// of consecutive failures. Below the threshold no lock is applied; above it the
+// delay doubles with every extra failure until MaxDelay is reached.
+func (p LockoutPolicy) LockDuration(failures, threshold int) time.Duration {
+	if threshold <= 0 || failures < threshold {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := threshold; i < failures; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+
+	return delay
+}
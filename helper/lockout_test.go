@@ -0,0 +1,17 @@
+package helper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockoutPolicy_LockDuration(t *testing.T) {
+	policy := LockoutPolicy{BaseDelay: time.Minute, MaxDelay: 5 * time.Minute}
+
+	assert.Equal(t, time.Duration(0), policy.LockDuration(2, 3))
+	assert.Equal(t, time.Minute, policy.LockDuration(3, 3))
+	assert.Equal(t, 4*time.Minute, policy.LockDuration(5, 3))
+	assert.Equal(t, 5*time.Minute, policy.LockDuration(9, 3))
+}
@@ -0,0 +1,54 @@
+package helper
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// PurposeOIDCFlow marks the signed cookie that carries the state, nonce and
+// PKCE verifier of an OpenID Connect login between redirect and callback.
+const PurposeOIDCFlow = "oidc"
+
+type OIDCFlow struct {
+	State    string
+	Nonce    string
+	Verifier string
+}
+
+func SealOIDCFlow(flow OIDCFlow, exp time.Time, jwtKey []byte) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"purpose":  PurposeOIDCFlow,
+		"state":    flow.State,
+		"nonce":    flow.Nonce,
+		"verifier": flow.Verifier,
+		"exp":      exp.Unix(),
+	})
+
+	return token.SignedString(jwtKey)
+}
+
+func OpenOIDCFlow(tokenString string, jwtKey []byte) (*OIDCFlow, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return jwtKey, nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+
+	if claims["purpose"] != PurposeOIDCFlow {
+		return nil, errors.New("not an oidc flow token")
+	}
+
+	flow := &OIDCFlow{}
+	flow.State, _ = claims["state"].(string)
+	flow.Nonce, _ = claims["nonce"].(string)
+	flow.Verifier, _ = claims["verifier"].(string)
+	if flow.State == "" || flow.Nonce == "" || flow.Verifier == "" {
+		return nil, errors.New("incomplete oidc flow token")
+	}
+
+	return flow, nil
+}
@@ -0,0 +1,85 @@
+package helper
+
+import (
+	"native-free-pollings/config"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// PollStatuses are the states a poll can be in, matching the check
+// constraint on polls.status.
+var PollStatuses = []string{"draft", "active", "closed", "archived"}
+
+// pollLimits holds the bounds used by the poll rules. It is set once at
+// startup, before requests are served.
+var pollLimits = config.Default().Polls
+
+// PollLimits returns the bounds currently enforced, for checks that need
+// stored data such as the number of options a poll already has.
+func PollLimits() config.Polls {
+	return pollLimits
+}
+
+// SetPollLimits replaces the bounds enforced by the poll validation rules.
+func SetPollLimits(limits config.Polls) {
+	pollLimits = limits
+}
+
+func init() {
+	rules := map[string]validator.Func{
+		"notblank":     notBlank,
+		"pollstatus":   pollStatus,
+		"polltitle":    maxChars(func() int { return pollLimits.MaxTitleLength }),
+		"polldesc":     maxChars(func() int { return pollLimits.MaxDescriptionLength }),
+		"optionlabel":  maxChars(func() int { return pollLimits.MaxOptionLength }),
+		"polloptions":  pollOptions,
+		"uniquelabels": uniqueLabels,
+	}
+	for tag, fn := range rules {
+		if err := validate.RegisterValidation(tag, fn); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func notBlank(fl validator.FieldLevel) bool {
+	return strings.TrimSpace(fl.Field().String()) != ""
+}
+
+func pollStatus(fl validator.FieldLevel) bool {
+	status := fl.Field().String()
+	for _, s := range PollStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func maxChars(limit func() int) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return utf8.RuneCountInString(fl.Field().String()) <= limit()
+	}
+}
+
+func pollOptions(fl validator.FieldLevel) bool {
+	n := fl.Field().Len()
+	return n >= pollLimits.MinOptions && n <= pollLimits.MaxOptions
+}
+
+// uniqueLabels reports whether no two labels are equal, ignoring case and
+// surrounding spaces.
+func uniqueLabels(fl validator.FieldLevel) bool {
+	labels := fl.Field()
+	seen := make(map[string]bool, labels.Len())
+	for i := 0; i < labels.Len(); i++ {
+		label := strings.ToLower(strings.TrimSpace(labels.Index(i).String()))
+		if seen[label] {
+			return false
+		}
+		seen[label] = true
+	}
+	return true
+}
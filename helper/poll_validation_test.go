@@ -0,0 +1,119 @@
+package helper
+
+import (
+	"native-free-pollings/config"
+	"native-free-pollings/dto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollValidation(t *testing.T) {
+	defer SetPollLimits(pollLimits)
+	SetPollLimits(config.Polls{MaxTitleLength: 10, MaxDescriptionLength: 20, MaxOptionLength: 5, MinOptions: 2, MaxOptions: 3})
+
+	start := time.Date(2025, 10, 26, 9, 0, 0, 0, time.UTC)
+	validCreate := func() *dto.CreatePollingRequest {
+		return &dto.CreatePollingRequest{
+			Title: "Languages", Description: "Pick one", Status: "active",
+			StartsAt: start, EndsAt: start.Add(time.Hour), Options: []string{"Go", "Rust"},
+		}
+	}
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name string
+		req  any
+		want []ValidatorError
+	}{
+		{name: "valid create", req: validCreate()},
+		{name: "valid update", req: &dto.UpdatePollingRequest{ID: 1, Title: str("Languages"), Status: str("draft")}},
+		{
+			name: "title limits are in characters",
+			req:  func() any { r := validCreate(); r.Title = strings.Repeat("é", 10); return r }(),
+		},
+		{
+			name: "long title",
+			req:  func() any { r := validCreate(); r.Title = strings.Repeat("a", 11); return r }(),
+			want: []ValidatorError{{Field: "Title", Message: "must be at most 10 characters"}},
+		},
+		{
+			name: "blank title",
+			req:  func() any { r := validCreate(); r.Title = "   "; return r }(),
+			want: []ValidatorError{{Field: "Title", Message: "must not be blank"}},
+		},
+		{
+			name: "long description",
+			req:  func() any { r := validCreate(); r.Description = strings.Repeat("a", 21); return r }(),
+			want: []ValidatorError{{Field: "Description", Message: "must be at most 20 characters"}},
+		},
+		{
+			name: "unknown status",
+			req:  func() any { r := validCreate(); r.Status = "open"; return r }(),
+			want: []ValidatorError{{Field: "Status", Message: "must be one of draft active closed archived"}},
+		},
+		{
+			name: "ends before start",
+			req:  func() any { r := validCreate(); r.EndsAt = start.Add(-time.Hour); return r }(),
+			want: []ValidatorError{{Field: "EndsAt", Message: "must be after StartsAt"}},
+		},
+		{
+			name: "ends at start",
+			req:  func() any { r := validCreate(); r.EndsAt = start; return r }(),
+			want: []ValidatorError{{Field: "EndsAt", Message: "must be after StartsAt"}},
+		},
+		{
+			name: "single option",
+			req:  func() any { r := validCreate(); r.Options = []string{"Go"}; return r }(),
+			want: []ValidatorError{{Field: "Options", Message: "must have between 2 and 3 options"}},
+		},
+		{
+			name: "too many options",
+			req:  func() any { r := validCreate(); r.Options = []string{"a", "b", "c", "d"}; return r }(),
+			want: []ValidatorError{{Field: "Options", Message: "must have between 2 and 3 options"}},
+		},
+		{
+			name: "duplicate labels ignore case and spaces",
+			req:  func() any { r := validCreate(); r.Options = []string{"Go", " go "}; return r }(),
+			want: []ValidatorError{{Field: "Options", Message: "must not contain duplicate labels"}},
+		},
+		{
+			name: "empty and long labels",
+			req:  func() any { r := validCreate(); r.Options = []string{"", "Kotlin"}; return r }(),
+			want: []ValidatorError{
+				{Field: "Options[0]", Message: "must not be blank"},
+				{Field: "Options[1]", Message: "must be at most 5 characters"},
+			},
+		},
+		{name: "empty update", req: &dto.UpdatePollingRequest{ID: 1}},
+		{
+			name: "update only checks given fields",
+			req:  &dto.UpdatePollingRequest{ID: 1, Title: str(" "), Status: str("open")},
+			want: []ValidatorError{
+				{Field: "Title", Message: "must not be blank"},
+				{Field: "Status", Message: "must be one of draft active closed archived"},
+			},
+		},
+		{
+			name: "long new option",
+			req:  &dto.CreateOptionRequest{Label: "Kotlin"},
+			want: []ValidatorError{{Field: "Label", Message: "must be at most 5 characters"}},
+		},
+		{
+			name: "reorder with duplicates",
+			req:  &dto.ReorderOptionsRequest{OptionIDs: []int64{1, 2, 1}},
+			want: []ValidatorError{{Field: "OptionIDs", Message: "must not contain duplicates"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := BindAndValidate(tt.req)
+
+			assert.Equal(t, tt.want, errs)
+			assert.Equal(t, tt.want != nil, err != nil)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package helper
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"strings"
+)
+
+const (
+	ScopePollsRead  = "polls:read"
+	ScopePollsWrite = "polls:write"
+	ScopeVotesWrite = "votes:write"
+	ScopeProfile    = "profile"
+)
+
+// AccessTokenPrefix identifies personal access tokens so middleware.Auth can
+// tell them apart from JWTs without trying to parse them.
+const AccessTokenPrefix = "nfp_"
+
+// HasScope reports whether the request may act with scope. Login sessions
+// (JWTs) carry no scope list and are allowed everything.
+func (a *AuthContext) HasScope(scope string) bool {
+	if a.Scopes == nil {
+		return true
+	}
+	return slices.Contains(a.Scopes, scope)
+}
+
+// IsSession reports whether the request was authenticated with a login token
+// rather than a personal access token.
+func (a *AuthContext) IsSession() bool {
+	return a.TokenID == 0
+}
+
+func GenerateAccessToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, AccessTokenPrefix)
+}
+
+func HashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
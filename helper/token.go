@@ -2,15 +2,23 @@ package helper
 
 import (
 	"errors"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// PurposeTwoFactor marks a short-lived token that only proves the password
+// step of a two-factor login. It must never be accepted as an access token.
+const PurposeTwoFactor = "2fa"
+
 type Claims struct {
-	UserID int64  `json:"user_id"`
-	Name   string `json:"name"`
-	Email  string `json:"email"`
-	Exp    int64  `json:"exp"`
+	UserID    int64  `json:"user_id"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID int64  `json:"sid"`
+	Exp       int64  `json:"exp"`
+	Purpose   string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -19,6 +27,8 @@ func CreateToken(i *Claims, jwtKey []byte) (*string, error) {
 		"user_id": i.UserID,
 		"name":    i.Name,
 		"email":   i.Email,
+		"role":    i.Role,
+		"sid":     i.SessionID,
 		"exp":     i.Exp,
 	})
 
@@ -30,6 +40,29 @@ func CreateToken(i *Claims, jwtKey []byte) (*string, error) {
 	return &tokenString, nil
 }
 
+func CreateChallengeToken(userID int64, email string, exp time.Time, jwtKey []byte) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"email":   email,
+		"purpose": PurposeTwoFactor,
+		"exp":     exp.Unix(),
+	})
+
+	return token.SignedString(jwtKey)
+}
+
+func ExtractChallengeToken(tokenString string, jwtKey []byte) (*Claims, error) {
+	claims, err := ExtractToken(tokenString, jwtKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != PurposeTwoFactor {
+		return nil, errors.New("not a two-factor challenge token")
+	}
+	return claims, nil
+}
+
 func ExtractToken(tokenString string, jwtKey []byte) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
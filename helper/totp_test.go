@@ -0,0 +1,65 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// RFC 6238 appendix B, SHA1 key "12345678901234567890", truncated to 6 digits.
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := TOTPCode(secret, TOTPStep(time.Unix(tt.unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, code)
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	assert.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	code, _ := TOTPCode(secret, TOTPStep(now)-1)
+
+	step, ok := VerifyTOTP(secret, code, now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, TOTPStep(now)-1, step)
+
+	_, ok = VerifyTOTP(secret, code, now.Add(2*time.Minute), 1)
+	assert.False(t, ok)
+
+	_, ok = VerifyTOTP(secret, "12345", now, 1)
+	assert.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("Free Polling", "a@mail.com", "ABC")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Free%20Polling:a@mail.com?"))
+	assert.Contains(t, uri, "secret=ABC")
+	assert.Contains(t, uri, "issuer=Free+Polling")
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(3)
+	assert.NoError(t, err)
+	assert.Len(t, codes, 3)
+
+	assert.Equal(t, HashRecoveryCode(codes[0]), HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))))
+	assert.NotEqual(t, HashRecoveryCode(codes[0]), HashRecoveryCode(codes[1]))
+}
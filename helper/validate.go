@@ -1,6 +1,11 @@
 package helper
 
-import "github.com/go-playground/validator/v10"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
 
 var validate = validator.New()
 
@@ -14,7 +19,7 @@ func BindAndValidate(dst any) ([]ValidatorError, error) {
 		var errs []ValidatorError
 		for _, e := range err.(validator.ValidationErrors) {
 			errs = append(errs, ValidatorError{
-				Field:   e.Field(),
+				Field:   fieldPath(e),
 				Message: validationMessage(e),
 			})
 		}
@@ -38,7 +43,37 @@ func validationMessage(e validator.FieldError) string {
 		return "must be greater than or equal to " + e.Param()
 	case "lte":
 		return "must be less than or equal to " + e.Param()
+	case "oneof":
+		return "must be one of " + e.Param()
+	case "gtfield":
+		return "must be after " + e.Param()
+	case "unique":
+		return "must not contain duplicates"
+	case "notblank":
+		return "must not be blank"
+	case "pollstatus":
+		return "must be one of " + strings.Join(PollStatuses, " ")
+	case "polltitle":
+		return fmt.Sprintf("must be at most %d characters", pollLimits.MaxTitleLength)
+	case "polldesc":
+		return fmt.Sprintf("must be at most %d characters", pollLimits.MaxDescriptionLength)
+	case "optionlabel":
+		return fmt.Sprintf("must be at most %d characters", pollLimits.MaxOptionLength)
+	case "polloptions":
+		return fmt.Sprintf("must have between %d and %d options", pollLimits.MinOptions, pollLimits.MaxOptions)
+	case "uniquelabels":
+		return "must not contain duplicate labels"
 	default:
 		return "is not valid"
 	}
 }
+
+// fieldPath names the field relative to the validated struct, so errors in
+// nested values read like "Options[1].Label" rather than just "Label".
+func fieldPath(e validator.FieldError) string {
+	ns := e.Namespace()
+	if i := strings.IndexByte(ns, '.'); i >= 0 {
+		return ns[i+1:]
+	}
+	return ns
+}
@@ -0,0 +1,58 @@
+// Package logging builds the process-wide slog logger. Records logged with a
+// request context carry its request and trace IDs, so every line can be
+// matched to the request that produced it.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"native-free-pollings/config"
+	"native-free-pollings/helper"
+	"native-free-pollings/tracing"
+)
+
+// New returns a logger writing conf.Format records of at least conf.Level
+// to w.
+func New(w io.Writer, conf config.Log) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLevel(conf.Level)}
+
+	var h slog.Handler
+	if conf.Format == "text" {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(contextHandler{h})
+}
+
+func parseLevel(level string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+// contextHandler adds request_id and trace_id from the record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := helper.GetRequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		r.AddAttrs(slog.String("trace_id", traceID))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"native-free-pollings/config"
+	"native-free-pollings/helper"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	traced := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected map[string]any
+	}{
+		{
+			name:     "Without request",
+			ctx:      context.Background(),
+			expected: map[string]any{"level": "INFO", "msg": "hello"},
+		},
+		{
+			name: "Request ID",
+			ctx:  context.WithValue(context.Background(), helper.RequestIDKey, "abc"),
+			expected: map[string]any{
+				"level": "INFO", "msg": "hello", "request_id": "abc",
+			},
+		},
+		{
+			name: "Request and trace ID",
+			ctx:  context.WithValue(traced, helper.RequestIDKey, "abc"),
+			expected: map[string]any{
+				"level": "INFO", "msg": "hello", "request_id": "abc",
+				"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			New(&buf, config.Log{Level: "info", Format: "json"}).InfoContext(tt.ctx, "hello")
+
+			var got map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+			delete(got, "time")
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestNewLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, config.Log{Level: "warn", Format: "text"})
+
+	logger.Info("dropped")
+	logger.Warn("kept", "user_id", 7)
+
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), "level=WARN msg=kept user_id=7")
+}
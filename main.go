@@ -1,17 +1,34 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"log"
+	"log/slog"
 	"native-free-pollings/config"
 	"native-free-pollings/database"
+	"native-free-pollings/domain"
+	"native-free-pollings/events"
 	"native-free-pollings/handler"
+	"native-free-pollings/health"
 	"native-free-pollings/helper"
+	"native-free-pollings/logging"
+	"native-free-pollings/metrics"
 	"native-free-pollings/middleware"
+	"native-free-pollings/migrations"
+	"native-free-pollings/oidc"
+	"native-free-pollings/realtime"
 	"native-free-pollings/repository"
+	"native-free-pollings/router"
+	"native-free-pollings/server"
 	"native-free-pollings/service"
-	"net/http"
-	"strings"
+	"native-free-pollings/tracing"
+	"native-free-pollings/webhook"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 // @title Free Polling API
@@ -25,105 +42,223 @@ import (
 // @in header
 // @name Authorization
 func main() {
-	conf := config.Get()
+	conf, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(os.Stderr, usage)
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logging.New(os.Stdout, conf.Log))
+	helper.SetPollLimits(conf.Polls)
+
+	cmd := "serve"
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "serve":
+		serve(conf, args)
+	case "migrate":
+		migrate(conf, args)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+}
+
+const usage = `Usage:
+  native-free-pollings [flags] [serve] [-migrate]
+  native-free-pollings [flags] migrate up|down [N]|status|redo|force VERSION
+
+Run with -h for the list of flags. Every flag can also be set through its
+environment variable or a YAML file given with -config.
+`
+
+func serve(conf *config.Config, args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	autoMigrate := flags.Bool("migrate", conf.Database.AutoMigrate, "apply pending migrations before serving")
+	flags.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, conf.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+
 	db := database.GetDatabaseConnection(conf.Database)
-	defer db.Close()
+	if err := database.WaitForConnection(ctx, db, conf.Database.ConnectTimeout); err != nil {
+		log.Fatal(err)
+	}
+
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if *autoMigrate {
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+		for _, mig := range applied {
+			slog.Info("Applied migration", "version", mig.Version, "name", mig.Name)
+		}
+	}
+
+	workers := server.NewWorkers()
+
+	readiness := health.NewRegistry(conf.Server.HealthCheckTimeout)
+	readiness.Register("database", health.CheckerFunc(db.PingContext))
+	readiness.Register("migrations", health.CheckerFunc(func(ctx context.Context) error {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if pending > 0 {
+			return fmt.Errorf("%d migrations pending", pending)
+		}
+		return nil
+	}))
+	readiness.Register("workers", workers)
+	healthHandler := handler.NewHealthHandler(readiness)
+
+	metrics.RegisterDBStats(metrics.Default, db)
+
+	jwtKey := []byte(conf.Auth.JwtKey)
 
 	authRepo := repository.NewAuth(db)
-	authServ := service.NewAuthService(authRepo, conf.JwtKey, helper.BcryptHasher{})
+	loginAttempts := repository.NewLoginAttempt(db, helper.LockoutPolicy(conf.Lockout))
+	twoFactorRepo := repository.NewTwoFactor(db)
+	identityRepo := repository.NewIdentity(db)
+	sessionRepo := repository.NewSession(db)
+	sessionServ := service.NewSessionService(sessionRepo)
+	sessionHandler := handler.NewSessionHandler(sessionServ)
+	authServ := service.NewAuthService(authRepo, loginAttempts, twoFactorRepo, identityRepo, sessionRepo, jwtKey, conf.Auth.TokenTTL, helper.BcryptHasher{})
 	authHandler := handler.NewAuthHandler(authServ)
 
+	tokenRepo := repository.NewAccessToken(db)
+	tokenServ := service.NewAccessTokenService(tokenRepo)
+	tokenHandler := handler.NewAccessTokenHandler(tokenServ)
+
 	userRepo := repository.NewUserRepository(db)
 	userServ := service.NewUserService(userRepo, helper.BcryptHasher{})
 	userHandler := handler.NewUserHandler(userServ)
 
+	accountRepo := repository.NewAccount(db)
+	accountServ := service.NewAccountService(accountRepo, userRepo, sessionRepo, helper.BcryptHasher{})
+	accountHandler := handler.NewAccountHandler(accountServ)
+
+	adminServ := service.NewAdminService(userRepo, sessionServ)
+	adminHandler := handler.NewAdminHandler(adminServ)
+
+	twoFactorServ := service.NewTwoFactorService(twoFactorRepo, userRepo, sessionRepo, helper.BcryptHasher{})
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorServ)
+
 	pollRepo := repository.NewPolling(db)
 	optRepo := repository.NewOption(db)
 	voteRepo := repository.NewVote(db)
-	pollServ := service.NewPolling(db, pollRepo, optRepo, voteRepo)
-	pollHandler := handler.NewPolling(pollServ)
-
-	mux := http.NewServeMux()
-
-	mux.HandleFunc("/register", http.HandlerFunc(authHandler.Register))
-	mux.HandleFunc("/login", http.HandlerFunc(authHandler.Login))
-	mux.Handle("/users/me", middleware.Auth(conf.JwtKey)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			userHandler.GetProfile(w, r)
-		case http.MethodPatch:
-			userHandler.UpdateProfile(w, r)
-		default:
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusMethodNotAllowed)
-			_ = json.NewEncoder(w).Encode(map[string]string{
-				"code":    "NOT_ALLOWED",
-				"message": "method not allowed",
-			})
-		}
-	})))
-	mux.Handle("/users/me/change-password", middleware.Auth(conf.JwtKey)(http.HandlerFunc(userHandler.ChangePassword)))
-	mux.Handle("/users/me/pollings/", middleware.Auth(conf.JwtKey)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		parts := strings.Split(r.URL.Path, "/")
-		switch {
-		case len(parts) == 5 && parts[4] == "creator":
-			userHandler.GetUserCreatedPollings(w, r)
-			return
-		case len(parts) == 5 && parts[4] == "voter":
-			userHandler.GetUserVotedPollings(w, r)
-			return
-		}
+	outboxRepo := repository.NewOutbox(db)
+	pollServ := service.NewPolling(db, pollRepo, optRepo, voteRepo, outboxRepo)
+	pollHandler := handler.NewPolling(pollServ, conf.Polls.RequireIfMatch)
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "NOT_FOUND",
-			"message": "request not found",
-		})
-	})))
-
-	mux.Handle("/pollings", middleware.Auth(conf.JwtKey)(http.HandlerFunc(pollHandler.CreatePolling)))
-	mux.HandleFunc("/pollings/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		parts := strings.Split(r.URL.Path, "/")
-
-		if len(parts) == 3 {
-			switch r.Method {
-			case http.MethodGet:
-				pollHandler.GetDetailPolling(w, r)
-			case http.MethodPatch:
-				middleware.Auth(conf.JwtKey)(http.HandlerFunc(pollHandler.UpdatePolling)).ServeHTTP(w, r)
-			case http.MethodDelete:
-				middleware.Auth(conf.JwtKey)(http.HandlerFunc(pollHandler.DeletePolling)).ServeHTTP(w, r)
-			default:
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusMethodNotAllowed)
-				_ = json.NewEncoder(w).Encode(map[string]string{
-					"code":    "NOT_ALLOWED",
-					"message": "method not allowed",
-				})
-			}
-			return
-		}
+	webhookRepo := repository.NewWebhook(db)
+	webhookServ := service.NewWebhookService(db, webhookRepo, pollRepo, conf.Webhooks.AllowPrivate)
+	webhookHandler := handler.NewWebhookHandler(webhookServ)
+	webhookDispatcher := webhook.NewDispatcher(webhookRepo, webhook.NewClient(conf.Webhooks.Timeout, conf.Webhooks.AllowPrivate), conf.Webhooks)
+	workers.Go("webhook-dispatcher", webhookDispatcher.Run)
 
-		switch {
-		case len(parts) == 4 && parts[3] == "votes":
-			middleware.AuthOptional(conf.JwtKey)(http.HandlerFunc(pollHandler.VoteOptionPolling)).ServeHTTP(w, r)
-			return
-		case len(parts) == 4 && parts[3] == "results":
-			pollHandler.GetPollingResult(w, r)
-			return
+	bus := events.NewBus()
+	for _, name := range domain.PollEvents {
+		events.Subscribe(bus, "webhooks", name, webhookServ.HandleEvent)
+	}
+	workers.Go("outbox-dispatcher", events.NewDispatcher(outboxRepo, bus, conf.Outbox).Run)
+
+	resultsHub := realtime.NewHub(conf.Stream.MaxConnections, conf.Stream.MaxPerClient)
+	resultsListener := realtime.NewListener(database.DSN(conf.Database), resultsHub, func(ctx context.Context, pollID int64) (any, error) {
+		return pollServ.GetPollingResult(ctx, pollID)
+	})
+	workers.Go("results-listener", resultsListener.Run)
+	metrics.Default.NewGaugeFunc("polling_result_streams", "Open live results streams.",
+		func() float64 { return float64(resultsHub.Open()) })
+	resultsHandler := handler.NewResultsStreamHandler(pollServ, resultsHub, conf.Stream)
+
+	liveSessions := realtime.NewLiveSessions(resultsHub, conf.Live.MaxParticipants, conf.Live.PresenterGrace)
+	metrics.Default.NewGaugeFunc("polling_live_sessions", "Running live poll sessions.",
+		func() float64 { return float64(liveSessions.Open()) })
+	liveHandler := handler.NewLiveHandler(pollServ, liveSessions, middleware.NewAuthenticator(jwtKey, tokenServ, sessionServ), conf.Live)
+
+	var oidcHandler *handler.OIDCHandler
+	if conf.OIDC.Issuer != "" {
+		oidcClient, err := oidc.NewClient(context.Background(), oidc.Config{
+			Issuer:       conf.OIDC.Issuer,
+			ClientID:     conf.OIDC.ClientID,
+			ClientSecret: conf.OIDC.ClientSecret,
+			RedirectURL:  conf.OIDC.RedirectURL,
+		}, nil)
+		if err != nil {
+			log.Fatalf("Failed to set up OIDC login: %v", err)
 		}
+		oidcHandler = handler.NewOIDCHandler(authServ, oidcClient, jwtKey)
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"code":    "NOT_FOUND",
-			"message": "request not found",
-		})
+	routes := router.New(router.Routes(router.Handlers{
+		Health:       healthHandler,
+		Metrics:      metrics.Default,
+		Auth:         authHandler,
+		OIDC:         oidcHandler,
+		User:         userHandler,
+		Account:      accountHandler,
+		Admin:        adminHandler,
+		TwoFactor:    twoFactorHandler,
+		Token:        tokenHandler,
+		Session:      sessionHandler,
+		Polling:      pollHandler,
+		Results:      resultsHandler,
+		Live:         liveHandler,
+		Webhook:      webhookHandler,
+		RequireAuth:  middleware.Auth(jwtKey, tokenServ, sessionServ),
+		OptionalAuth: middleware.AuthOptional(jwtKey, tokenServ, sessionServ),
 	}))
 
-	handler := middleware.Recovery(middleware.Logging(mux))
+	handler := middleware.RequestID(middleware.Recovery(middleware.Tracing(middleware.Logging(middleware.CORS(conf.CORS)(middleware.BodyLimit(int64(conf.Server.MaxBodyBytes))(middleware.Metrics(routes)))))))
+
+	var certs *server.CertReloader
+	if conf.Server.TLS.CertFile != "" {
+		reloader, err := server.NewCertReloader(conf.Server.TLS.CertFile, conf.Server.TLS.KeyFile)
+		if err != nil {
+			log.Fatalf("Failed to set up TLS: %v", err)
+		}
+		certs = reloader
+		workers.Go("tls-reload", func(ctx context.Context) error {
+			return certs.Watch(ctx, conf.Server.TLS.ReloadInterval)
+		})
+	}
+
+	srv := server.New(conf.Server, handler, certs)
+	// Streams never end on their own, so they are closed as soon as shutdown
+	// starts instead of holding it up until the timeout.
+	srv.RegisterOnShutdown(resultsHub.Close)
+	// Shutdown does not track hijacked connections at all.
+	srv.RegisterOnShutdown(liveSessions.Close)
+
+	scheme := "http"
+	if certs != nil {
+		scheme = "https"
+	}
+	slog.Info("Server running", "url", fmt.Sprintf("%s://%s:%s", scheme, conf.Server.Host, conf.Server.Port))
 
-	fmt.Printf("Server Running at http://%s:%s\n", conf.Server.Host, conf.Server.Port)
-	http.ListenAndServe(":"+conf.Server.Port, handler)
+	err = server.Run(ctx, srv, conf.Server.ShutdownTimeout,
+		workers.Stop,
+		func(context.Context) error { return db.Close() },
+		shutdownTracing,
+	)
+	if err != nil {
+		log.Fatalf("Server stopped with error: %v", err)
+	}
+	slog.Info("Server stopped")
 }
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"database/sql"
+)
+
+// HTTP metrics, recorded by middleware.Metrics. Route is the ServeMux
+// pattern that matched, never the raw path, to keep cardinality bounded.
+var (
+	HTTPRequests = Default.NewCounterVec("http_requests_total",
+		"HTTP requests served, by method, route pattern and status code.",
+		"method", "route", "status")
+	HTTPDuration = Default.NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency in seconds, by method, route pattern and status code.",
+		DefBuckets, "method", "route", "status")
+)
+
+// Business counters.
+var (
+	PollsCreated = Default.NewCounterVec("polling_polls_created_total",
+		"Polls created.")
+	VotesCast = Default.NewCounterVec("polling_votes_cast_total",
+		"Votes recorded, by whether the voter was logged in (user) or not (anonymous).",
+		"voter")
+	VotesAlreadyVoted = Default.NewCounterVec("polling_votes_already_voted_total",
+		"Votes rejected with ALREADY_VOTED because the user or device had voted before.")
+	LoginsFailed = Default.NewCounterVec("polling_logins_failed_total",
+		"Failed logins, by reason: credentials, two_factor or locked.",
+		"reason")
+	WebhookAttempts = Default.NewCounterVec("polling_webhook_attempts_total",
+		"Webhook delivery attempts, by result: succeeded, retrying or failed.",
+		"result")
+	WebhooksDisabled = Default.NewCounterVec("polling_webhooks_disabled_total",
+		"Webhooks disabled after failing too many times in a row.")
+	OutboxEvents = Default.NewCounterVec("polling_outbox_events_total",
+		"Outbox events dispatched to their subscribers, by result: handled, retrying or failed.",
+		"result")
+)
+
+// RegisterDBStats exposes the connection pool statistics of db.
+func RegisterDBStats(r *Registry, db *sql.DB) {
+	stat := func(f func(s sql.DBStats) float64) func() float64 {
+		return func() float64 { return f(db.Stats()) }
+	}
+
+	r.NewGaugeFunc("db_pool_max_open_connections", "Maximum number of open connections to the database.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	r.NewGaugeFunc("db_pool_open_connections", "Established connections, both in use and idle.",
+		stat(func(s sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	r.NewGaugeFunc("db_pool_in_use_connections", "Connections currently in use.",
+		stat(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	r.NewGaugeFunc("db_pool_idle_connections", "Idle connections.",
+		stat(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	r.NewCounterFunc("db_pool_wait_count_total", "Times a query waited for a free connection.",
+		stat(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	r.NewCounterFunc("db_pool_wait_duration_seconds_total", "Total time spent waiting for a free connection.",
+		stat(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+	r.NewCounterFunc("db_pool_max_idle_closed_total", "Connections closed because of max_idle_conns.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }))
+	r.NewCounterFunc("db_pool_max_idle_time_closed_total", "Connections closed because of conn_max_idle_time.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) }))
+	r.NewCounterFunc("db_pool_max_lifetime_closed_total", "Connections closed because of conn_max_lifetime.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }))
+}
@@ -0,0 +1,266 @@
+// Package metrics is a small Prometheus client: counters, histograms and
+// callback gauges written in the text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets suits request latencies in seconds, from 5ms to 10s.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Default is the registry served at /metrics.
+var Default = NewRegistry()
+
+type family interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]family
+}
+
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+func (r *Registry) register(f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.families[f.name()]; ok {
+		panic("metrics: duplicate metric " + f.name())
+	}
+	r.families[f.name()] = f
+}
+
+// NewCounterVec registers a counter. Calls must pass one value per label.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{meta: meta{metricName: name, help: help, labels: labels}, series: map[string]*counterSeries{}}
+	r.register(c)
+	return c
+}
+
+// NewHistogramVec registers a histogram with the given upper bounds.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{meta: meta{metricName: name, help: help, labels: labels}, buckets: buckets, series: map[string]*histogramSeries{}}
+	r.register(h)
+	return h
+}
+
+// NewGaugeFunc registers a gauge whose value is read from fn at scrape time.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{meta: meta{metricName: name, help: help}, kind: "gauge", fn: fn})
+}
+
+// NewCounterFunc registers a counter whose value is read from fn at scrape
+// time, for totals kept elsewhere such as sql.DBStats.
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{meta: meta{metricName: name, help: help}, kind: "counter", fn: fn})
+}
+
+// Write writes every metric, sorted by name.
+func (r *Registry) Write(w *bufio.Writer) error {
+	r.mu.Lock()
+	families := make([]family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(families, func(i, j int) bool { return families[i].name() < families[j].name() })
+	for _, f := range families {
+		f.write(w)
+	}
+
+	return w.Flush()
+}
+
+// ServeHTTP serves the registry in the Prometheus text format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = r.Write(bufio.NewWriter(w))
+}
+
+type meta struct {
+	metricName string
+	help       string
+	labels     []string
+}
+
+func (m meta) name() string { return m.metricName }
+
+func (m meta) writeHeader(w *bufio.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", m.metricName, escapeHelp(m.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", m.metricName, kind)
+}
+
+func (m meta) key(values []string) string {
+	if len(values) != len(m.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", m.metricName, len(m.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+type CounterVec struct {
+	meta
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	labels []string
+	value  float64
+}
+
+func (c *CounterVec) Inc(labelValues ...string) { c.Add(1, labelValues...) }
+
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic("metrics: counters cannot decrease")
+	}
+	key := c.key(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.series[key]
+	if !ok {
+		s = &counterSeries{labels: append([]string(nil), labelValues...)}
+		c.series[key] = s
+	}
+	s.value += v
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.writeHeader(w, "counter")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.series) {
+		s := c.series[key]
+		writeSample(w, c.metricName, c.labels, s.labels, "", "", s.value)
+	}
+}
+
+type HistogramVec struct {
+	meta
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labels []string
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := h.key(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{labels: append([]string(nil), labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, upper := range h.buckets {
+		if v <= upper {
+			s.counts[i]++
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.writeHeader(w, "histogram")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		for i, upper := range h.buckets {
+			writeSample(w, h.metricName+"_bucket", h.labels, s.labels, "le", formatFloat(upper), float64(s.counts[i]))
+		}
+		writeSample(w, h.metricName+"_bucket", h.labels, s.labels, "le", "+Inf", float64(s.count))
+		writeSample(w, h.metricName+"_sum", h.labels, s.labels, "", "", s.sum)
+		writeSample(w, h.metricName+"_count", h.labels, s.labels, "", "", float64(s.count))
+	}
+}
+
+type funcMetric struct {
+	meta
+	kind string
+	fn   func() float64
+}
+
+func (f *funcMetric) write(w *bufio.Writer) {
+	f.writeHeader(w, f.kind)
+	writeSample(w, f.metricName, nil, nil, "", "", f.fn())
+}
+
+func writeSample(w *bufio.Writer, name string, labels, values []string, extraLabel, extraValue string, v float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, label, escapeLabel(values[i]))
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, extraLabel, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	reg := NewRegistry()
+	requests := reg.NewCounterVec("requests_total", "Requests served.", "route", "status")
+	latency := reg.NewHistogramVec("latency_seconds", "Request latency.", []float64{0.1, 1}, "route")
+	reg.NewGaugeFunc("pool_open", "Open connections.", func() float64 { return 3 })
+
+	requests.Inc("/pollings/", "200")
+	requests.Add(2, "/pollings/", "200")
+	requests.Inc(`/odd"path`+"\n", "404")
+	latency.Observe(0.05, "/pollings/")
+	latency.Observe(0.5, "/pollings/")
+	latency.Observe(5, "/pollings/")
+
+	var out strings.Builder
+	assert.NoError(t, reg.Write(bufio.NewWriter(&out)))
+
+	want := `# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/pollings/",le="0.1"} 1
+latency_seconds_bucket{route="/pollings/",le="1"} 2
+latency_seconds_bucket{route="/pollings/",le="+Inf"} 3
+latency_seconds_sum{route="/pollings/"} 5.55
+latency_seconds_count{route="/pollings/"} 3
+# HELP pool_open Open connections.
+# TYPE pool_open gauge
+pool_open 3
+# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/odd\"path\n",status="404"} 1
+requests_total{route="/pollings/",status="200"} 3
+`
+	assert.Equal(t, want, out.String())
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounterVec("votes_total", "Votes.").Inc()
+
+	rec := httptest.NewRecorder()
+	reg.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "votes_total 1\n")
+}
+
+func TestRegistry_Misuse(t *testing.T) {
+	reg := NewRegistry()
+	c := reg.NewCounterVec("things_total", "Things.", "kind")
+
+	assert.Panics(t, func() { reg.NewCounterVec("things_total", "Again.") }, "duplicate name")
+	assert.Panics(t, func() { c.Inc() }, "missing label value")
+	assert.Panics(t, func() { c.Add(-1, "a") }, "negative add")
+}
@@ -2,73 +2,36 @@ package middleware
 
 import (
 	"context"
-	"encoding/json"
+	"native-free-pollings/domain"
 	"native-free-pollings/helper"
+	"native-free-pollings/response"
 	"net/http"
 	"strings"
 	"time"
 )
 
-func Auth(screet []byte) func(http.Handler) http.Handler {
+func Auth(screet []byte, tokens domain.AccessTokenService, sessions domain.SessionService) func(http.Handler) http.Handler {
+	authenticate := NewAuthenticator(screet, tokens, sessions)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				_ = json.NewEncoder(w).Encode(map[string]string{
-					"code":    "INVALID_TOKEN",
-					"message": "missing or invalid token",
-				})
+				response.WriteProblem(w, r, helper.CodeInvalidToken, "missing or invalid token")
 				return
 			}
 
-			token := strings.TrimPrefix(authHeader, "Bearer ")
-			claims, err := helper.ExtractToken(token, screet)
+			auth, err := authenticate(r.Context(), strings.TrimPrefix(authHeader, "Bearer "))
 			if err != nil {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				_ = json.NewEncoder(w).Encode(map[string]string{
-					"code":    "INVALID_TOKEN",
-					"message": err.Error(),
-				})
+				response.Error(w, r, err)
 				return
 			}
-
-			now := time.Now()
-			if claims.ExpiresAt != nil && claims.ExpiresAt.Before(now) {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				_ = json.NewEncoder(w).Encode(map[string]string{
-					"code":    "EXPIRED_TOKEN",
-					"message": "token expired",
-				})
-				return
-			}
-
-			if claims.NotBefore != nil && claims.NotBefore.After(now) {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				_ = json.NewEncoder(w).Encode(map[string]string{
-					"code":    "NOT_VALID",
-					"message": "token not yet valid",
-				})
-				return
-			}
-
-			auth := &helper.AuthContext{
-				UserID:    claims.UserID,
-				UserEmail: claims.Email,
-				UserName:  claims.Name,
-			}
-			ctx := context.WithValue(r.Context(), helper.AuthKey, auth)
-
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(w, withAuth(w, r, auth))
 		})
 	}
 }
 
-func AuthOptional(screet []byte) func(http.Handler) http.Handler {
+func AuthOptional(screet []byte, tokens domain.AccessTokenService, sessions domain.SessionService) func(http.Handler) http.Handler {
+	authenticate := NewAuthenticator(screet, tokens, sessions)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -77,47 +40,72 @@ func AuthOptional(screet []byte) func(http.Handler) http.Handler {
 				return
 			}
 
-			token := strings.TrimPrefix(authHeader, "Bearer ")
-			claims, err := helper.ExtractToken(token, screet)
+			auth, err := authenticate(r.Context(), strings.TrimPrefix(authHeader, "Bearer "))
 			if err != nil {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				_ = json.NewEncoder(w).Encode(map[string]string{
-					"code":    "INVALID_TOKEN",
-					"message": err.Error(),
-				})
+				response.Error(w, r, err)
 				return
 			}
+			next.ServeHTTP(w, withAuth(w, r, auth))
+		})
+	}
+}
 
-			now := time.Now()
-			if claims.ExpiresAt != nil && claims.ExpiresAt.Before(now) {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				_ = json.NewEncoder(w).Encode(map[string]string{
-					"code":    "EXPIRED_TOKEN",
-					"message": "token expired",
-				})
-				return
-			}
+// Authenticator checks a bearer token, either a login JWT or a personal
+// access token, and describes who it belongs to.
+type Authenticator func(ctx context.Context, token string) (*helper.AuthContext, error)
 
-			if claims.NotBefore != nil && claims.NotBefore.After(now) {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				_ = json.NewEncoder(w).Encode(map[string]string{
-					"code":    "NOT_VALID",
-					"message": "token not yet valid",
-				})
-				return
+// NewAuthenticator returns the token check used by Auth and AuthOptional,
+// for transports that receive the token some other way, such as a
+// WebSocket message.
+func NewAuthenticator(screet []byte, tokens domain.AccessTokenService, sessions domain.SessionService) Authenticator {
+	return func(ctx context.Context, token string) (*helper.AuthContext, error) {
+		if helper.IsAccessToken(token) {
+			if tokens == nil {
+				return nil, helper.NewAppError(helper.CodeInvalidToken, "access tokens are not accepted", nil)
 			}
+			return tokens.Authenticate(ctx, token)
+		}
 
-			auth := &helper.AuthContext{
-				UserID:    claims.UserID,
-				UserEmail: claims.Email,
-				UserName:  claims.Name,
-			}
-			ctx := context.WithValue(r.Context(), helper.AuthKey, auth)
+		claims, err := helper.ExtractToken(token, screet)
+		if err != nil {
+			return nil, helper.NewAppError(helper.CodeInvalidToken, err.Error(), err)
+		}
 
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
+		if claims.Purpose != "" {
+			return nil, helper.NewAppError(helper.CodeInvalidToken, "token cannot be used for this request", nil)
+		}
+
+		now := time.Now()
+		if claims.ExpiresAt != nil && claims.ExpiresAt.Before(now) {
+			return nil, helper.NewAppError(helper.CodeExpiredToken, "token expired", nil)
+		}
+
+		if claims.NotBefore != nil && claims.NotBefore.After(now) {
+			return nil, helper.NewAppError(helper.CodeTokenNotValidYet, "token not yet valid", nil)
+		}
+
+		// The role in the token is what it was at login; the session knows
+		// the current one.
+		role, err := sessions.Validate(ctx, claims.UserID, claims.SessionID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &helper.AuthContext{
+			UserID:    claims.UserID,
+			UserEmail: claims.Email,
+			UserName:  claims.Name,
+			Role:      role,
+			SessionID: claims.SessionID,
+		}, nil
+	}
+}
+
+// withAuth stores auth in the request context and tells the logging
+// middleware who made the request.
+func withAuth(w http.ResponseWriter, r *http.Request, auth *helper.AuthContext) *http.Request {
+	if rec := findRecorder(w); rec != nil {
+		rec.userID = auth.UserID
 	}
+	return r.WithContext(context.WithValue(r.Context(), helper.AuthKey, auth))
 }
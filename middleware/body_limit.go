@@ -0,0 +1,14 @@
+package middleware
+
+import "net/http"
+
+// BodyLimit caps every request body at limit bytes. Reading past it fails
+// with *http.MaxBytesError, which the JSON decoder answers with 413.
+func BodyLimit(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -1,18 +1,60 @@
 package middleware
 
-import "net/http"
+import (
+	"native-free-pollings/config"
+	"net/http"
+	"strconv"
+	"strings"
+)
 
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// CORS answers preflight requests and adds CORS headers for the configured
+// origins. With no allowed origins it is a no-op.
+func CORS(conf config.CORS) func(http.Handler) http.Handler {
+	allowAny := false
+	origins := make(map[string]bool, len(conf.AllowedOrigins))
+	for _, origin := range conf.AllowedOrigins {
+		if origin == "*" {
+			allowAny = true
+		}
+		origins[strings.ToLower(origin)] = true
+	}
+	methods := strings.Join(conf.AllowedMethods, ", ")
+	headers := strings.Join(conf.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(conf.MaxAge.Seconds()))
 
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusOK)
-			return
+	return func(next http.Handler) http.Handler {
+		if len(origins) == 0 {
+			return next
 		}
 
-		next.ServeHTTP(w, r)
-	})
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Origin")
+
+			origin := r.Header.Get("Origin")
+			if origin == "" || !(allowAny || origins[strings.ToLower(origin)]) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if allowAny {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			if conf.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", methods)
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			w.Header().Set("Access-Control-Expose-Headers", requestIDHeader+", ETag")
+			next.ServeHTTP(w, r)
+		})
+	}
 }
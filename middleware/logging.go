@@ -1,15 +1,51 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 )
 
+// Logging writes one record per request with its outcome. Server errors
+// are logged at error level together with their cause, which handlers
+// report through response.ErrorRecorder instead of sending it to the client.
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		next.ServeHTTP(w, r)
-		log.Printf("%s %s %v", r.Method, r.URL.Path, time.Since(start))
+		rec := newResponseRecorder(w)
+
+		defer func() {
+			status := rec.status
+			p := recover()
+			if p != nil {
+				status = http.StatusInternalServerError
+				defer panic(p)
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int64("bytes", rec.bytes),
+				slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("remote_addr", r.RemoteAddr),
+			}
+			if rec.userID != 0 {
+				attrs = append(attrs, slog.Int64("user_id", rec.userID))
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+				if rec.err != nil {
+					attrs = append(attrs, slog.String("error", rec.err.Error()))
+				} else if p != nil {
+					attrs = append(attrs, slog.Any("panic", p))
+				}
+			}
+			slog.LogAttrs(r.Context(), level, "request", attrs...)
+		}()
+
+		next.ServeHTTP(rec, r)
 	})
 }
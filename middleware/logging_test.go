@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"native-free-pollings/config"
+	"native-free-pollings/helper"
+	"native-free-pollings/logging"
+	"native-free-pollings/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogging(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		handler   http.HandlerFunc
+		expected  map[string]any
+	}{
+		{
+			name:      "Success with client request ID",
+			requestID: "client-id-1",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				withAuth(w, r, &helper.AuthContext{UserID: 7})
+				_, _ = w.Write([]byte("hello"))
+			},
+			expected: map[string]any{
+				"level": "INFO", "status": float64(200), "bytes": float64(5),
+				"user_id": float64(7), "request_id": "client-id-1",
+			},
+		},
+		{
+			name:      "Server error logs cause",
+			requestID: "has space",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				response.Error(w, r, helper.NewAppError("INTERNAL_ERROR", "failed to create poll", errors.New("connection reset")))
+			},
+			expected: map[string]any{
+				"level": "ERROR", "status": float64(500),
+				"error": "[INTERNAL_ERROR] failed to create poll: connection reset",
+			},
+		},
+		{
+			name: "Client error is not logged as failure",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				response.Error(w, r, helper.NewAppError("NOT_FOUND", "polling not found", errors.New("sql: no rows")))
+			},
+			expected: map[string]any{"level": "INFO", "status": float64(404)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			defer slog.SetDefault(slog.Default())
+			slog.SetDefault(logging.New(&buf, config.Log{Level: "info", Format: "json"}))
+
+			req := httptest.NewRequest(http.MethodGet, "/pollings/1", nil)
+			if tt.requestID != "" {
+				req.Header.Set("X-Request-ID", tt.requestID)
+			}
+			rr := httptest.NewRecorder()
+			RequestID(Logging(tt.handler)).ServeHTTP(rr, req)
+
+			var got map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+			for key, value := range tt.expected {
+				assert.Equal(t, value, got[key], key)
+			}
+			if _, ok := tt.expected["error"]; !ok {
+				assert.NotContains(t, got, "error")
+			}
+			if _, ok := tt.expected["user_id"]; !ok {
+				assert.NotContains(t, got, "user_id")
+			}
+
+			id := rr.Header().Get("X-Request-ID")
+			assert.Equal(t, got["request_id"], id)
+			if tt.requestID == "" || tt.requestID == "has space" {
+				assert.Len(t, id, 32)
+			}
+		})
+	}
+}
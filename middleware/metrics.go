@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"native-free-pollings/metrics"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metrics records request counts and latencies by route pattern. It must
+// wrap the router directly: the mux stores the matched pattern on the
+// request, which is only visible here if nothing in between copies it.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := newResponseRecorder(w)
+
+		defer func() {
+			status := rec.status
+			if p := recover(); p != nil {
+				status = http.StatusInternalServerError
+				defer panic(p)
+			}
+
+			route := routePath(r)
+			if route == "" {
+				route = "unmatched"
+			}
+			labels := []string{metricMethod(r.Method), route, strconv.Itoa(status)}
+			metrics.HTTPRequests.Inc(labels...)
+			metrics.HTTPDuration.Observe(time.Since(start).Seconds(), labels...)
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// routePath returns the path part of the pattern the request matched, or ""
+// when it matched none.
+func routePath(r *http.Request) string {
+	// Patterns may start with the method ("GET /pollings/{id}").
+	if _, path, found := strings.Cut(r.Pattern, " "); found {
+		return path
+	}
+	return r.Pattern
+}
+
+// metricMethod folds unknown methods together so clients cannot create
+// new series at will.
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	}
+	return "OTHER"
+}
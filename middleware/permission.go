@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"native-free-pollings/authz"
+	"native-free-pollings/helper"
+	"native-free-pollings/response"
+	"net/http"
+)
+
+// RequirePermission must be chained after Auth. It only lets through
+// interactive sessions whose role grants perm; personal access tokens are
+// never accepted for administrative routes.
+func RequirePermission(perm authz.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth, ok := helper.GetAuthContext(r.Context())
+			if !ok || !auth.IsSession() || !authz.Allowed(authz.SubjectFromAuth(auth), perm) {
+				response.WriteProblem(w, r, helper.CodeForbidden, "insufficient role")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
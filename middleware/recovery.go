@@ -1,22 +1,43 @@
 package middleware
 
 import (
-	"encoding/json"
+	"fmt"
+	"log/slog"
+	"native-free-pollings/helper"
+	"native-free-pollings/response"
 	"net/http"
+	"runtime/debug"
 )
 
+// Recovery turns a panic in a handler into a 500 problem response and logs
+// it with its stack trace. http.ErrAbortHandler is re-raised so the server
+// aborts the response as intended.
 func Recovery(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := newResponseRecorder(w)
+
 		defer func() {
-			if err := recover(); err != nil {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusMethodNotAllowed)
-				_ = json.NewEncoder(w).Encode(map[string]string{
-					"code":    "SERVER_ERROR",
-					"message": "internal server error",
-				})
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			slog.ErrorContext(r.Context(), "panic recovered",
+				slog.Any("panic", p),
+				slog.String("stack", string(debug.Stack())),
+			)
+			rec.RecordError(fmt.Errorf("panic: %v", p))
+
+			// Once the status line is out, the client gets a truncated body.
+			if rec.wroteHeader {
+				return
 			}
+			response.WriteProblem(rec, r, helper.CodeInternalError, "internal server error")
 		}()
-		next.ServeHTTP(w, r)
+
+		next.ServeHTTP(rec, r)
 	})
 }
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"native-free-pollings/config"
+	"native-free-pollings/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantBody   bool
+	}{
+		{
+			name: "Panic before writing",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				var m map[string]int
+				m["boom"] = 1
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   true,
+		},
+		{
+			name: "Panic after writing header",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				panic("late failure")
+			},
+			wantStatus: http.StatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			defer slog.SetDefault(slog.Default())
+			slog.SetDefault(logging.New(&buf, config.Log{Level: "info", Format: "json"}))
+
+			req := httptest.NewRequest(http.MethodGet, "/pollings/1", nil)
+			rr := httptest.NewRecorder()
+			RequestID(Recovery(Logging(tt.handler))).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantBody {
+				var body map[string]any
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+				assert.Equal(t, "INTERNAL_ERROR", body["code"])
+				assert.Equal(t, "internal server error", body["detail"])
+				assert.Equal(t, rr.Header().Get("X-Request-ID"), body["request_id"])
+				assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+			}
+
+			var recovered map[string]any
+			for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+				var record map[string]any
+				require.NoError(t, json.Unmarshal(line, &record))
+				if record["msg"] == "panic recovered" {
+					recovered = record
+				}
+			}
+			require.NotNil(t, recovered)
+			assert.Equal(t, "ERROR", recovered["level"])
+			assert.Contains(t, recovered["stack"], "runtime/debug.Stack")
+		})
+	}
+}
+
+func TestRecoveryAbortHandler(t *testing.T) {
+	h := Recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"native-free-pollings/helper"
+	"net/http"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds IDs accepted from clients so they cannot bloat
+// every log line.
+const maxRequestIDLength = 128
+
+// RequestID puts an ID for the request in its context and echoes it in the
+// X-Request-ID response header. An ID sent by the client or a proxy is
+// kept if it looks sane; otherwise a random one is generated. It must be the
+// outermost middleware so every log line of the request carries the ID.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), helper.RequestIDKey, id)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID accepts printable ASCII without spaces.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
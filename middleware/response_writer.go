@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// responseRecorder remembers the status code and body size written through
+// it. Unwrap lets http.ResponseController reach the underlying writer for
+// flushing and hijacking. It is shared by all middleware of a request, which
+// is how the logging middleware learns the authenticated user and the cause
+// of a server error.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+	userID      int64
+	err         error
+}
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	if rec, ok := w.(*responseRecorder); ok {
+		return rec
+	}
+	return &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+func (r *responseRecorder) Flush() {
+	r.wroteHeader = true
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Hijack records the switch to another protocol, such as WebSocket, so the
+// request is logged with status 101 instead of a 200 that was never sent.
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil && !r.wroteHeader {
+		r.status = http.StatusSwitchingProtocols
+		r.wroteHeader = true
+	}
+	return conn, brw, err
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// RecordError implements response.ErrorRecorder.
+func (r *responseRecorder) RecordError(err error) {
+	r.err = err
+}
+
+// findRecorder unwraps w until it reaches the request's responseRecorder.
+func findRecorder(w http.ResponseWriter) *responseRecorder {
+	for w != nil {
+		if rec, ok := w.(*responseRecorder); ok {
+			return rec
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return nil
+		}
+		w = u.Unwrap()
+	}
+	return nil
+}
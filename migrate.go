@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"native-free-pollings/config"
+	"native-free-pollings/database"
+	"native-free-pollings/migrations"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+)
+
+func migrate(conf *config.Config, args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db := database.GetDatabaseConnection(conf.Database)
+	defer db.Close()
+	if err := database.WaitForConnection(ctx, db, conf.Database.ConnectTimeout); err != nil {
+		log.Fatal(err)
+	}
+
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database is up to date")
+		}
+		for _, mig := range applied {
+			fmt.Printf("Applied %06d_%s\n", mig.Version, mig.Name)
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatalf("Invalid number of steps %q", args[1])
+			}
+		}
+		rolled, err := migrator.Down(ctx, steps)
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		for _, mig := range rolled {
+			fmt.Printf("Rolled back %06d_%s\n", mig.Version, mig.Name)
+		}
+
+	case "redo":
+		mig, err := migrator.Redo(ctx)
+		if err != nil {
+			log.Fatalf("Redo failed: %v", err)
+		}
+		fmt.Printf("Redid %06d_%s\n", mig.Version, mig.Name)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		for _, st := range statuses {
+			state := "pending"
+			if st.AppliedAt != nil {
+				state = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if st.Missing {
+				state += " (file missing)"
+			}
+			fmt.Printf("%06d_%-40s %s\n", st.Version, st.Name, state)
+		}
+
+	case "force":
+		if len(args) < 2 {
+			log.Fatal("force needs a version")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid version %q", args[1])
+		}
+		if err := migrator.Force(ctx, version); err != nil {
+			log.Fatalf("Force failed: %v", err)
+		}
+		fmt.Printf("Marked migrations up to %06d as applied\n", version)
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n\n%s", args[0], usage)
+		os.Exit(2)
+	}
+}
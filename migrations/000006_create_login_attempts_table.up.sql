@@ -0,0 +1,17 @@
+create table login_attempts(
+	id bigserial primary key,
+	email text not null,
+	ip text not null,
+	success boolean not null,
+	attempted_at timestamptz not null default now()
+);
+
+create index login_attempts_email_idx on login_attempts(email, attempted_at);
+create index login_attempts_ip_idx on login_attempts(ip, attempted_at);
+
+create table login_throttles(
+	key text primary key,
+	failures int not null default 0,
+	last_failure_at timestamptz not null default now(),
+	locked_until timestamptz
+)
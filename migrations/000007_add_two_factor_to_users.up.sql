@@ -0,0 +1,13 @@
+alter table users
+	add column totp_secret text,
+	add column totp_enabled boolean not null default false,
+	add column totp_last_step bigint not null default 0;
+
+create table user_recovery_codes(
+	id bigserial primary key,
+	user_id bigint not null references users(id) on delete cascade,
+	code_hash text not null,
+	used_at timestamptz,
+	created_at timestamptz not null default now(),
+	unique(user_id, code_hash)
+)
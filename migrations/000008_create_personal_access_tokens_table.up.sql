@@ -0,0 +1,13 @@
+create table personal_access_tokens(
+	id bigserial primary key,
+	user_id bigint not null references users(id) on delete cascade,
+	name text not null,
+	token_hash text not null unique,
+	prefix text not null,
+	scopes text[] not null,
+	expires_at timestamptz,
+	last_used_at timestamptz,
+	created_at timestamptz not null default now()
+);
+
+create index personal_access_tokens_user_idx on personal_access_tokens(user_id)
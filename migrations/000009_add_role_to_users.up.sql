@@ -0,0 +1,3 @@
+alter table users
+	add column role text not null default 'user' check (role in ('user', 'moderator', 'admin')),
+	add column disabled_at timestamptz
@@ -0,0 +1,11 @@
+create table user_identities(
+	id bigserial primary key,
+	user_id bigint not null references users(id) on delete cascade,
+	issuer text not null,
+	subject text not null,
+	email text not null,
+	created_at timestamptz not null default now(),
+	unique(issuer, subject)
+);
+
+create index user_identities_user_idx on user_identities(user_id)
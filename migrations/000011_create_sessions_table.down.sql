@@ -0,0 +1 @@
+DROP TABLE IF EXISTS sessions
\ No newline at end of file
@@ -0,0 +1,12 @@
+create table sessions(
+	id bigserial primary key,
+	user_id bigint not null references users(id) on delete cascade,
+	user_agent text not null default '',
+	ip text not null default '',
+	created_at timestamptz not null default now(),
+	last_seen_at timestamptz not null default now(),
+	expires_at timestamptz not null,
+	revoked_at timestamptz
+);
+
+create index sessions_user_idx on sessions(user_id)
@@ -0,0 +1 @@
+alter table polls add column version bigint not null default 1
\ No newline at end of file
@@ -0,0 +1,42 @@
+create table webhooks(
+	id bigserial primary key,
+	user_id bigint not null references users(id) on delete cascade,
+	poll_id bigint references polls(id) on delete cascade,
+	url text not null,
+	secret text not null,
+	events text[] not null,
+	consecutive_failures int not null default 0,
+	disabled_at timestamptz,
+	created_at timestamptz not null default now(),
+	updated_at timestamptz not null default now()
+);
+
+create index webhooks_user_idx on webhooks(user_id);
+create index webhooks_poll_idx on webhooks(poll_id) where poll_id is not null;
+
+create table webhook_deliveries(
+	id bigserial primary key,
+	webhook_id bigint not null references webhooks(id) on delete cascade,
+	event_id text not null,
+	event text not null,
+	payload jsonb not null,
+	status text not null default 'pending' check (status in ('pending', 'succeeded', 'failed')),
+	attempts int not null default 0,
+	next_attempt_at timestamptz not null default now(),
+	delivered_at timestamptz,
+	created_at timestamptz not null default now()
+);
+
+create index webhook_deliveries_due_idx on webhook_deliveries(next_attempt_at) where status = 'pending';
+create index webhook_deliveries_webhook_idx on webhook_deliveries(webhook_id, created_at desc);
+
+create table webhook_delivery_attempts(
+	id bigserial primary key,
+	delivery_id bigint not null references webhook_deliveries(id) on delete cascade,
+	status_code int,
+	error text not null default '',
+	duration_ms int not null,
+	attempted_at timestamptz not null default now()
+);
+
+create index webhook_delivery_attempts_delivery_idx on webhook_delivery_attempts(delivery_id)
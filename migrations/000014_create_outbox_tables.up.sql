@@ -0,0 +1,22 @@
+create table outbox_events(
+	id bigserial primary key,
+	event_id text not null unique,
+	name text not null,
+	poll_id bigint not null,
+	owner_id bigint not null,
+	data jsonb not null,
+	occurred_at timestamptz not null,
+	attempts int not null default 0,
+	next_attempt_at timestamptz not null default now(),
+	last_error text not null default '',
+	created_at timestamptz not null default now()
+);
+
+create index outbox_events_due_idx on outbox_events(next_attempt_at, id);
+
+create table outbox_consumers(
+	outbox_id bigint not null references outbox_events(id) on delete cascade,
+	consumer text not null,
+	handled_at timestamptz not null default now(),
+	primary key (outbox_id, consumer)
+)
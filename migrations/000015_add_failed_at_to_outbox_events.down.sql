@@ -0,0 +1,4 @@
+drop index if exists outbox_events_due_idx;
+create index outbox_events_due_idx on outbox_events(next_attempt_at, id);
+
+alter table outbox_events drop column if exists failed_at
\ No newline at end of file
@@ -0,0 +1,4 @@
+alter table outbox_events add column failed_at timestamptz;
+
+drop index if exists outbox_events_due_idx;
+create index outbox_events_due_idx on outbox_events(next_attempt_at, id) where failed_at is null
\ No newline at end of file
@@ -0,0 +1,83 @@
+package mocks
+
+import (
+	"context"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type AccessTokenRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *AccessTokenRepositoryMock) Create(ctx context.Context, token *models.AccessToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *AccessTokenRepositoryMock) ListByUserID(ctx context.Context, userID int64) ([]models.AccessToken, error) {
+	args := m.Called(ctx, userID)
+	if tokens, ok := args.Get(0).([]models.AccessToken); ok {
+		return tokens, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *AccessTokenRepositoryMock) Delete(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *AccessTokenRepositoryMock) GetByHash(ctx context.Context, hash string) (*models.AccessToken, error) {
+	args := m.Called(ctx, hash)
+	if token, ok := args.Get(0).(*models.AccessToken); ok {
+		return token, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *AccessTokenRepositoryMock) TouchLastUsed(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type AccessTokenServiceMock struct {
+	mock.Mock
+}
+
+func (m *AccessTokenServiceMock) Create(ctx context.Context, userID int64, req *dto.CreateAccessTokenRequest) (*dto.AccessTokenCreatedResponse, error) {
+	args := m.Called(ctx, userID, req)
+	if resp, ok := args.Get(0).(*dto.AccessTokenCreatedResponse); ok {
+		return resp, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *AccessTokenServiceMock) List(ctx context.Context, userID int64) ([]dto.AccessTokenResponse, error) {
+	args := m.Called(ctx, userID)
+	if resp, ok := args.Get(0).([]dto.AccessTokenResponse); ok {
+		return resp, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *AccessTokenServiceMock) Revoke(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *AccessTokenServiceMock) Authenticate(ctx context.Context, token string) (*helper.AuthContext, error) {
+	args := m.Called(ctx, token)
+	if auth, ok := args.Get(0).(*helper.AuthContext); ok {
+		return auth, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
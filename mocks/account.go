@@ -0,0 +1,45 @@
+package mocks
+
+import (
+	"context"
+	"native-free-pollings/dto"
+	"native-free-pollings/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type AccountRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *AccountRepositoryMock) Export(ctx context.Context, userID int64) (*models.AccountExport, error) {
+	args := m.Called(ctx, userID)
+	if export, ok := args.Get(0).(*models.AccountExport); ok {
+		return export, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *AccountRepositoryMock) Erase(ctx context.Context, userID int64, deletePolls bool) error {
+	args := m.Called(ctx, userID, deletePolls)
+	return args.Error(0)
+}
+
+type AccountServiceMock struct {
+	mock.Mock
+}
+
+func (m *AccountServiceMock) Export(ctx context.Context, userID int64) (*dto.AccountExport, error) {
+	args := m.Called(ctx, userID)
+	if export, ok := args.Get(0).(*dto.AccountExport); ok {
+		return export, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *AccountServiceMock) Delete(ctx context.Context, userID, sessionID int64, req *dto.DeleteAccountRequest) error {
+	args := m.Called(ctx, userID, sessionID, req)
+	return args.Error(0)
+}
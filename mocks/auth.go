@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"native-free-pollings/dto"
+	"native-free-pollings/oidc"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -28,3 +29,26 @@ func (m *AuthServiceMock) Register(ctx context.Context, req *dto.RegisterRequest
 
 	return nil, args.Error(1)
 }
+
+func (m *AuthServiceMock) UnlockAccount(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *AuthServiceMock) LoginOIDC(ctx context.Context, identity *oidc.Identity, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	args := m.Called(ctx, identity, client)
+	if resp, ok := args.Get(0).(*dto.LoginResponse); ok {
+		return resp, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *AuthServiceMock) LoginTwoFactor(ctx context.Context, req *dto.LoginTwoFactorRequest) (*dto.LoginResponse, error) {
+	args := m.Called(ctx, req)
+	if resp, ok := args.Get(0).(*dto.LoginResponse); ok {
+		return resp, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
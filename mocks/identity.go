@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"context"
+	"native-free-pollings/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type IdentityRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *IdentityRepositoryMock) GetUser(ctx context.Context, issuer, subject string) (*models.User, error) {
+	args := m.Called(ctx, issuer, subject)
+	if user, ok := args.Get(0).(*models.User); ok {
+		return user, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *IdentityRepositoryMock) Link(ctx context.Context, identity *models.Identity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+func (m *IdentityRepositoryMock) CreateUserWithIdentity(ctx context.Context, user *models.User, identity *models.Identity) error {
+	args := m.Called(ctx, user, identity)
+	return args.Error(0)
+}
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type LoginAttemptTrackerMock struct {
+	mock.Mock
+}
+
+func (m *LoginAttemptTrackerMock) LockedUntil(ctx context.Context, email, ip string) (time.Time, error) {
+	args := m.Called(ctx, email, ip)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *LoginAttemptTrackerMock) RecordFailure(ctx context.Context, email, ip string) (time.Time, error) {
+	args := m.Called(ctx, email, ip)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *LoginAttemptTrackerMock) RecordSuccess(ctx context.Context, email, ip string) error {
+	args := m.Called(ctx, email, ip)
+	return args.Error(0)
+}
+
+func (m *LoginAttemptTrackerMock) Unlock(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
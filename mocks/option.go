@@ -35,3 +35,8 @@ func (m *OptionRepositoryMock) GetByPollID(ctx context.Context, db domain.DB, id
 
 	return nil, args.Error(1)
 }
+
+func (m *OptionRepositoryMock) Reorder(ctx context.Context, db domain.DB, pollID int64, ids []int64) error {
+	args := m.Called(ctx, db, pollID, ids)
+	return args.Error(0)
+}
@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"context"
+	"native-free-pollings/domain"
+	"native-free-pollings/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type OutboxRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *OutboxRepositoryMock) Add(ctx context.Context, db domain.DB, ev domain.Event) error {
+	args := m.Called(ctx, db, ev)
+	return args.Error(0)
+}
+
+func (m *OutboxRepositoryMock) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	args := m.Called(ctx, limit, lease)
+	if events, ok := args.Get(0).([]models.OutboxEvent); ok {
+		return events, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *OutboxRepositoryMock) MarkConsumed(ctx context.Context, id int64, consumer string) error {
+	args := m.Called(ctx, id, consumer)
+	return args.Error(0)
+}
+
+func (m *OutboxRepositoryMock) Complete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *OutboxRepositoryMock) Retry(ctx context.Context, id int64, retryAt time.Time, lastError string) error {
+	args := m.Called(ctx, id, retryAt, lastError)
+	return args.Error(0)
+}
+
+func (m *OutboxRepositoryMock) Fail(ctx context.Context, id int64, lastError string) error {
+	args := m.Called(ctx, id, lastError)
+	return args.Error(0)
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"native-free-pollings/authz"
 	"native-free-pollings/domain"
 	"native-free-pollings/dto"
 	"native-free-pollings/models"
@@ -18,7 +19,17 @@ func (m *PollRepositoryMock) Create(ctx context.Context, db domain.DB, poll *mod
 	return args.Error(0)
 }
 
-func (m *PollRepositoryMock) Delete(ctx context.Context, db domain.DB, id int64) error {
+func (m *PollRepositoryMock) Delete(ctx context.Context, db domain.DB, id, version int64) error {
+	args := m.Called(ctx, db, id, version)
+	return args.Error(0)
+}
+
+func (m *PollRepositoryMock) Touch(ctx context.Context, db domain.DB, id int64) error {
+	args := m.Called(ctx, db, id)
+	return args.Error(0)
+}
+
+func (m *PollRepositoryMock) NotifyResults(ctx context.Context, db domain.DB, id int64) error {
 	args := m.Called(ctx, db, id)
 	return args.Error(0)
 }
@@ -37,6 +48,24 @@ func (m *PollRepositoryMock) GetByID(ctx context.Context, db domain.DB, id int64
 	return nil, args.Error(1)
 }
 
+func (m *PollRepositoryMock) GetByIDForUpdate(ctx context.Context, db domain.DB, id int64) (*models.Polling, error) {
+	args := m.Called(ctx, db, id)
+	if poll, ok := args.Get(0).(*models.Polling); ok {
+		return poll, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *PollRepositoryMock) GetByIDForShare(ctx context.Context, db domain.DB, id int64) (*models.Polling, error) {
+	args := m.Called(ctx, db, id)
+	if poll, ok := args.Get(0).(*models.Polling); ok {
+		return poll, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
 func (m *PollRepositoryMock) GetResultsByID(ctx context.Context, db domain.DB, id int64) ([]models.VoteResult, error) {
 	args := m.Called(ctx, db, id)
 	if result, ok := args.Get(0).([]models.VoteResult); ok {
@@ -59,8 +88,8 @@ func (m *PollServiceMock) CreatePolling(ctx context.Context, rq *dto.CreatePolli
 	return nil, args.Error(1)
 }
 
-func (m *PollServiceMock) UpdatePolling(ctx context.Context, rq *dto.UpdatePollingRequest, creator dto.CreatorInfo) (*dto.PollingResponse, error) {
-	args := m.Called(ctx, rq, creator)
+func (m *PollServiceMock) UpdatePolling(ctx context.Context, rq *dto.UpdatePollingRequest, actor authz.Subject) (*dto.PollingResponse, error) {
+	args := m.Called(ctx, rq, actor)
 	if result, ok := args.Get(0).(*dto.PollingResponse); ok {
 		return result, args.Error(1)
 	}
@@ -74,8 +103,8 @@ func (m *PollServiceMock) VoteOptionPolling(ctx context.Context, userID, pollID,
 	return args.Error(0)
 }
 
-func (m *PollServiceMock) DeletePolling(ctx context.Context, pollID, userID int64) error {
-	args := m.Called(ctx, pollID, userID)
+func (m *PollServiceMock) DeletePolling(ctx context.Context, pollID, version int64, actor authz.Subject) error {
+	args := m.Called(ctx, pollID, version, actor)
 
 	return args.Error(0)
 }
@@ -97,3 +126,36 @@ func (m *PollServiceMock) GetPollingResult(ctx context.Context, pollID int64) (*
 
 	return nil, args.Error(1)
 }
+
+func (m *PollServiceMock) AddOption(ctx context.Context, pollID, version int64, rq *dto.CreateOptionRequest, actor authz.Subject) (*dto.Option, int64, error) {
+	args := m.Called(ctx, pollID, version, rq, actor)
+	if result, ok := args.Get(0).(*dto.Option); ok {
+		return result, args.Get(1).(int64), args.Error(2)
+	}
+
+	return nil, 0, args.Error(2)
+}
+
+func (m *PollServiceMock) RenameOption(ctx context.Context, pollID, version, optionID int64, rq *dto.RenameOptionRequest, actor authz.Subject) (*dto.Option, int64, error) {
+	args := m.Called(ctx, pollID, version, optionID, rq, actor)
+	if result, ok := args.Get(0).(*dto.Option); ok {
+		return result, args.Get(1).(int64), args.Error(2)
+	}
+
+	return nil, 0, args.Error(2)
+}
+
+func (m *PollServiceMock) ReorderOptions(ctx context.Context, pollID, version int64, rq *dto.ReorderOptionsRequest, actor authz.Subject) ([]dto.Option, int64, error) {
+	args := m.Called(ctx, pollID, version, rq, actor)
+	if result, ok := args.Get(0).([]dto.Option); ok {
+		return result, args.Get(1).(int64), args.Error(2)
+	}
+
+	return nil, 0, args.Error(2)
+}
+
+func (m *PollServiceMock) DeleteOption(ctx context.Context, pollID, version, optionID int64, force bool, actor authz.Subject) (int64, error) {
+	args := m.Called(ctx, pollID, version, optionID, force, actor)
+
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,73 @@
+package mocks
+
+import (
+	"context"
+	"native-free-pollings/dto"
+	"native-free-pollings/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type SessionRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *SessionRepositoryMock) Create(ctx context.Context, session *models.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *SessionRepositoryMock) ListActive(ctx context.Context, userID int64) ([]models.Session, error) {
+	args := m.Called(ctx, userID)
+	if sessions, ok := args.Get(0).([]models.Session); ok {
+		return sessions, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *SessionRepositoryMock) Revoke(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *SessionRepositoryMock) GetActive(ctx context.Context, userID, id int64) (*models.Session, error) {
+	args := m.Called(ctx, userID, id)
+	if sess, ok := args.Get(0).(*models.Session); ok {
+		return sess, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *SessionRepositoryMock) Touch(ctx context.Context, id int64) (int64, string, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int64), args.String(1), args.Error(2)
+}
+
+type SessionServiceMock struct {
+	mock.Mock
+}
+
+func (m *SessionServiceMock) List(ctx context.Context, userID, currentID int64) ([]dto.SessionResponse, error) {
+	args := m.Called(ctx, userID, currentID)
+	if sessions, ok := args.Get(0).([]dto.SessionResponse); ok {
+		return sessions, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *SessionServiceMock) Revoke(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *SessionServiceMock) Validate(ctx context.Context, userID, id int64) (string, error) {
+	args := m.Called(ctx, userID, id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *SessionServiceMock) ForgetUser(userID int64) {
+	m.Called(userID)
+}
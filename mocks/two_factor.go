@@ -0,0 +1,74 @@
+package mocks
+
+import (
+	"context"
+	"native-free-pollings/dto"
+	"native-free-pollings/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type TwoFactorRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *TwoFactorRepositoryMock) Get(ctx context.Context, userID int64) (*models.TwoFactor, error) {
+	args := m.Called(ctx, userID)
+	if tf, ok := args.Get(0).(*models.TwoFactor); ok {
+		return tf, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *TwoFactorRepositoryMock) SaveSecret(ctx context.Context, userID int64, secret string) error {
+	args := m.Called(ctx, userID, secret)
+	return args.Error(0)
+}
+
+func (m *TwoFactorRepositoryMock) Enable(ctx context.Context, userID, step int64, recoveryHashes []string) error {
+	args := m.Called(ctx, userID, step, recoveryHashes)
+	return args.Error(0)
+}
+
+func (m *TwoFactorRepositoryMock) Disable(ctx context.Context, userID int64) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *TwoFactorRepositoryMock) UseStep(ctx context.Context, userID, step int64) (bool, error) {
+	args := m.Called(ctx, userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *TwoFactorRepositoryMock) UseRecoveryCode(ctx context.Context, userID int64, hash string) (bool, error) {
+	args := m.Called(ctx, userID, hash)
+	return args.Bool(0), args.Error(1)
+}
+
+type TwoFactorServiceMock struct {
+	mock.Mock
+}
+
+func (m *TwoFactorServiceMock) Setup(ctx context.Context, userID int64) (*dto.TwoFactorSetupResponse, error) {
+	args := m.Called(ctx, userID)
+	if resp, ok := args.Get(0).(*dto.TwoFactorSetupResponse); ok {
+		return resp, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *TwoFactorServiceMock) Confirm(ctx context.Context, userID int64, code string) (*dto.RecoveryCodesResponse, error) {
+	args := m.Called(ctx, userID, code)
+	if resp, ok := args.Get(0).(*dto.RecoveryCodesResponse); ok {
+		return resp, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *TwoFactorServiceMock) Disable(ctx context.Context, userID, sessionID int64, password string) error {
+	args := m.Called(ctx, userID, sessionID, password)
+	return args.Error(0)
+}
@@ -45,6 +45,30 @@ func (m *UserRepositoryMock) UpdatePassword(ctx context.Context, id int64, passw
 	return args.Error(0)
 }
 
+func (m *UserRepositoryMock) GetPasswordHash(ctx context.Context, id int64) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *UserRepositoryMock) List(ctx context.Context) ([]models.User, error) {
+	args := m.Called(ctx)
+	if users, ok := args.Get(0).([]models.User); ok {
+		return users, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *UserRepositoryMock) UpdateRole(ctx context.Context, id int64, role string) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *UserRepositoryMock) SetDisabled(ctx context.Context, id int64, disabled bool) error {
+	args := m.Called(ctx, id, disabled)
+	return args.Error(0)
+}
+
 func (m *UserRepositoryMock) FindPollingsByID(ctx context.Context, id int64) ([]models.PollingSummary, error) {
 	args := m.Called(ctx, id)
 	if results, ok := args.Get(0).([]models.PollingSummary); ok {
@@ -57,3 +57,8 @@ func (m *VoteRepostoryMock) HasDeviceVoted(ctx context.Context, db domain.DB, de
 
 	return false, args.Error(1)
 }
+
+func (m *VoteRepostoryMock) CountByOptionID(ctx context.Context, db domain.DB, optionID int64) (int64, error) {
+	args := m.Called(ctx, db, optionID)
+	return args.Get(0).(int64), args.Error(1)
+}
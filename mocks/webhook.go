@@ -0,0 +1,156 @@
+package mocks
+
+import (
+	"context"
+	"encoding/json"
+	"native-free-pollings/authz"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *WebhookRepositoryMock) Create(ctx context.Context, hook *models.Webhook) error {
+	args := m.Called(ctx, hook)
+	return args.Error(0)
+}
+
+func (m *WebhookRepositoryMock) ListByUserID(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	args := m.Called(ctx, userID)
+	if hooks, ok := args.Get(0).([]models.Webhook); ok {
+		return hooks, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *WebhookRepositoryMock) GetByID(ctx context.Context, userID, id int64) (*models.Webhook, error) {
+	args := m.Called(ctx, userID, id)
+	if hook, ok := args.Get(0).(*models.Webhook); ok {
+		return hook, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *WebhookRepositoryMock) Update(ctx context.Context, hook *models.Webhook) error {
+	args := m.Called(ctx, hook)
+	return args.Error(0)
+}
+
+func (m *WebhookRepositoryMock) Delete(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *WebhookRepositoryMock) EnqueueEvent(ctx context.Context, ownerID, pollID int64, eventID, event string, payload []byte) (int64, error) {
+	args := m.Called(ctx, ownerID, pollID, eventID, event, payload)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *WebhookRepositoryMock) Enqueue(ctx context.Context, delivery *models.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *WebhookRepositoryMock) ListDeliveries(ctx context.Context, webhookID int64, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, limit)
+	if deliveries, ok := args.Get(0).([]models.WebhookDelivery); ok {
+		return deliveries, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *WebhookRepositoryMock) GetDelivery(ctx context.Context, webhookID, id int64) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, id)
+	if delivery, ok := args.Get(0).(*models.WebhookDelivery); ok {
+		return delivery, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *WebhookRepositoryMock) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, limit, lease)
+	if deliveries, ok := args.Get(0).([]models.WebhookDelivery); ok {
+		return deliveries, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *WebhookRepositoryMock) RecordSuccess(ctx context.Context, delivery *models.WebhookDelivery, attempt *models.WebhookAttempt) error {
+	args := m.Called(ctx, delivery, attempt)
+	return args.Error(0)
+}
+
+func (m *WebhookRepositoryMock) RecordFailure(ctx context.Context, delivery *models.WebhookDelivery, attempt *models.WebhookAttempt, retryAt *time.Time, disableAfter int) (bool, error) {
+	args := m.Called(ctx, delivery, attempt, retryAt, disableAfter)
+	return args.Bool(0), args.Error(1)
+}
+
+type WebhookServiceMock struct {
+	mock.Mock
+}
+
+func (m *WebhookServiceMock) HandleEvent(ctx context.Context, ev domain.Event, data json.RawMessage) error {
+	args := m.Called(ctx, ev, data)
+	return args.Error(0)
+}
+
+func (m *WebhookServiceMock) Create(ctx context.Context, actor authz.Subject, req *dto.CreateWebhookRequest) (*dto.WebhookCreatedResponse, error) {
+	args := m.Called(ctx, actor, req)
+	if resp, ok := args.Get(0).(*dto.WebhookCreatedResponse); ok {
+		return resp, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *WebhookServiceMock) List(ctx context.Context, userID int64) ([]dto.WebhookResponse, error) {
+	args := m.Called(ctx, userID)
+	if resp, ok := args.Get(0).([]dto.WebhookResponse); ok {
+		return resp, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *WebhookServiceMock) Update(ctx context.Context, userID int64, req *dto.UpdateWebhookRequest) (*dto.WebhookResponse, error) {
+	args := m.Called(ctx, userID, req)
+	if resp, ok := args.Get(0).(*dto.WebhookResponse); ok {
+		return resp, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *WebhookServiceMock) Delete(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *WebhookServiceMock) ListDeliveries(ctx context.Context, userID, webhookID int64) ([]dto.WebhookDeliveryResponse, error) {
+	args := m.Called(ctx, userID, webhookID)
+	if resp, ok := args.Get(0).([]dto.WebhookDeliveryResponse); ok {
+		return resp, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *WebhookServiceMock) Redeliver(ctx context.Context, userID, webhookID, deliveryID int64) (*dto.WebhookDeliveryResponse, error) {
+	args := m.Called(ctx, userID, webhookID, deliveryID)
+	if resp, ok := args.Get(0).(*dto.WebhookDeliveryResponse); ok {
+		return resp, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
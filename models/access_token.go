@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type AccessToken struct {
+	ID         int64      `db:"id"`
+	UserID     int64      `db:"user_id"`
+	Name       string     `db:"name"`
+	TokenHash  string     `db:"token_hash"`
+	Prefix     string     `db:"prefix"`
+	Scopes     []string   `db:"scopes"`
+	ExpiresAt  *time.Time `db:"expires_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+	UserName   string     `db:"user_name"`
+	UserEmail  string     `db:"user_email"`
+	UserRole   string     `db:"user_role"`
+}
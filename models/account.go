@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// AccountExport is everything stored about one user, read in a single
+// snapshot for a personal data export.
+type AccountExport struct {
+	User          User
+	TwoFactor     bool
+	Polls         []ExportedPoll
+	Votes         []ExportedVote
+	Sessions      []Session
+	AccessTokens  []AccessToken
+	Identities    []Identity
+	LoginAttempts []LoginAttempt
+	Webhooks      []Webhook
+}
+
+type ExportedPoll struct {
+	ID          int64      `db:"id"`
+	Title       string     `db:"title"`
+	Description string     `db:"description"`
+	Status      string     `db:"status"`
+	StartsAt    *time.Time `db:"starts_at"`
+	EndsAt      *time.Time `db:"ends_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+
+	Options []ExportedOption
+}
+
+type ExportedOption struct {
+	ID       int64  `db:"id"`
+	Label    string `db:"label"`
+	Position int    `db:"position"`
+	Votes    int64  `db:"votes"`
+}
+
+type ExportedVote struct {
+	PollID      int64     `db:"poll_id"`
+	PollTitle   string    `db:"poll_title"`
+	OptionID    int64     `db:"option_id"`
+	OptionLabel string    `db:"option_label"`
+	VotedAt     time.Time `db:"voted_at"`
+}
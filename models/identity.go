@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Identity links a user to an account at an external OpenID Connect
+// provider, identified by the provider's issuer and subject.
+type Identity struct {
+	ID        int64     `db:"id" json:"id"`
+	UserID    int64     `db:"user_id" json:"user_id"`
+	Issuer    string    `db:"issuer" json:"issuer"`
+	Subject   string    `db:"subject" json:"subject"`
+	Email     string    `db:"email" json:"email"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
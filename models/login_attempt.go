@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type LoginAttempt struct {
+	ID          int64     `db:"id"`
+	Email       string    `db:"email"`
+	IP          string    `db:"ip"`
+	Success     bool      `db:"success"`
+	AttemptedAt time.Time `db:"attempted_at"`
+}
+
+type LoginThrottle struct {
+	Key           string    `db:"key"`
+	Failures      int       `db:"failures"`
+	LastFailureAt time.Time `db:"last_failure_at"`
+	LockedUntil   time.Time `db:"locked_until"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent is an event recorded together with the change that caused it
+// and not yet handled by all of its subscribers.
+type OutboxEvent struct {
+	ID            int64           `db:"id"`
+	EventID       string          `db:"event_id"`
+	Name          string          `db:"name"`
+	PollID        int64           `db:"poll_id"`
+	OwnerID       int64           `db:"owner_id"`
+	Data          json.RawMessage `db:"data"`
+	OccurredAt    time.Time       `db:"occurred_at"`
+	Attempts      int             `db:"attempts"`
+	NextAttemptAt time.Time       `db:"next_attempt_at"`
+	LastError     string          `db:"last_error"`
+	CreatedAt     time.Time       `db:"created_at"`
+
+	// Consumed lists the subscribers that already handled the event.
+	Consumed []string `db:"-"`
+}
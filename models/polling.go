@@ -12,6 +12,7 @@ type Polling struct {
 	EndsAt       time.Time `db:"ends_at"`
 	CreatedAt    time.Time `db:"created_at"`
 	UpdatedAt    time.Time `db:"updated_at"`
+	Version      int64     `db:"version"`
 	CreatorName  string    `db:"creator_name"`
 	CreatorEmail string    `db:"creator_email"`
 
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Session is one issued login JWT. The token carries the session ID so it
+// can be listed and revoked before it expires.
+type Session struct {
+	ID         int64      `db:"id" json:"id"`
+	UserID     int64      `db:"user_id" json:"user_id"`
+	UserAgent  string     `db:"user_agent" json:"user_agent"`
+	IP         string     `db:"ip" json:"ip"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	LastSeenAt time.Time  `db:"last_seen_at" json:"last_seen_at"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type TwoFactor struct {
+	UserID   int64  `db:"id"`
+	Secret   string `db:"totp_secret"`
+	Enabled  bool   `db:"totp_enabled"`
+	LastStep int64  `db:"totp_last_step"`
+}
+
+type RecoveryCode struct {
+	ID        int64      `db:"id"`
+	UserID    int64      `db:"user_id"`
+	CodeHash  string     `db:"code_hash"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
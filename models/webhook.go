@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Webhook struct {
+	ID                  int64      `db:"id"`
+	UserID              int64      `db:"user_id"`
+	PollID              *int64     `db:"poll_id"`
+	URL                 string     `db:"url"`
+	Secret              string     `db:"secret"`
+	Events              []string   `db:"events"`
+	ConsecutiveFailures int        `db:"consecutive_failures"`
+	DisabledAt          *time.Time `db:"disabled_at"`
+	CreatedAt           time.Time  `db:"created_at"`
+	UpdatedAt           time.Time  `db:"updated_at"`
+}
+
+// Delivery statuses. A pending delivery is retried until it succeeds or
+// runs out of attempts.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+type WebhookDelivery struct {
+	ID            int64           `db:"id"`
+	WebhookID     int64           `db:"webhook_id"`
+	EventID       string          `db:"event_id"`
+	Event         string          `db:"event"`
+	Payload       json.RawMessage `db:"payload"`
+	Status        string          `db:"status"`
+	Attempts      int             `db:"attempts"`
+	NextAttemptAt time.Time       `db:"next_attempt_at"`
+	DeliveredAt   *time.Time      `db:"delivered_at"`
+	CreatedAt     time.Time       `db:"created_at"`
+
+	// Set on claimed deliveries, which are sent without loading the webhook.
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+
+	AttemptLog []WebhookAttempt
+}
+
+type WebhookAttempt struct {
+	ID          int64     `db:"id"`
+	DeliveryID  int64     `db:"delivery_id"`
+	StatusCode  *int      `db:"status_code"`
+	Error       string    `db:"error"`
+	DurationMs  int       `db:"duration_ms"`
+	AttemptedAt time.Time `db:"attempted_at"`
+}
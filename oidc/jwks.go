@@ -0,0 +1,132 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// minRefreshInterval stops tokens with unknown key IDs from making us hammer
+// the provider's JWKS endpoint.
+const minRefreshInterval = 30 * time.Second
+
+type keySet struct {
+	http *http.Client
+	uri  string
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+func newKeySet(httpClient *http.Client, uri string) *keySet {
+	return &keySet{http: httpClient, uri: uri}
+}
+
+// get returns the key for kid, refreshing the set once when the key is
+// unknown so provider key rotation is picked up without a restart.
+func (k *keySet) get(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if key := k.lookup(kid); key != nil {
+		return key, nil
+	}
+
+	if time.Since(k.fetchedAt) < minRefreshInterval {
+		return nil, fmt.Errorf("oidc: unknown signing key %q", kid)
+	}
+
+	keys, err := k.fetch(ctx)
+	k.fetchedAt = time.Now()
+	if err != nil {
+		return nil, err
+	}
+	k.keys = keys
+
+	if key := k.lookup(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("oidc: unknown signing key %q", kid)
+}
+
+func (k *keySet) lookup(kid string) *rsa.PublicKey {
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key
+		}
+	}
+	return k.keys[kid]
+}
+
+func (k *keySet) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: jwks request: %w", err)
+	}
+
+	resp, err := k.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: jwks request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc: jwks returned %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("oidc: decode jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := rsaKey(jwk.N, jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("oidc: jwks contains no RSA signing keys")
+	}
+
+	return keys, nil
+}
+
+func rsaKey(n, e string) (*rsa.PublicKey, error) {
+	nb, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: bad jwk modulus: %w", err)
+	}
+	eb, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: bad jwk exponent: %w", err)
+	}
+
+	exp := new(big.Int).SetBytes(eb)
+	if !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+		return nil, errors.New("oidc: bad jwk exponent")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(nb), E: int(exp.Int64())}, nil
+}
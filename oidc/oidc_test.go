@@ -0,0 +1,158 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"native-free-pollings/oidc"
+	"native-free-pollings/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const redirectURL = "http://app.test/auth/oidc/callback"
+
+func newClient(t *testing.T, p *oidctest.Provider) *oidc.Client {
+	t.Helper()
+	client, err := oidc.NewClient(context.Background(), oidc.Config{
+		Issuer:      p.Issuer,
+		ClientID:    p.ClientID,
+		RedirectURL: redirectURL,
+	}, nil)
+	require.NoError(t, err)
+	return client
+}
+
+// authorize follows the browser leg of the flow and returns the code the
+// provider redirected back with.
+func authorize(t *testing.T, authURL, wantState string) string {
+	t.Helper()
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	resp, err := noRedirect.Get(authURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, wantState, loc.Query().Get("state"))
+	return loc.Query().Get("code")
+}
+
+func TestClient_Flow(t *testing.T) {
+	p := oidctest.NewProvider("polling-app")
+	defer p.Close()
+	client := newClient(t, p)
+
+	tests := []struct {
+		name      string
+		tamper    func(jwt.MapClaims)
+		verifier  func(real string) string
+		nonce     func(real string) string
+		wantErr   bool
+		wantEmail string
+	}{
+		{
+			name:      "success",
+			wantEmail: "user@example.com",
+		},
+		{
+			name:     "wrong verifier is rejected by provider",
+			verifier: func(string) string { return "not-the-verifier-not-the-verifier-not-the-v" },
+			wantErr:  true,
+		},
+		{
+			name:    "nonce mismatch",
+			nonce:   func(string) string { return "other" },
+			wantErr: true,
+		},
+		{
+			name:    "wrong audience",
+			tamper:  func(c jwt.MapClaims) { c["aud"] = "someone-else" },
+			wantErr: true,
+		},
+		{
+			name:    "wrong issuer",
+			tamper:  func(c jwt.MapClaims) { c["iss"] = "https://evil.test" },
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			tamper:  func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p.Tamper(tt.tamper)
+
+			state, _ := oidc.RandomString()
+			nonce, _ := oidc.RandomString()
+			verifier, _ := oidc.RandomString()
+
+			code := authorize(t, client.AuthCodeURL(state, nonce, verifier), state)
+
+			if tt.verifier != nil {
+				verifier = tt.verifier(verifier)
+			}
+			if tt.nonce != nil {
+				nonce = tt.nonce(nonce)
+			}
+
+			identity, err := client.Exchange(context.Background(), code, verifier, nonce)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, identity)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, p.Issuer, identity.Issuer)
+			assert.Equal(t, "user-1", identity.Subject)
+			assert.Equal(t, tt.wantEmail, identity.Email)
+			assert.True(t, identity.EmailVerified)
+		})
+	}
+}
+
+func TestClient_RejectsForeignSignature(t *testing.T) {
+	p := oidctest.NewProvider("polling-app")
+	defer p.Close()
+	other := oidctest.NewProvider("polling-app")
+	defer other.Close()
+
+	client := newClient(t, p)
+
+	forged := other.SignIDToken(jwt.MapClaims{
+		"iss":   p.Issuer,
+		"aud":   p.ClientID,
+		"sub":   "attacker",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"nonce": "n",
+	})
+
+	_, err := client.VerifyIDToken(context.Background(), forged, "n")
+	assert.Error(t, err)
+}
+
+func TestNewClient_IssuerMismatch(t *testing.T) {
+	p := oidctest.NewProvider("polling-app")
+	defer p.Close()
+
+	_, err := oidc.NewClient(context.Background(), oidc.Config{
+		Issuer:      p.Issuer + "/",
+		ClientID:    p.ClientID,
+		RedirectURL: redirectURL,
+	}, nil)
+	assert.Error(t, err)
+}
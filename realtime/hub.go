@@ -0,0 +1,197 @@
+// Package realtime pushes live poll results to connected clients. The Hub
+// fans events out to the streams of one instance, and the Listener feeds it
+// from Postgres notifications so every instance sees votes committed by any
+// of them.
+package realtime
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrTooManyStreams is returned by Subscribe when a stream limit is reached.
+var ErrTooManyStreams = errors.New("too many open streams")
+
+// Event is a snapshot of a poll's results. Snapshots replace each other, so
+// a stream only ever needs the latest one. ID is derived from the content:
+// equal tallies have equal IDs on every instance, which is what lets a
+// client resume with Last-Event-ID after reconnecting anywhere.
+type Event struct {
+	ID     string
+	PollID int64
+	Data   []byte
+}
+
+// NewEvent encodes results as the snapshot of pollID.
+func NewEvent(pollID int64, results any) (Event, error) {
+	data, err := json.Marshal(results)
+	if err != nil {
+		return Event{}, fmt.Errorf("encode results of polling %d: %w", pollID, err)
+	}
+	sum := sha256.Sum256(data)
+	return Event{ID: hex.EncodeToString(sum[:12]), PollID: pollID, Data: data}, nil
+}
+
+// Hub keeps the open streams of this instance by poll. The zero value is
+// not usable; create one with NewHub.
+type Hub struct {
+	maxTotal     int
+	maxPerClient int
+
+	mu        sync.Mutex
+	closed    bool
+	polls     map[int64]map[*Subscription]struct{}
+	perClient map[string]int
+	total     int
+}
+
+// NewHub returns a hub that allows at most maxTotal streams at once, and
+// at most maxPerClient of them from one client.
+func NewHub(maxTotal, maxPerClient int) *Hub {
+	return &Hub{
+		maxTotal:     maxTotal,
+		maxPerClient: maxPerClient,
+		polls:        make(map[int64]map[*Subscription]struct{}),
+		perClient:    make(map[string]int),
+	}
+}
+
+// Subscription receives the events of one poll until it is closed.
+type Subscription struct {
+	hub    *Hub
+	pollID int64
+	client string
+	events chan Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Events delivers the latest snapshot. A slow reader never blocks the hub:
+// a snapshot it has not read yet is replaced by the newer one.
+func (s *Subscription) Events() <-chan Event { return s.events }
+
+// Done is closed when the hub shuts down; the stream should end.
+func (s *Subscription) Done() <-chan struct{} { return s.done }
+
+// Close releases the subscription and its slot in the limits.
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+// Subscribe opens a stream of pollID for client, usually its IP address.
+func (h *Hub) Subscribe(pollID int64, client string) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, fmt.Errorf("server is shutting down: %w", ErrTooManyStreams)
+	}
+	if h.total >= h.maxTotal {
+		return nil, fmt.Errorf("server already serves %d streams: %w", h.maxTotal, ErrTooManyStreams)
+	}
+	if h.perClient[client] >= h.maxPerClient {
+		return nil, fmt.Errorf("at most %d streams per client: %w", h.maxPerClient, ErrTooManyStreams)
+	}
+
+	sub := &Subscription{
+		hub:    h,
+		pollID: pollID,
+		client: client,
+		events: make(chan Event, 1),
+		done:   make(chan struct{}),
+	}
+	if h.polls[pollID] == nil {
+		h.polls[pollID] = make(map[*Subscription]struct{})
+	}
+	h.polls[pollID][sub] = struct{}{}
+	h.perClient[client]++
+	h.total++
+
+	return sub, nil
+}
+
+func (h *Hub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs, ok := h.polls[sub.pollID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.polls, sub.pollID)
+	}
+	if h.perClient[sub.client]--; h.perClient[sub.client] == 0 {
+		delete(h.perClient, sub.client)
+	}
+	h.total--
+}
+
+// Publish hands ev to every stream of its poll.
+func (h *Hub) Publish(ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.polls[ev.PollID] {
+		// Only the hub sends, under mu, so after draining the stale
+		// snapshot the buffer has room.
+		select {
+		case sub.events <- ev:
+		default:
+			select {
+			case <-sub.events:
+			default:
+			}
+			sub.events <- ev
+		}
+	}
+}
+
+// Watched reports whether any stream of pollID is open here.
+func (h *Hub) Watched(pollID int64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.polls[pollID]) > 0
+}
+
+// Polls lists the polls that have open streams.
+func (h *Hub) Polls() []int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ids := make([]int64, 0, len(h.polls))
+	for id := range h.polls {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Open returns the number of open streams.
+func (h *Hub) Open() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.total
+}
+
+// Close ends every stream and refuses new ones. It is meant to run when
+// the HTTP server starts shutting down, which would otherwise wait for
+// streams that never finish on their own.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, subs := range h.polls {
+		for sub := range subs {
+			sub.once.Do(func() { close(sub.done) })
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package realtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEvent(t *testing.T) {
+	a, err := NewEvent(1, map[string]int{"votes": 1})
+	require.NoError(t, err)
+	b, err := NewEvent(1, map[string]int{"votes": 1})
+	require.NoError(t, err)
+	c, err := NewEvent(1, map[string]int{"votes": 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"votes":1}`, string(a.Data))
+	assert.Equal(t, a.ID, b.ID)
+	assert.NotEqual(t, a.ID, c.ID)
+}
+
+func TestHub_Limits(t *testing.T) {
+	hub := NewHub(3, 2)
+
+	first, err := hub.Subscribe(1, "10.0.0.1")
+	require.NoError(t, err)
+	_, err = hub.Subscribe(2, "10.0.0.1")
+	require.NoError(t, err)
+
+	_, err = hub.Subscribe(1, "10.0.0.1")
+	assert.ErrorIs(t, err, ErrTooManyStreams, "per client")
+
+	_, err = hub.Subscribe(1, "10.0.0.2")
+	require.NoError(t, err)
+	_, err = hub.Subscribe(1, "10.0.0.3")
+	assert.ErrorIs(t, err, ErrTooManyStreams, "total")
+
+	first.Close()
+	first.Close()
+	assert.Equal(t, 2, hub.Open())
+	_, err = hub.Subscribe(1, "10.0.0.1")
+	assert.NoError(t, err)
+}
+
+func TestHub_PublishKeepsLatest(t *testing.T) {
+	hub := NewHub(10, 10)
+	sub, err := hub.Subscribe(1, "10.0.0.1")
+	require.NoError(t, err)
+	other, err := hub.Subscribe(2, "10.0.0.1")
+	require.NoError(t, err)
+
+	assert.True(t, hub.Watched(1))
+	assert.False(t, hub.Watched(3))
+	assert.ElementsMatch(t, []int64{1, 2}, hub.Polls())
+
+	hub.Publish(Event{ID: "a", PollID: 1})
+	hub.Publish(Event{ID: "b", PollID: 1})
+
+	assert.Equal(t, "b", (<-sub.Events()).ID)
+	assert.Empty(t, other.Events())
+
+	sub.Close()
+	assert.False(t, hub.Watched(1))
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := NewHub(10, 10)
+	sub, err := hub.Subscribe(1, "10.0.0.1")
+	require.NoError(t, err)
+
+	hub.Close()
+	hub.Close()
+
+	select {
+	case <-sub.Done():
+	default:
+		t.Fatal("subscription not ended by Close")
+	}
+	_, err = hub.Subscribe(1, "10.0.0.1")
+	assert.ErrorIs(t, err, ErrTooManyStreams)
+}
@@ -0,0 +1,101 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"native-free-pollings/domain"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	reconnectMin = time.Second
+	reconnectMax = time.Minute
+	// pingInterval checks an idle LISTEN connection, which would otherwise
+	// go unnoticed when it drops.
+	pingInterval = 90 * time.Second
+)
+
+// Loader reads the current results of a poll.
+type Loader func(ctx context.Context, pollID int64) (any, error)
+
+// Listener turns notifications on domain.ResultsChannel into hub events.
+// The notification only carries the poll ID; the results are read back
+// here, and only for polls someone is watching on this instance.
+type Listener struct {
+	dsn  string
+	hub  *Hub
+	load Loader
+}
+
+func NewListener(dsn string, hub *Hub, load Loader) *Listener {
+	return &Listener{dsn: dsn, hub: hub, load: load}
+}
+
+// Run listens until ctx is cancelled, reconnecting on its own when the
+// connection drops. It fits server.Workers.
+func (l *Listener) Run(ctx context.Context) error {
+	pl := pq.NewListener(l.dsn, reconnectMin, reconnectMax, func(ev pq.ListenerEventType, err error) {
+		switch ev {
+		case pq.ListenerEventDisconnected:
+			slog.Warn("Results listener disconnected", "error", err)
+		case pq.ListenerEventReconnected:
+			slog.Info("Results listener reconnected")
+		case pq.ListenerEventConnectionAttemptFailed:
+			slog.Warn("Results listener cannot connect", "error", err)
+		}
+	})
+	defer pl.Close()
+
+	if err := pl.Listen(domain.ResultsChannel); err != nil {
+		return fmt.Errorf("listen on %s: %w", domain.ResultsChannel, err)
+	}
+
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case n := <-pl.Notify:
+			if n == nil {
+				// Notifications sent while disconnected are lost, so every
+				// watched poll is refreshed.
+				for _, id := range l.hub.Polls() {
+					l.refresh(ctx, id)
+				}
+				continue
+			}
+			id, err := strconv.ParseInt(n.Extra, 10, 64)
+			if err != nil {
+				slog.Warn("Ignoring malformed results notification", "payload", n.Extra)
+				continue
+			}
+			l.refresh(ctx, id)
+		case <-ping.C:
+			go pl.Ping()
+		}
+	}
+}
+
+func (l *Listener) refresh(ctx context.Context, pollID int64) {
+	if !l.hub.Watched(pollID) {
+		return
+	}
+
+	results, err := l.load(ctx, pollID)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to load results for stream", "poll_id", pollID, "error", err)
+		return
+	}
+	ev, err := NewEvent(pollID, results)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to encode results for stream", "poll_id", pollID, "error", err)
+		return
+	}
+	l.hub.Publish(ev)
+}
@@ -0,0 +1,385 @@
+package realtime
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrSessionNotFound is returned for an unknown or ended session code.
+	ErrSessionNotFound = errors.New("live session not found")
+	// ErrSessionFull is returned by Join when the participant limit is
+	// reached.
+	ErrSessionFull = errors.New("live session is full")
+	// ErrTooSlow ends the membership of a connection that does not keep up
+	// with the session's messages.
+	ErrTooSlow = errors.New("connection too slow for the live session")
+)
+
+// memberBuffer is how many messages a member may lag behind before it is
+// dropped from the session.
+const memberBuffer = 16
+
+// LiveMessage is a message the server sends on a live session connection.
+type LiveMessage struct {
+	Type      string          `json:"type"`
+	Code      string          `json:"code,omitempty"`
+	Role      string          `json:"role,omitempty"`
+	Poll      any             `json:"poll,omitempty"`
+	Results   json.RawMessage `json:"results,omitempty"`
+	OptionID  int64           `json:"option_id,omitempty"`
+	ErrorCode string          `json:"error,omitempty"`
+	Detail    string          `json:"detail,omitempty"`
+}
+
+// Encode returns m as JSON.
+func (m LiveMessage) Encode() []byte {
+	// LiveMessage only holds JSON-safe values, so this cannot fail.
+	data, _ := json.Marshal(m)
+	return data
+}
+
+// LiveSessions keeps the live sessions hosted by this instance. Sessions
+// exist only in memory, so every connection of a session has to reach the
+// same instance.
+type LiveSessions struct {
+	hub        *Hub
+	maxMembers int
+	grace      time.Duration
+
+	mu       sync.Mutex
+	closed   bool
+	done     chan struct{}
+	sessions map[string]*LiveSession
+}
+
+// NewLiveSessions returns a registry whose sessions take their results from
+// hub, admit at most maxMembers participants each, and end grace after the
+// last presenter connection left.
+func NewLiveSessions(hub *Hub, maxMembers int, grace time.Duration) *LiveSessions {
+	return &LiveSessions{
+		hub:        hub,
+		maxMembers: maxMembers,
+		grace:      grace,
+		done:       make(chan struct{}),
+		sessions:   make(map[string]*LiveSession),
+	}
+}
+
+// Start opens a session presented by presenterID.
+func (s *LiveSessions) Start(presenterID int64) (*LiveSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, fmt.Errorf("server is shutting down: %w", ErrSessionNotFound)
+	}
+
+	var code string
+	for {
+		var err error
+		if code, err = newSessionCode(); err != nil {
+			return nil, err
+		}
+		if _, taken := s.sessions[code]; !taken {
+			break
+		}
+	}
+
+	sess := &LiveSession{
+		Code:        code,
+		PresenterID: presenterID,
+		owner:       s,
+		members:     make(map[*Member]struct{}),
+	}
+	s.sessions[code] = sess
+	return sess, nil
+}
+
+// newSessionCode returns a random code short enough to type from a slide.
+func newSessionCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate session code: %w", err)
+	}
+	return base32.StdEncoding.EncodeToString(b), nil
+}
+
+// Find returns the session with code.
+func (s *LiveSessions) Find(code string) (*LiveSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[code]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return sess, nil
+}
+
+// Open returns the number of running sessions.
+func (s *LiveSessions) Open() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+// Done is closed when the server shuts down; connections should close.
+func (s *LiveSessions) Done() <-chan struct{} { return s.done }
+
+// Close ends every session and refuses new ones. Like Hub.Close it is meant
+// to run when the HTTP server starts shutting down, which does not wait for
+// hijacked connections.
+func (s *LiveSessions) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.done)
+	sessions := make([]*LiveSession, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.mu.Unlock()
+
+	for _, sess := range sessions {
+		sess.End()
+	}
+}
+
+func (s *LiveSessions) remove(code string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, code)
+}
+
+// LiveSession is one presentation: the poll on screen, its latest results
+// and everyone following along.
+type LiveSession struct {
+	Code        string
+	PresenterID int64
+
+	owner *LiveSessions
+
+	mu           sync.Mutex
+	ended        bool
+	members      map[*Member]struct{}
+	participants int
+	presenters   int
+	grace        *time.Timer
+
+	pollID    int64
+	state     []byte
+	resultsID string
+	results   []byte
+	sub       *Subscription
+	stop      chan struct{}
+}
+
+// Member is one connection in a session.
+type Member struct {
+	Presenter bool
+
+	session  *LiveSession
+	messages chan []byte
+	done     chan struct{}
+	err      error
+}
+
+// Messages delivers what the session broadcasts, in order.
+func (m *Member) Messages() <-chan []byte { return m.messages }
+
+// Done is closed when the membership ends: the member left, fell behind or
+// the session ended. Messages may still hold the last ones to send.
+func (m *Member) Done() <-chan struct{} { return m.done }
+
+// Err tells why Done was closed: ErrTooSlow, ErrSessionNotFound when the
+// session ended, or nil after Leave.
+func (m *Member) Err() error {
+	m.session.mu.Lock()
+	defer m.session.mu.Unlock()
+	return m.err
+}
+
+// Leave ends the membership.
+func (m *Member) Leave() {
+	m.session.leave(m, nil)
+}
+
+// Join adds a connection to the session. Participants are limited, the
+// presenter's own connections are not. A new member starts with the
+// current poll and results.
+func (s *LiveSession) Join(presenter bool) (*Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return nil, ErrSessionNotFound
+	}
+	if !presenter && s.participants >= s.owner.maxMembers {
+		return nil, fmt.Errorf("at most %d participants: %w", s.owner.maxMembers, ErrSessionFull)
+	}
+
+	m := &Member{
+		Presenter: presenter,
+		session:   s,
+		messages:  make(chan []byte, memberBuffer),
+		done:      make(chan struct{}),
+	}
+	s.members[m] = struct{}{}
+	if presenter {
+		s.presenters++
+		if s.grace != nil {
+			s.grace.Stop()
+			s.grace = nil
+		}
+	} else {
+		s.participants++
+	}
+
+	if s.state != nil {
+		m.messages <- s.state
+	}
+	if s.results != nil {
+		m.messages <- s.results
+	}
+	return m, nil
+}
+
+func (s *LiveSession) leave(m *Member, reason error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.leaveLocked(m, reason)
+}
+
+func (s *LiveSession) leaveLocked(m *Member, reason error) {
+	if _, ok := s.members[m]; !ok {
+		return
+	}
+	delete(s.members, m)
+	m.err = reason
+	close(m.done)
+
+	if !m.Presenter {
+		s.participants--
+		return
+	}
+	s.presenters--
+	// A presenter reconnecting after a network hiccup keeps the session;
+	// one that is gone for good takes it down after the grace period.
+	if s.presenters == 0 && !s.ended {
+		s.grace = time.AfterFunc(s.owner.grace, s.End)
+	}
+}
+
+// Show puts pollID on screen. poll is sent to every member as the session
+// state, and the results of pollID are followed from the hub from now on.
+// Showing the current poll again only refreshes the state, which is how
+// opening and closing the vote reach the audience.
+func (s *LiveSession) Show(pollID int64, poll any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return ErrSessionNotFound
+	}
+
+	if pollID != s.pollID {
+		sub, err := s.owner.hub.Subscribe(pollID, "live:"+s.Code)
+		if err != nil {
+			return err
+		}
+		s.stopFollowing()
+		s.pollID = pollID
+		s.sub = sub
+		s.stop = make(chan struct{})
+		s.resultsID = ""
+		s.results = nil
+		go s.follow(sub, s.stop)
+	}
+
+	s.state = LiveMessage{Type: "state", Code: s.Code, Poll: poll}.Encode()
+	s.broadcastLocked(s.state)
+	return nil
+}
+
+// PollID returns the poll on screen, or zero before the first Show.
+func (s *LiveSession) PollID() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pollID
+}
+
+func (s *LiveSession) follow(sub *Subscription, stop <-chan struct{}) {
+	for {
+		select {
+		case ev := <-sub.Events():
+			s.SetResults(ev)
+		case <-sub.Done():
+			return
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (s *LiveSession) stopFollowing() {
+	if s.sub != nil {
+		s.sub.Close()
+		close(s.stop)
+		s.sub = nil
+	}
+}
+
+// SetResults broadcasts ev when it is a new snapshot of the poll on screen.
+func (s *LiveSession) SetResults(ev Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended || ev.PollID != s.pollID || ev.ID == s.resultsID {
+		return
+	}
+	s.resultsID = ev.ID
+	s.results = LiveMessage{Type: "results", Code: s.Code, Results: ev.Data}.Encode()
+	s.broadcastLocked(s.results)
+}
+
+// broadcastLocked queues msg for every member. A member whose queue is full
+// is dropped rather than allowed to hold up everyone else.
+func (s *LiveSession) broadcastLocked(msg []byte) {
+	for m := range s.members {
+		select {
+		case m.messages <- msg:
+		default:
+			s.leaveLocked(m, ErrTooSlow)
+		}
+	}
+}
+
+// End tells every member the session is over and forgets it.
+func (s *LiveSession) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	s.broadcastLocked(LiveMessage{Type: "ended", Code: s.Code}.Encode())
+	s.ended = true
+	for m := range s.members {
+		s.leaveLocked(m, ErrSessionNotFound)
+	}
+	if s.grace != nil {
+		s.grace.Stop()
+		s.grace = nil
+	}
+	s.stopFollowing()
+	s.owner.remove(s.Code)
+}
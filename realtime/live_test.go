@@ -0,0 +1,169 @@
+package realtime
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func nextMessage(t *testing.T, m *Member) LiveMessage {
+	t.Helper()
+	select {
+	case data := <-m.Messages():
+		var msg LiveMessage
+		require.NoError(t, json.Unmarshal(data, &msg))
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no message")
+		return LiveMessage{}
+	}
+}
+
+func TestLiveSessions_StartFind(t *testing.T) {
+	sessions := NewLiveSessions(NewHub(10, 10), 10, time.Minute)
+
+	sess, err := sessions.Start(7)
+	require.NoError(t, err)
+	assert.Len(t, sess.Code, 8)
+	assert.Equal(t, int64(7), sess.PresenterID)
+
+	found, err := sessions.Find(sess.Code)
+	require.NoError(t, err)
+	assert.Same(t, sess, found)
+
+	_, err = sessions.Find("NOPE")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	assert.Equal(t, 1, sessions.Open())
+}
+
+func TestLiveSession_ParticipantLimit(t *testing.T) {
+	sessions := NewLiveSessions(NewHub(10, 10), 1, time.Minute)
+	sess, err := sessions.Start(1)
+	require.NoError(t, err)
+
+	first, err := sess.Join(false)
+	require.NoError(t, err)
+	_, err = sess.Join(false)
+	assert.ErrorIs(t, err, ErrSessionFull)
+	_, err = sess.Join(true)
+	assert.NoError(t, err, "the presenter does not count")
+
+	first.Leave()
+	_, err = sess.Join(false)
+	assert.NoError(t, err)
+}
+
+func TestLiveSession_ShowAndResults(t *testing.T) {
+	hub := NewHub(10, 10)
+	sess, err := NewLiveSessions(hub, 10, time.Minute).Start(1)
+	require.NoError(t, err)
+
+	presenter, err := sess.Join(true)
+	require.NoError(t, err)
+	require.NoError(t, sess.Show(5, map[string]string{"title": "Languages"}))
+
+	state := nextMessage(t, presenter)
+	assert.Equal(t, "state", state.Type)
+	assert.Equal(t, map[string]any{"title": "Languages"}, state.Poll)
+	assert.True(t, hub.Watched(5))
+
+	ev, err := NewEvent(5, map[string]int{"total_votes": 1})
+	require.NoError(t, err)
+	hub.Publish(ev)
+	results := nextMessage(t, presenter)
+	assert.Equal(t, "results", results.Type)
+	assert.JSONEq(t, `{"total_votes":1}`, string(results.Results))
+
+	// The same snapshot again, or one of another poll, is not sent.
+	sess.SetResults(ev)
+	other, err := NewEvent(6, map[string]int{"total_votes": 9})
+	require.NoError(t, err)
+	sess.SetResults(other)
+	assert.Empty(t, presenter.Messages())
+
+	// Late joiners catch up with the poll on screen and its results.
+	participant, err := sess.Join(false)
+	require.NoError(t, err)
+	assert.Equal(t, "state", nextMessage(t, participant).Type)
+	assert.Equal(t, "results", nextMessage(t, participant).Type)
+
+	// Switching polls follows the new one only.
+	require.NoError(t, sess.Show(6, nil))
+	assert.False(t, hub.Watched(5))
+	assert.True(t, hub.Watched(6))
+}
+
+func TestLiveSession_SlowMemberIsDropped(t *testing.T) {
+	sess, err := NewLiveSessions(NewHub(10, 10), 10, time.Minute).Start(1)
+	require.NoError(t, err)
+	member, err := sess.Join(false)
+	require.NoError(t, err)
+
+	for i := range memberBuffer + 1 {
+		require.NoError(t, sess.Show(1, i))
+	}
+
+	<-member.Done()
+	assert.ErrorIs(t, member.Err(), ErrTooSlow)
+}
+
+func TestLiveSession_End(t *testing.T) {
+	hub := NewHub(10, 10)
+	sessions := NewLiveSessions(hub, 10, time.Minute)
+	sess, err := sessions.Start(1)
+	require.NoError(t, err)
+	member, err := sess.Join(false)
+	require.NoError(t, err)
+	require.NoError(t, sess.Show(3, nil))
+	nextMessage(t, member)
+
+	sess.End()
+	sess.End()
+
+	assert.Equal(t, "ended", nextMessage(t, member).Type)
+	<-member.Done()
+	assert.ErrorIs(t, member.Err(), ErrSessionNotFound)
+	assert.False(t, hub.Watched(3))
+	_, err = sessions.Find(sess.Code)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	_, err = sess.Join(false)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestLiveSession_PresenterGrace(t *testing.T) {
+	sessions := NewLiveSessions(NewHub(10, 10), 10, 20*time.Millisecond)
+	sess, err := sessions.Start(1)
+	require.NoError(t, err)
+
+	presenter, err := sess.Join(true)
+	require.NoError(t, err)
+	presenter.Leave()
+
+	// Coming back within the grace period keeps the session.
+	presenter, err = sess.Join(true)
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, sessions.Open())
+
+	presenter.Leave()
+	assert.Eventually(t, func() bool { return sessions.Open() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestLiveSessions_Close(t *testing.T) {
+	sessions := NewLiveSessions(NewHub(10, 10), 10, time.Minute)
+	sess, err := sessions.Start(1)
+	require.NoError(t, err)
+	member, err := sess.Join(false)
+	require.NoError(t, err)
+
+	sessions.Close()
+	sessions.Close()
+
+	<-member.Done()
+	<-sessions.Done()
+	_, err = sessions.Start(1)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
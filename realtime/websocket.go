@@ -0,0 +1,325 @@
+package realtime
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// acceptGUID is appended to the client key to compute Sec-WebSocket-Accept
+// (RFC 6455, section 1.3).
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// writeWait bounds every frame written, so a peer that stopped reading
+// cannot hold a connection forever.
+const writeWait = 10 * time.Second
+
+// Close codes used by this server (RFC 6455, section 7.4.1).
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseTooBig          = 1009
+	CloseInternalError   = 1011
+	CloseTryAgainLater   = 1013
+)
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// ErrBadHandshake is returned by Upgrade when the request is not a valid
+// WebSocket opening handshake. Nothing has been written to the client yet.
+var ErrBadHandshake = errors.New("websocket: bad handshake")
+
+// CloseError reports why a connection was closed, by the peer or by this
+// side after a protocol violation.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket closed: %d %s", e.Code, e.Reason)
+}
+
+// Conn is the server side of a WebSocket connection. One goroutine may read
+// while any number write; writes are serialized.
+type Conn struct {
+	conn       net.Conn
+	br         *bufio.Reader
+	maxMessage int64
+	idle       time.Duration
+
+	wmu       sync.Mutex
+	closeSent bool
+}
+
+// Upgrade answers the opening handshake of r and takes over the connection.
+// Messages longer than maxMessage bytes are refused. The caller owns the
+// returned connection and must Close it.
+func Upgrade(w http.ResponseWriter, r *http.Request, maxMessage int64) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		return nil, fmt.Errorf("%w: method must be GET", ErrBadHandshake)
+	}
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") {
+		return nil, fmt.Errorf("%w: not a websocket upgrade", ErrBadHandshake)
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return nil, fmt.Errorf("%w: unsupported websocket version", ErrBadHandshake)
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if raw, err := base64.StdEncoding.DecodeString(key); err != nil || len(raw) != 16 {
+		return nil, fmt.Errorf("%w: invalid Sec-WebSocket-Key", ErrBadHandshake)
+	}
+
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("hijack connection: %w", err)
+	}
+	// The server timeouts were set for ordinary requests; the connection
+	// now manages its own deadlines.
+	_ = conn.SetDeadline(time.Time{})
+
+	resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+	if _, err := conn.Write([]byte(resp)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("write handshake: %w", err)
+	}
+
+	return &Conn{conn: conn, br: brw.Reader, maxMessage: maxMessage}, nil
+}
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// KeepAlive makes ReadMessage fail when nothing, not even a pong, arrives
+// for timeout. Pair it with Ping at a shorter interval.
+func (c *Conn) KeepAlive(timeout time.Duration) {
+	c.idle = timeout
+}
+
+// ReadMessage returns the next text message. Pings are answered and pongs
+// skipped on the way. When the peer closes, or breaks the protocol, the
+// close frame is answered and a *CloseError returned; the connection is
+// then unusable and only Close remains to be called.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	var msg []byte
+	inMessage := false
+
+	for {
+		if c.idle > 0 {
+			_ = c.conn.SetReadDeadline(time.Now().Add(c.idle))
+		}
+
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			var ce *CloseError
+			if errors.As(err, &ce) {
+				c.writeClose(ce.Code, ce.Reason)
+			}
+			return nil, err
+		}
+
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			ce := parseClose(payload)
+			c.writeClose(ce.Code, "")
+			return nil, ce
+		case opBinary:
+			return nil, c.fail(CloseUnsupportedData, "binary messages are not supported")
+		case opText:
+			if inMessage {
+				return nil, c.fail(CloseProtocolError, "new message inside a fragmented one")
+			}
+			inMessage = true
+		case opContinuation:
+			if !inMessage {
+				return nil, c.fail(CloseProtocolError, "continuation without a message")
+			}
+		default:
+			return nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+
+		if int64(len(msg))+int64(len(payload)) > c.maxMessage {
+			return nil, c.fail(CloseTooBig, fmt.Sprintf("message exceeds %d bytes", c.maxMessage))
+		}
+		msg = append(msg, payload...)
+
+		if fin {
+			if !utf8.Valid(msg) {
+				return nil, c.fail(CloseInvalidPayload, "text message is not valid UTF-8")
+			}
+			return msg, nil
+		}
+	}
+}
+
+// readFrame reads one frame and unmasks its payload. Protocol violations
+// come back as *CloseError.
+func (c *Conn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = head[0]&0x80 != 0
+	op = head[0] & 0x0F
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, &CloseError{Code: CloseProtocolError, Reason: "reserved bits set"}
+	}
+	if head[1]&0x80 == 0 {
+		return false, 0, nil, &CloseError{Code: CloseProtocolError, Reason: "client frames must be masked"}
+	}
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if op >= opClose && (!fin || length > 125) {
+		return false, 0, nil, &CloseError{Code: CloseProtocolError, Reason: "invalid control frame"}
+	}
+	// Checked before allocating, so a forged length cannot exhaust memory.
+	if length > uint64(c.maxMessage) {
+		return false, 0, nil, &CloseError{Code: CloseTooBig, Reason: fmt.Sprintf("message exceeds %d bytes", c.maxMessage)}
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, op, payload, nil
+}
+
+func parseClose(payload []byte) *CloseError {
+	if len(payload) < 2 {
+		return &CloseError{Code: CloseNormal}
+	}
+	return &CloseError{Code: int(binary.BigEndian.Uint16(payload)), Reason: string(payload[2:])}
+}
+
+// fail sends a close frame for a protocol violation and returns the error.
+func (c *Conn) fail(code int, reason string) error {
+	c.writeClose(code, reason)
+	return &CloseError{Code: code, Reason: reason}
+}
+
+// WriteText sends msg as one text message.
+func (c *Conn) WriteText(msg []byte) error {
+	return c.writeFrame(opText, msg)
+}
+
+// Ping sends a ping; the peer's pong keeps the connection alive.
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// Close sends a close frame with code and reason, unless one was sent
+// already, and closes the connection.
+func (c *Conn) Close(code int, reason string) error {
+	c.writeClose(code, reason)
+	return c.conn.Close()
+}
+
+func (c *Conn) writeClose(code int, reason string) {
+	// Control frame payloads are limited to 125 bytes, two of them the code.
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	_ = c.writeFrame(opClose, payload)
+}
+
+func (c *Conn) writeFrame(op byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if c.closeSent {
+		return net.ErrClosed
+	}
+	if op == opClose {
+		c.closeSent = true
+	}
+
+	// Server frames are never masked.
+	head := make([]byte, 0, 10+len(payload))
+	head = append(head, 0x80|op)
+	switch n := len(payload); {
+	case n <= 125:
+		head = append(head, byte(n))
+	case n <= 0xFFFF:
+		head = append(head, 126)
+		head = binary.BigEndian.AppendUint16(head, uint16(n))
+	default:
+		head = append(head, 127)
+		head = binary.BigEndian.AppendUint64(head, uint64(n))
+	}
+	frame := append(head, payload...)
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	_, err := c.conn.Write(frame)
+	return err
+}
@@ -0,0 +1,218 @@
+package realtime
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestUpgrade_BadHandshake(t *testing.T) {
+	valid := func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/live", nil)
+		r.Header.Set("Connection", "keep-alive, Upgrade")
+		r.Header.Set("Upgrade", "websocket")
+		r.Header.Set("Sec-WebSocket-Version", "13")
+		r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		return r
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(r *http.Request)
+		wantErr string
+	}{
+		{name: "not GET", modify: func(r *http.Request) { r.Method = http.MethodPost }, wantErr: "method must be GET"},
+		{name: "no upgrade", modify: func(r *http.Request) { r.Header.Del("Upgrade") }, wantErr: "not a websocket upgrade"},
+		{name: "old version", modify: func(r *http.Request) { r.Header.Set("Sec-WebSocket-Version", "8") }, wantErr: "unsupported websocket version"},
+		{name: "short key", modify: func(r *http.Request) { r.Header.Set("Sec-WebSocket-Key", "c2hvcnQ=") }, wantErr: "invalid Sec-WebSocket-Key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid()
+			tt.modify(r)
+			_, err := Upgrade(httptest.NewRecorder(), r, 1024)
+			assert.ErrorIs(t, err, ErrBadHandshake)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAcceptKey(t *testing.T) {
+	// The example from RFC 6455, section 1.3.
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", acceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+// echoServer echoes text messages and reports how each connection ended.
+func echoServer(t *testing.T, maxMessage int64) (*httptest.Server, <-chan error) {
+	ended := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, maxMessage)
+		if err != nil {
+			ended <- err
+			return
+		}
+		defer conn.Close(CloseNormal, "")
+		for {
+			msg, err := conn.ReadMessage()
+			if err != nil {
+				ended <- err
+				return
+			}
+			if err := conn.WriteText(msg); err != nil {
+				ended <- err
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, ended
+}
+
+func dial(t *testing.T, srv *httptest.Server) *websocket.Conn {
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), "", srv.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { ws.Close() })
+	require.NoError(t, ws.SetDeadline(time.Now().Add(5*time.Second)))
+	return ws
+}
+
+func TestConn_Echo(t *testing.T) {
+	srv, ended := echoServer(t, 1<<20)
+	ws := dial(t, srv)
+
+	for _, msg := range []string{"hello", "", strings.Repeat("x", 70000)} {
+		require.NoError(t, websocket.Message.Send(ws, msg))
+		var got string
+		require.NoError(t, websocket.Message.Receive(ws, &got))
+		assert.Equal(t, msg, got)
+	}
+
+	ws.Close()
+	err := <-ended
+	var ce *CloseError
+	require.ErrorAs(t, err, &ce)
+	assert.Equal(t, CloseNormal, ce.Code)
+}
+
+func TestConn_TooBig(t *testing.T) {
+	srv, ended := echoServer(t, 8)
+	ws := dial(t, srv)
+
+	require.NoError(t, websocket.Message.Send(ws, "more than eight bytes"))
+	var got string
+	assert.Error(t, websocket.Message.Receive(ws, &got))
+
+	var ce *CloseError
+	require.ErrorAs(t, <-ended, &ce)
+	assert.Equal(t, CloseTooBig, ce.Code)
+}
+
+func TestConn_ProtocolErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		frames   [][]byte
+		wantCode int
+	}{
+		{name: "unmasked frame", frames: [][]byte{{0x81, 0x02, 'h', 'i'}}, wantCode: CloseProtocolError},
+		{name: "binary message", frames: [][]byte{maskedFrame(0x82, []byte{1, 2})}, wantCode: CloseUnsupportedData},
+		{name: "invalid UTF-8", frames: [][]byte{maskedFrame(0x81, []byte{0xff, 0xfe})}, wantCode: CloseInvalidPayload},
+		{name: "stray continuation", frames: [][]byte{maskedFrame(0x80, []byte("hi"))}, wantCode: CloseProtocolError},
+		{
+			name:     "fragmented ping",
+			frames:   [][]byte{maskedFrame(0x09, nil)},
+			wantCode: CloseProtocolError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, ended := echoServer(t, 1024)
+			conn, br := rawDial(t, srv)
+
+			for _, f := range tt.frames {
+				_, err := conn.Write(f)
+				require.NoError(t, err)
+			}
+
+			op, payload := readServerFrame(t, br)
+			assert.Equal(t, byte(opClose), op)
+			assert.Equal(t, tt.wantCode, int(binary.BigEndian.Uint16(payload)))
+
+			var ce *CloseError
+			require.ErrorAs(t, <-ended, &ce)
+			assert.Equal(t, tt.wantCode, ce.Code)
+		})
+	}
+}
+
+func TestConn_FragmentsAndPing(t *testing.T) {
+	srv, _ := echoServer(t, 1024)
+	conn, br := rawDial(t, srv)
+
+	for _, f := range [][]byte{
+		maskedFrame(0x01, []byte("hel")),
+		maskedFrame(0x89, []byte("p")),
+		maskedFrame(0x80, []byte("lo")),
+	} {
+		_, err := conn.Write(f)
+		require.NoError(t, err)
+	}
+
+	op, payload := readServerFrame(t, br)
+	assert.Equal(t, byte(opPong), op)
+	assert.Equal(t, "p", string(payload))
+	op, payload = readServerFrame(t, br)
+	assert.Equal(t, byte(opText), op)
+	assert.Equal(t, "hello", string(payload))
+}
+
+func rawDial(t *testing.T, srv *httptest.Server) (net.Conn, *bufio.Reader) {
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	_, err = io.WriteString(conn, "GET / HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	require.NoError(t, err)
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+	return conn, br
+}
+
+// maskedFrame builds a client frame; head is the first byte, FIN and opcode.
+func maskedFrame(head byte, payload []byte) []byte {
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{head, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	return frame
+}
+
+func readServerFrame(t *testing.T, br *bufio.Reader) (byte, []byte) {
+	var head [2]byte
+	_, err := io.ReadFull(br, head[:])
+	require.NoError(t, err)
+	require.Zero(t, head[1]&0x80, "server frames must not be masked")
+	payload := make([]byte, head[1]&0x7F)
+	_, err = io.ReadFull(br, payload)
+	require.NoError(t, err)
+	return head[0] & 0x0F, payload
+}
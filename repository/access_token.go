@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"native-free-pollings/domain"
+	"native-free-pollings/models"
+	"native-free-pollings/tracing"
+
+	"github.com/lib/pq"
+)
+
+type accessToken struct {
+	DB domain.DB
+}
+
+func NewAccessToken(db *sql.DB) domain.AccessTokenRepository {
+	return &accessToken{DB: tracing.WrapDB(db)}
+}
+
+func (a *accessToken) Create(ctx context.Context, token *models.AccessToken) error {
+	query := `
+		INSERT INTO personal_access_tokens (user_id, name, token_hash, prefix, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	err := a.DB.QueryRowContext(ctx, query, token.UserID, token.Name, token.TokenHash, token.Prefix, pq.Array(token.Scopes), token.ExpiresAt).
+		Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert access token failed: %w", err)
+	}
+
+	return nil
+}
+
+func (a *accessToken) ListByUserID(ctx context.Context, userID int64) ([]models.AccessToken, error) {
+	query := `
+		SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at
+		FROM personal_access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := a.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []models.AccessToken
+	for rows.Next() {
+		var t models.AccessToken
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.Prefix, pq.Array(&t.Scopes), &t.ExpiresAt, &t.LastUsedAt, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("interation failed: %w", err)
+	}
+
+	return tokens, nil
+}
+
+func (a *accessToken) Delete(ctx context.Context, userID, id int64) error {
+	query := `
+		DELETE FROM personal_access_tokens WHERE id = $1 AND user_id = $2
+	`
+	result, err := a.DB.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("delete access token failed: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (a *accessToken) GetByHash(ctx context.Context, hash string) (*models.AccessToken, error) {
+	query := `
+		SELECT t.id, t.user_id, t.name, t.prefix, t.scopes, t.expires_at, t.last_used_at, t.created_at,
+			   u.name AS user_name, u.email AS user_email, u.role AS user_role
+		FROM personal_access_tokens t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = $1 AND u.disabled_at IS NULL
+	`
+
+	var t models.AccessToken
+	err := a.DB.QueryRowContext(ctx, query, hash).
+		Scan(&t.ID, &t.UserID, &t.Name, &t.Prefix, pq.Array(&t.Scopes), &t.ExpiresAt, &t.LastUsedAt, &t.CreatedAt, &t.UserName, &t.UserEmail, &t.UserRole)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// TouchLastUsed updates last_used_at at most once a minute per token so busy
+// automation does not turn every request into a write.
+func (a *accessToken) TouchLastUsed(ctx context.Context, id int64) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET last_used_at = now()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+	`
+	if _, err := a.DB.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("update last used failed: %w", err)
+	}
+
+	return nil
+}
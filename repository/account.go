@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"native-free-pollings/domain"
+	"native-free-pollings/models"
+	"native-free-pollings/tracing"
+
+	"github.com/lib/pq"
+)
+
+// erasedDeviceHash replaces the device hash of votes whose voter deleted
+// their account. The vote still counts; it just can no longer be traced.
+const erasedDeviceHash = "erased"
+
+type account struct {
+	DB *tracing.DB
+}
+
+func NewAccount(db *sql.DB) domain.AccountRepository {
+	return &account{DB: tracing.WrapDB(db)}
+}
+
+func (a *account) Export(ctx context.Context, userID int64) (*models.AccountExport, error) {
+	// One snapshot so the sections of the export agree with each other.
+	tx, err := a.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	export := &models.AccountExport{}
+
+	query := `
+		SELECT id, email, name, role, disabled_at, totp_enabled, created_at, updated_at
+		FROM users
+		WHERE id = $1
+	`
+	u := &export.User
+	err = tx.QueryRowContext(ctx, query, userID).
+		Scan(&u.ID, &u.Email, &u.Name, &u.Role, &u.DisabledAt, &export.TwoFactor, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := []func(context.Context, *tracing.Tx, *models.AccountExport) error{
+		exportPolls,
+		exportVotes,
+		exportSessions,
+		exportAccessTokens,
+		exportIdentities,
+		exportLoginAttempts,
+		exportWebhooks,
+	}
+	for _, step := range steps {
+		if err := step(ctx, tx, export); err != nil {
+			return nil, err
+		}
+	}
+
+	return export, nil
+}
+
+func exportPolls(ctx context.Context, tx *tracing.Tx, export *models.AccountExport) error {
+	query := `
+		SELECT id, title, COALESCE(description, ''), status, starts_at, ends_at, created_at, updated_at
+		FROM polls
+		WHERE user_id = $1
+		ORDER BY id
+	`
+	rows, err := tx.QueryContext(ctx, query, export.User.ID)
+	if err != nil {
+		return fmt.Errorf("query polls error: %w", err)
+	}
+	defer rows.Close()
+
+	index := map[int64]int{}
+	for rows.Next() {
+		var p models.ExportedPoll
+		if err := rows.Scan(&p.ID, &p.Title, &p.Description, &p.Status, &p.StartsAt, &p.EndsAt, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return fmt.Errorf("scan poll error: %w", err)
+		}
+		index[p.ID] = len(export.Polls)
+		export.Polls = append(export.Polls, p)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("interation failed: %w", err)
+	}
+
+	query = `
+		SELECT po.poll_id, po.id, po.label, po.position, count(v.id)
+		FROM poll_options po
+		JOIN polls p ON p.id = po.poll_id
+		LEFT JOIN votes v ON v.option_id = po.id
+		WHERE p.user_id = $1
+		GROUP BY po.id
+		ORDER BY po.poll_id, po.position
+	`
+	optRows, err := tx.QueryContext(ctx, query, export.User.ID)
+	if err != nil {
+		return fmt.Errorf("query options error: %w", err)
+	}
+	defer optRows.Close()
+
+	for optRows.Next() {
+		var pollID int64
+		var o models.ExportedOption
+		if err := optRows.Scan(&pollID, &o.ID, &o.Label, &o.Position, &o.Votes); err != nil {
+			return fmt.Errorf("scan option error: %w", err)
+		}
+		if i, ok := index[pollID]; ok {
+			export.Polls[i].Options = append(export.Polls[i].Options, o)
+		}
+	}
+
+	return optRows.Err()
+}
+
+func exportVotes(ctx context.Context, tx *tracing.Tx, export *models.AccountExport) error {
+	query := `
+		SELECT p.id, p.title, po.id, po.label, v.created_at
+		FROM user_votes uv
+		JOIN votes v ON v.id = uv.vote_id
+		JOIN poll_options po ON po.id = v.option_id
+		JOIN polls p ON p.id = po.poll_id
+		WHERE uv.user_id = $1
+		ORDER BY v.created_at
+	`
+	rows, err := tx.QueryContext(ctx, query, export.User.ID)
+	if err != nil {
+		return fmt.Errorf("query votes error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v models.ExportedVote
+		if err := rows.Scan(&v.PollID, &v.PollTitle, &v.OptionID, &v.OptionLabel, &v.VotedAt); err != nil {
+			return fmt.Errorf("scan vote error: %w", err)
+		}
+		export.Votes = append(export.Votes, v)
+	}
+
+	return rows.Err()
+}
+
+func exportSessions(ctx context.Context, tx *tracing.Tx, export *models.AccountExport) error {
+	query := `
+		SELECT id, user_id, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+	rows, err := tx.QueryContext(ctx, query, export.User.ID)
+	if err != nil {
+		return fmt.Errorf("query sessions error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s models.Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt, &s.RevokedAt); err != nil {
+			return fmt.Errorf("scan session error: %w", err)
+		}
+		export.Sessions = append(export.Sessions, s)
+	}
+
+	return rows.Err()
+}
+
+func exportAccessTokens(ctx context.Context, tx *tracing.Tx, export *models.AccountExport) error {
+	query := `
+		SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at
+		FROM personal_access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+	rows, err := tx.QueryContext(ctx, query, export.User.ID)
+	if err != nil {
+		return fmt.Errorf("query access tokens error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.AccessToken
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.Prefix, pq.Array(&t.Scopes), &t.ExpiresAt, &t.LastUsedAt, &t.CreatedAt); err != nil {
+			return fmt.Errorf("scan access token error: %w", err)
+		}
+		export.AccessTokens = append(export.AccessTokens, t)
+	}
+
+	return rows.Err()
+}
+
+func exportIdentities(ctx context.Context, tx *tracing.Tx, export *models.AccountExport) error {
+	query := `
+		SELECT id, user_id, issuer, subject, email, created_at
+		FROM user_identities
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+	rows, err := tx.QueryContext(ctx, query, export.User.ID)
+	if err != nil {
+		return fmt.Errorf("query identities error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i models.Identity
+		if err := rows.Scan(&i.ID, &i.UserID, &i.Issuer, &i.Subject, &i.Email, &i.CreatedAt); err != nil {
+			return fmt.Errorf("scan identity error: %w", err)
+		}
+		export.Identities = append(export.Identities, i)
+	}
+
+	return rows.Err()
+}
+
+func exportLoginAttempts(ctx context.Context, tx *tracing.Tx, export *models.AccountExport) error {
+	query := `
+		SELECT id, email, ip, success, attempted_at
+		FROM login_attempts
+		WHERE lower(email) = lower($1)
+		ORDER BY attempted_at
+	`
+	rows, err := tx.QueryContext(ctx, query, export.User.Email)
+	if err != nil {
+		return fmt.Errorf("query login attempts error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l models.LoginAttempt
+		if err := rows.Scan(&l.ID, &l.Email, &l.IP, &l.Success, &l.AttemptedAt); err != nil {
+			return fmt.Errorf("scan login attempt error: %w", err)
+		}
+		export.LoginAttempts = append(export.LoginAttempts, l)
+	}
+
+	return rows.Err()
+}
+
+// exportWebhooks leaves out the signing secrets, the same way access tokens
+// are exported without their hashes.
+func exportWebhooks(ctx context.Context, tx *tracing.Tx, export *models.AccountExport) error {
+	query := `
+		SELECT id, user_id, poll_id, url, events, consecutive_failures, disabled_at, created_at, updated_at
+		FROM webhooks
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+	rows, err := tx.QueryContext(ctx, query, export.User.ID)
+	if err != nil {
+		return fmt.Errorf("query webhooks error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var h models.Webhook
+		if err := rows.Scan(&h.ID, &h.UserID, &h.PollID, &h.URL, pq.Array(&h.Events), &h.ConsecutiveFailures, &h.DisabledAt, &h.CreatedAt, &h.UpdatedAt); err != nil {
+			return fmt.Errorf("scan webhook error: %w", err)
+		}
+		export.Webhooks = append(export.Webhooks, h)
+	}
+
+	return rows.Err()
+}
+
+type eraseStep struct {
+	query string
+	arg   any
+}
+
+func (a *account) Erase(ctx context.Context, userID int64, deletePolls bool) error {
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	var email string
+	var polls int64
+	query := `
+		SELECT email, (SELECT count(*) FROM polls WHERE user_id = u.id)
+		FROM users u
+		WHERE id = $1
+		FOR UPDATE
+	`
+	if err := tx.QueryRowContext(ctx, query, userID).Scan(&email, &polls); err != nil {
+		return err
+	}
+
+	// Votes on other people's polls keep counting, but are detached from the
+	// account and stripped of the device hash.
+	statements := []eraseStep{
+		{`UPDATE votes SET device_hash = '` + erasedDeviceHash + `' WHERE id IN (SELECT vote_id FROM user_votes WHERE user_id = $1)`, userID},
+		{`DELETE FROM user_votes WHERE user_id = $1`, userID},
+		{`DELETE FROM login_attempts WHERE lower(email) = lower($1)`, email},
+		{`DELETE FROM login_throttles WHERE key = 'email:' || lower($1)`, email},
+	}
+
+	if deletePolls || polls == 0 {
+		statements = append(statements, eraseStep{`DELETE FROM users WHERE id = $1`, userID})
+	} else {
+		statements = append(statements, []eraseStep{
+			{`DELETE FROM sessions WHERE user_id = $1`, userID},
+			{`DELETE FROM personal_access_tokens WHERE user_id = $1`, userID},
+			{`DELETE FROM user_identities WHERE user_id = $1`, userID},
+			{`DELETE FROM user_recovery_codes WHERE user_id = $1`, userID},
+			{`DELETE FROM webhooks WHERE user_id = $1`, userID},
+			{`
+				UPDATE users
+				SET email = 'deleted-' || id || '@deleted.invalid',
+					name = 'Deleted user',
+					password_hash = '',
+					role = 'user',
+					totp_secret = NULL,
+					totp_enabled = false,
+					disabled_at = now(),
+					updated_at = now()
+				WHERE id = $1
+			`, userID},
+		}...)
+	}
+
+	for _, st := range statements {
+		if _, err := tx.ExecContext(ctx, st.query, st.arg); err != nil {
+			return fmt.Errorf("erase account failed: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
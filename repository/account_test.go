@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountRepository_Erase(t *testing.T) {
+	tests := []struct {
+		name        string
+		polls       int64
+		deletePolls bool
+		wantDelete  bool
+	}{
+		{name: "no polls deletes the row", polls: 0, deletePolls: false, wantDelete: true},
+		{name: "polls kept under anonymous owner", polls: 2, deletePolls: false, wantDelete: false},
+		{name: "polls deleted on request", polls: 2, deletePolls: true, wantDelete: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT email, \(SELECT count\(\*\) FROM polls`).
+				WithArgs(int64(1)).
+				WillReturnRows(sqlmock.NewRows([]string{"email", "count"}).AddRow("a@mail.com", tt.polls))
+			mock.ExpectExec(`UPDATE votes SET device_hash = 'erased'`).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 3))
+			mock.ExpectExec(`DELETE FROM user_votes`).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 3))
+			mock.ExpectExec(`DELETE FROM login_attempts`).WithArgs("a@mail.com").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(`DELETE FROM login_throttles`).WithArgs("a@mail.com").WillReturnResult(sqlmock.NewResult(0, 0))
+			if tt.wantDelete {
+				mock.ExpectExec(`DELETE FROM users WHERE id = \$1`).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+			} else {
+				mock.ExpectExec(`DELETE FROM sessions`).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`DELETE FROM personal_access_tokens`).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`DELETE FROM user_identities`).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`DELETE FROM user_recovery_codes`).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`DELETE FROM webhooks`).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE users\s+SET email = 'deleted-'`).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectCommit()
+
+			err = NewAccount(db).Erase(context.Background(), 1, tt.deletePolls)
+
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
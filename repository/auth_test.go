@@ -38,13 +38,13 @@ func TestAuthRepository_CreateUser(t *testing.T) {
 	expectedCreatedAt := time.Now()
 	expectedUpdatedAt := time.Now()
 
-	rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
-		AddRow(expectedId, expectedCreatedAt, expectedUpdatedAt)
+	rows := sqlmock.NewRows([]string{"id", "role", "created_at", "updated_at"}).
+		AddRow(expectedId, "user", expectedCreatedAt, expectedUpdatedAt)
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
 		INSERT INTO users (email, password_hash, name)
         VALUES ($1, $2, $3)
-        RETURNING id, created_at, updated_at
+        RETURNING id, role, created_at, updated_at
 	`)).
 		WithArgs(user.Email, user.PasswordHash, user.Name).
 		WillReturnRows(rows)
@@ -53,6 +53,7 @@ func TestAuthRepository_CreateUser(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedId, user.ID)
+	assert.Equal(t, "user", user.Role)
 	assert.Equal(t, expectedCreatedAt, user.CreatedAt)
 	assert.Equal(t, expectedUpdatedAt, user.UpdatedAt)
 }
@@ -70,11 +71,11 @@ func TestAuthRepository_GetByEmail(t *testing.T) {
 		UpdatedAt:    time.Now(),
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "name", "created_at", "updated_at"}).
-		AddRow(expedtedUser.ID, expedtedUser.Email, expedtedUser.PasswordHash, expedtedUser.Name, expedtedUser.CreatedAt, expedtedUser.UpdatedAt)
+	rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "name", "role", "disabled_at", "created_at", "updated_at"}).
+		AddRow(expedtedUser.ID, expedtedUser.Email, expedtedUser.PasswordHash, expedtedUser.Name, "admin", nil, expedtedUser.CreatedAt, expedtedUser.UpdatedAt)
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT id, email, password_hash, name, created_at, updated_at
+		SELECT id, email, password_hash, name, role, disabled_at, created_at, updated_at
         FROM users
         WHERE email = $1
 		LIMIT 1 
@@ -88,4 +89,6 @@ func TestAuthRepository_GetByEmail(t *testing.T) {
 	assert.Equal(t, expedtedUser.ID, user.ID)
 	assert.Equal(t, expedtedUser.Email, user.Email)
 	assert.Equal(t, expedtedUser.Name, user.Name)
+	assert.Equal(t, "admin", user.Role)
+	assert.Nil(t, user.DisabledAt)
 }
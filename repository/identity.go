@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"native-free-pollings/domain"
+	"native-free-pollings/models"
+	"native-free-pollings/tracing"
+)
+
+type identity struct {
+	DB *tracing.DB
+}
+
+func NewIdentity(db *sql.DB) domain.IdentityRepository {
+	return &identity{DB: tracing.WrapDB(db)}
+}
+
+func (i *identity) GetUser(ctx context.Context, issuer, subject string) (*models.User, error) {
+	query := `
+		SELECT u.id, u.email, u.password_hash, u.name, u.role, u.disabled_at, u.created_at, u.updated_at
+		FROM user_identities i
+		JOIN users u ON u.id = i.user_id
+		WHERE i.issuer = $1 AND i.subject = $2
+	`
+
+	var user models.User
+	err := i.DB.QueryRowContext(ctx, query, issuer, subject).Scan(
+		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &user.DisabledAt, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (i *identity) Link(ctx context.Context, identity *models.Identity) error {
+	return i.insert(ctx, i.DB, identity)
+}
+
+func (i *identity) CreateUserWithIdentity(ctx context.Context, user *models.User, identity *models.Identity) error {
+	tx, err := i.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO users (email, password_hash, name)
+		VALUES ($1, $2, $3)
+		RETURNING id, role, created_at, updated_at
+	`
+	err = tx.QueryRowContext(ctx, query, user.Email, user.PasswordHash, user.Name).Scan(
+		&user.ID, &user.Role, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert user failed: %w", err)
+	}
+
+	identity.UserID = user.ID
+	if err := i.insert(ctx, tx, identity); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (i *identity) insert(ctx context.Context, db domain.DB, identity *models.Identity) error {
+	query := `
+		INSERT INTO user_identities (user_id, issuer, subject, email)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	err := db.QueryRowContext(ctx, query, identity.UserID, identity.Issuer, identity.Subject, identity.Email).
+		Scan(&identity.ID, &identity.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert identity failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"native-free-pollings/domain"
+	"native-free-pollings/helper"
+	"native-free-pollings/tracing"
+	"strings"
+	"time"
+)
+
+type loginAttempt struct {
+	DB     *tracing.DB
+	Policy helper.LockoutPolicy
+}
+
+func NewLoginAttempt(db *sql.DB, policy helper.LockoutPolicy) domain.LoginAttemptTracker {
+	return &loginAttempt{DB: tracing.WrapDB(db), Policy: policy}
+}
+
+func accountKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (l *loginAttempt) LockedUntil(ctx context.Context, email, ip string) (time.Time, error) {
+	query := `
+		SELECT MAX(locked_until)
+		FROM login_throttles
+		WHERE key IN ($1, $2)
+	`
+
+	var until sql.NullTime
+	err := l.DB.QueryRowContext(ctx, query, accountKey(email), ipKey(ip)).Scan(&until)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("query lockout failed: %w", err)
+	}
+
+	return until.Time, nil
+}
+
+func (l *loginAttempt) RecordFailure(ctx context.Context, email, ip string) (time.Time, error) {
+	tx, err := l.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := l.audit(ctx, tx, email, ip, false); err != nil {
+		return time.Time{}, err
+	}
+
+	accountUntil, err := l.bump(ctx, tx, accountKey(email), l.Policy.MaxAccountFailures)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	ipUntil, err := l.bump(ctx, tx, ipKey(ip), l.Policy.MaxIPFailures)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, fmt.Errorf("commit failed: %w", err)
+	}
+
+	if ipUntil.After(accountUntil) {
+		return ipUntil, nil
+	}
+	return accountUntil, nil
+}
+
+func (l *loginAttempt) RecordSuccess(ctx context.Context, email, ip string) error {
+	if err := l.audit(ctx, l.DB, email, ip, true); err != nil {
+		return err
+	}
+
+	return l.Unlock(ctx, email)
+}
+
+func (l *loginAttempt) Unlock(ctx context.Context, email string) error {
+	query := `
+		DELETE FROM login_throttles WHERE key = $1
+	`
+	_, err := l.DB.ExecContext(ctx, query, accountKey(email))
+	if err != nil {
+		return fmt.Errorf("delete lockout failed: %w", err)
+	}
+
+	return nil
+}
+
+func (l *loginAttempt) audit(ctx context.Context, db domain.DB, email, ip string, success bool) error {
+	query := `
+		INSERT INTO login_attempts (email, ip, success)
+		VALUES ($1, $2, $3)
+	`
+	_, err := db.ExecContext(ctx, query, strings.ToLower(strings.TrimSpace(email)), ip, success)
+	if err != nil {
+		return fmt.Errorf("insert login attempt failed: %w", err)
+	}
+
+	return nil
+}
+
+func (l *loginAttempt) bump(ctx context.Context, db domain.DB, key string, threshold int) (time.Time, error) {
+	query := `
+		INSERT INTO login_throttles (key, failures, last_failure_at)
+		VALUES ($1, 1, now())
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE
+				WHEN login_throttles.last_failure_at < now() - make_interval(secs => $2) THEN 1
+				ELSE login_throttles.failures + 1
+			END,
+			last_failure_at = now()
+		RETURNING failures
+	`
+
+	var failures int
+	err := db.QueryRowContext(ctx, query, key, l.Policy.Window.Seconds()).Scan(&failures)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("update throttle failed: %w", err)
+	}
+
+	lock := l.Policy.LockDuration(failures, threshold)
+	if lock == 0 {
+		return time.Time{}, nil
+	}
+
+	until := time.Now().Add(lock)
+	_, err = db.ExecContext(ctx, `UPDATE login_throttles SET locked_until = $1 WHERE key = $2`, until, key)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("update lockout failed: %w", err)
+	}
+
+	return until, nil
+}
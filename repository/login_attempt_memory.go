@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"native-free-pollings/domain"
+	"native-free-pollings/helper"
+	"native-free-pollings/models"
+	"sync"
+	"time"
+)
+
+// maxMemoryThrottleKeys caps the keys memoryLoginAttempt keeps, so failures
+// from many addresses cannot grow it without bound.
+const maxMemoryThrottleKeys = 100_000
+
+// memoryLoginAttempt keeps throttle state in process memory. It is meant for
+// single-instance deployments and tests; state is lost on restart and no
+// audit trail is written.
+type memoryLoginAttempt struct {
+	mu        sync.Mutex
+	policy    helper.LockoutPolicy
+	throttle  map[string]*models.LoginThrottle
+	maxKeys   int
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryLoginAttempt(policy helper.LockoutPolicy) domain.LoginAttemptTracker {
+	return &memoryLoginAttempt{
+		policy:   policy,
+		throttle: make(map[string]*models.LoginThrottle),
+		maxKeys:  maxMemoryThrottleKeys,
+		now:      time.Now,
+	}
+}
+
+func (m *memoryLoginAttempt) LockedUntil(ctx context.Context, email, ip string) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var until time.Time
+	for _, key := range []string{accountKey(email), ipKey(ip)} {
+		if t, ok := m.throttle[key]; ok && t.LockedUntil.After(until) {
+			until = t.LockedUntil
+		}
+	}
+
+	return until, nil
+}
+
+func (m *memoryLoginAttempt) RecordFailure(ctx context.Context, email, ip string) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	accountUntil := m.bump(accountKey(email), m.policy.MaxAccountFailures)
+	ipUntil := m.bump(ipKey(ip), m.policy.MaxIPFailures)
+
+	if ipUntil.After(accountUntil) {
+		return ipUntil, nil
+	}
+	return accountUntil, nil
+}
+
+func (m *memoryLoginAttempt) RecordSuccess(ctx context.Context, email, ip string) error {
+	return m.Unlock(ctx, email)
+}
+
+func (m *memoryLoginAttempt) Unlock(ctx context.Context, email string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.throttle, accountKey(email))
+	return nil
+}
+
+func (m *memoryLoginAttempt) bump(key string, threshold int) time.Time {
+	now := m.now()
+
+	if now.Sub(m.lastSweep) > m.policy.Window {
+		m.sweep(now)
+	}
+
+	t, ok := m.throttle[key]
+	if !ok || now.Sub(t.LastFailureAt) > m.policy.Window {
+		if !ok && len(m.throttle) >= m.maxKeys {
+			m.sweep(now)
+			if len(m.throttle) >= m.maxKeys {
+				m.evict(now)
+			}
+		}
+		t = &models.LoginThrottle{Key: key}
+		m.throttle[key] = t
+	}
+
+	t.Failures++
+	t.LastFailureAt = now
+
+	if lock := m.policy.LockDuration(t.Failures, threshold); lock > 0 {
+		t.LockedUntil = now.Add(lock)
+	}
+
+	return t.LockedUntil
+}
+
+// sweep drops keys whose failures left the window and whose lock ran out;
+// they behave exactly like keys never seen.
+func (m *memoryLoginAttempt) sweep(now time.Time) {
+	for key, t := range m.throttle {
+		if now.Sub(t.LastFailureAt) > m.policy.Window && !t.LockedUntil.After(now) {
+			delete(m.throttle, key)
+		}
+	}
+	m.lastSweep = now
+}
+
+// evict makes room when the cap is reached with nothing expired. It drops
+// the key with the oldest failure, sparing locked keys while there is any
+// other, so a flood of new addresses does not lift existing locks.
+func (m *memoryLoginAttempt) evict(now time.Time) {
+	var victim *models.LoginThrottle
+	for _, t := range m.throttle {
+		switch {
+		case victim == nil:
+			victim = t
+		case victim.LockedUntil.After(now) != t.LockedUntil.After(now):
+			if victim.LockedUntil.After(now) {
+				victim = t
+			}
+		case t.LastFailureAt.Before(victim.LastFailureAt):
+			victim = t
+		}
+	}
+	if victim != nil {
+		delete(m.throttle, victim.Key)
+	}
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"native-free-pollings/helper"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryLoginAttempt_Lockout(t *testing.T) {
+	policy := helper.LockoutPolicy{
+		MaxAccountFailures: 3,
+		MaxIPFailures:      10,
+		BaseDelay:          time.Minute,
+		MaxDelay:           10 * time.Minute,
+		Window:             15 * time.Minute,
+	}
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewMemoryLoginAttempt(policy).(*memoryLoginAttempt)
+	tracker.now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		until, err := tracker.RecordFailure(ctx, "a@mail.com", "10.0.0.1")
+		assert.NoError(t, err)
+		assert.False(t, until.After(now))
+	}
+
+	until, err := tracker.RecordFailure(ctx, "A@mail.com", "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(time.Minute), until)
+
+	until, err = tracker.RecordFailure(ctx, "a@mail.com", "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(2*time.Minute), until)
+
+	locked, err := tracker.LockedUntil(ctx, "a@mail.com", "10.0.0.2")
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(2*time.Minute), locked)
+
+	assert.NoError(t, tracker.Unlock(ctx, "a@mail.com"))
+	locked, err = tracker.LockedUntil(ctx, "a@mail.com", "10.0.0.2")
+	assert.NoError(t, err)
+	assert.True(t, locked.IsZero())
+}
+
+func TestMemoryLoginAttempt_WindowReset(t *testing.T) {
+	policy := helper.LockoutPolicy{
+		MaxAccountFailures: 2,
+		MaxIPFailures:      10,
+		BaseDelay:          time.Minute,
+		MaxDelay:           time.Hour,
+		Window:             5 * time.Minute,
+	}
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewMemoryLoginAttempt(policy).(*memoryLoginAttempt)
+	tracker.now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	_, _ = tracker.RecordFailure(ctx, "a@mail.com", "10.0.0.1")
+	now = now.Add(10 * time.Minute)
+
+	until, err := tracker.RecordFailure(ctx, "a@mail.com", "10.0.0.1")
+	assert.NoError(t, err)
+	assert.True(t, until.IsZero())
+}
+
+func TestMemoryLoginAttempt_Sweep(t *testing.T) {
+	policy := helper.LockoutPolicy{
+		MaxAccountFailures: 2,
+		MaxIPFailures:      10,
+		BaseDelay:          time.Hour,
+		MaxDelay:           time.Hour,
+		Window:             5 * time.Minute,
+	}
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewMemoryLoginAttempt(policy).(*memoryLoginAttempt)
+	tracker.now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	// a@mail.com ends up locked for an hour, b@mail.com is not locked.
+	_, _ = tracker.RecordFailure(ctx, "a@mail.com", "10.0.0.1")
+	_, _ = tracker.RecordFailure(ctx, "a@mail.com", "10.0.0.1")
+	_, _ = tracker.RecordFailure(ctx, "b@mail.com", "10.0.0.2")
+	now = now.Add(10 * time.Minute)
+
+	_, _ = tracker.RecordFailure(ctx, "c@mail.com", "10.0.0.3")
+
+	assert.Contains(t, tracker.throttle, accountKey("a@mail.com"))
+	assert.NotContains(t, tracker.throttle, accountKey("b@mail.com"))
+	assert.NotContains(t, tracker.throttle, ipKey("10.0.0.1"))
+	assert.NotContains(t, tracker.throttle, ipKey("10.0.0.2"))
+	assert.Len(t, tracker.throttle, 3)
+}
+
+func TestMemoryLoginAttempt_Cap(t *testing.T) {
+	policy := helper.LockoutPolicy{
+		MaxAccountFailures: 1,
+		MaxIPFailures:      10,
+		BaseDelay:          time.Hour,
+		MaxDelay:           time.Hour,
+		Window:             time.Hour,
+	}
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewMemoryLoginAttempt(policy).(*memoryLoginAttempt)
+	tracker.now = func() time.Time { return now }
+	tracker.maxKeys = 4
+
+	ctx := context.Background()
+
+	// Fills the cap with the locked account and three addresses, the first
+	// of which failed longest ago.
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		_, _ = tracker.RecordFailure(ctx, "a@mail.com", ip)
+		now = now.Add(time.Second)
+	}
+	assert.Len(t, tracker.throttle, 4)
+
+	_, _ = tracker.RecordFailure(ctx, "a@mail.com", "10.0.0.4")
+
+	assert.Len(t, tracker.throttle, 4)
+	assert.Contains(t, tracker.throttle, accountKey("a@mail.com"))
+	assert.NotContains(t, tracker.throttle, ipKey("10.0.0.1"))
+
+	locked, err := tracker.LockedUntil(ctx, "a@mail.com", "10.0.0.9")
+	assert.NoError(t, err)
+	assert.True(t, locked.After(now))
+}
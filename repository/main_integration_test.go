@@ -31,6 +31,8 @@ func Get() *config.Config {
 			Pass: os.Getenv("DB_PASS"),
 			SSL:  os.Getenv("DB_SSLMODE"),
 		},
-		JwtKey: []byte(os.Getenv("JWT_KEY")),
+		Auth: config.Auth{
+			JwtKey: os.Getenv("JWT_KEY"),
+		},
 	}
 }
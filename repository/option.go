@@ -6,14 +6,15 @@ import (
 	"fmt"
 	"native-free-pollings/domain"
 	"native-free-pollings/models"
+	"native-free-pollings/tracing"
 )
 
 type option struct {
-	DB *sql.DB
+	DB domain.DB
 }
 
 func NewOption(db *sql.DB) domain.OptionRepository {
-	return &option{DB: db}
+	return &option{DB: tracing.WrapDB(db)}
 }
 
 func (o *option) Create(ctx context.Context, db domain.DB, option *models.PollOption) error {
@@ -98,3 +99,31 @@ func (o *option) GetByPollID(ctx context.Context, db domain.DB, id int64) ([]mod
 
 	return options, nil
 }
+
+func (o *option) Reorder(ctx context.Context, db domain.DB, pollID int64, ids []int64) error {
+	// unique(poll_id, position) is checked row by row, so move every option
+	// out of the way first.
+	_, err := db.ExecContext(ctx, `UPDATE poll_options SET position = -position WHERE poll_id = $1`, pollID)
+	if err != nil {
+		return fmt.Errorf("reorder options failed: %w", err)
+	}
+
+	query := `
+		UPDATE poll_options
+		SET position = $1
+		WHERE id = $2 AND poll_id = $3
+	`
+	for i, id := range ids {
+		result, err := db.ExecContext(ctx, query, i+1, id, pollID)
+		if err != nil {
+			return fmt.Errorf("reorder options failed: %w", err)
+		}
+
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			return sql.ErrNoRows
+		}
+	}
+
+	return nil
+}
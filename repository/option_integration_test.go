@@ -197,3 +197,44 @@ func TestGetOptionByPollID(t *testing.T) {
 		assert.Equal(t, i+1, options[i].Position)
 	}
 }
+
+func TestReorderOptions(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	conf := Get()
+	db := database.GetDatabaseConnection(conf.Database)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	poll := &models.Polling{
+		UserID:      1,
+		Title:       "polling test",
+		Description: "polling description test",
+		Status:      "active",
+		StartsAt:    time.Now(),
+		EndsAt:      time.Now(),
+	}
+
+	insertDummyPolling(t, db, poll)
+
+	first := &models.PollOption{PollID: poll.ID, Label: "Go", Position: 1}
+	second := &models.PollOption{PollID: poll.ID, Label: "Rust", Position: 2}
+	insertDummyOption(t, db, first)
+	insertDummyOption(t, db, second)
+
+	repo := NewOption(db)
+	tx, err := db.BeginTx(ctx, nil)
+	assert.NoError(t, err)
+	defer tx.Rollback()
+
+	err = repo.Reorder(ctx, tx, poll.ID, []int64{second.ID, first.ID})
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Commit())
+
+	assert.Equal(t, 2, getOption(db, first.ID).Position)
+	assert.Equal(t, 1, getOption(db, second.ID).Position)
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"native-free-pollings/domain"
+	"native-free-pollings/models"
+	"native-free-pollings/tracing"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type outbox struct {
+	DB *tracing.DB
+}
+
+func NewOutbox(db *sql.DB) domain.OutboxRepository {
+	return &outbox{DB: tracing.WrapDB(db)}
+}
+
+func (o *outbox) Add(ctx context.Context, db domain.DB, ev domain.Event) error {
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		return fmt.Errorf("encode event data failed: %w", err)
+	}
+
+	query := `
+		INSERT INTO outbox_events (event_id, name, poll_id, owner_id, data, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	// As a string: lib/pq would send []byte as bytea, which jsonb rejects.
+	_, err = db.ExecContext(ctx, query, ev.ID, ev.Name, ev.PollID, ev.OwnerID, string(data), ev.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("insert outbox event failed: %w", err)
+	}
+
+	return nil
+}
+
+func (o *outbox) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	// SKIP LOCKED lets several instances claim at once without waiting on
+	// each other or handing out the same event twice.
+	query := `
+		UPDATE outbox_events o
+		SET next_attempt_at = now() + $2::bigint * interval '1 millisecond'
+		WHERE o.id IN (
+			SELECT id
+			FROM outbox_events
+			WHERE next_attempt_at <= now() AND failed_at IS NULL
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING o.id, o.event_id, o.name, o.poll_id, o.owner_id, o.data, o.occurred_at,
+			o.attempts, o.next_attempt_at, o.last_error, o.created_at,
+			ARRAY(SELECT c.consumer FROM outbox_consumers c WHERE c.outbox_id = o.id)
+	`
+	rows, err := o.DB.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox events failed: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.OutboxEvent
+	for rows.Next() {
+		var ev models.OutboxEvent
+		var data []byte
+		err := rows.Scan(&ev.ID, &ev.EventID, &ev.Name, &ev.PollID, &ev.OwnerID, &data, &ev.OccurredAt,
+			&ev.Attempts, &ev.NextAttemptAt, &ev.LastError, &ev.CreatedAt, pq.Array(&ev.Consumed))
+		if err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		ev.Data = data
+		events = append(events, ev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iteration failed: %w", err)
+	}
+
+	// UPDATE ... RETURNING does not keep the subquery's order; handing
+	// events out in the order they were recorded does.
+	slices.SortFunc(events, func(a, b models.OutboxEvent) int { return cmp.Compare(a.ID, b.ID) })
+
+	return events, nil
+}
+
+func (o *outbox) MarkConsumed(ctx context.Context, id int64, consumer string) error {
+	query := `
+		INSERT INTO outbox_consumers (outbox_id, consumer) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := o.DB.ExecContext(ctx, query, id, consumer); err != nil {
+		return fmt.Errorf("mark outbox event consumed failed: %w", err)
+	}
+
+	return nil
+}
+
+func (o *outbox) Complete(ctx context.Context, id int64) error {
+	if _, err := o.DB.ExecContext(ctx, `DELETE FROM outbox_events WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete outbox event failed: %w", err)
+	}
+
+	return nil
+}
+
+func (o *outbox) Retry(ctx context.Context, id int64, retryAt time.Time, lastError string) error {
+	query := `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, next_attempt_at = $1, last_error = $2
+		WHERE id = $3
+	`
+	if _, err := o.DB.ExecContext(ctx, query, retryAt, lastError, id); err != nil {
+		return fmt.Errorf("reschedule outbox event failed: %w", err)
+	}
+
+	return nil
+}
+
+func (o *outbox) Fail(ctx context.Context, id int64, lastError string) error {
+	query := `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, failed_at = now(), last_error = $1
+		WHERE id = $2
+	`
+	if _, err := o.DB.ExecContext(ctx, query, lastError, id); err != nil {
+		return fmt.Errorf("fail outbox event failed: %w", err)
+	}
+
+	return nil
+}
@@ -6,26 +6,28 @@ import (
 	"fmt"
 	"native-free-pollings/domain"
 	"native-free-pollings/models"
+	"native-free-pollings/tracing"
+	"strconv"
 	"time"
 )
 
 type polling struct {
-	DB *sql.DB
+	DB domain.DB
 }
 
 func NewPolling(db *sql.DB) domain.PollRepository {
-	return &polling{DB: db}
+	return &polling{DB: tracing.WrapDB(db)}
 }
 
 func (p *polling) Create(ctx context.Context, db domain.DB, poll *models.Polling) error {
 	query := `
 		INSERT INTO polls (user_id, title, description, status, starts_at, ends_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at, updated_at
+		RETURNING id, created_at, updated_at, version
 	`
 
 	err := db.QueryRowContext(ctx, query, poll.UserID, poll.Title, poll.Description, poll.Status, poll.StartsAt, poll.EndsAt).
-		Scan(&poll.ID, &poll.CreatedAt, &poll.UpdatedAt)
+		Scan(&poll.ID, &poll.CreatedAt, &poll.UpdatedAt, &poll.Version)
 	if err != nil {
 		return fmt.Errorf("insert polling failed: %w", err)
 	}
@@ -33,11 +35,13 @@ func (p *polling) Create(ctx context.Context, db domain.DB, poll *models.Polling
 	return nil
 }
 
-func (p *polling) Delete(ctx context.Context, db domain.DB, id int64) error {
+// Delete removes the poll only while it is still at version; otherwise it
+// returns sql.ErrNoRows.
+func (p *polling) Delete(ctx context.Context, db domain.DB, id, version int64) error {
 	query := `
-		DELETE FROM polls WHERE id = $1
+		DELETE FROM polls WHERE id = $1 AND version = $2
 	`
-	result, err := p.DB.ExecContext(ctx, query, id)
+	result, err := db.ExecContext(ctx, query, id, version)
 	if err != nil {
 		return fmt.Errorf("delete poll failed: %w", err)
 	}
@@ -53,6 +57,9 @@ func (p *polling) Delete(ctx context.Context, db domain.DB, id int64) error {
 	return nil
 }
 
+// Update saves poll only while the stored version still equals
+// poll.Version, and bumps the version on success. A poll that is missing or
+// was changed in between yields sql.ErrNoRows.
 func (p *polling) Update(ctx context.Context, db domain.DB, poll *models.Polling) error {
 	query := `
 		UPDATE polls
@@ -61,14 +68,31 @@ func (p *polling) Update(ctx context.Context, db domain.DB, poll *models.Polling
 			status = $3,
 			starts_at = $4,
 			ends_at = $5,
-			updated_at = $6
-		WHERE id = $7
+			updated_at = $6,
+			version = version + 1
+		WHERE id = $7 AND version = $8
+		RETURNING version, updated_at
 	`
-	result, err := db.ExecContext(ctx, query, poll.Title, poll.Description, poll.Status, poll.StartsAt, poll.EndsAt, time.Now(), poll.ID)
+	err := db.QueryRowContext(ctx, query, poll.Title, poll.Description, poll.Status, poll.StartsAt, poll.EndsAt, time.Now(), poll.ID, poll.Version).
+		Scan(&poll.Version, &poll.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("update polling failed: %w", err)
 	}
 
+	return nil
+}
+
+// Touch bumps the version of a poll whose options changed, so cached
+// representations of it become stale.
+func (p *polling) Touch(ctx context.Context, db domain.DB, id int64) error {
+	query := `
+		UPDATE polls SET version = version + 1, updated_at = $1 WHERE id = $2
+	`
+	result, err := db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("touch polling failed: %w", err)
+	}
+
 	rows, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to check rows affected: %w", err)
@@ -80,20 +104,45 @@ func (p *polling) Update(ctx context.Context, db domain.DB, poll *models.Polling
 	return nil
 }
 
+func (p *polling) NotifyResults(ctx context.Context, db domain.DB, id int64) error {
+	query := `
+		SELECT pg_notify($1, $2)
+	`
+	_, err := db.ExecContext(ctx, query, domain.ResultsChannel, strconv.FormatInt(id, 10))
+	if err != nil {
+		return fmt.Errorf("notify results failed: %w", err)
+	}
+
+	return nil
+}
+
 func (p *polling) GetByID(ctx context.Context, db domain.DB, id int64) (*models.Polling, error) {
+	return p.getByID(ctx, db, id, "")
+}
+
+func (p *polling) GetByIDForUpdate(ctx context.Context, db domain.DB, id int64) (*models.Polling, error) {
+	return p.getByID(ctx, db, id, "FOR UPDATE OF p")
+}
+
+func (p *polling) GetByIDForShare(ctx context.Context, db domain.DB, id int64) (*models.Polling, error) {
+	return p.getByID(ctx, db, id, "FOR SHARE OF p")
+}
+
+// getByID reads the poll with an optional locking clause. Only the polls
+// row is locked, never the creator's.
+func (p *polling) getByID(ctx context.Context, db domain.DB, id int64, lock string) (*models.Polling, error) {
 	var poll models.Polling
 
 	query := `
 		SELECT p.id, p.user_id, p.title, p.description,
        		   p.status, p.starts_at, p.ends_at, p.created_at,
-       		   p.updated_at, u.name AS creator_name, u.email AS creator_email
+       		   p.updated_at, p.version, u.name AS creator_name, u.email AS creator_email
 		FROM polls p
 		JOIN users u ON u.id = p.user_id
 		WHERE p.id = $1
-
-	`
+		` + lock
 	err := db.QueryRowContext(ctx, query, id).
-		Scan(&poll.ID, &poll.UserID, &poll.Title, &poll.Description, &poll.Status, &poll.StartsAt, &poll.EndsAt, &poll.CreatedAt, &poll.UpdatedAt, &poll.CreatorName, &poll.CreatorEmail)
+		Scan(&poll.ID, &poll.UserID, &poll.Title, &poll.Description, &poll.Status, &poll.StartsAt, &poll.EndsAt, &poll.CreatedAt, &poll.UpdatedAt, &poll.Version, &poll.CreatorName, &poll.CreatorEmail)
 	if err != nil {
 		return nil, fmt.Errorf("get polling failed: %w", err)
 	}
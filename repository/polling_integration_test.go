@@ -145,17 +145,29 @@ func TestUpdatePolling(t *testing.T) {
 		Status:      "active",
 		StartsAt:    time.Now(),
 		EndsAt:      time.Now(),
+		Version:     1,
 	}
 
 	repo := NewPolling(db)
 	err := repo.Update(ctx, db, updatePoll)
 
 	assert.NoError(t, err)
+	assert.Equal(t, int64(2), updatePoll.Version)
 
 	poll, err := repo.GetByID(ctx, db, updatePoll.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, poll.Title, updatePoll.Title)
 	assert.Equal(t, poll.Description, updatePoll.Description)
+	assert.Equal(t, int64(2), poll.Version)
+
+	// A writer still holding version 1 must not overwrite the change.
+	stale := *updatePoll
+	stale.Version = 1
+	assert.ErrorIs(t, repo.Update(ctx, db, &stale), sql.ErrNoRows)
+	assert.ErrorIs(t, repo.Delete(ctx, db, updatePoll.ID, 1), sql.ErrNoRows)
+
+	assert.NoError(t, repo.Touch(ctx, db, updatePoll.ID))
+	assert.NoError(t, repo.Delete(ctx, db, updatePoll.ID, 3))
 }
 
 func TestGetResultByIDPolling(t *testing.T) {
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"native-free-pollings/domain"
+	"native-free-pollings/models"
+	"native-free-pollings/tracing"
+)
+
+type session struct {
+	DB domain.DB
+}
+
+func NewSession(db *sql.DB) domain.SessionRepository {
+	return &session{DB: tracing.WrapDB(db)}
+}
+
+func (s *session) Create(ctx context.Context, sess *models.Session) error {
+	query := `
+		INSERT INTO sessions (user_id, user_agent, ip, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, last_seen_at
+	`
+	err := s.DB.QueryRowContext(ctx, query, sess.UserID, sess.UserAgent, sess.IP, sess.ExpiresAt).
+		Scan(&sess.ID, &sess.CreatedAt, &sess.LastSeenAt)
+	if err != nil {
+		return fmt.Errorf("insert session failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *session) ListActive(ctx context.Context, userID int64) ([]models.Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > now()
+		ORDER BY last_seen_at DESC
+	`
+	rows, err := s.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var sess models.Session
+		if err := rows.Scan(&sess.ID, &sess.UserID, &sess.UserAgent, &sess.IP, &sess.CreatedAt, &sess.LastSeenAt, &sess.ExpiresAt, &sess.RevokedAt); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		sessions = append(sessions, sess)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("interation failed: %w", err)
+	}
+
+	return sessions, nil
+}
+
+func (s *session) GetActive(ctx context.Context, userID, id int64) (*models.Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > now()
+	`
+	var sess models.Session
+	err := s.DB.QueryRowContext(ctx, query, id, userID).
+		Scan(&sess.ID, &sess.UserID, &sess.UserAgent, &sess.IP, &sess.CreatedAt, &sess.LastSeenAt, &sess.ExpiresAt, &sess.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sess, nil
+}
+
+func (s *session) Revoke(ctx context.Context, userID, id int64) error {
+	query := `
+		UPDATE sessions
+		SET revoked_at = now()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+	result, err := s.DB.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("revoke session failed: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (s *session) Touch(ctx context.Context, id int64) (int64, string, error) {
+	query := `
+		UPDATE sessions s
+		SET last_seen_at = now()
+		FROM users u
+		WHERE s.id = $1
+			AND u.id = s.user_id
+			AND s.revoked_at IS NULL
+			AND s.expires_at > now()
+			AND u.disabled_at IS NULL
+		RETURNING s.user_id, u.role
+	`
+
+	var userID int64
+	var role string
+	if err := s.DB.QueryRowContext(ctx, query, id).Scan(&userID, &role); err != nil {
+		return 0, "", err
+	}
+
+	return userID, role, nil
+}
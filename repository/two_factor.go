@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"native-free-pollings/domain"
+	"native-free-pollings/models"
+	"native-free-pollings/tracing"
+)
+
+type twoFactor struct {
+	DB *tracing.DB
+}
+
+func NewTwoFactor(db *sql.DB) domain.TwoFactorRepository {
+	return &twoFactor{DB: tracing.WrapDB(db)}
+}
+
+func (t *twoFactor) Get(ctx context.Context, userID int64) (*models.TwoFactor, error) {
+	query := `
+		SELECT id, COALESCE(totp_secret, ''), totp_enabled, totp_last_step
+		FROM users
+		WHERE id = $1
+	`
+
+	var tf models.TwoFactor
+	err := t.DB.QueryRowContext(ctx, query, userID).Scan(&tf.UserID, &tf.Secret, &tf.Enabled, &tf.LastStep)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tf, nil
+}
+
+func (t *twoFactor) SaveSecret(ctx context.Context, userID int64, secret string) error {
+	query := `
+		UPDATE users
+		SET totp_secret = $1,
+			totp_enabled = false,
+			totp_last_step = 0
+		WHERE id = $2
+	`
+	result, err := t.DB.ExecContext(ctx, query, secret, userID)
+	if err != nil {
+		return fmt.Errorf("save totp secret failed: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (t *twoFactor) Enable(ctx context.Context, userID, step int64, recoveryHashes []string) error {
+	tx, err := t.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET totp_enabled = true,
+			totp_last_step = $1
+		WHERE id = $2 AND totp_secret IS NOT NULL
+	`
+	result, err := tx.ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return fmt.Errorf("enable totp failed: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("delete recovery codes failed: %w", err)
+	}
+
+	for _, hash := range recoveryHashes {
+		_, err := tx.ExecContext(ctx, `INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash)
+		if err != nil {
+			return fmt.Errorf("insert recovery code failed: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	return nil
+}
+
+func (t *twoFactor) Disable(ctx context.Context, userID int64) error {
+	tx, err := t.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET totp_secret = NULL,
+			totp_enabled = false,
+			totp_last_step = 0
+		WHERE id = $1
+	`
+	result, err := tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("disable totp failed: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("delete recovery codes failed: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// UseStep records step as the last accepted TOTP step. It reports false when
+// the step is not newer than the stored one, i.e. the code was already used.
+func (t *twoFactor) UseStep(ctx context.Context, userID, step int64) (bool, error) {
+	query := `
+		UPDATE users
+		SET totp_last_step = $1
+		WHERE id = $2 AND totp_last_step < $1
+	`
+	result, err := t.DB.ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return false, fmt.Errorf("update totp step failed: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+func (t *twoFactor) UseRecoveryCode(ctx context.Context, userID int64, hash string) (bool, error) {
+	query := `
+		UPDATE user_recovery_codes
+		SET used_at = now()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	result, err := t.DB.ExecContext(ctx, query, userID, hash)
+	if err != nil {
+		return false, fmt.Errorf("use recovery code failed: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
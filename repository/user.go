@@ -6,27 +6,28 @@ import (
 	"fmt"
 	"native-free-pollings/domain"
 	"native-free-pollings/models"
+	"native-free-pollings/tracing"
 	"time"
 )
 
 type userRepository struct {
-	DB *sql.DB
+	DB domain.DB
 }
 
 func NewUserRepository(db *sql.DB) domain.UserRepository {
-	return &userRepository{DB: db}
+	return &userRepository{DB: tracing.WrapDB(db)}
 }
 
 func (u *userRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
 	query := `
-	SELECT id, email, name, created_at, updated_at
+	SELECT id, email, name, role, disabled_at, created_at, updated_at
 	FROM users
 	WHERE id = $1
 	`
 	row := u.DB.QueryRowContext(ctx, query, id)
 
 	var user models.User
-	err := row.Scan(&user.ID, &user.Email, &user.Name, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(&user.ID, &user.Email, &user.Name, &user.Role, &user.DisabledAt, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -79,6 +80,90 @@ func (u *userRepository) UpdatePassword(ctx context.Context, id int64, passwordH
 	return err
 }
 
+func (u *userRepository) GetPasswordHash(ctx context.Context, id int64) (string, error) {
+	query := `
+		SELECT password_hash
+		FROM users
+		WHERE id = $1
+	`
+
+	var hash string
+	if err := u.DB.QueryRowContext(ctx, query, id).Scan(&hash); err != nil {
+		return "", err
+	}
+
+	return hash, nil
+}
+
+func (u *userRepository) List(ctx context.Context) ([]models.User, error) {
+	query := `
+		SELECT id, email, name, role, disabled_at, created_at, updated_at
+		FROM users
+		ORDER BY id
+	`
+
+	rows, err := u.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Email, &user.Name, &user.Role, &user.DisabledAt, &user.CreatedAt, &user.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("interation failed: %w", err)
+	}
+
+	return users, nil
+}
+
+func (u *userRepository) UpdateRole(ctx context.Context, id int64, role string) error {
+	query := `
+		UPDATE users
+		SET role = $1,
+			updated_at = $2
+		WHERE id = $3
+	`
+	result, err := u.DB.ExecContext(ctx, query, role, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("update role failed: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (u *userRepository) SetDisabled(ctx context.Context, id int64, disabled bool) error {
+	query := `
+		UPDATE users
+		SET disabled_at = CASE WHEN $1 THEN COALESCE(disabled_at, now()) ELSE NULL END,
+			updated_at = $2
+		WHERE id = $3
+	`
+	result, err := u.DB.ExecContext(ctx, query, disabled, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("update disabled failed: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
 func (u *userRepository) FindPollingsByID(ctx context.Context, id int64) ([]models.PollingSummary, error) {
 	query := `
 		SELECT p.id, p.title, p.status, count(v.id) 
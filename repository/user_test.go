@@ -34,11 +34,11 @@ func TestUserRepository_GetById(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "email", "name", "created_at", "updated_at"}).
-		AddRow(expectedUser.ID, expectedUser.Email, expectedUser.Name, expectedUser.CreatedAt, expectedUser.UpdatedAt)
+	rows := sqlmock.NewRows([]string{"id", "email", "name", "role", "disabled_at", "created_at", "updated_at"}).
+		AddRow(expectedUser.ID, expectedUser.Email, expectedUser.Name, "user", nil, expectedUser.CreatedAt, expectedUser.UpdatedAt)
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT id, email, name, created_at, updated_at
+		SELECT id, email, name, role, disabled_at, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`)).
@@ -6,14 +6,15 @@ import (
 	"fmt"
 	"native-free-pollings/domain"
 	"native-free-pollings/models"
+	"native-free-pollings/tracing"
 )
 
 type vote struct {
-	DB *sql.DB
+	DB domain.DB
 }
 
 func NewVote(db *sql.DB) domain.VoteRepository {
-	return &vote{DB: db}
+	return &vote{DB: tracing.WrapDB(db)}
 }
 
 func (v *vote) Create(ctx context.Context, db domain.DB, vote *models.Vote) error {
@@ -44,6 +45,22 @@ func (v *vote) CreateUserVote(ctx context.Context, db domain.DB, userID int64, v
 	return nil
 }
 
+func (v *vote) CountByOptionID(ctx context.Context, db domain.DB, optionID int64) (int64, error) {
+	var count int64
+	query := `
+		SELECT COUNT(*)
+		FROM votes
+		WHERE option_id = $1
+	`
+
+	err := db.QueryRowContext(ctx, query, optionID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count votes failed: %w", err)
+	}
+
+	return count, nil
+}
+
 func (v *vote) GetByOptionID(ctx context.Context, db domain.DB, optionID int64) ([]models.Vote, error) {
 	query := `
 		SELECT v.id, v.option_id, v.device_hash, v.created_at
@@ -146,6 +146,48 @@ func TestGetVotesByOptionID(t *testing.T) {
 	}
 }
 
+func TestCountVotesByOptionID(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	conf := Get()
+	db := database.GetDatabaseConnection(conf.Database)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	poll := &models.Polling{
+		UserID:      1,
+		Title:       "Title test",
+		Description: "Description test",
+		Status:      "active",
+		StartsAt:    time.Now(),
+		EndsAt:      time.Now(),
+	}
+
+	insertDummyPolling(t, db, poll)
+
+	option := &models.PollOption{
+		PollID:   poll.ID,
+		Label:    "Rust",
+		Position: 1,
+	}
+
+	insertDummyOption(t, db, option)
+
+	for _, d := range []string{"device 1", "device 2"} {
+		insertDummyVote(t, db, &models.Vote{OptionID: option.ID, DeviceHash: d})
+	}
+
+	repo := NewVote(db)
+	count, err := repo.CountByOptionID(ctx, db, option.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
 func TestGetVotesByPollID(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
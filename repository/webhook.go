@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"native-free-pollings/domain"
+	"native-free-pollings/models"
+	"native-free-pollings/tracing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type webhook struct {
+	DB *tracing.DB
+}
+
+func NewWebhook(db *sql.DB) domain.WebhookRepository {
+	return &webhook{DB: tracing.WrapDB(db)}
+}
+
+const webhookColumns = `id, user_id, poll_id, url, secret, events, consecutive_failures, disabled_at, created_at, updated_at`
+
+func scanWebhook(row interface{ Scan(...any) error }) (*models.Webhook, error) {
+	var h models.Webhook
+	err := row.Scan(&h.ID, &h.UserID, &h.PollID, &h.URL, &h.Secret, pq.Array(&h.Events), &h.ConsecutiveFailures, &h.DisabledAt, &h.CreatedAt, &h.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+func (w *webhook) Create(ctx context.Context, hook *models.Webhook) error {
+	query := `
+		INSERT INTO webhooks (user_id, poll_id, url, secret, events)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+	err := w.DB.QueryRowContext(ctx, query, hook.UserID, hook.PollID, hook.URL, hook.Secret, pq.Array(hook.Events)).
+		Scan(&hook.ID, &hook.CreatedAt, &hook.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("insert webhook failed: %w", err)
+	}
+
+	return nil
+}
+
+func (w *webhook) ListByUserID(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE user_id = $1 ORDER BY created_at DESC`
+	rows, err := w.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var hooks []models.Webhook
+	for rows.Next() {
+		h, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		hooks = append(hooks, *h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iteration failed: %w", err)
+	}
+
+	return hooks, nil
+}
+
+func (w *webhook) EnqueueEvent(ctx context.Context, ownerID, pollID int64, eventID, event string, payload []byte) (int64, error) {
+	// One statement, so a failure queues nothing and a retry starts over.
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event, payload)
+		SELECT h.id, $4::text, $3::text, $5::jsonb
+		FROM webhooks h
+		WHERE h.disabled_at IS NULL
+		  AND $3 = ANY(h.events)
+		  AND (h.poll_id = $2 OR (h.poll_id IS NULL AND h.user_id = $1))
+		  AND NOT EXISTS (
+			SELECT 1 FROM webhook_deliveries d WHERE d.webhook_id = h.id AND d.event_id = $4
+		  )
+	`
+	// As a string: lib/pq would send []byte as bytea, which jsonb rejects.
+	result, err := w.DB.ExecContext(ctx, query, ownerID, pollID, event, eventID, string(payload))
+	if err != nil {
+		return 0, fmt.Errorf("queue webhook deliveries failed: %w", err)
+	}
+
+	queued, _ := result.RowsAffected()
+	return queued, nil
+}
+
+func (w *webhook) GetByID(ctx context.Context, userID, id int64) (*models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1 AND user_id = $2`
+	return scanWebhook(w.DB.QueryRowContext(ctx, query, id, userID))
+}
+
+func (w *webhook) Update(ctx context.Context, hook *models.Webhook) error {
+	query := `
+		UPDATE webhooks
+		SET url = $1, events = $2, disabled_at = $3, consecutive_failures = 0, updated_at = now()
+		WHERE id = $4 AND user_id = $5
+		RETURNING consecutive_failures, updated_at
+	`
+	err := w.DB.QueryRowContext(ctx, query, hook.URL, pq.Array(hook.Events), hook.DisabledAt, hook.ID, hook.UserID).
+		Scan(&hook.ConsecutiveFailures, &hook.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("update webhook failed: %w", err)
+	}
+
+	return nil
+}
+
+func (w *webhook) Delete(ctx context.Context, userID, id int64) error {
+	query := `
+		DELETE FROM webhooks WHERE id = $1 AND user_id = $2
+	`
+	result, err := w.DB.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("delete webhook failed: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (w *webhook) Enqueue(ctx context.Context, d *models.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event, payload)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, status, attempts, next_attempt_at, created_at
+	`
+	err := w.DB.QueryRowContext(ctx, query, d.WebhookID, d.EventID, d.Event, string(d.Payload)).
+		Scan(&d.ID, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert webhook delivery failed: %w", err)
+	}
+
+	return nil
+}
+
+const deliveryColumns = `d.id, d.webhook_id, d.event_id, d.event, d.payload, d.status, d.attempts, d.next_attempt_at, d.delivered_at, d.created_at`
+
+func scanDelivery(row interface{ Scan(...any) error }, extra ...any) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var payload []byte
+	dest := append([]any{&d.ID, &d.WebhookID, &d.EventID, &d.Event, &payload, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.DeliveredAt, &d.CreatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	return &d, nil
+}
+
+// ListDeliveries returns the latest deliveries of a webhook with every
+// attempt made for them.
+func (w *webhook) ListDeliveries(ctx context.Context, webhookID int64, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.webhook_id = $1
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $2
+	`
+	rows, err := w.DB.QueryContext(ctx, query, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	index := make(map[int64]int)
+	ids := []int64{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		index[d.ID] = len(deliveries)
+		ids = append(ids, d.ID)
+		deliveries = append(deliveries, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iteration failed: %w", err)
+	}
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	attempts, err := w.DB.QueryContext(ctx, `
+		SELECT id, delivery_id, status_code, error, duration_ms, attempted_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = ANY($1)
+		ORDER BY attempted_at, id
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("query attempts error: %w", err)
+	}
+	defer attempts.Close()
+
+	for attempts.Next() {
+		var a models.WebhookAttempt
+		if err := attempts.Scan(&a.ID, &a.DeliveryID, &a.StatusCode, &a.Error, &a.DurationMs, &a.AttemptedAt); err != nil {
+			return nil, fmt.Errorf("scan attempt error: %w", err)
+		}
+		d := &deliveries[index[a.DeliveryID]]
+		d.AttemptLog = append(d.AttemptLog, a)
+	}
+	if err := attempts.Err(); err != nil {
+		return nil, fmt.Errorf("iteration failed: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (w *webhook) GetDelivery(ctx context.Context, webhookID, id int64) (*models.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries d WHERE d.id = $1 AND d.webhook_id = $2`
+	return scanDelivery(w.DB.QueryRowContext(ctx, query, id, webhookID))
+}
+
+func (w *webhook) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	// SKIP LOCKED lets several instances claim at once without waiting on
+	// or double-sending each other's rows. Deliveries of disabled webhooks
+	// stay pending until the webhook is enabled again.
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = now() + $2::bigint * interval '1 millisecond'
+		FROM webhooks h
+		WHERE h.id = d.webhook_id
+		  AND d.id IN (
+			SELECT d2.id
+			FROM webhook_deliveries d2
+			JOIN webhooks h2 ON h2.id = d2.webhook_id
+			WHERE d2.status = 'pending' AND d2.next_attempt_at <= now() AND h2.disabled_at IS NULL
+			ORDER BY d2.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d2 SKIP LOCKED
+		  )
+		RETURNING ` + deliveryColumns + `, h.url, h.secret
+	`
+	rows, err := w.DB.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim deliveries failed: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var url, secret string
+		d, err := scanDelivery(rows, &url, &secret)
+		if err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		d.URL, d.Secret = url, secret
+		deliveries = append(deliveries, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iteration failed: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (w *webhook) RecordSuccess(ctx context.Context, d *models.WebhookDelivery, attempt *models.WebhookAttempt) error {
+	tx, err := w.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertAttempt(ctx, tx, d.ID, attempt); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'succeeded', attempts = attempts + 1, delivered_at = now()
+		WHERE id = $1
+	`, d.ID)
+	if err != nil {
+		return fmt.Errorf("update webhook delivery failed: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE webhooks SET consecutive_failures = 0 WHERE id = $1`, d.WebhookID)
+	if err != nil {
+		return fmt.Errorf("reset webhook failures failed: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (w *webhook) RecordFailure(ctx context.Context, d *models.WebhookDelivery, attempt *models.WebhookAttempt, retryAt *time.Time, disableAfter int) (bool, error) {
+	tx, err := w.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertAttempt(ctx, tx, d.ID, attempt); err != nil {
+		return false, err
+	}
+
+	status := models.DeliveryPending
+	if retryAt == nil {
+		status = models.DeliveryFailed
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = attempts + 1, next_attempt_at = COALESCE($2, next_attempt_at)
+		WHERE id = $3
+	`, status, retryAt, d.ID)
+	if err != nil {
+		return false, fmt.Errorf("update webhook delivery failed: %w", err)
+	}
+
+	// RETURNING only sees the new row, so the old disabled_at comes from a
+	// locked read of the same row: a webhook that was disabled already is
+	// not reported again.
+	var disabled bool
+	err = tx.QueryRowContext(ctx, `
+		UPDATE webhooks w
+		SET consecutive_failures = w.consecutive_failures + 1,
+			disabled_at = CASE
+				WHEN w.disabled_at IS NULL AND w.consecutive_failures + 1 >= $1 THEN now()
+				ELSE w.disabled_at
+			END
+		FROM (SELECT id, disabled_at FROM webhooks WHERE id = $2 FOR UPDATE) old
+		WHERE w.id = old.id
+		RETURNING old.disabled_at IS NULL AND w.disabled_at IS NOT NULL
+	`, disableAfter, d.WebhookID).Scan(&disabled)
+	if err != nil {
+		return false, fmt.Errorf("count webhook failure failed: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit failed: %w", err)
+	}
+	return disabled, nil
+}
+
+func insertAttempt(ctx context.Context, db domain.DB, deliveryID int64, a *models.WebhookAttempt) error {
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, status_code, error, duration_ms)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, attempted_at
+	`, deliveryID, a.StatusCode, a.Error, a.DurationMs).Scan(&a.ID, &a.AttemptedAt)
+	if err != nil {
+		return fmt.Errorf("insert webhook attempt failed: %w", err)
+	}
+	a.DeliveryID = deliveryID
+	return nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"native-free-pollings/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookRepository_RecordFailure(t *testing.T) {
+	tests := []struct {
+		name         string
+		newlyDisable bool
+	}{
+		{name: "this failure disabled the webhook", newlyDisable: true},
+		// Also the answer for a webhook that was disabled before.
+		{name: "webhook not disabled now", newlyDisable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO webhook_delivery_attempts`)).
+				WithArgs(int64(7), nil, "timeout", 1000).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "attempted_at"}).AddRow(int64(1), time.Now()))
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE webhook_deliveries`)).
+				WithArgs(models.DeliveryFailed, nil, int64(7)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(regexp.QuoteMeta(`RETURNING old.disabled_at IS NULL AND w.disabled_at IS NOT NULL`)).
+				WithArgs(5, int64(3)).
+				WillReturnRows(sqlmock.NewRows([]string{"disabled"}).AddRow(tt.newlyDisable))
+			mock.ExpectCommit()
+
+			delivery := &models.WebhookDelivery{ID: 7, WebhookID: 3}
+			attempt := &models.WebhookAttempt{Error: "timeout", DurationMs: 1000}
+			disabled, err := NewWebhook(db).RecordFailure(context.Background(), delivery, attempt, nil, 5)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.newlyDisable, disabled)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package response
+
+import (
+	"native-free-pollings/helper"
+	"net/http"
+	"strings"
+)
+
+// problemType describes how an error code is presented to clients.
+type problemType struct {
+	status int
+	title  string
+}
+
+var problemTypes = map[helper.ErrorCode]problemType{}
+
+// Register binds code to the HTTP status and short title used in its
+// problem details. It is meant to be called from init functions and panics
+// when a code is registered twice.
+func Register(code helper.ErrorCode, status int, title string) {
+	if _, ok := problemTypes[code]; ok {
+		panic("response: error code " + string(code) + " registered twice")
+	}
+	problemTypes[code] = problemType{status: status, title: title}
+}
+
+// Registered reports whether code has a registered status.
+func Registered(code helper.ErrorCode) bool {
+	_, ok := problemTypes[code]
+	return ok
+}
+
+// Status returns the HTTP status registered for code, or 500 for an
+// unregistered one.
+func Status(code helper.ErrorCode) int {
+	if pt, ok := problemTypes[code]; ok {
+		return pt.status
+	}
+	return http.StatusInternalServerError
+}
+
+// TypeBase prefixes the problem "type" URI, which ends in the code in
+// kebab case, e.g. /problems/not-found.
+var TypeBase = "/problems/"
+
+func typeURI(code helper.ErrorCode) string {
+	return TypeBase + strings.ReplaceAll(strings.ToLower(string(code)), "_", "-")
+}
+
+func init() {
+	Register(helper.CodeBadRequest, http.StatusBadRequest, "Bad request")
+	Register(helper.CodeInvalidInput, http.StatusBadRequest, "Invalid input")
+	Register(helper.CodeInvalidRequest, http.StatusBadRequest, "Invalid request payload")
+	Register(helper.CodeInvalidID, http.StatusBadRequest, "Invalid ID")
+	Register(helper.CodeValidationError, http.StatusBadRequest, "Validation failed")
+	Register(helper.CodePayloadTooLarge, http.StatusRequestEntityTooLarge, "Payload too large")
+	Register(helper.CodeUnsupportedMedia, http.StatusUnsupportedMediaType, "Unsupported media type")
+	Register(helper.CodeLoginFailed, http.StatusBadRequest, "Login failed")
+	Register(helper.CodeInvalidCode, http.StatusBadRequest, "Invalid verification code")
+	Register(helper.CodeAuthFailed, http.StatusUnauthorized, "Authentication failed")
+	Register(helper.CodeReauthRequired, http.StatusUnauthorized, "Recent login required")
+	Register(helper.CodeInvalidToken, http.StatusUnauthorized, "Invalid token")
+	Register(helper.CodeExpiredToken, http.StatusUnauthorized, "Token expired")
+	Register(helper.CodeTokenNotValidYet, http.StatusUnauthorized, "Token not yet valid")
+	Register(helper.CodeForbidden, http.StatusForbidden, "Forbidden")
+	Register(helper.CodeAccountDisabled, http.StatusForbidden, "Account disabled")
+	Register(helper.CodeInsufficientScope, http.StatusForbidden, "Insufficient scope")
+	Register(helper.CodeNotFound, http.StatusNotFound, "Not found")
+	Register(helper.CodeNotAllowed, http.StatusMethodNotAllowed, "Method not allowed")
+	Register(helper.CodeEmailExist, http.StatusConflict, "Email already registered")
+	Register(helper.CodeAlreadyVoted, http.StatusConflict, "Already voted")
+	Register(helper.CodeOptionHasVotes, http.StatusConflict, "Option has votes")
+	Register(helper.CodePreconditionFailed, http.StatusPreconditionFailed, "Precondition failed")
+	Register(helper.CodePreconditionRequired, http.StatusPreconditionRequired, "Precondition required")
+	Register(helper.CodeTooManyAttempts, http.StatusTooManyRequests, "Too many attempts")
+	Register(helper.CodeTooManyStreams, http.StatusTooManyRequests, "Too many streams")
+	Register(helper.CodeSessionFull, http.StatusConflict, "Live session full")
+	Register(helper.CodeInternalError, http.StatusInternalServerError, "Internal server error")
+	Register(helper.CodeDBError, http.StatusInternalServerError, "Database error")
+	Register(helper.CodeHashFailed, http.StatusInternalServerError, "Password hashing failed")
+	Register(helper.CodeTokenFailed, http.StatusInternalServerError, "Token issuing failed")
+}
@@ -0,0 +1,44 @@
+package response
+
+import (
+	"errors"
+	"native-free-pollings/domain"
+	"native-free-pollings/helper"
+)
+
+// domainErrors maps sentinel errors to the code and detail clients see. The
+// detail is fixed because the wrapped error text may carry internals.
+var domainErrors = []struct {
+	err    error
+	code   helper.ErrorCode
+	detail string
+}{
+	{domain.ErrPollNotFound, helper.CodeNotFound, "polling not found"},
+	{domain.ErrOptionNotFound, helper.CodeNotFound, "option not found"},
+	{domain.ErrForbidden, helper.CodeForbidden, "you are not allowed to perform this action"},
+	{domain.ErrAlreadyVoted, helper.CodeAlreadyVoted, "you have already voted in this polling"},
+	{domain.ErrOptionHasVotes, helper.CodeOptionHasVotes, "option already has votes; delete it with force=true to remove its votes too"},
+	{domain.ErrVersionMismatch, helper.CodePreconditionFailed, "polling was changed by someone else; fetch it again and retry"},
+}
+
+// Describe returns the code and detail Error would send for err, for
+// transports that report errors in their own messages.
+func Describe(err error) (helper.ErrorCode, string) {
+	appErr := translate(err)
+	return appErr.Code, appErr.Message
+}
+
+// translate turns any error into an *helper.AppError. An AppError in the
+// chain wins, then the domain sentinels; anything else is an internal error.
+func translate(err error) *helper.AppError {
+	var appErr *helper.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	for _, de := range domainErrors {
+		if errors.Is(err, de.err) {
+			return helper.NewAppError(de.code, de.detail, err)
+		}
+	}
+	return helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+}
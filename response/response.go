@@ -0,0 +1,116 @@
+// Package response writes every API response: successful results in the
+// {message, data} envelope and errors as RFC 7807 problem details.
+package response
+
+import (
+	"encoding/json"
+	"math"
+	"native-free-pollings/helper"
+	"net/http"
+	"strconv"
+)
+
+// ProblemContentType is the media type of error bodies.
+const ProblemContentType = "application/problem+json"
+
+// Envelope is the body of every successful JSON response.
+type Envelope struct {
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// Problem is an RFC 7807 problem details object. Code, RequestID and Errors
+// are extension members.
+type Problem struct {
+	Type      string                  `json:"type"`
+	Title     string                  `json:"title"`
+	Status    int                     `json:"status"`
+	Detail    string                  `json:"detail,omitempty"`
+	Instance  string                  `json:"instance,omitempty"`
+	Code      helper.ErrorCode        `json:"code"`
+	RequestID string                  `json:"request_id,omitempty"`
+	Errors    []helper.ValidatorError `json:"errors,omitempty"`
+}
+
+// ErrorRecorder is implemented by response writers that want the cause of a
+// server error, which is never sent to the client. The logging middleware
+// uses it to log what went wrong.
+type ErrorRecorder interface {
+	RecordError(err error)
+}
+
+// JSON writes data wrapped in the response envelope. A nil data is omitted.
+func JSON(w http.ResponseWriter, status int, message string, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(Envelope{Message: message, Data: data})
+}
+
+// Error writes err as problem details. An *helper.AppError anywhere in the
+// chain keeps its code and message, domain sentinel errors get theirs from
+// domainErrors, and anything else becomes an internal error whose cause is
+// only logged.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := translate(err)
+
+	if appErr.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+	}
+	p := newProblem(r, appErr.Code, appErr.Message)
+	if p.Status >= http.StatusInternalServerError {
+		recordError(w, appErr)
+	}
+	write(w, p)
+}
+
+// WriteProblem writes problem details for code with detail as the
+// human-readable explanation.
+func WriteProblem(w http.ResponseWriter, r *http.Request, code helper.ErrorCode, detail string) {
+	write(w, newProblem(r, code, detail))
+}
+
+// ValidationError answers 400 listing every invalid field.
+func ValidationError(w http.ResponseWriter, r *http.Request, errs []helper.ValidatorError) {
+	p := newProblem(r, helper.CodeValidationError, "payload validation failed")
+	p.Errors = errs
+	write(w, p)
+}
+
+func newProblem(r *http.Request, code helper.ErrorCode, detail string) Problem {
+	// Unregistered codes would otherwise reach clients with a guessed status.
+	pt, ok := problemTypes[code]
+	if !ok {
+		code = helper.CodeInternalError
+		pt = problemTypes[code]
+	}
+	return Problem{
+		Type:      typeURI(code),
+		Title:     pt.title,
+		Status:    pt.status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: helper.GetRequestID(r.Context()),
+	}
+}
+
+func write(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// recordError hands err to the first ErrorRecorder found by unwrapping w.
+func recordError(w http.ResponseWriter, err error) {
+	for w != nil {
+		if rec, ok := w.(ErrorRecorder); ok {
+			rec.RecordError(err)
+			return
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return
+		}
+		w = u.Unwrap()
+	}
+}
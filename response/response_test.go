@@ -0,0 +1,283 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"native-free-pollings/domain"
+	"native-free-pollings/helper"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type errorRecorder struct {
+	http.ResponseWriter
+	err error
+}
+
+func (r *errorRecorder) RecordError(err error) { r.err = err }
+
+func TestError(t *testing.T) {
+	cause := errors.New("connection reset")
+	tooMany := helper.NewAppError("TOO_MANY_ATTEMPTS", "too many failed logins", nil)
+	tooMany.RetryAfter = 1500 * time.Millisecond
+
+	tests := []struct {
+		name         string
+		err          error
+		wantStatus   int
+		wantProblem  Problem
+		wantRecorded bool
+		wantRetry    string
+	}{
+		{
+			name:       "Client error",
+			err:        helper.NewAppError("NOT_FOUND", "polling not found", cause),
+			wantStatus: http.StatusNotFound,
+			wantProblem: Problem{
+				Type: "/problems/not-found", Title: "Not found", Status: 404,
+				Detail: "polling not found", Instance: "/pollings/7", Code: "NOT_FOUND", RequestID: "req-1",
+			},
+		},
+		{
+			name:       "Wrapped app error",
+			err:        fmt.Errorf("get polling: %w", helper.NewAppError("NOT_FOUND", "polling not found", cause)),
+			wantStatus: http.StatusNotFound,
+			wantProblem: Problem{
+				Type: "/problems/not-found", Title: "Not found", Status: 404,
+				Detail: "polling not found", Instance: "/pollings/7", Code: "NOT_FOUND", RequestID: "req-1",
+			},
+		},
+		{
+			name:       "Domain error",
+			err:        domain.ErrAlreadyVoted,
+			wantStatus: http.StatusConflict,
+			wantProblem: Problem{
+				Type: "/problems/already-voted", Title: "Already voted", Status: 409,
+				Detail: "you have already voted in this polling", Instance: "/pollings/7", Code: "ALREADY_VOTED", RequestID: "req-1",
+			},
+		},
+		{
+			name:       "Wrapped domain error keeps cause private",
+			err:        fmt.Errorf("only creator can delete polling 7 (connection reset): %w", domain.ErrForbidden),
+			wantStatus: http.StatusForbidden,
+			wantProblem: Problem{
+				Type: "/problems/forbidden-error", Title: "Forbidden", Status: 403,
+				Detail: "you are not allowed to perform this action", Instance: "/pollings/7", Code: "FORBIDDEN_ERROR", RequestID: "req-1",
+			},
+		},
+		{
+			name:       "Version mismatch",
+			err:        fmt.Errorf("polling 7 is at version 4, not 3: %w", domain.ErrVersionMismatch),
+			wantStatus: http.StatusPreconditionFailed,
+			wantProblem: Problem{
+				Type: "/problems/precondition-failed", Title: "Precondition failed", Status: 412,
+				Detail: "polling was changed by someone else; fetch it again and retry", Instance: "/pollings/7", Code: "PRECONDITION_FAILED", RequestID: "req-1",
+			},
+		},
+		{
+			name:       "Registered server error",
+			err:        helper.NewAppError("DB_ERROR", "failed to get polling", cause),
+			wantStatus: http.StatusInternalServerError,
+			wantProblem: Problem{
+				Type: "/problems/db-error", Title: "Database error", Status: 500,
+				Detail: "failed to get polling", Instance: "/pollings/7", Code: "DB_ERROR", RequestID: "req-1",
+			},
+			wantRecorded: true,
+		},
+		{
+			name:       "Unregistered code",
+			err:        helper.NewAppError("SOMETHING_NEW", "it broke", cause),
+			wantStatus: http.StatusInternalServerError,
+			wantProblem: Problem{
+				Type: "/problems/internal-error", Title: "Internal server error", Status: 500,
+				Detail: "it broke", Instance: "/pollings/7", Code: "INTERNAL_ERROR", RequestID: "req-1",
+			},
+			wantRecorded: true,
+		},
+		{
+			name:       "Plain error",
+			err:        cause,
+			wantStatus: http.StatusInternalServerError,
+			wantProblem: Problem{
+				Type: "/problems/internal-error", Title: "Internal server error", Status: 500,
+				Detail: "internal server error", Instance: "/pollings/7", Code: "INTERNAL_ERROR", RequestID: "req-1",
+			},
+			wantRecorded: true,
+		},
+		{
+			name:       "Retry after",
+			err:        tooMany,
+			wantStatus: http.StatusTooManyRequests,
+			wantProblem: Problem{
+				Type: "/problems/too-many-attempts", Title: "Too many attempts", Status: 429,
+				Detail: "too many failed logins", Instance: "/pollings/7", Code: "TOO_MANY_ATTEMPTS", RequestID: "req-1",
+			},
+			wantRetry: "2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/pollings/7", nil)
+			req = req.WithContext(context.WithValue(req.Context(), helper.RequestIDKey, "req-1"))
+			rr := httptest.NewRecorder()
+			rec := &errorRecorder{ResponseWriter: rr}
+
+			Error(rec, req, tt.err)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, ProblemContentType, rr.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantRetry, rr.Header().Get("Retry-After"))
+
+			var got Problem
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+			assert.Equal(t, tt.wantProblem, got)
+			assert.NotContains(t, rr.Body.String(), "connection reset")
+
+			if tt.wantRecorded {
+				assert.ErrorContains(t, rec.err, cause.Error())
+			} else {
+				assert.Nil(t, rec.err)
+			}
+		})
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/pollings", nil)
+	rr := httptest.NewRecorder()
+
+	ValidationError(rr, req, []helper.ValidatorError{{Field: "Title", Message: "is required"}})
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.JSONEq(t, `{
+		"type": "/problems/validation-error",
+		"title": "Validation failed",
+		"status": 400,
+		"detail": "payload validation failed",
+		"instance": "/pollings",
+		"code": "VALIDATION_ERROR",
+		"errors": [{"field": "Title", "message": "is required"}]
+	}`, rr.Body.String())
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     any
+		expected string
+	}{
+		{name: "With data", data: map[string]int{"id": 1}, expected: `{"message":"created","data":{"id":1}}`},
+		{name: "Without data", data: nil, expected: `{"message":"created"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			JSON(rr, http.StatusCreated, "created", tt.data)
+
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			assert.JSONEq(t, tt.expected, rr.Body.String())
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	tests := []struct {
+		name        string
+		etag        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{name: "no header", etag: `"2"`, want: false},
+		{name: "same etag", etag: `"2"`, ifNoneMatch: `"2"`, want: true},
+		{name: "weak comparison", etag: `"2"`, ifNoneMatch: `W/"2"`, want: true},
+		{name: "one of a list", etag: `"2"`, ifNoneMatch: `"1", "2"`, want: true},
+		{name: "any", etag: `"2"`, ifNoneMatch: "*", want: true},
+		{name: "stale etag", etag: `"2"`, ifNoneMatch: `"1"`, want: false},
+		{name: "no etag", etag: "", ifNoneMatch: "*", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/pollings/1", nil)
+			if tt.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rr := httptest.NewRecorder()
+
+			assert.Equal(t, tt.want, NotModified(rr, r, tt.etag))
+			assert.Equal(t, tt.etag, rr.Header().Get("ETag"))
+			if tt.want {
+				assert.Equal(t, http.StatusNotModified, rr.Code)
+			}
+		})
+	}
+}
+
+func TestContentETag(t *testing.T) {
+	a := ContentETag(map[string]int{"votes": 1})
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, a)
+	assert.Equal(t, a, ContentETag(map[string]int{"votes": 1}))
+	assert.NotEqual(t, a, ContentETag(map[string]int{"votes": 2}))
+}
+
+// TestCodesRegistered guards against codes that would silently fall back to
+// 500 because nobody registered them.
+func TestCodesRegistered(t *testing.T) {
+	literal := regexp.MustCompile(`NewAppError\("([A-Z_]+)"`)
+	constant := regexp.MustCompile(`helper\.(Code\w+)`)
+	constants := helperCodes(t)
+
+	err := filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (d.Name() == "docs" || strings.HasPrefix(d.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, m := range literal.FindAllStringSubmatch(string(src), -1) {
+			assert.True(t, Registered(helper.ErrorCode(m[1])), "%s uses unregistered code %s", path, m[1])
+		}
+		for _, m := range constant.FindAllStringSubmatch(string(src), -1) {
+			code, ok := constants[m[1]]
+			require.True(t, ok, "%s uses unknown constant %s", path, m[1])
+			assert.True(t, Registered(code), "%s uses unregistered code %s", path, code)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func helperCodes(t *testing.T) map[string]helper.ErrorCode {
+	src, err := os.ReadFile("../helper/error.go")
+	require.NoError(t, err)
+
+	codes := map[string]helper.ErrorCode{}
+	for _, m := range regexp.MustCompile(`(Code\w+)\s+ErrorCode = "(\w+)"`).FindAllStringSubmatch(string(src), -1) {
+		codes[m[1]] = helper.ErrorCode(m[2])
+	}
+	require.NotEmpty(t, codes)
+	return codes
+}
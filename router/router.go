@@ -0,0 +1,85 @@
+// Package router holds the route table of the API. Routes use ServeMux
+// method and wildcard patterns, so handlers read IDs with r.PathValue and
+// never check the method themselves.
+package router
+
+import (
+	"native-free-pollings/helper"
+	"native-free-pollings/response"
+	"net/http"
+)
+
+// Middleware wraps a handler, e.g. to authenticate the request.
+type Middleware func(http.Handler) http.Handler
+
+// Route binds a "METHOD /path" pattern to a handler. Middleware is applied
+// in order, the first entry being the outermost.
+type Route struct {
+	Pattern    string
+	Handler    http.Handler
+	Middleware []Middleware
+}
+
+// Router dispatches requests to routes and answers unmatched requests with
+// the API's JSON errors: 405 with an Allow header when only the method is
+// wrong, 404 otherwise.
+type Router struct {
+	mux *http.ServeMux
+}
+
+// New registers routes on a fresh ServeMux. It panics on conflicting
+// patterns, like ServeMux.Handle.
+func New(routes []Route) *Router {
+	mux := http.NewServeMux()
+	for _, route := range routes {
+		h := route.Handler
+		for i := len(route.Middleware) - 1; i >= 0; i-- {
+			h = route.Middleware[i](h)
+		}
+		mux.Handle(route.Pattern, h)
+	}
+	return &Router{mux: mux}
+}
+
+// ServeHTTP lets the mux set r.Pattern on r itself, which the metrics and
+// tracing middleware read after the request.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h, pattern := rt.mux.Handler(r)
+	if pattern != "" {
+		rt.mux.ServeHTTP(w, r)
+		return
+	}
+
+	// The mux's own fallback knows the allowed methods; run it against a
+	// throwaway writer and keep only its status and Allow header.
+	fallback := &headerRecorder{header: http.Header{}, status: http.StatusOK}
+	h.ServeHTTP(fallback, r)
+
+	if fallback.status == http.StatusMethodNotAllowed {
+		w.Header().Set("Allow", fallback.header.Get("Allow"))
+		response.WriteProblem(w, r, helper.CodeNotAllowed, "method not allowed")
+		return
+	}
+
+	response.WriteProblem(w, r, helper.CodeNotFound, "request not found")
+}
+
+type headerRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+}
+
+func (h *headerRecorder) Header() http.Header { return h.header }
+
+func (h *headerRecorder) Write(b []byte) (int, error) {
+	h.WriteHeader(http.StatusOK)
+	return len(b), nil
+}
+
+func (h *headerRecorder) WriteHeader(status int) {
+	if !h.wroteHeader {
+		h.status = status
+		h.wroteHeader = true
+	}
+}
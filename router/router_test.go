@@ -0,0 +1,165 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"native-free-pollings/dto"
+	"native-free-pollings/handler"
+	"native-free-pollings/helper"
+	"native-free-pollings/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuth authenticates requests carrying any Authorization header.
+func fakeAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		auth := &helper.AuthContext{UserID: 1, SessionID: 1}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), helper.AuthKey, auth)))
+	})
+}
+
+func newTestRouter(svc *mocks.PollServiceMock) *Router {
+	return New(Routes(Handlers{
+		Metrics:      http.NotFoundHandler(),
+		Polling:      handler.NewPolling(svc, false),
+		RequireAuth:  fakeAuth,
+		OptionalAuth: func(next http.Handler) http.Handler { return next },
+	}))
+}
+
+func TestRouter(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		authorized  bool
+		setupMocks  func(svc *mocks.PollServiceMock)
+		wantCode    int
+		wantAllow   string
+		wantErrCode string
+	}{
+		{
+			name:   "path value reaches handler",
+			method: http.MethodGet,
+			path:   "/pollings/42",
+			setupMocks: func(svc *mocks.PollServiceMock) {
+				svc.On("GetDetailPolling", mock.Anything, int64(42)).Return(&dto.PollingResponse{ID: 42}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:   "HEAD matches GET route",
+			method: http.MethodHead,
+			path:   "/pollings/42/results",
+			setupMocks: func(svc *mocks.PollServiceMock) {
+				svc.On("GetPollingResult", mock.Anything, int64(42)).Return(&dto.ResultPolling{}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:       "route middleware runs",
+			method:     http.MethodDelete,
+			path:       "/pollings/42",
+			setupMocks: func(svc *mocks.PollServiceMock) {},
+			wantCode:   http.StatusUnauthorized,
+		},
+		{
+			name:       "authorized route",
+			method:     http.MethodDelete,
+			path:       "/pollings/42",
+			authorized: true,
+			setupMocks: func(svc *mocks.PollServiceMock) {
+				svc.On("DeletePolling", mock.Anything, int64(42), int64(0), mock.AnythingOfType("authz.Subject")).Return(nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:        "wrong method",
+			method:      http.MethodPut,
+			path:        "/pollings/42",
+			setupMocks:  func(svc *mocks.PollServiceMock) {},
+			wantCode:    http.StatusMethodNotAllowed,
+			wantAllow:   "DELETE, GET, HEAD, PATCH",
+			wantErrCode: "NOT_ALLOWED",
+		},
+		{
+			name:        "unknown path",
+			method:      http.MethodGet,
+			path:        "/pollings/42/unknown",
+			setupMocks:  func(svc *mocks.PollServiceMock) {},
+			wantCode:    http.StatusNotFound,
+			wantErrCode: "NOT_FOUND",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(mocks.PollServiceMock)
+			tt.setupMocks(svc)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.authorized {
+				req.Header.Set("Authorization", "Bearer test")
+			}
+			rr := httptest.NewRecorder()
+			newTestRouter(svc).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Equal(t, tt.wantAllow, rr.Header().Get("Allow"))
+			if tt.wantErrCode != "" {
+				var body map[string]any
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+				assert.Equal(t, tt.wantErrCode, body["code"])
+				assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRouterSetsPattern(t *testing.T) {
+	svc := new(mocks.PollServiceMock)
+	svc.On("GetDetailPolling", mock.Anything, int64(7)).Return(&dto.PollingResponse{ID: 7}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/pollings/7", nil)
+	newTestRouter(svc).ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "GET /pollings/{id}", req.Pattern)
+}
+
+func TestRouterDeprecatedPaths(t *testing.T) {
+	userSvc := new(mocks.UserServiceMock)
+	userSvc.On("GetUserCreatedPollings", mock.Anything, int64(1)).Return([]dto.PollingSummaryForCreator{}, nil)
+	router := New(Routes(Handlers{
+		Metrics:      http.NotFoundHandler(),
+		User:         handler.NewUserHandler(userSvc),
+		RequireAuth:  fakeAuth,
+		OptionalAuth: func(next http.Handler) http.Handler { return next },
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/users/me/pollings/creator", nil)
+	req.Header.Set("Authorization", "Bearer test")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "true", rr.Header().Get("Deprecation"))
+	assert.Equal(t, `</users/me/pollings/created>; rel="successor-version"`, rr.Header().Get("Link"))
+	userSvc.AssertExpectations(t)
+}
+
+func TestRoutesWithoutOIDC(t *testing.T) {
+	for _, route := range Routes(Handlers{Metrics: http.NotFoundHandler()}) {
+		assert.NotContains(t, route.Pattern, "/auth/oidc/")
+	}
+}
@@ -0,0 +1,114 @@
+package router
+
+import (
+	"native-free-pollings/authz"
+	"native-free-pollings/handler"
+	"native-free-pollings/middleware"
+	"net/http"
+)
+
+// Handlers are everything the route table dispatches to. OIDC may be nil
+// when single sign-on is disabled.
+type Handlers struct {
+	Health    *handler.HealthHandler
+	Metrics   http.Handler
+	Auth      *handler.AuthHandler
+	OIDC      *handler.OIDCHandler
+	User      *handler.UserHandler
+	Account   *handler.AccountHandler
+	Admin     *handler.AdminHandler
+	TwoFactor *handler.TwoFactorHandler
+	Token     *handler.AccessTokenHandler
+	Session   *handler.SessionHandler
+	Polling   *handler.Polling
+	Results   *handler.ResultsStreamHandler
+	Live      *handler.LiveHandler
+	Webhook   *handler.WebhookHandler
+
+	// RequireAuth rejects requests without valid credentials. OptionalAuth
+	// authenticates requests that carry a token and lets the rest through.
+	RequireAuth  Middleware
+	OptionalAuth Middleware
+}
+
+// deprecated marks the responses of an old path with a Deprecation header
+// and a link to the path that replaces it, then runs the given middleware.
+func deprecated(successor string, middleware []Middleware) []Middleware {
+	mark := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+			next.ServeHTTP(w, r)
+		})
+	}
+	return append([]Middleware{mark}, middleware...)
+}
+
+// Routes returns the route table of the API.
+func Routes(h Handlers) []Route {
+	auth := []Middleware{h.RequireAuth}
+	admin := func(perm authz.Permission) []Middleware {
+		return []Middleware{h.RequireAuth, middleware.RequirePermission(perm)}
+	}
+
+	routes := []Route{
+		{Pattern: "GET /healthz", Handler: http.HandlerFunc(h.Health.Liveness)},
+		{Pattern: "GET /readyz", Handler: http.HandlerFunc(h.Health.Readiness)},
+		{Pattern: "GET /metrics", Handler: h.Metrics},
+
+		{Pattern: "POST /register", Handler: http.HandlerFunc(h.Auth.Register)},
+		{Pattern: "POST /login", Handler: http.HandlerFunc(h.Auth.Login)},
+		{Pattern: "POST /login/2fa", Handler: http.HandlerFunc(h.Auth.LoginTwoFactor)},
+
+		{Pattern: "POST /admin/unlock", Handler: http.HandlerFunc(h.Auth.UnlockAccount), Middleware: admin(authz.LoginUnlock)},
+		{Pattern: "GET /admin/users", Handler: http.HandlerFunc(h.Admin.ListUsers), Middleware: admin(authz.UserManage)},
+		{Pattern: "PATCH /admin/users/{id}", Handler: http.HandlerFunc(h.Admin.UpdateUser), Middleware: admin(authz.UserManage)},
+
+		{Pattern: "GET /users/me", Handler: http.HandlerFunc(h.User.GetProfile), Middleware: auth},
+		{Pattern: "PATCH /users/me", Handler: http.HandlerFunc(h.User.UpdateProfile), Middleware: auth},
+		{Pattern: "DELETE /users/me", Handler: http.HandlerFunc(h.Account.DeleteAccount), Middleware: auth},
+		{Pattern: "GET /users/me/export", Handler: http.HandlerFunc(h.Account.Export), Middleware: auth},
+		{Pattern: "PATCH /users/me/change-password", Handler: http.HandlerFunc(h.User.ChangePassword), Middleware: auth},
+		{Pattern: "POST /users/me/2fa/setup", Handler: http.HandlerFunc(h.TwoFactor.Setup), Middleware: auth},
+		{Pattern: "POST /users/me/2fa/confirm", Handler: http.HandlerFunc(h.TwoFactor.Confirm), Middleware: auth},
+		{Pattern: "POST /users/me/2fa/disable", Handler: http.HandlerFunc(h.TwoFactor.Disable), Middleware: auth},
+		{Pattern: "GET /users/me/tokens", Handler: http.HandlerFunc(h.Token.ListTokens), Middleware: auth},
+		{Pattern: "POST /users/me/tokens", Handler: http.HandlerFunc(h.Token.CreateToken), Middleware: auth},
+		{Pattern: "DELETE /users/me/tokens/{id}", Handler: http.HandlerFunc(h.Token.RevokeToken), Middleware: auth},
+		{Pattern: "GET /users/me/sessions", Handler: http.HandlerFunc(h.Session.ListSessions), Middleware: auth},
+		{Pattern: "DELETE /users/me/sessions/{id}", Handler: http.HandlerFunc(h.Session.RevokeSession), Middleware: auth},
+		{Pattern: "GET /users/me/webhooks", Handler: http.HandlerFunc(h.Webhook.ListWebhooks), Middleware: auth},
+		{Pattern: "POST /users/me/webhooks", Handler: http.HandlerFunc(h.Webhook.CreateWebhook), Middleware: auth},
+		{Pattern: "PATCH /users/me/webhooks/{id}", Handler: http.HandlerFunc(h.Webhook.UpdateWebhook), Middleware: auth},
+		{Pattern: "DELETE /users/me/webhooks/{id}", Handler: http.HandlerFunc(h.Webhook.DeleteWebhook), Middleware: auth},
+		{Pattern: "GET /users/me/webhooks/{id}/deliveries", Handler: http.HandlerFunc(h.Webhook.ListDeliveries), Middleware: auth},
+		{Pattern: "POST /users/me/webhooks/{id}/deliveries/{deliveryID}/redeliver", Handler: http.HandlerFunc(h.Webhook.Redeliver), Middleware: auth},
+		{Pattern: "GET /users/me/pollings/created", Handler: http.HandlerFunc(h.User.GetUserCreatedPollings), Middleware: auth},
+		{Pattern: "GET /users/me/pollings/voted", Handler: http.HandlerFunc(h.User.GetUserVotedPollings), Middleware: auth},
+		// Served before the route table existed; kept for older clients.
+		{Pattern: "GET /users/me/pollings/creator", Handler: http.HandlerFunc(h.User.GetUserCreatedPollings), Middleware: deprecated("/users/me/pollings/created", auth)},
+		{Pattern: "GET /users/me/pollings/voter", Handler: http.HandlerFunc(h.User.GetUserVotedPollings), Middleware: deprecated("/users/me/pollings/voted", auth)},
+
+		{Pattern: "POST /pollings", Handler: http.HandlerFunc(h.Polling.CreatePolling), Middleware: auth},
+		{Pattern: "GET /pollings/{id}", Handler: http.HandlerFunc(h.Polling.GetDetailPolling)},
+		{Pattern: "PATCH /pollings/{id}", Handler: http.HandlerFunc(h.Polling.UpdatePolling), Middleware: auth},
+		{Pattern: "DELETE /pollings/{id}", Handler: http.HandlerFunc(h.Polling.DeletePolling), Middleware: auth},
+		{Pattern: "POST /pollings/{id}/votes", Handler: http.HandlerFunc(h.Polling.VoteOptionPolling), Middleware: []Middleware{h.OptionalAuth}},
+		{Pattern: "GET /pollings/{id}/results", Handler: http.HandlerFunc(h.Polling.GetPollingResult)},
+		{Pattern: "GET /pollings/{id}/results/stream", Handler: http.HandlerFunc(h.Results.Stream)},
+		{Pattern: "GET /live", Handler: http.HandlerFunc(h.Live.Live)},
+		{Pattern: "POST /pollings/{id}/options", Handler: http.HandlerFunc(h.Polling.AddOption), Middleware: auth},
+		{Pattern: "PUT /pollings/{id}/options/order", Handler: http.HandlerFunc(h.Polling.ReorderOptions), Middleware: auth},
+		{Pattern: "PATCH /pollings/{id}/options/{optionID}", Handler: http.HandlerFunc(h.Polling.RenameOption), Middleware: auth},
+		{Pattern: "DELETE /pollings/{id}/options/{optionID}", Handler: http.HandlerFunc(h.Polling.DeleteOption), Middleware: auth},
+	}
+
+	if h.OIDC != nil {
+		routes = append(routes,
+			Route{Pattern: "GET /auth/oidc/login", Handler: http.HandlerFunc(h.OIDC.Login)},
+			Route{Pattern: "GET /auth/oidc/callback", Handler: http.HandlerFunc(h.OIDC.Callback)},
+		)
+	}
+
+	return routes
+}
@@ -0,0 +1,78 @@
+// Package server runs the HTTP server and the background workers around it
+// and shuts everything down in order when the process is asked to stop.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"native-free-pollings/config"
+	"net/http"
+	"time"
+)
+
+// New builds an http.Server from the configuration. When certs is not nil
+// the server speaks TLS with certificates taken from it.
+func New(conf config.Server, handler http.Handler, certs *CertReloader) *http.Server {
+	srv := &http.Server{
+		Addr:              ":" + conf.Port,
+		Handler:           handler,
+		ReadTimeout:       conf.ReadTimeout,
+		ReadHeaderTimeout: conf.ReadHeaderTimeout,
+		WriteTimeout:      conf.WriteTimeout,
+		IdleTimeout:       conf.IdleTimeout,
+		MaxHeaderBytes:    conf.MaxHeaderBytes,
+	}
+
+	if certs != nil {
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
+	}
+
+	return srv
+}
+
+// Run serves until ctx is cancelled or the listener fails. It then stops
+// accepting connections, waits for in-flight requests and runs each
+// shutdown step in order, all within timeout. Steps still run when draining
+// times out so resources are released either way.
+func Run(ctx context.Context, srv *http.Server, timeout time.Duration, steps ...func(ctx context.Context) error) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			serveErr <- srv.ListenAndServeTLS("", "")
+		} else {
+			serveErr <- srv.ListenAndServe()
+		}
+	}()
+
+	var errs []error
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, err)
+		}
+	case <-ctx.Done():
+		slog.Info("Shutting down, waiting for in-flight requests", "timeout", timeout.String())
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, err)
+		// Whatever is still running past the deadline is cut off.
+		srv.Close()
+	}
+
+	for _, step := range steps {
+		if err := step(shutdownCtx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
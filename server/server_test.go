@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun_ShutdownStepsInOrder(t *testing.T) {
+	srv := &http.Server{Addr: "127.0.0.1:0", Handler: http.NotFoundHandler()}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var order []string
+	err := Run(ctx, srv, time.Second,
+		func(context.Context) error { order = append(order, "workers"); return nil },
+		func(context.Context) error { order = append(order, "db"); return errors.New("close failed") },
+	)
+
+	assert.ErrorContains(t, err, "close failed")
+	assert.Equal(t, []string{"workers", "db"}, order)
+}
+
+func TestRun_ListenError(t *testing.T) {
+	srv := &http.Server{Addr: "127.0.0.1:-1", Handler: http.NotFoundHandler()}
+
+	stepRan := false
+	err := Run(context.Background(), srv, time.Second, func(context.Context) error {
+		stepRan = true
+		return nil
+	})
+
+	assert.Error(t, err)
+	assert.True(t, stepRan, "resources are released even when the listener fails")
+}
+
+func TestWorkers_Stop(t *testing.T) {
+	t.Run("waits for workers", func(t *testing.T) {
+		w := NewWorkers()
+		stopped := make(chan struct{})
+		w.Go("test", func(ctx context.Context) error {
+			<-ctx.Done()
+			close(stopped)
+			return nil
+		})
+
+		assert.NoError(t, w.Stop(context.Background()))
+		select {
+		case <-stopped:
+		default:
+			t.Fatal("Stop returned before the worker finished")
+		}
+	})
+
+	t.Run("gives up at the deadline", func(t *testing.T) {
+		w := NewWorkers()
+		release := make(chan struct{})
+		defer close(release)
+		w.Go("stuck", func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, w.Stop(ctx), context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCert(t *testing.T, dir, commonName string, modTime time.Time) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+
+	return certFile, keyFile
+}
+
+func commonName(t *testing.T, r *CertReloader) string {
+	t.Helper()
+
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+
+	return leaf.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Minute)
+	certFile, keyFile := writeCert(t, dir, "first", start)
+
+	r, err := NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, "first", commonName(t, r))
+
+	reloaded, err := r.Reload()
+	assert.NoError(t, err)
+	assert.False(t, reloaded, "unchanged files are not reloaded")
+
+	writeCert(t, dir, "second", start.Add(time.Second))
+	reloaded, err = r.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, "second", commonName(t, r))
+
+	require.NoError(t, os.WriteFile(keyFile, []byte("garbage"), 0o600))
+	_, err = r.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, "second", commonName(t, r), "a broken key keeps the current certificate")
+}
+
+func TestNewCertReloader_MissingFiles(t *testing.T) {
+	_, err := NewCertReloader("missing-cert.pem", "missing-key.pem")
+
+	assert.Error(t, err)
+}
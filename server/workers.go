@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Workers runs background goroutines that must finish before the process
+// exits, such as queue consumers and the certificate reloader.
+type Workers struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	stopped []string
+}
+
+func NewWorkers() *Workers {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Workers{ctx: ctx, cancel: cancel}
+}
+
+// Go starts fn in its own goroutine. fn must return once ctx is cancelled.
+func (w *Workers) Go(name string, fn func(ctx context.Context) error) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		err := fn(w.ctx)
+		if w.ctx.Err() != nil {
+			return
+		}
+
+		slog.Error("Worker stopped unexpectedly", "worker", name, "error", err)
+		w.mu.Lock()
+		w.stopped = append(w.stopped, name)
+		w.mu.Unlock()
+	}()
+}
+
+// Check fails when a worker has exited before Stop was called, so the
+// readiness probe takes the instance out of rotation.
+func (w *Workers) Check(context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.stopped) > 0 {
+		return fmt.Errorf("workers not running: %s", strings.Join(w.stopped, ", "))
+	}
+	return nil
+}
+
+// Stop cancels every worker and waits for them until ctx is done.
+func (w *Workers) Stop(ctx context.Context) error {
+	w.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("background workers did not stop: %w", ctx.Err())
+	}
+}
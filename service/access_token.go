@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/models"
+	"time"
+)
+
+type accessTokenService struct {
+	repo domain.AccessTokenRepository
+}
+
+func NewAccessTokenService(repo domain.AccessTokenRepository) domain.AccessTokenService {
+	return &accessTokenService{repo: repo}
+}
+
+func (s *accessTokenService) Create(ctx context.Context, userID int64, req *dto.CreateAccessTokenRequest) (*dto.AccessTokenCreatedResponse, error) {
+	raw, err := helper.GenerateAccessToken()
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "failed to generate token", err)
+	}
+
+	token := &models.AccessToken{
+		UserID:    userID,
+		Name:      req.Name,
+		TokenHash: helper.HashAccessToken(raw),
+		Prefix:    raw[:len(helper.AccessTokenPrefix)+6],
+		Scopes:    req.Scopes,
+	}
+	if req.ExpiresInDays > 0 {
+		exp := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &exp
+	}
+
+	if err := s.repo.Create(ctx, token); err != nil {
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to save token", err)
+	}
+
+	return &dto.AccessTokenCreatedResponse{
+		AccessTokenResponse: toAccessTokenResponse(token),
+		Token:               raw,
+	}, nil
+}
+
+func (s *accessTokenService) List(ctx context.Context, userID int64) ([]dto.AccessTokenResponse, error) {
+	tokens, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to get tokens", err)
+	}
+
+	results := []dto.AccessTokenResponse{}
+	for i := range tokens {
+		results = append(results, toAccessTokenResponse(&tokens[i]))
+	}
+
+	return results, nil
+}
+
+func (s *accessTokenService) Revoke(ctx context.Context, userID, id int64) error {
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return helper.NewAppError(helper.CodeNotFound, "token not found", err)
+		}
+		return helper.NewAppError(helper.CodeDBError, "failed to revoke token", err)
+	}
+
+	return nil
+}
+
+func (s *accessTokenService) Authenticate(ctx context.Context, raw string) (*helper.AuthContext, error) {
+	token, err := s.repo.GetByHash(ctx, helper.HashAccessToken(raw))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, helper.NewAppError(helper.CodeInvalidToken, "invalid access token", err)
+		}
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+
+	if token.ExpiresAt != nil && token.ExpiresAt.Before(time.Now()) {
+		return nil, helper.NewAppError(helper.CodeExpiredToken, "access token expired", nil)
+	}
+
+	if err := s.repo.TouchLastUsed(ctx, token.ID); err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+
+	scopes := token.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	return &helper.AuthContext{
+		UserID:    token.UserID,
+		UserEmail: token.UserEmail,
+		UserName:  token.UserName,
+		Role:      token.UserRole,
+		TokenID:   token.ID,
+		Scopes:    scopes,
+	}, nil
+}
+
+func toAccessTokenResponse(t *models.AccessToken) dto.AccessTokenResponse {
+	return dto.AccessTokenResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Scopes:     t.Scopes,
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
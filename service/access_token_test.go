@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/mocks"
+	"native-free-pollings/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAccessTokenService_Create(t *testing.T) {
+	repo := new(mocks.AccessTokenRepositoryMock)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*models.AccessToken")).
+		Run(func(args mock.Arguments) {
+			token := args.Get(1).(*models.AccessToken)
+			token.ID = 7
+		}).
+		Return(nil)
+
+	svc := NewAccessTokenService(repo)
+	resp, err := svc.Create(context.Background(), 1, &dto.CreateAccessTokenRequest{
+		Name:          "ci",
+		Scopes:        []string{helper.ScopePollsWrite},
+		ExpiresInDays: 30,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), resp.ID)
+	assert.True(t, strings.HasPrefix(resp.Token, helper.AccessTokenPrefix))
+	assert.True(t, strings.HasPrefix(resp.Token, resp.Prefix))
+	assert.NotNil(t, resp.ExpiresAt)
+
+	saved := repo.Calls[0].Arguments.Get(1).(*models.AccessToken)
+	assert.Equal(t, helper.HashAccessToken(resp.Token), saved.TokenHash)
+}
+
+func TestAccessTokenService_Authenticate(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		setupMocks func(repo *mocks.AccessTokenRepositoryMock)
+		wantErr    helper.ErrorCode
+		wantScopes []string
+	}{
+		{
+			name: "unknown token",
+			setupMocks: func(repo *mocks.AccessTokenRepositoryMock) {
+				repo.On("GetByHash", mock.Anything, helper.HashAccessToken("nfp_token")).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: "INVALID_TOKEN",
+		},
+		{
+			name: "expired token",
+			setupMocks: func(repo *mocks.AccessTokenRepositoryMock) {
+				repo.On("GetByHash", mock.Anything, helper.HashAccessToken("nfp_token")).
+					Return(&models.AccessToken{ID: 1, UserID: 2, ExpiresAt: &past}, nil)
+			},
+			wantErr: "EXPIRED_TOKEN",
+		},
+		{
+			name: "success",
+			setupMocks: func(repo *mocks.AccessTokenRepositoryMock) {
+				repo.On("GetByHash", mock.Anything, helper.HashAccessToken("nfp_token")).
+					Return(&models.AccessToken{ID: 1, UserID: 2, Scopes: []string{helper.ScopePollsRead}, UserEmail: "a@mail.com"}, nil)
+				repo.On("TouchLastUsed", mock.Anything, int64(1)).Return(nil)
+			},
+			wantScopes: []string{helper.ScopePollsRead},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.AccessTokenRepositoryMock)
+			tt.setupMocks(repo)
+
+			svc := NewAccessTokenService(repo)
+			auth, err := svc.Authenticate(context.Background(), "nfp_token")
+
+			if tt.wantErr != "" {
+				assert.Nil(t, auth)
+				assert.Equal(t, tt.wantErr, err.(*helper.AppError).Code)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, int64(2), auth.UserID)
+			assert.Equal(t, tt.wantScopes, auth.Scopes)
+			assert.False(t, auth.IsSession())
+			assert.True(t, auth.HasScope(helper.ScopePollsRead))
+			assert.False(t, auth.HasScope(helper.ScopePollsWrite))
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"strings"
+	"time"
+)
+
+// reauthWindow is how recent the login of an account without a password
+// must be to delete it or turn off two-factor authentication.
+const reauthWindow = 5 * time.Minute
+
+type accountService struct {
+	repo     domain.AccountRepository
+	users    domain.UserRepository
+	sessions domain.SessionRepository
+	hasher   helper.PasswordHasher
+	now      func() time.Time
+}
+
+func NewAccountService(repo domain.AccountRepository, users domain.UserRepository, sessions domain.SessionRepository, hasher helper.PasswordHasher) domain.AccountService {
+	return &accountService{repo: repo, users: users, sessions: sessions, hasher: hasher, now: time.Now}
+}
+
+func (s *accountService) Export(ctx context.Context, userID int64) (*dto.AccountExport, error) {
+	data, err := s.repo.Export(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, helper.NewAppError(helper.CodeNotFound, "user not found", err)
+		}
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to export account", err)
+	}
+
+	export := &dto.AccountExport{
+		ExportedAt: time.Now().UTC(),
+		Profile: dto.ExportProfile{
+			ID:               data.User.ID,
+			Name:             data.User.Name,
+			Email:            data.User.Email,
+			Role:             data.User.Role,
+			TwoFactorEnabled: data.TwoFactor,
+			DisabledAt:       data.User.DisabledAt,
+			CreatedAt:        data.User.CreatedAt,
+			UpdatedAt:        data.User.UpdatedAt,
+		},
+		Polls:         []dto.ExportPoll{},
+		Votes:         []dto.ExportVote{},
+		Sessions:      []dto.ExportSession{},
+		AccessTokens:  []dto.AccessTokenResponse{},
+		Identities:    []dto.ExportIdentity{},
+		LoginAttempts: []dto.ExportLoginAttempt{},
+		Webhooks:      []dto.WebhookResponse{},
+	}
+
+	for _, p := range data.Polls {
+		poll := dto.ExportPoll{
+			ID:          p.ID,
+			Title:       p.Title,
+			Description: p.Description,
+			Status:      p.Status,
+			StartsAt:    p.StartsAt,
+			EndsAt:      p.EndsAt,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+			Options:     []dto.ExportOption{},
+		}
+		for _, o := range p.Options {
+			poll.Options = append(poll.Options, dto.ExportOption{ID: o.ID, Label: o.Label, Position: o.Position, Votes: o.Votes})
+		}
+		export.Polls = append(export.Polls, poll)
+	}
+
+	for _, v := range data.Votes {
+		export.Votes = append(export.Votes, dto.ExportVote(v))
+	}
+
+	for _, sess := range data.Sessions {
+		export.Sessions = append(export.Sessions, dto.ExportSession{
+			ID:         sess.ID,
+			UserAgent:  sess.UserAgent,
+			IP:         sess.IP,
+			CreatedAt:  sess.CreatedAt,
+			LastSeenAt: sess.LastSeenAt,
+			ExpiresAt:  sess.ExpiresAt,
+			RevokedAt:  sess.RevokedAt,
+		})
+	}
+
+	for i := range data.AccessTokens {
+		export.AccessTokens = append(export.AccessTokens, toAccessTokenResponse(&data.AccessTokens[i]))
+	}
+
+	for _, id := range data.Identities {
+		export.Identities = append(export.Identities, dto.ExportIdentity{
+			Issuer:    id.Issuer,
+			Subject:   id.Subject,
+			Email:     id.Email,
+			CreatedAt: id.CreatedAt,
+		})
+	}
+
+	for _, l := range data.LoginAttempts {
+		export.LoginAttempts = append(export.LoginAttempts, dto.ExportLoginAttempt{
+			IP:          l.IP,
+			Success:     l.Success,
+			AttemptedAt: l.AttemptedAt,
+		})
+	}
+
+	for i := range data.Webhooks {
+		export.Webhooks = append(export.Webhooks, toWebhookResponse(&data.Webhooks[i]))
+	}
+
+	return export, nil
+}
+
+// Delete erases the account after re-checking the password. Accounts created
+// through single sign-on have no password; they confirm with their email
+// address and must have signed in with their identity provider within
+// reauthWindow, since whoever holds the session knows the email anyway.
+func (s *accountService) Delete(ctx context.Context, userID, sessionID int64, req *dto.DeleteAccountRequest) error {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return helper.NewAppError(helper.CodeNotFound, "user not found", err)
+		}
+		return helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+
+	hash, err := s.users.GetPasswordHash(ctx, userID)
+	if err != nil {
+		return helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+
+	if hash == "" {
+		if !strings.EqualFold(req.Password, user.Email) {
+			return helper.NewAppError(helper.CodeAuthFailed, "confirm with your email address", nil)
+		}
+		if err := requireRecentLogin(ctx, s.sessions, s.now(), userID, sessionID, "delete the account"); err != nil {
+			return err
+		}
+	} else if err := s.hasher.Compare(hash, req.Password); err != nil {
+		return helper.NewAppError(helper.CodeAuthFailed, "invalid password", err)
+	}
+
+	if err := s.repo.Erase(ctx, userID, req.DeletePolls); err != nil {
+		return helper.NewAppError(helper.CodeDBError, "failed to delete account", err)
+	}
+
+	return nil
+}
+
+// requireRecentLogin checks that sessionID was created within reauthWindow
+// before now. A new session comes only from a new login, so it proves what a
+// password would. action completes the error message.
+func requireRecentLogin(ctx context.Context, sessions domain.SessionRepository, now time.Time, userID, sessionID int64, action string) error {
+	sess, err := sessions.GetActive(ctx, userID, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return helper.NewAppError(helper.CodeInvalidToken, "session is no longer valid", err)
+		}
+		return helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+
+	if now.Sub(sess.CreatedAt) > reauthWindow {
+		return helper.NewAppError(helper.CodeReauthRequired,
+			fmt.Sprintf("sign in again with your identity provider and %s within %s", action, reauthWindow), nil)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/mocks"
+	"native-free-pollings/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAccountService_Delete(t *testing.T) {
+	now := time.Date(2025, 10, 26, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		req        *dto.DeleteAccountRequest
+		hasher     mocks.MockHasher
+		setupMocks func(repo *mocks.AccountRepositoryMock, users *mocks.UserRepositoryMock, sessions *mocks.SessionRepositoryMock)
+		wantErr    helper.ErrorCode
+	}{
+		{
+			name:   "wrong password",
+			req:    &dto.DeleteAccountRequest{Password: "wrong"},
+			hasher: mocks.MockHasher{ShouldFail: true},
+			setupMocks: func(repo *mocks.AccountRepositoryMock, users *mocks.UserRepositoryMock, sessions *mocks.SessionRepositoryMock) {
+				users.On("GetByID", mock.Anything, int64(1)).Return(&models.User{ID: 1, Email: "a@mail.com"}, nil)
+				users.On("GetPasswordHash", mock.Anything, int64(1)).Return("hash", nil)
+			},
+			wantErr: helper.CodeAuthFailed,
+		},
+		{
+			name: "sso account confirms with email after a fresh login",
+			req:  &dto.DeleteAccountRequest{Password: "A@mail.com"},
+			setupMocks: func(repo *mocks.AccountRepositoryMock, users *mocks.UserRepositoryMock, sessions *mocks.SessionRepositoryMock) {
+				users.On("GetByID", mock.Anything, int64(1)).Return(&models.User{ID: 1, Email: "a@mail.com"}, nil)
+				users.On("GetPasswordHash", mock.Anything, int64(1)).Return("", nil)
+				sessions.On("GetActive", mock.Anything, int64(1), int64(3)).Return(&models.Session{ID: 3, CreatedAt: now.Add(-time.Minute)}, nil)
+				repo.On("Erase", mock.Anything, int64(1), false).Return(nil)
+			},
+		},
+		{
+			name: "sso account with an old session",
+			req:  &dto.DeleteAccountRequest{Password: "a@mail.com"},
+			setupMocks: func(repo *mocks.AccountRepositoryMock, users *mocks.UserRepositoryMock, sessions *mocks.SessionRepositoryMock) {
+				users.On("GetByID", mock.Anything, int64(1)).Return(&models.User{ID: 1, Email: "a@mail.com"}, nil)
+				users.On("GetPasswordHash", mock.Anything, int64(1)).Return("", nil)
+				sessions.On("GetActive", mock.Anything, int64(1), int64(3)).Return(&models.Session{ID: 3, CreatedAt: now.Add(-time.Hour)}, nil)
+			},
+			wantErr: helper.CodeReauthRequired,
+		},
+		{
+			name: "sso account with wrong confirmation",
+			req:  &dto.DeleteAccountRequest{Password: "b@mail.com"},
+			setupMocks: func(repo *mocks.AccountRepositoryMock, users *mocks.UserRepositoryMock, sessions *mocks.SessionRepositoryMock) {
+				users.On("GetByID", mock.Anything, int64(1)).Return(&models.User{ID: 1, Email: "a@mail.com"}, nil)
+				users.On("GetPasswordHash", mock.Anything, int64(1)).Return("", nil)
+			},
+			wantErr: helper.CodeAuthFailed,
+		},
+		{
+			name: "success deleting polls",
+			req:  &dto.DeleteAccountRequest{Password: "secret", DeletePolls: true},
+			setupMocks: func(repo *mocks.AccountRepositoryMock, users *mocks.UserRepositoryMock, sessions *mocks.SessionRepositoryMock) {
+				users.On("GetByID", mock.Anything, int64(1)).Return(&models.User{ID: 1, Email: "a@mail.com"}, nil)
+				users.On("GetPasswordHash", mock.Anything, int64(1)).Return("hash", nil)
+				repo.On("Erase", mock.Anything, int64(1), true).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.AccountRepositoryMock)
+			users := new(mocks.UserRepositoryMock)
+			sessions := new(mocks.SessionRepositoryMock)
+			tt.setupMocks(repo, users, sessions)
+
+			svc := NewAccountService(repo, users, sessions, tt.hasher).(*accountService)
+			svc.now = func() time.Time { return now }
+			err := svc.Delete(context.Background(), 1, 3, tt.req)
+
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tt.wantErr, err.(*helper.AppError).Code)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountService_Export(t *testing.T) {
+	starts := time.Now()
+	repo := new(mocks.AccountRepositoryMock)
+	repo.On("Export", mock.Anything, int64(1)).Return(&models.AccountExport{
+		User:      models.User{ID: 1, Email: "a@mail.com", Name: "John", Role: "user"},
+		TwoFactor: true,
+		Polls: []models.ExportedPoll{{
+			ID: 5, Title: "Lunch", Status: "active", StartsAt: &starts,
+			Options: []models.ExportedOption{{ID: 9, Label: "Pizza", Position: 1, Votes: 3}},
+		}},
+		Votes:        []models.ExportedVote{{PollID: 7, PollTitle: "Other", OptionID: 11, OptionLabel: "Yes"}},
+		AccessTokens: []models.AccessToken{{ID: 2, Name: "ci", TokenHash: "secret-hash"}},
+	}, nil)
+
+	svc := NewAccountService(repo, new(mocks.UserRepositoryMock), new(mocks.SessionRepositoryMock), mocks.MockHasher{})
+	export, err := svc.Export(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a@mail.com", export.Profile.Email)
+	assert.True(t, export.Profile.TwoFactorEnabled)
+	assert.Equal(t, int64(3), export.Polls[0].Options[0].Votes)
+	assert.Equal(t, "Yes", export.Votes[0].OptionLabel)
+	assert.Equal(t, "ci", export.AccessTokens[0].Name)
+	assert.NotNil(t, export.Sessions)
+	assert.NotNil(t, export.Identities)
+}
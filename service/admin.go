@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"native-free-pollings/authz"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/models"
+)
+
+type adminService struct {
+	users    domain.UserRepository
+	sessions domain.SessionService
+}
+
+func NewAdminService(users domain.UserRepository, sessions domain.SessionService) domain.AdminService {
+	return &adminService{users: users, sessions: sessions}
+}
+
+func (s *adminService) ListUsers(ctx context.Context) ([]dto.AdminUserResponse, error) {
+	users, err := s.users.List(ctx)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to get users", err)
+	}
+
+	results := []dto.AdminUserResponse{}
+	for i := range users {
+		results = append(results, toAdminUserResponse(&users[i]))
+	}
+
+	return results, nil
+}
+
+func (s *adminService) UpdateUser(ctx context.Context, actor authz.Subject, id int64, req *dto.AdminUpdateUserRequest) (*dto.AdminUserResponse, error) {
+	if !authz.Allowed(actor, authz.UserManage) {
+		return nil, helper.NewAppError(helper.CodeForbidden, "insufficient role", authz.ErrForbidden)
+	}
+
+	// An admin demoting or disabling themselves could leave nobody able to
+	// undo it.
+	if actor.UserID == id && (req.Role != nil || req.Disabled != nil) {
+		return nil, helper.NewAppError(helper.CodeForbidden, "admins cannot change their own role or status", authz.ErrForbidden)
+	}
+
+	if req.Role != nil && !authz.ValidRole(*req.Role) {
+		return nil, helper.NewAppError(helper.CodeBadRequest, "unknown role", nil)
+	}
+
+	if req.Role != nil {
+		if err := s.users.UpdateRole(ctx, id, *req.Role); err != nil {
+			return nil, userWriteError(err)
+		}
+	}
+
+	if req.Disabled != nil {
+		if err := s.users.SetDisabled(ctx, id, *req.Disabled); err != nil {
+			return nil, userWriteError(err)
+		}
+	}
+
+	// Sessions read the role and status from the database, but cache them;
+	// other instances notice within the session cache TTL.
+	if req.Role != nil || req.Disabled != nil {
+		s.sessions.ForgetUser(id)
+	}
+
+	user, err := s.users.GetByID(ctx, id)
+	if err != nil {
+		return nil, userWriteError(err)
+	}
+
+	resp := toAdminUserResponse(user)
+	return &resp, nil
+}
+
+func userWriteError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return helper.NewAppError(helper.CodeNotFound, "user not found", err)
+	}
+	return helper.NewAppError(helper.CodeDBError, "failed to update user", err)
+}
+
+func toAdminUserResponse(u *models.User) dto.AdminUserResponse {
+	return dto.AdminUserResponse{
+		ID:         u.ID,
+		Name:       u.Name,
+		Email:      u.Email,
+		Role:       u.Role,
+		DisabledAt: u.DisabledAt,
+		CreatedAt:  u.CreatedAt,
+	}
+}
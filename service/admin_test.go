@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"native-free-pollings/authz"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/mocks"
+	"native-free-pollings/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAdminService_UpdateUser(t *testing.T) {
+	admin := authz.Subject{UserID: 1, Role: authz.RoleAdmin}
+	moderator := authz.Subject{UserID: 3, Role: authz.RoleModerator}
+	role := authz.RoleModerator
+	badRole := "root"
+	disabled := true
+
+	tests := []struct {
+		name       string
+		actor      authz.Subject
+		id         int64
+		req        *dto.AdminUpdateUserRequest
+		setupMocks func(repo *mocks.UserRepositoryMock)
+		wantErr    helper.ErrorCode
+	}{
+		{
+			name:       "moderator cannot manage users",
+			actor:      moderator,
+			id:         2,
+			req:        &dto.AdminUpdateUserRequest{Disabled: &disabled},
+			setupMocks: func(repo *mocks.UserRepositoryMock) {},
+			wantErr:    "FORBIDDEN_ERROR",
+		},
+		{
+			name:       "admin cannot disable themselves",
+			actor:      admin,
+			id:         1,
+			req:        &dto.AdminUpdateUserRequest{Disabled: &disabled},
+			setupMocks: func(repo *mocks.UserRepositoryMock) {},
+			wantErr:    "FORBIDDEN_ERROR",
+		},
+		{
+			name:       "unknown role",
+			actor:      admin,
+			id:         2,
+			req:        &dto.AdminUpdateUserRequest{Role: &badRole},
+			setupMocks: func(repo *mocks.UserRepositoryMock) {},
+			wantErr:    "BAD_REQUEST",
+		},
+		{
+			name:  "user not found",
+			actor: admin,
+			id:    9,
+			req:   &dto.AdminUpdateUserRequest{Role: &role},
+			setupMocks: func(repo *mocks.UserRepositoryMock) {
+				repo.On("UpdateRole", mock.Anything, int64(9), role).Return(sql.ErrNoRows)
+			},
+			wantErr: "NOT_FOUND",
+		},
+		{
+			name:  "success",
+			actor: admin,
+			id:    2,
+			req:   &dto.AdminUpdateUserRequest{Role: &role, Disabled: &disabled},
+			setupMocks: func(repo *mocks.UserRepositoryMock) {
+				repo.On("UpdateRole", mock.Anything, int64(2), role).Return(nil)
+				repo.On("SetDisabled", mock.Anything, int64(2), true).Return(nil)
+				repo.On("GetByID", mock.Anything, int64(2)).Return(&models.User{ID: 2, Role: role}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.UserRepositoryMock)
+			tt.setupMocks(repo)
+			sessions := new(mocks.SessionServiceMock)
+			sessions.On("ForgetUser", tt.id).Return()
+
+			svc := NewAdminService(repo, sessions)
+			resp, err := svc.UpdateUser(context.Background(), tt.actor, tt.id, tt.req)
+
+			if tt.wantErr != "" {
+				assert.Nil(t, resp)
+				assert.Equal(t, tt.wantErr, err.(*helper.AppError).Code)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, role, resp.Role)
+			repo.AssertExpectations(t)
+			sessions.AssertCalled(t, "ForgetUser", tt.id)
+		})
+	}
+}
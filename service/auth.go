@@ -2,43 +2,229 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"native-free-pollings/domain"
 	"native-free-pollings/dto"
 	"native-free-pollings/helper"
+	"native-free-pollings/metrics"
 	"native-free-pollings/models"
+	"native-free-pollings/oidc"
 	"time"
 )
 
+const twoFactorChallengeTTL = 5 * time.Minute
+
 type authService struct {
-	repo   domain.AuthRepository
-	jwtKey []byte
-	hasher helper.PasswordHasher
+	repo       domain.AuthRepository
+	attempts   domain.LoginAttemptTracker
+	twoFactor  domain.TwoFactorRepository
+	identities domain.IdentityRepository
+	sessions   domain.SessionRepository
+	jwtKey     []byte
+	tokenTTL   time.Duration
+	hasher     helper.PasswordHasher
 }
 
-func NewAuthService(repo domain.AuthRepository, jwtKey []byte, hasher helper.PasswordHasher) domain.AuthService {
-	return &authService{repo: repo, jwtKey: jwtKey, hasher: hasher}
+func NewAuthService(repo domain.AuthRepository, attempts domain.LoginAttemptTracker, twoFactor domain.TwoFactorRepository, identities domain.IdentityRepository, sessions domain.SessionRepository, jwtKey []byte, tokenTTL time.Duration, hasher helper.PasswordHasher) domain.AuthService {
+	return &authService{repo: repo, attempts: attempts, twoFactor: twoFactor, identities: identities, sessions: sessions, jwtKey: jwtKey, tokenTTL: tokenTTL, hasher: hasher}
 }
 
 func (a *authService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, error) {
+	lockedUntil, err := a.attempts.LockedUntil(ctx, req.Email, req.IP)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+	if wait := time.Until(lockedUntil); wait > 0 {
+		metrics.LoginsFailed.Inc("locked")
+		return nil, tooManyAttempts(wait)
+	}
+
 	user, err := a.repo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, helper.NewAppError("LOGIN_FAILED", "invalid email or password", err)
+		return nil, a.loginFailed(ctx, req, "credentials", err)
 	}
 
 	if err := a.hasher.Compare(user.PasswordHash, req.Password); err != nil {
-		return nil, helper.NewAppError("LOGIN_FAILED", "invalid email or password", err)
+		return nil, a.loginFailed(ctx, req, "credentials", err)
+	}
+
+	if user.DisabledAt != nil {
+		return nil, helper.NewAppError(helper.CodeAccountDisabled, "account has been disabled", nil)
+	}
+
+	challenge, err := a.twoFactorChallenge(ctx, user)
+	if err != nil || challenge != nil {
+		return challenge, err
+	}
+
+	if err := a.attempts.RecordSuccess(ctx, req.Email, req.IP); err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+
+	return a.issueToken(ctx, user, dto.ClientInfo{IP: req.IP, UserAgent: req.UserAgent})
+}
+
+func (a *authService) LoginTwoFactor(ctx context.Context, req *dto.LoginTwoFactorRequest) (*dto.LoginResponse, error) {
+	claims, err := helper.ExtractChallengeToken(req.ChallengeToken, a.jwtKey)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeAuthFailed, "invalid or expired challenge token", err)
+	}
+
+	lockedUntil, err := a.attempts.LockedUntil(ctx, claims.Email, req.IP)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+	if wait := time.Until(lockedUntil); wait > 0 {
+		metrics.LoginsFailed.Inc("locked")
+		return nil, tooManyAttempts(wait)
+	}
+
+	tf, err := a.twoFactor.Get(ctx, claims.UserID)
+	if err != nil || !tf.Enabled {
+		return nil, helper.NewAppError(helper.CodeAuthFailed, "invalid or expired challenge token", err)
+	}
+
+	ok, err := verifySecondFactor(ctx, a.twoFactor, tf, req.Code)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+	if !ok {
+		// Counted like a wrong password, but the user already proved the
+		// password and only needs to retype the code.
+		return nil, a.recordFailure(ctx, claims.Email, req.IP, "two_factor",
+			helper.NewAppError(helper.CodeInvalidCode, "invalid verification code", nil))
+	}
+
+	if err := a.attempts.RecordSuccess(ctx, claims.Email, req.IP); err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+
+	user, err := a.repo.GetUserByEmail(ctx, claims.Email)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeAuthFailed, "invalid or expired challenge token", err)
+	}
+	if user.DisabledAt != nil {
+		return nil, helper.NewAppError(helper.CodeAccountDisabled, "account has been disabled", nil)
+	}
+
+	return a.issueToken(ctx, user, dto.ClientInfo{IP: req.IP, UserAgent: req.UserAgent})
+}
+
+// LoginOIDC signs in the user behind a verified OpenID Connect identity. A
+// known issuer/subject pair logs straight in; otherwise the identity is linked
+// to the account with the same verified email, or a new account is created.
+// Accounts created this way have no password and can only use SSO.
+func (a *authService) LoginOIDC(ctx context.Context, identity *oidc.Identity, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	user, err := a.identities.GetUser(ctx, identity.Issuer, identity.Subject)
+	if errors.Is(err, sql.ErrNoRows) {
+		user, err = a.linkOrProvision(ctx, identity)
+	} else if err != nil {
+		err = helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DisabledAt != nil {
+		return nil, helper.NewAppError(helper.CodeAccountDisabled, "account has been disabled", nil)
+	}
+
+	challenge, err := a.twoFactorChallenge(ctx, user)
+	if err != nil || challenge != nil {
+		return challenge, err
+	}
+
+	return a.issueToken(ctx, user, client)
+}
+
+func (a *authService) linkOrProvision(ctx context.Context, identity *oidc.Identity) (*models.User, error) {
+	// Linking on an unverified email would let anyone who can register that
+	// address at the provider take over the local account.
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, helper.NewAppError(helper.CodeAuthFailed, "identity provider did not return a verified email", nil)
+	}
+
+	link := &models.Identity{
+		Issuer:  identity.Issuer,
+		Subject: identity.Subject,
+		Email:   identity.Email,
+	}
+
+	user, err := a.repo.GetUserByEmail(ctx, identity.Email)
+	if err == nil {
+		link.UserID = user.ID
+		if err := a.identities.Link(ctx, link); err != nil {
+			return nil, helper.NewAppError(helper.CodeDBError, "failed to link identity", err)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+
+	name := identity.Name
+	if name == "" {
+		name = identity.Email
+	}
+	user = &models.User{
+		Email: identity.Email,
+		Name:  name,
+	}
+	if err := a.identities.CreateUserWithIdentity(ctx, user, link); err != nil {
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to save user", err)
+	}
+
+	return user, nil
+}
+
+// twoFactorChallenge returns a challenge response when user has two-factor
+// authentication enabled, and nil when a token may be issued right away.
+func (a *authService) twoFactorChallenge(ctx context.Context, user *models.User) (*dto.LoginResponse, error) {
+	tf, err := a.twoFactor.Get(ctx, user.ID)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+	if !tf.Enabled {
+		return nil, nil
+	}
+
+	challenge, err := helper.CreateChallengeToken(user.ID, user.Email, time.Now().Add(twoFactorChallengeTTL), a.jwtKey)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeTokenFailed, "failed create token", err)
+	}
+
+	return &dto.LoginResponse{
+		ID:                user.ID,
+		Name:              user.Name,
+		TwoFactorRequired: true,
+		ChallengeToken:    challenge,
+	}, nil
+}
+
+func (a *authService) issueToken(ctx context.Context, user *models.User, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	session := &models.Session{
+		UserID:    user.ID,
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+		ExpiresAt: time.Now().Add(a.tokenTTL),
+	}
+	if err := a.sessions.Create(ctx, session); err != nil {
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to save session", err)
 	}
 
 	tokenInfo := &helper.Claims{
-		UserID: user.ID,
-		Name:   user.Name,
-		Email:  user.Email,
-		Exp:    time.Now().Add(time.Hour * 72).Unix(),
+		UserID:    user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Role:      user.Role,
+		SessionID: session.ID,
+		Exp:       session.ExpiresAt.Unix(),
 	}
 
 	token, err := helper.CreateToken(tokenInfo, a.jwtKey)
 	if err != nil {
-		return nil, helper.NewAppError("TOKEN_FAILED", "failed create token", err)
+		return nil, helper.NewAppError(helper.CodeTokenFailed, "failed create token", err)
 	}
 
 	return &dto.LoginResponse{
@@ -48,15 +234,53 @@ func (a *authService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.Lo
 	}, nil
 }
 
+func (a *authService) UnlockAccount(ctx context.Context, email string) error {
+	if email == "" {
+		return helper.NewAppError(helper.CodeBadRequest, "email is required", nil)
+	}
+
+	if err := a.attempts.Unlock(ctx, email); err != nil {
+		return helper.NewAppError(helper.CodeInternalError, "failed to unlock account", err)
+	}
+
+	return nil
+}
+
+func (a *authService) loginFailed(ctx context.Context, req *dto.LoginRequest, reason string, cause error) error {
+	return a.recordFailure(ctx, req.Email, req.IP, reason, helper.NewAppError(helper.CodeLoginFailed, "invalid email or password", cause))
+}
+
+// recordFailure counts a failed login toward the lockout and returns failed,
+// or the lockout error once the failure locks the account or IP.
+func (a *authService) recordFailure(ctx context.Context, email, ip, reason string, failed error) error {
+	metrics.LoginsFailed.Inc(reason)
+
+	lockedUntil, err := a.attempts.RecordFailure(ctx, email, ip)
+	if err != nil {
+		return helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+	if wait := time.Until(lockedUntil); wait > 0 {
+		return tooManyAttempts(wait)
+	}
+
+	return failed
+}
+
+func tooManyAttempts(wait time.Duration) error {
+	appErr := helper.NewAppError(helper.CodeTooManyAttempts, "too many failed login attempts, try again later", nil)
+	appErr.RetryAfter = wait
+	return appErr
+}
+
 func (a *authService) Register(ctx context.Context, req *dto.RegisterRequest) (*dto.RegisterResponse, error) {
 	existing, _ := a.repo.GetUserByEmail(ctx, req.Email)
 	if existing != nil {
-		return nil, helper.NewAppError("EMAIL_EXIST", "email already registered", nil)
+		return nil, helper.NewAppError(helper.CodeEmailExist, "email already registered", nil)
 	}
 
 	hashed, err := a.hasher.Hash(req.Pass)
 	if err != nil {
-		return nil, helper.NewAppError("HASH_FAILED", "failed hash password", err)
+		return nil, helper.NewAppError(helper.CodeHashFailed, "failed hash password", err)
 	}
 
 	user := &models.User{
@@ -67,7 +291,7 @@ func (a *authService) Register(ctx context.Context, req *dto.RegisterRequest) (*
 
 	err = a.repo.CreateUser(ctx, user)
 	if err != nil {
-		return nil, helper.NewAppError("DB_ERROR", "failed to save user", err)
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to save user", err)
 	}
 
 	return &dto.RegisterResponse{
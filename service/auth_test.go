@@ -2,24 +2,39 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"native-free-pollings/dto"
+	"native-free-pollings/helper"
 	"native-free-pollings/mocks"
 	"native-free-pollings/models"
+	"native-free-pollings/oidc"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+func newSessionRepoMock() *mocks.SessionRepositoryMock {
+	sessions := new(mocks.SessionRepositoryMock)
+	sessions.On("Create", mock.Anything, mock.AnythingOfType("*models.Session")).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Session).ID = 1
+		}).
+		Return(nil).
+		Maybe()
+	return sessions
+}
+
 func TestAuthService_Register(t *testing.T) {
 	tests := []struct {
 		name       string
 		setupMocks func(repo *mocks.UserRepositoryMock)
 		hasher     mocks.MockHasher
 		req        *dto.RegisterRequest
-		wantErr    string
+		wantErr    helper.ErrorCode
 	}{
 		{
 			name: "email alread exists",
@@ -72,7 +87,7 @@ func TestAuthService_Register(t *testing.T) {
 			repo := new(mocks.UserRepositoryMock)
 			tt.setupMocks(repo)
 
-			svc := NewAuthService(repo, []byte("test-secret"), tt.hasher)
+			svc := NewAuthService(repo, new(mocks.LoginAttemptTrackerMock), new(mocks.TwoFactorRepositoryMock), new(mocks.IdentityRepositoryMock), newSessionRepoMock(), []byte("test-secret"), time.Hour, tt.hasher)
 			resp, err := svc.Register(context.Background(), tt.req)
 
 			if tt.wantErr == "" {
@@ -88,3 +103,269 @@ func TestAuthService_Register(t *testing.T) {
 	}
 
 }
+
+func TestAuthService_Login(t *testing.T) {
+	tests := []struct {
+		name          string
+		setupMocks    func(repo *mocks.UserRepositoryMock, attempts *mocks.LoginAttemptTrackerMock, twoFactor *mocks.TwoFactorRepositoryMock)
+		hasher        mocks.MockHasher
+		wantErr       helper.ErrorCode
+		wantChallenge bool
+	}{
+		{
+			name: "account locked",
+			setupMocks: func(repo *mocks.UserRepositoryMock, attempts *mocks.LoginAttemptTrackerMock, twoFactor *mocks.TwoFactorRepositoryMock) {
+				attempts.On("LockedUntil", mock.Anything, "a@mail.com", "10.0.0.1").
+					Return(time.Now().Add(time.Minute), nil)
+			},
+			hasher:  mocks.MockHasher{},
+			wantErr: "TOO_MANY_ATTEMPTS",
+		},
+		{
+			name: "wrong password",
+			setupMocks: func(repo *mocks.UserRepositoryMock, attempts *mocks.LoginAttemptTrackerMock, twoFactor *mocks.TwoFactorRepositoryMock) {
+				attempts.On("LockedUntil", mock.Anything, "a@mail.com", "10.0.0.1").
+					Return(time.Time{}, nil)
+				repo.On("GetUserByEmail", mock.Anything, "a@mail.com").
+					Return(&models.User{ID: 1, Email: "a@mail.com"}, nil)
+				attempts.On("RecordFailure", mock.Anything, "a@mail.com", "10.0.0.1").
+					Return(time.Time{}, nil)
+			},
+			hasher:  mocks.MockHasher{ShouldFail: true},
+			wantErr: "LOGIN_FAILED",
+		},
+		{
+			name: "failure reaches lockout threshold",
+			setupMocks: func(repo *mocks.UserRepositoryMock, attempts *mocks.LoginAttemptTrackerMock, twoFactor *mocks.TwoFactorRepositoryMock) {
+				attempts.On("LockedUntil", mock.Anything, "a@mail.com", "10.0.0.1").
+					Return(time.Time{}, nil)
+				repo.On("GetUserByEmail", mock.Anything, "a@mail.com").
+					Return(nil, errors.New("not found"))
+				attempts.On("RecordFailure", mock.Anything, "a@mail.com", "10.0.0.1").
+					Return(time.Now().Add(30*time.Second), nil)
+			},
+			hasher:  mocks.MockHasher{},
+			wantErr: "TOO_MANY_ATTEMPTS",
+		},
+		{
+			name: "success",
+			setupMocks: func(repo *mocks.UserRepositoryMock, attempts *mocks.LoginAttemptTrackerMock, twoFactor *mocks.TwoFactorRepositoryMock) {
+				attempts.On("LockedUntil", mock.Anything, "a@mail.com", "10.0.0.1").
+					Return(time.Time{}, nil)
+				repo.On("GetUserByEmail", mock.Anything, "a@mail.com").
+					Return(&models.User{ID: 1, Email: "a@mail.com", Name: "John"}, nil)
+				twoFactor.On("Get", mock.Anything, int64(1)).
+					Return(&models.TwoFactor{UserID: 1}, nil)
+				attempts.On("RecordSuccess", mock.Anything, "a@mail.com", "10.0.0.1").
+					Return(nil)
+			},
+			hasher:  mocks.MockHasher{},
+			wantErr: "",
+		},
+		{
+			name: "two factor required",
+			setupMocks: func(repo *mocks.UserRepositoryMock, attempts *mocks.LoginAttemptTrackerMock, twoFactor *mocks.TwoFactorRepositoryMock) {
+				attempts.On("LockedUntil", mock.Anything, "a@mail.com", "10.0.0.1").
+					Return(time.Time{}, nil)
+				repo.On("GetUserByEmail", mock.Anything, "a@mail.com").
+					Return(&models.User{ID: 1, Email: "a@mail.com", Name: "John"}, nil)
+				twoFactor.On("Get", mock.Anything, int64(1)).
+					Return(&models.TwoFactor{UserID: 1, Enabled: true}, nil)
+			},
+			hasher:        mocks.MockHasher{},
+			wantChallenge: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.UserRepositoryMock)
+			attempts := new(mocks.LoginAttemptTrackerMock)
+			twoFactor := new(mocks.TwoFactorRepositoryMock)
+			tt.setupMocks(repo, attempts, twoFactor)
+
+			svc := NewAuthService(repo, attempts, twoFactor, new(mocks.IdentityRepositoryMock), newSessionRepoMock(), []byte("test-secret"), time.Hour, tt.hasher)
+			resp, err := svc.Login(context.Background(), &dto.LoginRequest{Email: "a@mail.com", Password: "secret", IP: "10.0.0.1"})
+
+			if tt.wantChallenge {
+				assert.NoError(t, err)
+				assert.True(t, resp.TwoFactorRequired)
+				assert.Empty(t, resp.Token)
+				assert.NotEmpty(t, resp.ChallengeToken)
+			} else if tt.wantErr == "" {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, resp.Token)
+			} else {
+				assert.Nil(t, resp)
+				appErr := err.(*helper.AppError)
+				assert.Equal(t, tt.wantErr, appErr.Code)
+				if tt.wantErr == "TOO_MANY_ATTEMPTS" {
+					assert.Greater(t, appErr.RetryAfter, time.Duration(0))
+				}
+			}
+			attempts.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthService_LoginTwoFactor(t *testing.T) {
+	key := []byte("test-secret")
+	secret, _ := helper.GenerateTOTPSecret()
+	code, _ := helper.TOTPCode(secret, helper.TOTPStep(time.Now()))
+	challenge, _ := helper.CreateChallengeToken(1, "a@mail.com", time.Now().Add(time.Minute), key)
+	accessToken, _ := helper.CreateToken(&helper.Claims{UserID: 1, Email: "a@mail.com", Exp: time.Now().Add(time.Minute).Unix()}, key)
+
+	tests := []struct {
+		name       string
+		req        *dto.LoginTwoFactorRequest
+		setupMocks func(repo *mocks.UserRepositoryMock, attempts *mocks.LoginAttemptTrackerMock, twoFactor *mocks.TwoFactorRepositoryMock)
+		wantErr    helper.ErrorCode
+	}{
+		{
+			name: "access token is not a challenge",
+			req:  &dto.LoginTwoFactorRequest{ChallengeToken: *accessToken, Code: code, IP: "10.0.0.1"},
+			setupMocks: func(repo *mocks.UserRepositoryMock, attempts *mocks.LoginAttemptTrackerMock, twoFactor *mocks.TwoFactorRepositoryMock) {
+			},
+			wantErr: "AUTH_FAILED",
+		},
+		{
+			name: "invalid code",
+			req:  &dto.LoginTwoFactorRequest{ChallengeToken: challenge, Code: "wrong-code", IP: "10.0.0.1"},
+			setupMocks: func(repo *mocks.UserRepositoryMock, attempts *mocks.LoginAttemptTrackerMock, twoFactor *mocks.TwoFactorRepositoryMock) {
+				attempts.On("LockedUntil", mock.Anything, "a@mail.com", "10.0.0.1").Return(time.Time{}, nil)
+				twoFactor.On("Get", mock.Anything, int64(1)).Return(&models.TwoFactor{UserID: 1, Secret: secret, Enabled: true}, nil)
+				twoFactor.On("UseRecoveryCode", mock.Anything, int64(1), helper.HashRecoveryCode("wrong-code")).Return(false, nil)
+				attempts.On("RecordFailure", mock.Anything, "a@mail.com", "10.0.0.1").Return(time.Time{}, nil)
+			},
+			wantErr: helper.CodeInvalidCode,
+		},
+		{
+			name: "totp code replayed",
+			req:  &dto.LoginTwoFactorRequest{ChallengeToken: challenge, Code: code, IP: "10.0.0.1"},
+			setupMocks: func(repo *mocks.UserRepositoryMock, attempts *mocks.LoginAttemptTrackerMock, twoFactor *mocks.TwoFactorRepositoryMock) {
+				attempts.On("LockedUntil", mock.Anything, "a@mail.com", "10.0.0.1").Return(time.Time{}, nil)
+				twoFactor.On("Get", mock.Anything, int64(1)).Return(&models.TwoFactor{UserID: 1, Secret: secret, Enabled: true}, nil)
+				twoFactor.On("UseStep", mock.Anything, int64(1), mock.AnythingOfType("int64")).Return(false, nil)
+				attempts.On("RecordFailure", mock.Anything, "a@mail.com", "10.0.0.1").Return(time.Time{}, nil)
+			},
+			wantErr: helper.CodeInvalidCode,
+		},
+		{
+			name: "success",
+			req:  &dto.LoginTwoFactorRequest{ChallengeToken: challenge, Code: code, IP: "10.0.0.1"},
+			setupMocks: func(repo *mocks.UserRepositoryMock, attempts *mocks.LoginAttemptTrackerMock, twoFactor *mocks.TwoFactorRepositoryMock) {
+				attempts.On("LockedUntil", mock.Anything, "a@mail.com", "10.0.0.1").Return(time.Time{}, nil)
+				twoFactor.On("Get", mock.Anything, int64(1)).Return(&models.TwoFactor{UserID: 1, Secret: secret, Enabled: true}, nil)
+				twoFactor.On("UseStep", mock.Anything, int64(1), mock.AnythingOfType("int64")).Return(true, nil)
+				attempts.On("RecordSuccess", mock.Anything, "a@mail.com", "10.0.0.1").Return(nil)
+				repo.On("GetUserByEmail", mock.Anything, "a@mail.com").Return(&models.User{ID: 1, Email: "a@mail.com", Name: "John"}, nil)
+			},
+			wantErr: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.UserRepositoryMock)
+			attempts := new(mocks.LoginAttemptTrackerMock)
+			twoFactor := new(mocks.TwoFactorRepositoryMock)
+			tt.setupMocks(repo, attempts, twoFactor)
+
+			svc := NewAuthService(repo, attempts, twoFactor, new(mocks.IdentityRepositoryMock), newSessionRepoMock(), key, time.Hour, mocks.MockHasher{})
+			resp, err := svc.LoginTwoFactor(context.Background(), tt.req)
+
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, resp.Token)
+			} else {
+				assert.Nil(t, resp)
+				assert.Equal(t, tt.wantErr, err.(*helper.AppError).Code)
+			}
+			twoFactor.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthService_LoginOIDC(t *testing.T) {
+	identity := &oidc.Identity{Issuer: "https://sso.example.com", Subject: "sub-1", Email: "a@mail.com", EmailVerified: true, Name: "John"}
+	unverified := *identity
+	unverified.EmailVerified = false
+
+	tests := []struct {
+		name       string
+		identity   *oidc.Identity
+		setupMocks func(repo *mocks.UserRepositoryMock, identities *mocks.IdentityRepositoryMock, twoFactor *mocks.TwoFactorRepositoryMock)
+		wantErr    helper.ErrorCode
+	}{
+		{
+			name:     "known identity",
+			identity: identity,
+			setupMocks: func(repo *mocks.UserRepositoryMock, identities *mocks.IdentityRepositoryMock, twoFactor *mocks.TwoFactorRepositoryMock) {
+				identities.On("GetUser", mock.Anything, identity.Issuer, identity.Subject).Return(&models.User{ID: 1, Email: "a@mail.com"}, nil)
+				twoFactor.On("Get", mock.Anything, int64(1)).Return(&models.TwoFactor{UserID: 1}, nil)
+			},
+		},
+		{
+			name:     "links existing account by verified email",
+			identity: identity,
+			setupMocks: func(repo *mocks.UserRepositoryMock, identities *mocks.IdentityRepositoryMock, twoFactor *mocks.TwoFactorRepositoryMock) {
+				identities.On("GetUser", mock.Anything, identity.Issuer, identity.Subject).Return(nil, sql.ErrNoRows)
+				repo.On("GetUserByEmail", mock.Anything, "a@mail.com").Return(&models.User{ID: 2, Email: "a@mail.com"}, nil)
+				identities.On("Link", mock.Anything, &models.Identity{UserID: 2, Issuer: identity.Issuer, Subject: identity.Subject, Email: "a@mail.com"}).Return(nil)
+				twoFactor.On("Get", mock.Anything, int64(2)).Return(&models.TwoFactor{UserID: 2}, nil)
+			},
+		},
+		{
+			name:     "provisions new account",
+			identity: identity,
+			setupMocks: func(repo *mocks.UserRepositoryMock, identities *mocks.IdentityRepositoryMock, twoFactor *mocks.TwoFactorRepositoryMock) {
+				identities.On("GetUser", mock.Anything, identity.Issuer, identity.Subject).Return(nil, sql.ErrNoRows)
+				repo.On("GetUserByEmail", mock.Anything, "a@mail.com").Return(nil, sql.ErrNoRows)
+				identities.On("CreateUserWithIdentity", mock.Anything, mock.AnythingOfType("*models.User"), mock.AnythingOfType("*models.Identity")).
+					Run(func(args mock.Arguments) {
+						args.Get(1).(*models.User).ID = 3
+					}).
+					Return(nil)
+				twoFactor.On("Get", mock.Anything, int64(3)).Return(&models.TwoFactor{UserID: 3}, nil)
+			},
+		},
+		{
+			name:     "unverified email is not linked",
+			identity: &unverified,
+			setupMocks: func(repo *mocks.UserRepositoryMock, identities *mocks.IdentityRepositoryMock, twoFactor *mocks.TwoFactorRepositoryMock) {
+				identities.On("GetUser", mock.Anything, identity.Issuer, identity.Subject).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: "AUTH_FAILED",
+		},
+		{
+			name:     "disabled account",
+			identity: identity,
+			setupMocks: func(repo *mocks.UserRepositoryMock, identities *mocks.IdentityRepositoryMock, twoFactor *mocks.TwoFactorRepositoryMock) {
+				now := time.Now()
+				identities.On("GetUser", mock.Anything, identity.Issuer, identity.Subject).Return(&models.User{ID: 1, DisabledAt: &now}, nil)
+			},
+			wantErr: "ACCOUNT_DISABLED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.UserRepositoryMock)
+			identities := new(mocks.IdentityRepositoryMock)
+			twoFactor := new(mocks.TwoFactorRepositoryMock)
+			tt.setupMocks(repo, identities, twoFactor)
+
+			svc := NewAuthService(repo, new(mocks.LoginAttemptTrackerMock), twoFactor, identities, newSessionRepoMock(), []byte("test-secret"), time.Hour, mocks.MockHasher{})
+			resp, err := svc.LoginOIDC(context.Background(), tt.identity, dto.ClientInfo{IP: "10.0.0.1", UserAgent: "test"})
+
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, resp.Token)
+			} else {
+				assert.Nil(t, resp)
+				assert.Equal(t, tt.wantErr, err.(*helper.AppError).Code)
+			}
+			identities.AssertExpectations(t)
+		})
+	}
+}
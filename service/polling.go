@@ -2,30 +2,44 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"native-free-pollings/authz"
 	"native-free-pollings/domain"
 	"native-free-pollings/dto"
 	"native-free-pollings/helper"
+	"native-free-pollings/metrics"
 	"native-free-pollings/models"
+	"native-free-pollings/tracing"
+	"strings"
+	"time"
 )
 
 type polling struct {
-	DB       *sql.DB
+	DB       *tracing.DB
 	PollRepo domain.PollRepository
 	OptRepo  domain.OptionRepository
 	VoteRepo domain.VoteRepository
+	Outbox   domain.OutboxRepository
 }
 
-func NewPolling(db *sql.DB, pollRepo domain.PollRepository, optRepo domain.OptionRepository, voteRepo domain.VoteRepository) domain.PollService {
-	return &polling{DB: db, PollRepo: pollRepo, OptRepo: optRepo, VoteRepo: voteRepo}
+// NewPolling returns the poll service. Created and updated polls and cast
+// votes are recorded in outbox within their transaction; with a nil outbox
+// no events are recorded.
+func NewPolling(db *sql.DB, pollRepo domain.PollRepository, optRepo domain.OptionRepository, voteRepo domain.VoteRepository, outbox domain.OutboxRepository) domain.PollService {
+	return &polling{DB: tracing.WrapDB(db), PollRepo: pollRepo, OptRepo: optRepo, VoteRepo: voteRepo, Outbox: outbox}
 }
 
 func (p *polling) CreatePolling(ctx context.Context, rq *dto.CreatePollingRequest, creator dto.CreatorInfo) (*dto.PollingResponse, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "polling.CreatePolling")
+	defer span.End()
+
 	tx, err := p.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, helper.NewAppError("INTERNAL_ERROR", "internal server error", err)
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
 	}
 	defer tx.Rollback()
 
@@ -41,7 +55,7 @@ func (p *polling) CreatePolling(ctx context.Context, rq *dto.CreatePollingReques
 
 	err = p.PollRepo.Create(ctx, tx, poll)
 	if err != nil {
-		return nil, helper.NewAppError("DB_ERROR", "failed to save polling", err)
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to save polling", err)
 	}
 
 	//insert option
@@ -54,7 +68,7 @@ func (p *polling) CreatePolling(ctx context.Context, rq *dto.CreatePollingReques
 		}
 		err := p.OptRepo.Create(ctx, tx, opt)
 		if err != nil {
-			return nil, helper.NewAppError("DB_ERROR", "failed to save option", err)
+			return nil, helper.NewAppError(helper.CodeDBError, "failed to save option", err)
 		}
 		dOpt := dto.Option{
 			ID:       opt.ID,
@@ -64,11 +78,7 @@ func (p *polling) CreatePolling(ctx context.Context, rq *dto.CreatePollingReques
 		options = append(options, dOpt)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, helper.NewAppError("INTERNAL_ERROR", "internal server error", err)
-	}
-
-	return &dto.PollingResponse{
+	resp := &dto.PollingResponse{
 		ID:          poll.ID,
 		Title:       poll.Title,
 		Description: poll.Description,
@@ -77,147 +87,408 @@ func (p *polling) CreatePolling(ctx context.Context, rq *dto.CreatePollingReques
 		EndsAt:      poll.EndsAt,
 		CreatedAt:   poll.CreatedAt,
 		UpdatedAt:   poll.UpdatedAt,
+		Version:     poll.Version,
 		Options:     options,
 		Creator:     creator,
-	}, nil
+	}
+	if err := p.record(ctx, tx, domain.EventPollCreated, poll.ID, creator.ID, resp); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+	metrics.PollsCreated.Inc()
 
+	return resp, nil
 }
 
-func (p *polling) UpdatePolling(ctx context.Context, rq *dto.UpdatePollingRequest, creator dto.CreatorInfo) (*dto.PollingResponse, error) {
-	oldPoll, err := p.PollRepo.GetByID(ctx, p.DB, rq.ID)
+func (p *polling) UpdatePolling(ctx context.Context, rq *dto.UpdatePollingRequest, actor authz.Subject) (*dto.PollingResponse, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "polling.UpdatePolling")
+	defer span.End()
+
+	poll, err := p.managedPoll(ctx, rq.ID, actor, "update")
 	if err != nil {
+		return nil, err
+	}
+	if rq.Version != 0 && rq.Version != poll.Version {
+		return nil, fmt.Errorf("polling %d is at version %d, not %d: %w", poll.ID, poll.Version, rq.Version, domain.ErrVersionMismatch)
+	}
+	wasStatus := poll.Status
+
+	if rq.Title != nil {
+		poll.Title = *rq.Title
+	}
+	if rq.Description != nil {
+		poll.Description = *rq.Description
+	}
+	if rq.Status != nil {
+		poll.Status = *rq.Status
+	}
+	if rq.StartsAt != nil {
+		poll.StartsAt = *rq.StartsAt
+	}
+	if rq.EndsAt != nil {
+		poll.EndsAt = *rq.EndsAt
+	}
+	// Checked after merging, since either bound may come from the stored poll.
+	if !poll.EndsAt.After(poll.StartsAt) {
+		return nil, helper.NewAppError(helper.CodeInvalidInput, "ends_at must be after starts_at", nil)
+	}
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+	defer tx.Rollback()
+
+	if err := p.PollRepo.Update(ctx, tx, poll); err != nil {
+		// The poll was there a moment ago, so no row means a concurrent change.
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, helper.NewAppError("NOT_FOUND", "polling not found", err)
+			return nil, fmt.Errorf("polling %d changed during update: %w", poll.ID, domain.ErrVersionMismatch)
 		}
-		return nil, helper.NewAppError("INTERNAL_ERROR", "internal server error", err)
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to save polling", err)
 	}
 
-	if oldPoll.UserID != creator.ID {
-		return nil, helper.NewAppError("FORBIDDEN_ERROR", "only creator can update this polling", nil)
+	options, err := p.OptRepo.GetByPollID(ctx, tx, poll.ID)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeDBError, "failed get options polling", err)
+	}
+
+	resp := pollingResponse(poll, options)
+	if err := p.record(ctx, tx, domain.EventPollUpdated, poll.ID, poll.UserID, resp); err != nil {
+		return nil, err
+	}
+	if poll.Status == "closed" && wasStatus != "closed" {
+		if err := p.record(ctx, tx, domain.EventPollClosed, poll.ID, poll.UserID, resp); err != nil {
+			return nil, err
+		}
 	}
 
-	oldOptions, err := p.OptRepo.GetByPollID(ctx, p.DB, rq.ID)
+	if err := tx.Commit(); err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+
+	return resp, nil
+}
+
+func (p *polling) AddOption(ctx context.Context, pollID, version int64, rq *dto.CreateOptionRequest, actor authz.Subject) (*dto.Option, int64, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "polling.AddOption")
+	defer span.End()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+	defer tx.Rollback()
+
+	poll, err := p.lockManagedPoll(ctx, tx, pollID, version, actor)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	options, err := p.OptRepo.GetByPollID(ctx, tx, pollID)
 	if err != nil {
-		return nil, helper.NewAppError("INTERNAL_ERROR", "internal server error", err)
+		return nil, 0, helper.NewAppError(helper.CodeDBError, "failed get options polling", err)
+	}
+	if limit := helper.PollLimits().MaxOptions; len(options) >= limit {
+		return nil, 0, helper.NewAppError(helper.CodeInvalidInput, fmt.Sprintf("a polling can have at most %d options", limit), nil)
+	}
+	if labelTaken(options, rq.Label, 0) {
+		return nil, 0, helper.NewAppError(helper.CodeInvalidInput, "an option with this label already exists", nil)
+	}
+
+	// New options go last; use the order endpoint to move them.
+	option := &models.PollOption{PollID: pollID, Label: rq.Label, Position: 1}
+	for _, o := range options {
+		option.Position = max(option.Position, o.Position+1)
+	}
+	if err := p.OptRepo.Create(ctx, tx, option); err != nil {
+		return nil, 0, helper.NewAppError(helper.CodeDBError, "failed to save option", err)
+	}
+	if err := p.touch(ctx, tx, poll); err != nil {
+		return nil, 0, err
 	}
 
-	idOptions := make(map[int64]bool)
-	for _, opt := range oldOptions {
-		idOptions[opt.ID] = true
+	if err := tx.Commit(); err != nil {
+		return nil, 0, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
 	}
 
+	return &dto.Option{ID: option.ID, Label: option.Label, Position: option.Position}, poll.Version, nil
+}
+
+func (p *polling) RenameOption(ctx context.Context, pollID, version, optionID int64, rq *dto.RenameOptionRequest, actor authz.Subject) (*dto.Option, int64, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "polling.RenameOption")
+	defer span.End()
+
 	tx, err := p.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, helper.NewAppError("INTERNAL_ERROR", "internal server error", err)
+		return nil, 0, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
 	}
 	defer tx.Rollback()
 
-	//update polling
-	updatedPoll := &models.Polling{
-		ID:          rq.ID,
-		UserID:      creator.ID,
-		Title:       rq.Title,
-		Description: rq.Description,
-		Status:      rq.Status,
-		StartsAt:    rq.StartsAt,
-		EndsAt:      rq.EndsAt,
+	poll, err := p.lockManagedPoll(ctx, tx, pollID, version, actor)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	err = p.PollRepo.Update(ctx, tx, updatedPoll)
+	options, err := p.OptRepo.GetByPollID(ctx, tx, pollID)
 	if err != nil {
-		return nil, helper.NewAppError("DB_ERROR", "failed to save polling", err)
+		return nil, 0, helper.NewAppError(helper.CodeDBError, "failed get options polling", err)
+	}
+	option := findOption(options, optionID)
+	if option == nil {
+		return nil, 0, domain.ErrOptionNotFound
+	}
+	if labelTaken(options, rq.Label, optionID) {
+		return nil, 0, helper.NewAppError(helper.CodeInvalidInput, "an option with this label already exists", nil)
 	}
 
-	//options
-	var newOptions []dto.Option
-	for _, opt := range rq.Options {
-		option := &models.PollOption{
-			ID:       opt.ID,
-			PollID:   oldPoll.ID,
-			Label:    opt.Label,
-			Position: opt.Position,
-		}
-		//update
-		if opt.ID > 0 && idOptions[opt.ID] {
-			err := p.OptRepo.Update(ctx, tx, option)
-			if err != nil {
-				return nil, helper.NewAppError("DB_ERROR", "failed to update option", err)
-			}
-			newOptions = append(newOptions, dto.Option{ID: option.ID, Label: option.Label, Position: option.Position})
-		} else {
-			//insert
-			err := p.OptRepo.Create(ctx, tx, option)
-			if err != nil {
-				return nil, helper.NewAppError("DB_ERROR", "failed to create new option", err)
-			}
+	option.Label = rq.Label
+	if err := p.OptRepo.Update(ctx, tx, option); err != nil {
+		return nil, 0, helper.NewAppError(helper.CodeDBError, "failed to update option", err)
+	}
+	if err := p.touch(ctx, tx, poll); err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+
+	return &dto.Option{ID: option.ID, Label: option.Label, Position: option.Position}, poll.Version, nil
+}
+
+func (p *polling) ReorderOptions(ctx context.Context, pollID, version int64, rq *dto.ReorderOptionsRequest, actor authz.Subject) ([]dto.Option, int64, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "polling.ReorderOptions")
+	defer span.End()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+	defer tx.Rollback()
+
+	poll, err := p.lockManagedPoll(ctx, tx, pollID, version, actor)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	options, err := p.OptRepo.GetByPollID(ctx, tx, pollID)
+	if err != nil {
+		return nil, 0, helper.NewAppError(helper.CodeDBError, "failed get options polling", err)
+	}
+	if len(rq.OptionIDs) != len(options) {
+		return nil, 0, helper.NewAppError(helper.CodeInvalidInput, "option_ids must list every option of the polling", nil)
+	}
+	for _, id := range rq.OptionIDs {
+		if findOption(options, id) == nil {
+			return nil, 0, domain.ErrOptionNotFound
 		}
-		idOptions[opt.ID] = false
 	}
 
-	//delete
-	for id, c := range idOptions {
-		if c {
-			err := p.OptRepo.Delete(ctx, tx, id)
-			if err != nil {
-				return nil, helper.NewAppError("DB_ERROR", "failed to delete option", err)
-			}
+	if err := p.OptRepo.Reorder(ctx, tx, pollID, rq.OptionIDs); err != nil {
+		return nil, 0, helper.NewAppError(helper.CodeDBError, "failed to reorder options", err)
+	}
+	if err := p.touch(ctx, tx, poll); err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+
+	reordered := make([]dto.Option, len(rq.OptionIDs))
+	for i, id := range rq.OptionIDs {
+		reordered[i] = dto.Option{ID: id, Label: findOption(options, id).Label, Position: i + 1}
+	}
+	return reordered, poll.Version, nil
+}
+
+func (p *polling) DeleteOption(ctx context.Context, pollID, version, optionID int64, force bool, actor authz.Subject) (int64, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "polling.DeleteOption")
+	defer span.End()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+	defer tx.Rollback()
+
+	// The lock keeps votes out until the option is gone, so the count
+	// below still holds when it is deleted.
+	poll, err := p.lockManagedPoll(ctx, tx, pollID, version, actor)
+	if err != nil {
+		return 0, err
+	}
+
+	options, err := p.OptRepo.GetByPollID(ctx, tx, pollID)
+	if err != nil {
+		return 0, helper.NewAppError(helper.CodeDBError, "failed get options polling", err)
+	}
+	if findOption(options, optionID) == nil {
+		return 0, domain.ErrOptionNotFound
+	}
+	if limit := helper.PollLimits().MinOptions; len(options) <= limit {
+		return 0, helper.NewAppError(helper.CodeInvalidInput, fmt.Sprintf("a polling needs at least %d options", limit), nil)
+	}
+
+	if !force {
+		votes, err := p.VoteRepo.CountByOptionID(ctx, tx, optionID)
+		if err != nil {
+			return 0, helper.NewAppError(helper.CodeDBError, "failed count votes", err)
 		}
+		if votes > 0 {
+			return 0, fmt.Errorf("option %d has %d votes: %w", optionID, votes, domain.ErrOptionHasVotes)
+		}
+	}
+
+	// Votes and user votes of the option go with it by cascade.
+	if err := p.OptRepo.Delete(ctx, tx, optionID); err != nil {
+		return 0, helper.NewAppError(helper.CodeDBError, "failed to delete option", err)
+	}
+	if err := p.touch(ctx, tx, poll); err != nil {
+		return 0, err
 	}
 
 	if err := tx.Commit(); err != nil {
-		return nil, helper.NewAppError("INTERNAL_ERROR", "internal server error", err)
+		return 0, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
 	}
 
-	return &dto.PollingResponse{
-		ID:          updatedPoll.ID,
-		Title:       updatedPoll.Title,
-		Description: updatedPoll.Description,
-		Status:      updatedPoll.Status,
-		StartsAt:    updatedPoll.StartsAt,
-		EndsAt:      updatedPoll.EndsAt,
-		CreatedAt:   updatedPoll.CreatedAt,
-		UpdatedAt:   updatedPoll.UpdatedAt,
-		Options:     newOptions,
-		Creator:     creator,
-	}, nil
+	return poll.Version, nil
+}
+
+// managedPoll loads a poll that actor is allowed to change; action names
+// the change in the forbidden error.
+func (p *polling) managedPoll(ctx context.Context, pollID int64, actor authz.Subject, action string) (*models.Polling, error) {
+	poll, err := p.PollRepo.GetByID(ctx, p.DB, pollID)
+	return checkManaged(poll, err, pollID, actor, action)
+}
+
+// lockManagedPoll is managedPoll for option changes inside tx, which are
+// refused unless the poll is at version; zero skips the check. The poll
+// stays locked until tx ends, so option changes and votes on it run one
+// after another.
+func (p *polling) lockManagedPoll(ctx context.Context, tx domain.DB, pollID, version int64, actor authz.Subject) (*models.Polling, error) {
+	poll, err := p.PollRepo.GetByIDForUpdate(ctx, tx, pollID)
+	poll, err = checkManaged(poll, err, pollID, actor, "change options of")
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != poll.Version {
+		return nil, fmt.Errorf("polling %d is at version %d, not %d: %w", pollID, poll.Version, version, domain.ErrVersionMismatch)
+	}
+	return poll, nil
+}
+
+func checkManaged(poll *models.Polling, err error, pollID int64, actor authz.Subject, action string) (*models.Polling, error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPollNotFound
+		}
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+
+	if !authz.CanManage(actor, poll.UserID, authz.PollManageAny) {
+		return nil, fmt.Errorf("only creator can %s polling %d: %w", action, pollID, domain.ErrForbidden)
+	}
+
+	return poll, nil
+}
+
+// touch bumps the version of the locked poll after its options changed,
+// since they are part of the representation its ETag covers, tells results
+// streams that labels, order or counts moved, and records poll.updated.
+func (p *polling) touch(ctx context.Context, tx domain.DB, poll *models.Polling) error {
+	if err := p.PollRepo.Touch(ctx, tx, poll.ID); err != nil {
+		return helper.NewAppError(helper.CodeDBError, "failed to update polling version", err)
+	}
+	// The lock keeps the version from moving between the read and Touch.
+	poll.Version++
+	if err := p.PollRepo.NotifyResults(ctx, tx, poll.ID); err != nil {
+		return helper.NewAppError(helper.CodeDBError, "failed to notify results", err)
+	}
+
+	options, err := p.OptRepo.GetByPollID(ctx, tx, poll.ID)
+	if err != nil {
+		return helper.NewAppError(helper.CodeDBError, "failed get options polling", err)
+	}
+	return p.record(ctx, tx, domain.EventPollUpdated, poll.ID, poll.UserID, pollingResponse(poll, options))
+}
+
+func findOption(options []models.PollOption, id int64) *models.PollOption {
+	for i := range options {
+		if options[i].ID == id {
+			return &options[i]
+		}
+	}
+	return nil
+}
+
+// labelTaken reports whether an option other than exceptID already uses
+// label, with the same case-insensitive comparison as the request rules.
+func labelTaken(options []models.PollOption, label string, exceptID int64) bool {
+	label = strings.ToLower(strings.TrimSpace(label))
+	for _, o := range options {
+		if o.ID != exceptID && strings.ToLower(strings.TrimSpace(o.Label)) == label {
+			return true
+		}
+	}
+	return false
 }
 
 func (p *polling) VoteOptionPolling(ctx context.Context, userID, pollID, optionID int64, deviceHash string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "polling.VoteOptionPolling")
+	defer span.End()
+
 	//checking user vote
 	if userID > 0 {
 		exist, err := p.VoteRepo.HasUserVoted(ctx, p.DB, pollID, userID)
 		if err != nil {
-			return helper.NewAppError("INTERNAL_ERROR", "internal server error", err)
+			return helper.NewAppError(helper.CodeInternalError, "internal server error", err)
 		}
 		if exist {
-			return helper.NewAppError("ALREADY_VOTED", "you have alread voted in this polling", err)
+			metrics.VotesAlreadyVoted.Inc()
+			return domain.ErrAlreadyVoted
 		}
 	} else {
 		exist, err := p.VoteRepo.HasDeviceVoted(ctx, p.DB, deviceHash, pollID)
 		if err != nil {
-			return helper.NewAppError("INTERNAL_ERROR", "internal server error", err)
+			return helper.NewAppError(helper.CodeInternalError, "internal server error", err)
 		}
 		if exist {
-			return helper.NewAppError("ALREADY_VOTED", "you have alread voted in this polling", err)
+			metrics.VotesAlreadyVoted.Inc()
+			return domain.ErrAlreadyVoted
 		}
 	}
 
 	tx, err := p.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return helper.NewAppError("INTERNAL_ERROR", "internal server error", err)
+		return helper.NewAppError(helper.CodeInternalError, "internal server error", err)
 	}
 	defer tx.Rollback()
 
-	poll, err := p.PollRepo.GetByID(ctx, p.DB, pollID)
+	// Votes share the poll lock with each other, but wait for status and
+	// option changes, so the outbox sees them in the order they happened.
+	poll, err := p.PollRepo.GetByIDForShare(ctx, tx, pollID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return helper.NewAppError("NOT_FOUND", "polling not found", err)
+			return domain.ErrPollNotFound
 		}
-		return helper.NewAppError("DB_ERROR", "failed get polling", err)
+		return helper.NewAppError(helper.CodeDBError, "failed get polling", err)
 	}
 
 	if poll.Status != "active" {
-		return helper.NewAppError("BAD_REQUEST", fmt.Sprintf("cannot vote polling: %s", poll.Status), err)
+		return helper.NewAppError(helper.CodeBadRequest, fmt.Sprintf("cannot vote polling: %s", poll.Status), err)
+	}
+
+	// The option ID comes from the client and must not point at another poll.
+	options, err := p.OptRepo.GetByPollID(ctx, tx, pollID)
+	if err != nil {
+		return helper.NewAppError(helper.CodeDBError, "failed get options polling", err)
+	}
+	if findOption(options, optionID) == nil {
+		return domain.ErrOptionNotFound
 	}
 
 	vote := &models.Vote{
@@ -226,39 +497,97 @@ func (p *polling) VoteOptionPolling(ctx context.Context, userID, pollID, optionI
 	}
 	err = p.VoteRepo.Create(ctx, tx, vote)
 	if err != nil {
-		return helper.NewAppError("DB_ERROR", "failed save vote", err)
+		return helper.NewAppError(helper.CodeDBError, "failed save vote", err)
 	}
 
 	if userID > 0 {
 		err := p.VoteRepo.CreateUserVote(ctx, tx, userID, vote.ID)
 		if err != nil {
-			return helper.NewAppError("DB_ERROR", "failed insert user vote", err)
+			return helper.NewAppError(helper.CodeDBError, "failed insert user vote", err)
 		}
 	}
 
+	// Sent with the commit, so streams never show a vote that was rolled back.
+	if err := p.PollRepo.NotifyResults(ctx, tx, pollID); err != nil {
+		return helper.NewAppError(helper.CodeDBError, "failed to notify results", err)
+	}
+	if err := p.record(ctx, tx, domain.EventVoteCast, pollID, poll.UserID, dto.VoteCastData{PollID: pollID, OptionID: optionID}); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
-		return helper.NewAppError("INTERNAL_ERROR", "internal server error", err)
+		return helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+
+	if userID > 0 {
+		metrics.VotesCast.Inc("user")
+	} else {
+		metrics.VotesCast.Inc("anonymous")
 	}
 
 	return nil
 }
 
-func (p *polling) DeletePolling(ctx context.Context, pollID, userID int64) error {
-	poll, err := p.PollRepo.GetByID(ctx, p.DB, pollID)
+// record adds an event to the outbox through tx, so it is committed or
+// rolled back together with the change it describes.
+func (p *polling) record(ctx context.Context, tx domain.DB, name string, pollID, ownerID int64, data any) error {
+	if p.Outbox == nil {
+		return nil
+	}
+	id, err := newEventID()
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return helper.NewAppError("NOT_FOUND", "polling not found", err)
-		}
-		return helper.NewAppError("INTERNAL_ERROR", "internal server error", err)
+		return helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+	ev := domain.Event{
+		ID:         id,
+		Name:       name,
+		PollID:     pollID,
+		OwnerID:    ownerID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+	if err := p.Outbox.Add(ctx, tx, ev); err != nil {
+		return helper.NewAppError(helper.CodeDBError, "failed to record event", err)
+	}
+	return nil
+}
+
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate event id: %w", err)
 	}
+	return "evt_" + hex.EncodeToString(b), nil
+}
 
-	if poll.UserID != userID {
-		return helper.NewAppError("FORBIDDEN_ERROR", "only creator can delete this polling", nil)
+func (p *polling) DeletePolling(ctx context.Context, pollID, version int64, actor authz.Subject) error {
+	poll, err := p.managedPoll(ctx, pollID, actor, "delete")
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != poll.Version {
+		return fmt.Errorf("polling %d is at version %d, not %d: %w", pollID, poll.Version, version, domain.ErrVersionMismatch)
 	}
 
-	err = p.PollRepo.Delete(ctx, p.DB, pollID)
+	tx, err := p.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return helper.NewAppError("DB_ERROR", "failed delete polling", err)
+		return helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+	defer tx.Rollback()
+
+	err = p.PollRepo.Delete(ctx, tx, pollID, poll.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("polling %d changed during delete: %w", pollID, domain.ErrVersionMismatch)
+		}
+		return helper.NewAppError(helper.CodeDBError, "failed delete polling", err)
+	}
+	if err := p.record(ctx, tx, domain.EventPollDeleted, pollID, poll.UserID, dto.PollDeletedData{PollID: pollID}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return helper.NewAppError(helper.CodeInternalError, "internal server error", err)
 	}
 
 	return nil
@@ -268,26 +597,25 @@ func (p *polling) GetDetailPolling(ctx context.Context, id int64) (*dto.PollingR
 	poll, err := p.PollRepo.GetByID(ctx, p.DB, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, helper.NewAppError("NOT_FOUND", "polling not found", err)
+			return nil, domain.ErrPollNotFound
 		}
-		return nil, helper.NewAppError("DB_ERROR", "failed get detail polling", err)
+		return nil, helper.NewAppError(helper.CodeDBError, "failed get detail polling", err)
 	}
 
 	opt, err := p.OptRepo.GetByPollID(ctx, p.DB, id)
 	if err != nil {
-		return nil, helper.NewAppError("DB_ERROR", "failed get options polling", err)
+		return nil, helper.NewAppError(helper.CodeDBError, "failed get options polling", err)
 	}
+
+	return pollingResponse(poll, opt), nil
+}
+
+func pollingResponse(poll *models.Polling, opts []models.PollOption) *dto.PollingResponse {
 	var options []dto.Option
-	for _, o := range opt {
+	for _, o := range opts {
 		options = append(options, dto.Option{ID: o.ID, Label: o.Label, Position: o.Position})
 	}
 
-	creator := dto.CreatorInfo{
-		ID:    poll.UserID,
-		Name:  poll.CreatorName,
-		Email: poll.CreatorEmail,
-	}
-
 	return &dto.PollingResponse{
 		ID:          poll.ID,
 		Title:       poll.Title,
@@ -297,17 +625,26 @@ func (p *polling) GetDetailPolling(ctx context.Context, id int64) (*dto.PollingR
 		EndsAt:      poll.EndsAt,
 		CreatedAt:   poll.CreatedAt,
 		UpdatedAt:   poll.UpdatedAt,
+		Version:     poll.Version,
 		Options:     options,
-		Creator:     creator,
-	}, nil
+		Creator: dto.CreatorInfo{
+			ID:    poll.UserID,
+			Name:  poll.CreatorName,
+			Email: poll.CreatorEmail,
+		},
+	}
 }
 func (p *polling) GetPollingResult(ctx context.Context, pollID int64) (*dto.ResultPolling, error) {
 	vr, err := p.PollRepo.GetResultsByID(ctx, p.DB, pollID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, helper.NewAppError("NOT_FOUND", "polling not found", err)
+			return nil, domain.ErrPollNotFound
 		}
-		return nil, helper.NewAppError("DB_ERROR", "failed get votes", err)
+		return nil, helper.NewAppError(helper.CodeDBError, "failed get votes", err)
+	}
+	// Every poll has options, so no rows means there is no such poll.
+	if len(vr) == 0 {
+		return nil, domain.ErrPollNotFound
 	}
 
 	var totalVotes int64
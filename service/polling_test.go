@@ -4,16 +4,20 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"native-free-pollings/authz"
+	"native-free-pollings/domain"
 	"native-free-pollings/dto"
 	"native-free-pollings/helper"
 	"native-free-pollings/mocks"
 	"native-free-pollings/models"
+	"native-free-pollings/tracing"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type BundleMockPoll struct {
@@ -28,7 +32,7 @@ func TestPollingService_CreatePolling(t *testing.T) {
 		req        *dto.CreatePollingRequest
 		setupMocks func(repo *BundleMockPoll)
 		setupDB    func(db *sql.DB, mock sqlmock.Sqlmock)
-		wantErr    string
+		wantErr    helper.ErrorCode
 	}{
 		{
 			name:       "error begin tx",
@@ -41,7 +45,7 @@ func TestPollingService_CreatePolling(t *testing.T) {
 			name: "error create polling",
 			req:  &dto.CreatePollingRequest{Title: "test create poll", Description: "test description create poll", Options: []string{"Go"}},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("Create", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.Polling")).
+				repo.PollRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Polling")).
 					Return(errors.New("error create polling"))
 			},
 			setupDB: func(db *sql.DB, mock sqlmock.Sqlmock) {
@@ -53,10 +57,10 @@ func TestPollingService_CreatePolling(t *testing.T) {
 			name: "error create option",
 			req:  &dto.CreatePollingRequest{Title: "test create poll", Description: "test description create poll", Options: []string{"Go"}},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("Create", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.Polling")).
+				repo.PollRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Polling")).
 					Return(errors.New("error create polling"))
 
-				repo.OptRepo.On("Create", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.PollOption")).
+				repo.OptRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.PollOption")).
 					Return(errors.New("failed to save option"))
 			},
 			setupDB: func(db *sql.DB, mock sqlmock.Sqlmock) {
@@ -68,7 +72,7 @@ func TestPollingService_CreatePolling(t *testing.T) {
 			name: "error tx commit",
 			req:  &dto.CreatePollingRequest{Title: "test create poll", Description: "test description create poll", Options: []string{"Go"}},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("Create", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.Polling")).
+				repo.PollRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Polling")).
 					Run(func(args mock.Arguments) {
 						poll := args.Get(2).(*models.Polling)
 						poll.ID = 1
@@ -77,7 +81,7 @@ func TestPollingService_CreatePolling(t *testing.T) {
 					}).
 					Return(nil)
 
-				repo.OptRepo.On("Create", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.PollOption")).
+				repo.OptRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.PollOption")).
 					Return(nil)
 			},
 			setupDB: func(db *sql.DB, mock sqlmock.Sqlmock) {
@@ -90,7 +94,7 @@ func TestPollingService_CreatePolling(t *testing.T) {
 			name: "success",
 			req:  &dto.CreatePollingRequest{Title: "test create poll", Description: "test description create poll", Options: []string{"Go"}},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("Create", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.Polling")).
+				repo.PollRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Polling")).
 					Run(func(args mock.Arguments) {
 						poll := args.Get(2).(*models.Polling)
 						poll.ID = 1
@@ -99,7 +103,7 @@ func TestPollingService_CreatePolling(t *testing.T) {
 					}).
 					Return(nil)
 
-				repo.OptRepo.On("Create", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.PollOption")).
+				repo.OptRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.PollOption")).
 					Return(nil)
 			},
 			setupDB: func(db *sql.DB, mock sqlmock.Sqlmock) {
@@ -124,7 +128,7 @@ func TestPollingService_CreatePolling(t *testing.T) {
 
 			tt.setupMocks(bundleMock)
 
-			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo)
+			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo, nil)
 			creator := dto.CreatorInfo{ID: 1, Name: "user test", Email: "test@example.com"}
 
 			resp, err := svc.CreatePolling(context.Background(), tt.req, creator)
@@ -143,157 +147,100 @@ func TestPollingService_CreatePolling(t *testing.T) {
 }
 
 func TestPollingService_UpdatePolling(t *testing.T) {
+	start := time.Date(2025, 10, 26, 9, 0, 0, 0, time.UTC)
+	stored := func() *models.Polling {
+		return &models.Polling{
+			ID: 1, UserID: 1, Title: "Old title", Description: "Old description",
+			Status: "draft", StartsAt: start, EndsAt: start.Add(24 * time.Hour), Version: 3,
+		}
+	}
+	title := "New title"
+	tooEarly := start.Add(-time.Hour)
+
 	tests := []struct {
 		name       string
 		req        *dto.UpdatePollingRequest
-		creator    dto.CreatorInfo
+		actor      authz.Subject
 		setupMocks func(repo *BundleMockPoll)
 		setupDB    func(mock sqlmock.Sqlmock)
-		wantErr    string
+		wantErr    helper.ErrorCode
+		wantErrIs  error
+		wantPoll   *models.Polling
 	}{
 		{
-			name:    "error get polling",
-			req:     &dto.UpdatePollingRequest{},
-			creator: dto.CreatorInfo{ID: 1, Name: "test user", Email: "test@example.com"},
+			name:  "polling not found",
+			req:   &dto.UpdatePollingRequest{ID: 1},
+			actor: authz.Subject{UserID: 1, Role: authz.RoleUser},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return(nil, errors.New("failed get polling"))
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), int64(1)).
+					Return(nil, sql.ErrNoRows)
 			},
-			setupDB: func(mock sqlmock.Sqlmock) {},
-			wantErr: "INTERNAL_ERROR",
+			wantErrIs: domain.ErrPollNotFound,
 		},
 		{
-			name:    "error not creator polling",
-			req:     &dto.UpdatePollingRequest{ID: 1, Title: "Test title", Description: "Test description"},
-			creator: dto.CreatorInfo{ID: 1, Name: "test user", Email: "test@example.com"},
+			name:  "error get polling",
+			req:   &dto.UpdatePollingRequest{ID: 1},
+			actor: authz.Subject{UserID: 1, Role: authz.RoleUser},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return(&models.Polling{
-						ID:          1,
-						UserID:      2,
-						Title:       "Test title",
-						Description: "Test description",
-					}, nil)
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), int64(1)).
+					Return(nil, errors.New("failed get polling"))
 			},
-			setupDB: func(mock sqlmock.Sqlmock) {},
-			wantErr: "FORBIDDEN_ERROR",
+			wantErr: "INTERNAL_ERROR",
 		},
 		{
-			name:    "error get options",
-			req:     &dto.UpdatePollingRequest{ID: 1, Title: "Test title", Description: "Test description"},
-			creator: dto.CreatorInfo{ID: 1, Name: "test user", Email: "test@example.com"},
+			name:  "error not creator polling",
+			req:   &dto.UpdatePollingRequest{ID: 1, Title: &title},
+			actor: authz.Subject{UserID: 2, Role: authz.RoleUser},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return(&models.Polling{
-						ID:          1,
-						UserID:      1,
-						Title:       "Test title",
-						Description: "Test description",
-					}, nil)
-				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return(nil, errors.New("failed get options"))
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), int64(1)).
+					Return(stored(), nil)
 			},
-			setupDB: func(mock sqlmock.Sqlmock) {},
-			wantErr: "INTERNAL_ERROR",
+			wantErrIs: domain.ErrForbidden,
 		},
 		{
-			name:    "error begin tx",
-			req:     &dto.UpdatePollingRequest{ID: 1, Title: "Test title", Description: "Test description"},
-			creator: dto.CreatorInfo{ID: 1, Name: "test user", Email: "test@example.com"},
+			name:  "ends before stored start",
+			req:   &dto.UpdatePollingRequest{ID: 1, EndsAt: &tooEarly},
+			actor: authz.Subject{UserID: 1, Role: authz.RoleUser},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return(&models.Polling{
-						ID:          1,
-						UserID:      1,
-						Title:       "Test title",
-						Description: "Test description",
-					}, nil)
-				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return([]models.PollOption{
-						{ID: 1},
-						{ID: 2},
-						{ID: 3},
-					}, nil)
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), int64(1)).
+					Return(stored(), nil)
 			},
-			setupDB: func(mock sqlmock.Sqlmock) {},
-			wantErr: "INTERNAL_ERROR",
+			wantErr: "INVALID_INPUT",
 		},
 		{
-			name:    "error update polling",
-			req:     &dto.UpdatePollingRequest{ID: 1, Title: "Test title", Description: "Test description"},
-			creator: dto.CreatorInfo{ID: 1, Name: "test user", Email: "test@example.com"},
+			name:  "stale if-match version",
+			req:   &dto.UpdatePollingRequest{ID: 1, Version: 2, Title: &title},
+			actor: authz.Subject{UserID: 1, Role: authz.RoleUser},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return(&models.Polling{
-						ID:          1,
-						UserID:      1,
-						Title:       "Test title",
-						Description: "Test description",
-					}, nil)
-				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return([]models.PollOption{
-						{ID: 1},
-						{ID: 2},
-						{ID: 3},
-					}, nil)
-				repo.PollRepo.On("Update", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.Polling")).
-					Return(errors.New("failed update polling"))
-			},
-			setupDB: func(mock sqlmock.Sqlmock) {
-				mock.ExpectBegin()
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), int64(1)).
+					Return(stored(), nil)
 			},
-			wantErr: "DB_ERROR",
+			wantErrIs: domain.ErrVersionMismatch,
 		},
 		{
-			name:    "error update option",
-			req:     &dto.UpdatePollingRequest{ID: 1, Title: "Test title", Description: "Test description", Options: []dto.Option{{ID: 1}}},
-			creator: dto.CreatorInfo{ID: 1, Name: "test user", Email: "test@example.com"},
+			name:  "changed concurrently",
+			req:   &dto.UpdatePollingRequest{ID: 1, Version: 3, Title: &title},
+			actor: authz.Subject{UserID: 1, Role: authz.RoleUser},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return(&models.Polling{
-						ID:          1,
-						UserID:      1,
-						Title:       "Test title",
-						Description: "Test description",
-					}, nil)
-				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return([]models.PollOption{
-						{ID: 1},
-						{ID: 2},
-						{ID: 3},
-					}, nil)
-				repo.PollRepo.On("Update", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.Polling")).
-					Return(nil)
-				repo.OptRepo.On("Update", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.PollOption")).
-					Return(errors.New("failed update option"))
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), int64(1)).
+					Return(stored(), nil)
+				repo.PollRepo.On("Update", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Polling")).
+					Return(sql.ErrNoRows)
 			},
 			setupDB: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
 			},
-			wantErr: "DB_ERROR",
+			wantErrIs: domain.ErrVersionMismatch,
 		},
 		{
-			name:    "error create option",
-			req:     &dto.UpdatePollingRequest{ID: 1, Title: "Test title", Description: "Test description", Options: []dto.Option{{ID: 4}}},
-			creator: dto.CreatorInfo{ID: 1, Name: "test user", Email: "test@example.com"},
+			name:  "error update polling",
+			req:   &dto.UpdatePollingRequest{ID: 1, Title: &title},
+			actor: authz.Subject{UserID: 1, Role: authz.RoleUser},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return(&models.Polling{
-						ID:          1,
-						UserID:      1,
-						Title:       "Test title",
-						Description: "Test description",
-					}, nil)
-				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return([]models.PollOption{
-						{ID: 1},
-						{ID: 2},
-						{ID: 3},
-					}, nil)
-				repo.PollRepo.On("Update", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.Polling")).
-					Return(nil)
-				repo.OptRepo.On("Create", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.PollOption")).
-					Return(errors.New("failed create option"))
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), int64(1)).
+					Return(stored(), nil)
+				repo.PollRepo.On("Update", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Polling")).
+					Return(errors.New("failed update polling"))
 			},
 			setupDB: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
@@ -301,27 +248,16 @@ func TestPollingService_UpdatePolling(t *testing.T) {
 			wantErr: "DB_ERROR",
 		},
 		{
-			name:    "error delete option",
-			req:     &dto.UpdatePollingRequest{ID: 1, Title: "Test title", Description: "Test description"},
-			creator: dto.CreatorInfo{ID: 1, Name: "test user", Email: "test@example.com"},
+			name:  "error get options",
+			req:   &dto.UpdatePollingRequest{ID: 1, Title: &title},
+			actor: authz.Subject{UserID: 1, Role: authz.RoleUser},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return(&models.Polling{
-						ID:          1,
-						UserID:      1,
-						Title:       "Test title",
-						Description: "Test description",
-					}, nil)
-				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return([]models.PollOption{
-						{ID: 1},
-						{ID: 2},
-						{ID: 3},
-					}, nil)
-				repo.PollRepo.On("Update", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.Polling")).
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), int64(1)).
+					Return(stored(), nil)
+				repo.PollRepo.On("Update", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Polling")).
 					Return(nil)
-				repo.OptRepo.On("Delete", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("int64")).
-					Return(errors.New("failed delete option"))
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).
+					Return(nil, errors.New("failed get options"))
 			},
 			setupDB: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
@@ -329,21 +265,16 @@ func TestPollingService_UpdatePolling(t *testing.T) {
 			wantErr: "DB_ERROR",
 		},
 		{
-			name:    "error commit tx",
-			req:     &dto.UpdatePollingRequest{ID: 1, Title: "Test title", Description: "Test description"},
-			creator: dto.CreatorInfo{ID: 1, Name: "test user", Email: "test@example.com"},
+			name:  "error tx commit",
+			req:   &dto.UpdatePollingRequest{ID: 1, Title: &title},
+			actor: authz.Subject{UserID: 1, Role: authz.RoleUser},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return(&models.Polling{
-						ID:          1,
-						UserID:      1,
-						Title:       "Test title",
-						Description: "Test description",
-					}, nil)
-				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return([]models.PollOption{}, nil)
-				repo.PollRepo.On("Update", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.Polling")).
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), int64(1)).
+					Return(stored(), nil)
+				repo.PollRepo.On("Update", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Polling")).
 					Return(nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).
+					Return([]models.PollOption{}, nil)
 			},
 			setupDB: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
@@ -352,36 +283,32 @@ func TestPollingService_UpdatePolling(t *testing.T) {
 			wantErr: "INTERNAL_ERROR",
 		},
 		{
-			name:    "success",
-			req:     &dto.UpdatePollingRequest{ID: 1, Title: "Test title", Description: "Test description"},
-			creator: dto.CreatorInfo{ID: 1, Name: "test user", Email: "test@example.com"},
+			name:  "success keeps omitted fields and options",
+			req:   &dto.UpdatePollingRequest{ID: 1, Title: &title},
+			actor: authz.Subject{UserID: 1, Role: authz.RoleUser},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return(&models.Polling{
-						ID:          1,
-						UserID:      1,
-						Title:       "Test title",
-						Description: "Test description",
-					}, nil)
-				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return([]models.PollOption{}, nil)
-				repo.PollRepo.On("Update", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.Polling")).
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), int64(1)).
+					Return(stored(), nil)
+				repo.PollRepo.On("Update", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Polling")).
 					Return(nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).
+					Return([]models.PollOption{{ID: 1, Label: "Go", Position: 1}, {ID: 2, Label: "Rust", Position: 2}}, nil)
 			},
 			setupDB: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
 				mock.ExpectCommit()
 			},
-			wantErr: "",
+			wantPoll: func() *models.Polling { p := stored(); p.Title = title; return p }(),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			db, mock, _ := sqlmock.New()
+			db, dbMock, _ := sqlmock.New()
 			defer db.Close()
-
-			tt.setupDB(mock)
+			if tt.setupDB != nil {
+				tt.setupDB(dbMock)
+			}
 
 			bundleMock := &BundleMockPoll{
 				PollRepo: new(mocks.PollRepositoryMock),
@@ -391,19 +318,27 @@ func TestPollingService_UpdatePolling(t *testing.T) {
 
 			tt.setupMocks(bundleMock)
 
-			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo)
+			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo, nil)
 
-			resp, err := svc.UpdatePolling(context.Background(), tt.req, tt.creator)
+			resp, err := svc.UpdatePolling(context.Background(), tt.req, tt.actor)
 
-			if tt.wantErr != "" {
-				assert.NotNil(t, err)
+			if tt.wantErrIs != nil {
+				assert.ErrorIs(t, err, tt.wantErrIs)
+				assert.Nil(t, resp)
+			} else if tt.wantErr != "" {
+				var appErr *helper.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tt.wantErr, appErr.Code)
 				assert.Nil(t, resp)
-				assert.Equal(t, tt.wantErr, err.(*helper.AppError).Code)
 			} else {
 				assert.NoError(t, err)
-				assert.NotNil(t, resp)
-				assert.Equal(t, tt.req.ID, resp.ID)
+				assert.Equal(t, tt.wantPoll.Title, resp.Title)
+				assert.Equal(t, tt.wantPoll.Description, resp.Description)
+				assert.Equal(t, tt.wantPoll.EndsAt, resp.EndsAt)
+				assert.Len(t, resp.Options, 2)
+				bundleMock.PollRepo.AssertCalled(t, "Update", mock.Anything, mock.Anything, tt.wantPoll)
 			}
+			bundleMock.OptRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
 		})
 	}
 }
@@ -416,7 +351,8 @@ func TestPollingService_VoteOptionPolling(t *testing.T) {
 		optionID   int64
 		setupMocks func(repo *BundleMockPoll)
 		setupDB    func(mock sqlmock.Sqlmock)
-		wantErr    string
+		wantErr    helper.ErrorCode
+		wantErrIs  error
 	}{
 		{
 			name:     "error has user voted",
@@ -424,7 +360,7 @@ func TestPollingService_VoteOptionPolling(t *testing.T) {
 			pollID:   1,
 			optionID: 1,
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.VoteRepo.On("HasUserVoted", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
+				repo.VoteRepo.On("HasUserVoted", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
 					Return(false, errors.New("failed get user voted"))
 			},
 			setupDB: func(mock sqlmock.Sqlmock) {},
@@ -436,11 +372,11 @@ func TestPollingService_VoteOptionPolling(t *testing.T) {
 			pollID:   1,
 			optionID: 1,
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.VoteRepo.On("HasUserVoted", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
+				repo.VoteRepo.On("HasUserVoted", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
 					Return(true, nil)
 			},
-			setupDB: func(mock sqlmock.Sqlmock) {},
-			wantErr: "ALREADY_VOTED",
+			setupDB:   func(mock sqlmock.Sqlmock) {},
+			wantErrIs: domain.ErrAlreadyVoted,
 		},
 		{
 			name:     "error has device voted",
@@ -448,7 +384,7 @@ func TestPollingService_VoteOptionPolling(t *testing.T) {
 			pollID:   1,
 			optionID: 1,
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.VoteRepo.On("HasDeviceVoted", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
+				repo.VoteRepo.On("HasDeviceVoted", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
 					Return(false, errors.New("failed get device voted"))
 			},
 			setupDB: func(mock sqlmock.Sqlmock) {},
@@ -460,11 +396,11 @@ func TestPollingService_VoteOptionPolling(t *testing.T) {
 			pollID:   1,
 			optionID: 1,
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.VoteRepo.On("HasDeviceVoted", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
+				repo.VoteRepo.On("HasDeviceVoted", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
 					Return(true, nil)
 			},
-			setupDB: func(mock sqlmock.Sqlmock) {},
-			wantErr: "ALREADY_VOTED",
+			setupDB:   func(mock sqlmock.Sqlmock) {},
+			wantErrIs: domain.ErrAlreadyVoted,
 		},
 		{
 			name:     "error begin tx",
@@ -472,7 +408,7 @@ func TestPollingService_VoteOptionPolling(t *testing.T) {
 			pollID:   1,
 			optionID: 1,
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.VoteRepo.On("HasDeviceVoted", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
+				repo.VoteRepo.On("HasDeviceVoted", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
 					Return(false, nil)
 			},
 			setupDB: func(mock sqlmock.Sqlmock) {},
@@ -484,9 +420,9 @@ func TestPollingService_VoteOptionPolling(t *testing.T) {
 			pollID:   1,
 			optionID: 1,
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.VoteRepo.On("HasDeviceVoted", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
+				repo.VoteRepo.On("HasDeviceVoted", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
 					Return(false, nil)
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("GetByIDForShare", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("int64")).
 					Return(nil, errors.New("failed get polling"))
 			},
 			setupDB: func(mock sqlmock.Sqlmock) {
@@ -500,9 +436,9 @@ func TestPollingService_VoteOptionPolling(t *testing.T) {
 			pollID:   1,
 			optionID: 1,
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.VoteRepo.On("HasDeviceVoted", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
+				repo.VoteRepo.On("HasDeviceVoted", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
 					Return(false, nil)
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("GetByIDForShare", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("int64")).
 					Return(&models.Polling{
 						Status: "draft",
 					}, nil)
@@ -512,19 +448,42 @@ func TestPollingService_VoteOptionPolling(t *testing.T) {
 			},
 			wantErr: "BAD_REQUEST",
 		},
+		{
+			name:     "error option of another polling",
+			userID:   0,
+			pollID:   1,
+			optionID: 7,
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.VoteRepo.On("HasDeviceVoted", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
+					Return(false, nil)
+				repo.PollRepo.On("GetByIDForShare", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("int64")).
+					Return(&models.Polling{
+						Status: "active",
+					}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).
+					Return([]models.PollOption{{ID: 1, PollID: 1}}, nil)
+			},
+			setupDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			wantErrIs: domain.ErrOptionNotFound,
+		},
 		{
 			name:     "error create vote",
 			userID:   0,
 			pollID:   1,
 			optionID: 1,
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.VoteRepo.On("HasDeviceVoted", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
+				repo.VoteRepo.On("HasDeviceVoted", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
 					Return(false, nil)
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("GetByIDForShare", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("int64")).
 					Return(&models.Polling{
 						Status: "active",
 					}, nil)
-				repo.VoteRepo.On("Create", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.Vote")).
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).
+					Return([]models.PollOption{{ID: 1, PollID: 1}}, nil)
+				repo.VoteRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Vote")).
 					Return(errors.New("failed create vote"))
 			},
 			setupDB: func(mock sqlmock.Sqlmock) {
@@ -538,15 +497,17 @@ func TestPollingService_VoteOptionPolling(t *testing.T) {
 			pollID:   1,
 			optionID: 1,
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.VoteRepo.On("HasUserVoted", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
+				repo.VoteRepo.On("HasUserVoted", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
 					Return(false, nil)
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("GetByIDForShare", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("int64")).
 					Return(&models.Polling{
 						Status: "active",
 					}, nil)
-				repo.VoteRepo.On("Create", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.Vote")).
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).
+					Return([]models.PollOption{{ID: 1, PollID: 1}}, nil)
+				repo.VoteRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Vote")).
 					Return(nil)
-				repo.VoteRepo.On("CreateUserVote", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
+				repo.VoteRepo.On("CreateUserVote", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
 					Return(errors.New("failed create user vote"))
 			},
 			setupDB: func(mock sqlmock.Sqlmock) {
@@ -554,21 +515,52 @@ func TestPollingService_VoteOptionPolling(t *testing.T) {
 			},
 			wantErr: "DB_ERROR",
 		},
+		{
+			name:     "error notify results",
+			userID:   1,
+			pollID:   1,
+			optionID: 1,
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.VoteRepo.On("HasUserVoted", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
+					Return(false, nil)
+				repo.PollRepo.On("GetByIDForShare", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("int64")).
+					Return(&models.Polling{
+						Status: "active",
+					}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).
+					Return([]models.PollOption{{ID: 1, PollID: 1}}, nil)
+				repo.VoteRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Vote")).
+					Return(nil)
+				repo.VoteRepo.On("CreateUserVote", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
+					Return(nil)
+				repo.PollRepo.On("NotifyResults", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).
+					Return(errors.New("failed notify"))
+			},
+			setupDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			wantErr: "DB_ERROR",
+		},
 		{
 			name:     "error commit tx",
 			userID:   1,
 			pollID:   1,
 			optionID: 1,
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.VoteRepo.On("HasUserVoted", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
+				repo.VoteRepo.On("HasUserVoted", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
 					Return(false, nil)
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("GetByIDForShare", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("int64")).
 					Return(&models.Polling{
 						Status: "active",
 					}, nil)
-				repo.VoteRepo.On("Create", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.Vote")).
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).
+					Return([]models.PollOption{{ID: 1, PollID: 1}}, nil)
+				repo.VoteRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Vote")).
 					Return(nil)
-				repo.VoteRepo.On("CreateUserVote", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
+				repo.VoteRepo.On("CreateUserVote", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
+					Return(nil)
+				repo.PollRepo.On("NotifyResults", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).
 					Return(nil)
 			},
 			setupDB: func(mock sqlmock.Sqlmock) {
@@ -583,15 +575,19 @@ func TestPollingService_VoteOptionPolling(t *testing.T) {
 			pollID:   1,
 			optionID: 1,
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.VoteRepo.On("HasUserVoted", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
+				repo.VoteRepo.On("HasUserVoted", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
 					Return(false, nil)
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("GetByIDForShare", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("int64")).
 					Return(&models.Polling{
 						Status: "active",
 					}, nil)
-				repo.VoteRepo.On("Create", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("*models.Vote")).
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).
+					Return([]models.PollOption{{ID: 1, PollID: 1}}, nil)
+				repo.VoteRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Vote")).
+					Return(nil)
+				repo.VoteRepo.On("CreateUserVote", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
 					Return(nil)
-				repo.VoteRepo.On("CreateUserVote", mock.Anything, mock.IsType(&sql.Tx{}), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("NotifyResults", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).
 					Return(nil)
 			},
 			setupDB: func(mock sqlmock.Sqlmock) {
@@ -617,11 +613,13 @@ func TestPollingService_VoteOptionPolling(t *testing.T) {
 
 			tt.setupMocks(bundleMock)
 
-			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo)
+			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo, nil)
 
 			err := svc.VoteOptionPolling(context.Background(), tt.userID, tt.pollID, tt.optionID, "example")
 
-			if tt.wantErr != "" {
+			if tt.wantErrIs != nil {
+				assert.ErrorIs(t, err, tt.wantErrIs)
+			} else if tt.wantErr != "" {
 				assert.NotNil(t, err)
 				assert.Equal(t, tt.wantErr, err.(*helper.AppError).Code)
 			} else {
@@ -634,51 +632,111 @@ func TestPollingService_VoteOptionPolling(t *testing.T) {
 func TestPollingService_DeletePolling(t *testing.T) {
 	tests := []struct {
 		name       string
+		version    int64
+		actor      authz.Subject
 		setupMocks func(repo *BundleMockPoll)
-		wantErr    string
+		setupDB    func(mock sqlmock.Sqlmock)
+		wantErr    helper.ErrorCode
+		wantErrIs  error
 	}{
 		{
-			name: "error get polling",
+			name:  "error get polling",
+			actor: authz.Subject{UserID: 1, Role: authz.RoleUser},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
 					Return(nil, errors.New("failed get polling"))
 			},
 			wantErr: "INTERNAL_ERROR",
 		},
 		{
-			name: "error not creator",
+			name:  "error not creator",
+			actor: authz.Subject{UserID: 1, Role: authz.RoleUser},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
 					Return(&models.Polling{ID: 1, UserID: 0}, nil)
 			},
-			wantErr: "FORBIDDEN_ERROR",
+			wantErrIs: domain.ErrForbidden,
+		},
+		{
+			name:  "moderator deletes other user's polling",
+			actor: authz.Subject{UserID: 2, Role: authz.RoleModerator},
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
+					Return(&models.Polling{ID: 1, UserID: 1, Version: 3}, nil)
+				repo.PollRepo.On("Delete", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1), int64(3)).
+					Return(nil)
+			},
+			setupDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+			},
+			wantErr: "",
 		},
 		{
-			name: "error delete polling",
+			name:  "error delete polling",
+			actor: authz.Subject{UserID: 1, Role: authz.RoleUser},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return(&models.Polling{ID: 1, UserID: 1}, nil)
-				repo.PollRepo.On("Delete", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
+					Return(&models.Polling{ID: 1, UserID: 1, Version: 3}, nil)
+				repo.PollRepo.On("Delete", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1), int64(3)).
 					Return(errors.New("failed delete polling"))
 			},
+			setupDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
 			wantErr: "DB_ERROR",
 		},
 		{
-			name: "success",
+			name:    "stale if-match version",
+			version: 2,
+			actor:   authz.Subject{UserID: 1, Role: authz.RoleUser},
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
+					Return(&models.Polling{ID: 1, UserID: 1, Version: 3}, nil)
+			},
+			wantErrIs: domain.ErrVersionMismatch,
+		},
+		{
+			name:    "changed concurrently",
+			version: 3,
+			actor:   authz.Subject{UserID: 1, Role: authz.RoleUser},
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
+					Return(&models.Polling{ID: 1, UserID: 1, Version: 3}, nil)
+				repo.PollRepo.On("Delete", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1), int64(3)).
+					Return(sql.ErrNoRows)
+			},
+			setupDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			wantErrIs: domain.ErrVersionMismatch,
+		},
+		{
+			name:  "success",
+			actor: authz.Subject{UserID: 1, Role: authz.RoleUser},
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
-					Return(&models.Polling{ID: 1, UserID: 1}, nil)
-				repo.PollRepo.On("Delete", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
+					Return(&models.Polling{ID: 1, UserID: 1, Version: 3}, nil)
+				repo.PollRepo.On("Delete", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1), int64(3)).
 					Return(nil)
 			},
+			setupDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+			},
 			wantErr: "",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			db, _, _ := sqlmock.New()
+			db, dbMock, _ := sqlmock.New()
 			defer db.Close()
+			if tt.setupDB != nil {
+				tt.setupDB(dbMock)
+			}
 
 			bundleMock := &BundleMockPoll{
 				PollRepo: new(mocks.PollRepositoryMock),
@@ -688,16 +746,19 @@ func TestPollingService_DeletePolling(t *testing.T) {
 
 			tt.setupMocks(bundleMock)
 
-			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo)
+			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo, nil)
 
-			err := svc.DeletePolling(context.Background(), 1, 1)
+			err := svc.DeletePolling(context.Background(), 1, tt.version, tt.actor)
 
-			if tt.wantErr != "" {
+			if tt.wantErrIs != nil {
+				assert.ErrorIs(t, err, tt.wantErrIs)
+			} else if tt.wantErr != "" {
 				assert.NotNil(t, err)
 				assert.Equal(t, tt.wantErr, err.(*helper.AppError).Code)
 			} else {
 				assert.NoError(t, err)
 			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
 		})
 	}
 }
@@ -706,12 +767,21 @@ func TestPollingService_GetDetailPolling(t *testing.T) {
 	tests := []struct {
 		name       string
 		setupMocks func(repo *BundleMockPoll)
-		wantErr    string
+		wantErr    helper.ErrorCode
+		wantErrIs  error
 	}{
+		{
+			name: "polling not found",
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
+					Return(nil, sql.ErrNoRows)
+			},
+			wantErrIs: domain.ErrPollNotFound,
+		},
 		{
 			name: "error get polling",
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
 					Return(nil, errors.New("failed get polling"))
 			},
 			wantErr: "DB_ERROR",
@@ -719,9 +789,9 @@ func TestPollingService_GetDetailPolling(t *testing.T) {
 		{
 			name: "error get options",
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
 					Return(&models.Polling{ID: 1}, nil)
-				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
 					Return(nil, errors.New("failed get options"))
 			},
 			wantErr: "DB_ERROR",
@@ -729,9 +799,9 @@ func TestPollingService_GetDetailPolling(t *testing.T) {
 		{
 			name: "success",
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
 					Return(&models.Polling{ID: 1}, nil)
-				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
 					Return([]models.PollOption{{}}, nil)
 			},
 			wantErr: "",
@@ -751,11 +821,14 @@ func TestPollingService_GetDetailPolling(t *testing.T) {
 
 			tt.setupMocks(bundleMock)
 
-			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo)
+			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo, nil)
 
 			resp, err := svc.GetDetailPolling(context.Background(), 1)
 
-			if tt.wantErr != "" {
+			if tt.wantErrIs != nil {
+				assert.ErrorIs(t, err, tt.wantErrIs)
+				assert.Nil(t, resp)
+			} else if tt.wantErr != "" {
 				assert.NotNil(t, err)
 				assert.Nil(t, resp)
 				assert.Equal(t, tt.wantErr, err.(*helper.AppError).Code)
@@ -770,20 +843,29 @@ func TestPollingService_GetPollingResult(t *testing.T) {
 	tests := []struct {
 		name       string
 		setupMocks func(repo *BundleMockPoll)
-		wantErr    string
+		wantErr    helper.ErrorCode
+		wantErrIs  error
 	}{
 		{
 			name: "error get polling result",
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetResultsByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("GetResultsByID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
 					Return(nil, errors.New("failed get polling"))
 			},
 			wantErr: "DB_ERROR",
 		},
+		{
+			name: "polling not found",
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetResultsByID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
+					Return([]models.VoteResult(nil), nil)
+			},
+			wantErrIs: domain.ErrPollNotFound,
+		},
 		{
 			name: "success",
 			setupMocks: func(repo *BundleMockPoll) {
-				repo.PollRepo.On("GetResultsByID", mock.Anything, mock.IsType(&sql.DB{}), mock.AnythingOfType("int64")).
+				repo.PollRepo.On("GetResultsByID", mock.Anything, mock.IsType(&tracing.DB{}), mock.AnythingOfType("int64")).
 					Return([]models.VoteResult{
 						{OptionID: 1, OptionLabel: "go", Votes: 10},
 						{OptionID: 2, OptionLabel: "go", Votes: 10},
@@ -807,11 +889,14 @@ func TestPollingService_GetPollingResult(t *testing.T) {
 
 			tt.setupMocks(bundleMock)
 
-			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo)
+			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo, nil)
 
 			resp, err := svc.GetPollingResult(context.Background(), 1)
 
-			if tt.wantErr != "" {
+			if tt.wantErrIs != nil {
+				assert.ErrorIs(t, err, tt.wantErrIs)
+				assert.Nil(t, resp)
+			} else if tt.wantErr != "" {
 				assert.NotNil(t, err)
 				assert.Nil(t, resp)
 				assert.Equal(t, tt.wantErr, err.(*helper.AppError).Code)
@@ -822,3 +907,602 @@ func TestPollingService_GetPollingResult(t *testing.T) {
 		})
 	}
 }
+
+func TestPollingService_AddOption(t *testing.T) {
+	owner := authz.Subject{UserID: 1, Role: authz.RoleUser}
+	existing := []models.PollOption{{ID: 1, Label: "Go", Position: 1}, {ID: 2, Label: "Rust", Position: 3}}
+
+	tests := []struct {
+		name        string
+		label       string
+		version     int64
+		actor       authz.Subject
+		setupMocks  func(repo *BundleMockPoll)
+		wantErr     helper.ErrorCode
+		wantErrIs   error
+		wantOption  *dto.Option
+		wantVersion int64
+	}{
+		{
+			name:  "error not creator",
+			label: "Zig",
+			actor: authz.Subject{UserID: 2, Role: authz.RoleUser},
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+			},
+			wantErrIs: domain.ErrForbidden,
+		},
+		{
+			name:    "stale version",
+			label:   "Zig",
+			version: 2,
+			actor:   owner,
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1, Version: 3}, nil)
+			},
+			wantErrIs: domain.ErrVersionMismatch,
+		},
+		{
+			name:  "duplicate label",
+			label: " go ",
+			actor: owner,
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing, nil)
+			},
+			wantErr: "INVALID_INPUT",
+		},
+		{
+			name:  "too many options",
+			label: "Zig",
+			actor: owner,
+			setupMocks: func(repo *BundleMockPoll) {
+				full := make([]models.PollOption, helper.PollLimits().MaxOptions)
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(full, nil)
+			},
+			wantErr: "INVALID_INPUT",
+		},
+		{
+			name:    "appended after last position",
+			label:   "Zig",
+			version: 3,
+			actor:   owner,
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1, Version: 3}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing, nil)
+				repo.OptRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), &models.PollOption{PollID: 1, Label: "Zig", Position: 4}).
+					Run(func(args mock.Arguments) { args.Get(2).(*models.PollOption).ID = 9 }).
+					Return(nil)
+				repo.PollRepo.On("Touch", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil)
+				repo.PollRepo.On("NotifyResults", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil)
+			},
+			wantOption:  &dto.Option{ID: 9, Label: "Zig", Position: 4},
+			wantVersion: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+			dbMock.ExpectBegin()
+			if tt.wantOption != nil {
+				dbMock.ExpectCommit()
+			} else {
+				dbMock.ExpectRollback()
+			}
+
+			bundleMock := &BundleMockPoll{
+				PollRepo: new(mocks.PollRepositoryMock),
+				OptRepo:  new(mocks.OptionRepositoryMock),
+				VoteRepo: new(mocks.VoteRepostoryMock),
+			}
+			tt.setupMocks(bundleMock)
+			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo, nil)
+
+			resp, version, err := svc.AddOption(context.Background(), 1, tt.version, &dto.CreateOptionRequest{Label: tt.label}, tt.actor)
+
+			assertPollErr(t, err, tt.wantErr, tt.wantErrIs)
+			assert.Equal(t, tt.wantOption, resp)
+			assert.Equal(t, tt.wantVersion, version)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPollingService_RenameOption(t *testing.T) {
+	owner := authz.Subject{UserID: 1, Role: authz.RoleUser}
+	existing := func() []models.PollOption {
+		return []models.PollOption{{ID: 1, PollID: 1, Label: "Go", Position: 1}, {ID: 2, PollID: 1, Label: "Rust", Position: 2}}
+	}
+
+	tests := []struct {
+		name       string
+		optionID   int64
+		label      string
+		setupMocks func(repo *BundleMockPoll)
+		wantErr    helper.ErrorCode
+		wantErrIs  error
+		wantOption *dto.Option
+	}{
+		{
+			name:     "option of another polling",
+			optionID: 7,
+			label:    "Zig",
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing(), nil)
+			},
+			wantErrIs: domain.ErrOptionNotFound,
+		},
+		{
+			name:     "label used by another option",
+			optionID: 1,
+			label:    "RUST",
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing(), nil)
+			},
+			wantErr: "INVALID_INPUT",
+		},
+		{
+			name:     "error bump polling version",
+			optionID: 1,
+			label:    "Golang",
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing(), nil)
+				repo.OptRepo.On("Update", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.PollOption")).Return(nil)
+				repo.PollRepo.On("Touch", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(errors.New("failed touch"))
+			},
+			wantErr: "DB_ERROR",
+		},
+		{
+			name:     "change case of own label",
+			optionID: 1,
+			label:    "GO",
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing(), nil)
+				repo.OptRepo.On("Update", mock.Anything, mock.IsType(&tracing.Tx{}), &models.PollOption{ID: 1, PollID: 1, Label: "GO", Position: 1}).Return(nil)
+				repo.PollRepo.On("Touch", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil)
+				repo.PollRepo.On("NotifyResults", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil)
+			},
+			wantOption: &dto.Option{ID: 1, Label: "GO", Position: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+			dbMock.ExpectBegin()
+			if tt.wantOption != nil {
+				dbMock.ExpectCommit()
+			} else {
+				dbMock.ExpectRollback()
+			}
+
+			bundleMock := &BundleMockPoll{
+				PollRepo: new(mocks.PollRepositoryMock),
+				OptRepo:  new(mocks.OptionRepositoryMock),
+				VoteRepo: new(mocks.VoteRepostoryMock),
+			}
+			tt.setupMocks(bundleMock)
+			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo, nil)
+
+			resp, version, err := svc.RenameOption(context.Background(), 1, 0, tt.optionID, &dto.RenameOptionRequest{Label: tt.label}, owner)
+
+			assertPollErr(t, err, tt.wantErr, tt.wantErrIs)
+			assert.Equal(t, tt.wantOption, resp)
+			if tt.wantOption != nil {
+				assert.Equal(t, int64(1), version)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPollingService_ReorderOptions(t *testing.T) {
+	owner := authz.Subject{UserID: 1, Role: authz.RoleUser}
+	existing := []models.PollOption{{ID: 1, Label: "Go", Position: 1}, {ID: 2, Label: "Rust", Position: 2}}
+
+	tests := []struct {
+		name        string
+		ids         []int64
+		setupMocks  func(repo *BundleMockPoll)
+		setupDB     func(mock sqlmock.Sqlmock)
+		wantErr     helper.ErrorCode
+		wantErrIs   error
+		wantOptions []dto.Option
+	}{
+		{
+			name: "missing option",
+			ids:  []int64{2},
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing, nil)
+			},
+			setupDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			wantErr: "INVALID_INPUT",
+		},
+		{
+			name: "unknown option",
+			ids:  []int64{2, 3},
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing, nil)
+			},
+			setupDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			wantErrIs: domain.ErrOptionNotFound,
+		},
+		{
+			name: "error reorder",
+			ids:  []int64{2, 1},
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing, nil)
+				repo.OptRepo.On("Reorder", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1), []int64{2, 1}).Return(errors.New("failed reorder"))
+			},
+			setupDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			wantErr: "DB_ERROR",
+		},
+		{
+			name: "success",
+			ids:  []int64{2, 1},
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing, nil)
+				repo.OptRepo.On("Reorder", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1), []int64{2, 1}).Return(nil)
+				repo.PollRepo.On("Touch", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil)
+				repo.PollRepo.On("NotifyResults", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil)
+			},
+			setupDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+			},
+			wantOptions: []dto.Option{{ID: 2, Label: "Rust", Position: 1}, {ID: 1, Label: "Go", Position: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+			tt.setupDB(dbMock)
+
+			bundleMock := &BundleMockPoll{
+				PollRepo: new(mocks.PollRepositoryMock),
+				OptRepo:  new(mocks.OptionRepositoryMock),
+				VoteRepo: new(mocks.VoteRepostoryMock),
+			}
+			tt.setupMocks(bundleMock)
+			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo, nil)
+
+			resp, version, err := svc.ReorderOptions(context.Background(), 1, 0, &dto.ReorderOptionsRequest{OptionIDs: tt.ids}, owner)
+
+			assertPollErr(t, err, tt.wantErr, tt.wantErrIs)
+			assert.Equal(t, tt.wantOptions, resp)
+			if tt.wantOptions != nil {
+				assert.Equal(t, int64(1), version)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPollingService_DeleteOption(t *testing.T) {
+	owner := authz.Subject{UserID: 1, Role: authz.RoleUser}
+	existing := []models.PollOption{{ID: 1, Label: "Go"}, {ID: 2, Label: "Rust"}, {ID: 3, Label: "Zig"}}
+
+	tests := []struct {
+		name       string
+		optionID   int64
+		force      bool
+		setupMocks func(repo *BundleMockPoll)
+		wantErr    helper.ErrorCode
+		wantErrIs  error
+	}{
+		{
+			name:     "polling not found",
+			optionID: 1,
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil, sql.ErrNoRows)
+			},
+			wantErrIs: domain.ErrPollNotFound,
+		},
+		{
+			name:     "option not found",
+			optionID: 9,
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing, nil)
+			},
+			wantErrIs: domain.ErrOptionNotFound,
+		},
+		{
+			name:     "too few options left",
+			optionID: 1,
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing[:2], nil)
+			},
+			wantErr: "INVALID_INPUT",
+		},
+		{
+			name:     "option has votes",
+			optionID: 1,
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing, nil)
+				repo.VoteRepo.On("CountByOptionID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(int64(3), nil)
+			},
+			wantErrIs: domain.ErrOptionHasVotes,
+		},
+		{
+			name:     "without votes",
+			optionID: 1,
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing, nil)
+				repo.VoteRepo.On("CountByOptionID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(int64(0), nil)
+				repo.OptRepo.On("Delete", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil)
+				repo.PollRepo.On("Touch", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil)
+				repo.PollRepo.On("NotifyResults", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil)
+			},
+		},
+		{
+			name:     "forced with votes",
+			optionID: 1,
+			force:    true,
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing, nil)
+				repo.OptRepo.On("Delete", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil)
+				repo.PollRepo.On("Touch", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil)
+				repo.PollRepo.On("NotifyResults", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil)
+			},
+		},
+		{
+			name:     "error delete option",
+			optionID: 1,
+			force:    true,
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(&models.Polling{ID: 1, UserID: 1}, nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(existing, nil)
+				repo.OptRepo.On("Delete", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(errors.New("failed delete option"))
+			},
+			wantErr: "DB_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+			dbMock.ExpectBegin()
+			if tt.wantErr == "" && tt.wantErrIs == nil {
+				dbMock.ExpectCommit()
+			} else {
+				dbMock.ExpectRollback()
+			}
+
+			bundleMock := &BundleMockPoll{
+				PollRepo: new(mocks.PollRepositoryMock),
+				OptRepo:  new(mocks.OptionRepositoryMock),
+				VoteRepo: new(mocks.VoteRepostoryMock),
+			}
+			tt.setupMocks(bundleMock)
+			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo, nil)
+
+			version, err := svc.DeleteOption(context.Background(), 1, 0, tt.optionID, tt.force, owner)
+
+			assertPollErr(t, err, tt.wantErr, tt.wantErrIs)
+			if tt.wantErr == "" && tt.wantErrIs == nil {
+				assert.Equal(t, int64(1), version)
+			}
+			bundleMock.OptRepo.AssertExpectations(t)
+			bundleMock.VoteRepo.AssertExpectations(t)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+// assertPollErr checks err against a sentinel, an AppError code, or success
+// when neither is given.
+func assertPollErr(t *testing.T, err error, wantCode helper.ErrorCode, wantIs error) {
+	t.Helper()
+	switch {
+	case wantIs != nil:
+		assert.ErrorIs(t, err, wantIs)
+	case wantCode != "":
+		var appErr *helper.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, wantCode, appErr.Code)
+	default:
+		assert.NoError(t, err)
+	}
+}
+
+func TestPollingService_RecordsEvents(t *testing.T) {
+	start := time.Date(2025, 10, 26, 9, 0, 0, 0, time.UTC)
+	stored := func() *models.Polling {
+		return &models.Polling{
+			ID: 1, UserID: 5, Title: "Old title", Status: "active",
+			StartsAt: start, EndsAt: start.Add(24 * time.Hour), Version: 3,
+		}
+	}
+	title := "New title"
+	closed := "closed"
+	owner := authz.Subject{UserID: 5, Role: authz.RoleUser}
+
+	createMocks := func(repo *BundleMockPoll) {
+		repo.PollRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Polling")).
+			Run(func(args mock.Arguments) { args.Get(2).(*models.Polling).ID = 1 }).
+			Return(nil)
+		repo.OptRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.PollOption")).
+			Return(nil)
+	}
+	updateMocks := func(repo *BundleMockPoll) {
+		repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), int64(1)).Return(stored(), nil)
+		repo.PollRepo.On("Update", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Polling")).Return(nil)
+		repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return([]models.PollOption{}, nil)
+	}
+	committed := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+	}
+	createReq := &dto.CreatePollingRequest{Title: "test create poll", Description: "test description create poll", Options: []string{"Go"}}
+	creator := dto.CreatorInfo{ID: 5, Name: "user test", Email: "test@example.com"}
+
+	tests := []struct {
+		name       string
+		setupMocks func(repo *BundleMockPoll)
+		setupDB    func(mock sqlmock.Sqlmock)
+		outboxErr  error
+		call       func(svc domain.PollService) error
+		wantErr    helper.ErrorCode
+		wantEvents []string
+	}{
+		{
+			name:       "created",
+			setupMocks: createMocks,
+			setupDB:    committed,
+			call: func(svc domain.PollService) error {
+				_, err := svc.CreatePolling(context.Background(), createReq, creator)
+				return err
+			},
+			wantEvents: []string{domain.EventPollCreated},
+		},
+		{
+			name:       "outbox failure rolls back the poll",
+			setupMocks: createMocks,
+			setupDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			outboxErr: errors.New("outbox unavailable"),
+			call: func(svc domain.PollService) error {
+				_, err := svc.CreatePolling(context.Background(), createReq, creator)
+				return err
+			},
+			wantErr:    "DB_ERROR",
+			wantEvents: []string{domain.EventPollCreated},
+		},
+		{
+			name:       "updated",
+			setupMocks: updateMocks,
+			setupDB:    committed,
+			call: func(svc domain.PollService) error {
+				_, err := svc.UpdatePolling(context.Background(), &dto.UpdatePollingRequest{ID: 1, Title: &title}, owner)
+				return err
+			},
+			wantEvents: []string{domain.EventPollUpdated},
+		},
+		{
+			name:       "updated and closed",
+			setupMocks: updateMocks,
+			setupDB:    committed,
+			call: func(svc domain.PollService) error {
+				_, err := svc.UpdatePolling(context.Background(), &dto.UpdatePollingRequest{ID: 1, Status: &closed}, owner)
+				return err
+			},
+			wantEvents: []string{domain.EventPollUpdated, domain.EventPollClosed},
+		},
+		{
+			name: "vote cast",
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.VoteRepo.On("HasDeviceVoted", mock.Anything, mock.IsType(&tracing.DB{}), "device", int64(1)).Return(false, nil)
+				repo.PollRepo.On("GetByIDForShare", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(stored(), nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return([]models.PollOption{{ID: 2, PollID: 1}}, nil)
+				repo.VoteRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.Vote")).Return(nil)
+				repo.PollRepo.On("NotifyResults", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil)
+			},
+			setupDB: committed,
+			call: func(svc domain.PollService) error {
+				return svc.VoteOptionPolling(context.Background(), 0, 1, 2, "device")
+			},
+			wantEvents: []string{domain.EventVoteCast},
+		},
+		{
+			name: "option added",
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByIDForUpdate", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(stored(), nil)
+				repo.OptRepo.On("GetByPollID", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return([]models.PollOption{{ID: 2, PollID: 1, Label: "Go"}}, nil)
+				repo.OptRepo.On("Create", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("*models.PollOption")).Return(nil)
+				repo.PollRepo.On("Touch", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil)
+				repo.PollRepo.On("NotifyResults", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1)).Return(nil)
+			},
+			setupDB: committed,
+			call: func(svc domain.PollService) error {
+				_, _, err := svc.AddOption(context.Background(), 1, 3, &dto.CreateOptionRequest{Label: "Zig"}, owner)
+				return err
+			},
+			wantEvents: []string{domain.EventPollUpdated},
+		},
+		{
+			name: "deleted",
+			setupMocks: func(repo *BundleMockPoll) {
+				repo.PollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), int64(1)).Return(stored(), nil)
+				repo.PollRepo.On("Delete", mock.Anything, mock.IsType(&tracing.Tx{}), int64(1), int64(3)).Return(nil)
+			},
+			setupDB: committed,
+			call: func(svc domain.PollService) error {
+				return svc.DeletePolling(context.Background(), 1, 3, owner)
+			},
+			wantEvents: []string{domain.EventPollDeleted},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+			tt.setupDB(dbMock)
+
+			bundleMock := &BundleMockPoll{
+				PollRepo: new(mocks.PollRepositoryMock),
+				OptRepo:  new(mocks.OptionRepositoryMock),
+				VoteRepo: new(mocks.VoteRepostoryMock),
+			}
+			tt.setupMocks(bundleMock)
+
+			var recorded []domain.Event
+			outbox := new(mocks.OutboxRepositoryMock)
+			outbox.On("Add", mock.Anything, mock.IsType(&tracing.Tx{}), mock.AnythingOfType("domain.Event")).
+				Run(func(args mock.Arguments) { recorded = append(recorded, args.Get(2).(domain.Event)) }).
+				Return(tt.outboxErr)
+
+			svc := NewPolling(db, bundleMock.PollRepo, bundleMock.OptRepo, bundleMock.VoteRepo, outbox)
+			err := tt.call(svc)
+
+			if tt.wantErr != "" {
+				var appErr *helper.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tt.wantErr, appErr.Code)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+
+			names := []string{}
+			for _, ev := range recorded {
+				names = append(names, ev.Name)
+				assert.Equal(t, int64(1), ev.PollID)
+				assert.Equal(t, int64(5), ev.OwnerID)
+				assert.NotEmpty(t, ev.ID)
+			}
+			assert.Equal(t, tt.wantEvents, names)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"sync"
+	"time"
+)
+
+const (
+	// sessionCacheTTL bounds how long a revocation made on another server
+	// instance can go unnoticed, and how often last_seen_at is written.
+	sessionCacheTTL        = 30 * time.Second
+	sessionCacheMaxEntries = 10000
+)
+
+type sessionEntry struct {
+	userID int64
+	role   string
+	valid  bool
+	until  time.Time
+}
+
+type sessionService struct {
+	repo domain.SessionRepository
+	now  func() time.Time
+
+	mu    sync.Mutex
+	cache map[int64]sessionEntry
+}
+
+func NewSessionService(repo domain.SessionRepository) domain.SessionService {
+	return &sessionService{repo: repo, now: time.Now, cache: map[int64]sessionEntry{}}
+}
+
+func (s *sessionService) List(ctx context.Context, userID, currentID int64) ([]dto.SessionResponse, error) {
+	sessions, err := s.repo.ListActive(ctx, userID)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to get sessions", err)
+	}
+
+	results := []dto.SessionResponse{}
+	for _, sess := range sessions {
+		results = append(results, dto.SessionResponse{
+			ID:         sess.ID,
+			UserAgent:  sess.UserAgent,
+			IP:         sess.IP,
+			CreatedAt:  sess.CreatedAt,
+			LastSeenAt: sess.LastSeenAt,
+			ExpiresAt:  sess.ExpiresAt,
+			Current:    sess.ID == currentID,
+		})
+	}
+
+	return results, nil
+}
+
+func (s *sessionService) Revoke(ctx context.Context, userID, id int64) error {
+	if err := s.repo.Revoke(ctx, userID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return helper.NewAppError(helper.CodeNotFound, "session not found", err)
+		}
+		return helper.NewAppError(helper.CodeDBError, "failed to revoke session", err)
+	}
+
+	s.store(id, sessionEntry{userID: userID, valid: false})
+	return nil
+}
+
+// Validate reports whether the session behind a login token is still active
+// and returns the owner's current role. Answers are cached for
+// sessionCacheTTL so most requests skip the database; revocations, role
+// changes and disabled accounts handled through this instance take effect
+// immediately.
+func (s *sessionService) Validate(ctx context.Context, userID, id int64) (string, error) {
+	if id == 0 {
+		return "", helper.NewAppError(helper.CodeInvalidToken, "token has no session, log in again", nil)
+	}
+
+	entry, ok := s.lookup(id)
+	if !ok {
+		owner, role, err := s.repo.Touch(ctx, id)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			entry = sessionEntry{valid: false}
+		case err != nil:
+			return "", helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+		default:
+			entry = sessionEntry{userID: owner, role: role, valid: true}
+		}
+		s.store(id, entry)
+	}
+
+	if !entry.valid || entry.userID != userID {
+		return "", helper.NewAppError(helper.CodeInvalidToken, "session has been revoked", nil)
+	}
+
+	return entry.role, nil
+}
+
+func (s *sessionService) ForgetUser(userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, e := range s.cache {
+		if e.userID == userID {
+			delete(s.cache, id)
+		}
+	}
+}
+
+func (s *sessionService) lookup(id int64) (sessionEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.cache[id]
+	if !ok || s.now().After(entry.until) {
+		return sessionEntry{}, false
+	}
+	return entry, true
+}
+
+func (s *sessionService) store(id int64, entry sessionEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if len(s.cache) >= sessionCacheMaxEntries {
+		for k, e := range s.cache {
+			if now.After(e.until) {
+				delete(s.cache, k)
+			}
+		}
+		if len(s.cache) >= sessionCacheMaxEntries {
+			s.cache = map[int64]sessionEntry{}
+		}
+	}
+
+	entry.until = now.Add(sessionCacheTTL)
+	s.cache[id] = entry
+}
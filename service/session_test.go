@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"native-free-pollings/helper"
+	"native-free-pollings/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func validateCode(t *testing.T, svc *sessionService, userID, id int64) helper.ErrorCode {
+	t.Helper()
+	_, err := svc.Validate(context.Background(), userID, id)
+	if !assert.Error(t, err) {
+		return ""
+	}
+	return err.(*helper.AppError).Code
+}
+
+func TestSessionService_Validate(t *testing.T) {
+	now := time.Now()
+	repo := new(mocks.SessionRepositoryMock)
+	repo.On("Touch", mock.Anything, int64(10)).Return(int64(1), "admin", nil).Once()
+	repo.On("Touch", mock.Anything, int64(11)).Return(int64(0), "", sql.ErrNoRows).Once()
+	repo.On("Revoke", mock.Anything, int64(1), int64(10)).Return(nil)
+
+	svc := NewSessionService(repo).(*sessionService)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// The first check hits the database, the second is served from cache.
+	role, err := svc.Validate(ctx, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", role)
+	role, err = svc.Validate(ctx, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", role)
+
+	// A token for another user never matches the session owner.
+	assert.Equal(t, helper.CodeInvalidToken, validateCode(t, svc, 2, 10))
+
+	// Unknown or revoked sessions are rejected and the answer is cached too.
+	assert.Equal(t, helper.CodeInvalidToken, validateCode(t, svc, 1, 11))
+	assert.Equal(t, helper.CodeInvalidToken, validateCode(t, svc, 1, 11))
+
+	// Tokens issued before session tracking carry no session id.
+	assert.Equal(t, helper.CodeInvalidToken, validateCode(t, svc, 1, 0))
+
+	// Revoking invalidates the cached entry immediately.
+	assert.NoError(t, svc.Revoke(ctx, 1, 10))
+	assert.Equal(t, helper.CodeInvalidToken, validateCode(t, svc, 1, 10))
+
+	repo.AssertExpectations(t)
+}
+
+func TestSessionService_ValidateRefreshesAfterTTL(t *testing.T) {
+	now := time.Now()
+	repo := new(mocks.SessionRepositoryMock)
+	repo.On("Touch", mock.Anything, int64(10)).Return(int64(1), "user", nil).Once()
+	repo.On("Touch", mock.Anything, int64(10)).Return(int64(0), "", sql.ErrNoRows).Once()
+
+	svc := NewSessionService(repo).(*sessionService)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := svc.Validate(ctx, 1, 10)
+	assert.NoError(t, err)
+
+	// Revoked on another instance: noticed once the cache entry expires.
+	now = now.Add(sessionCacheTTL + time.Second)
+	_, err = svc.Validate(ctx, 1, 10)
+	assert.Error(t, err)
+
+	repo.AssertExpectations(t)
+}
+
+func TestSessionService_ForgetUser(t *testing.T) {
+	repo := new(mocks.SessionRepositoryMock)
+	repo.On("Touch", mock.Anything, int64(10)).Return(int64(1), "admin", nil).Once()
+	repo.On("Touch", mock.Anything, int64(10)).Return(int64(1), "user", nil).Once()
+	repo.On("Touch", mock.Anything, int64(20)).Return(int64(2), "user", nil).Once()
+
+	svc := NewSessionService(repo).(*sessionService)
+	ctx := context.Background()
+
+	role, err := svc.Validate(ctx, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", role)
+	_, err = svc.Validate(ctx, 2, 20)
+	assert.NoError(t, err)
+
+	// A demoted user is re-read from the database; others stay cached.
+	svc.ForgetUser(1)
+	role, err = svc.Validate(ctx, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "user", role)
+	_, err = svc.Validate(ctx, 2, 20)
+	assert.NoError(t, err)
+
+	repo.AssertExpectations(t)
+}
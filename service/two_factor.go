@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/models"
+	"strings"
+	"time"
+)
+
+const (
+	totpIssuer        = "Free Polling"
+	totpSkew          = 1
+	recoveryCodeCount = 10
+)
+
+type twoFactorService struct {
+	repo     domain.TwoFactorRepository
+	users    domain.UserRepository
+	sessions domain.SessionRepository
+	hasher   helper.PasswordHasher
+	now      func() time.Time
+}
+
+func NewTwoFactorService(repo domain.TwoFactorRepository, users domain.UserRepository, sessions domain.SessionRepository, hasher helper.PasswordHasher) domain.TwoFactorService {
+	return &twoFactorService{repo: repo, users: users, sessions: sessions, hasher: hasher, now: time.Now}
+}
+
+func (s *twoFactorService) Setup(ctx context.Context, userID int64) (*dto.TwoFactorSetupResponse, error) {
+	tf, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, helper.NewAppError(helper.CodeNotFound, "user not found", err)
+		}
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+	if tf.Enabled {
+		return nil, helper.NewAppError(helper.CodeBadRequest, "two-factor authentication is already enabled", nil)
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+
+	secret, err := helper.GenerateTOTPSecret()
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "failed to generate secret", err)
+	}
+
+	if err := s.repo.SaveSecret(ctx, userID, secret); err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "failed to save secret", err)
+	}
+
+	return &dto.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: helper.TOTPURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+func (s *twoFactorService) Confirm(ctx context.Context, userID int64, code string) (*dto.RecoveryCodesResponse, error) {
+	tf, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, helper.NewAppError(helper.CodeNotFound, "user not found", err)
+		}
+		return nil, helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+	if tf.Enabled {
+		return nil, helper.NewAppError(helper.CodeBadRequest, "two-factor authentication is already enabled", nil)
+	}
+	if tf.Secret == "" {
+		return nil, helper.NewAppError(helper.CodeBadRequest, "two-factor setup has not been started", nil)
+	}
+
+	step, ok := helper.VerifyTOTP(tf.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, helper.NewAppError(helper.CodeBadRequest, "invalid verification code", nil)
+	}
+
+	codes, err := helper.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "failed to generate recovery codes", err)
+	}
+
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = helper.HashRecoveryCode(c)
+	}
+
+	if err := s.repo.Enable(ctx, userID, step, hashes); err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "failed to enable two-factor authentication", err)
+	}
+
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns two-factor authentication off after re-checking the
+// password. Accounts without a password confirm like they do for deleting
+// the account: with their email address and a login within reauthWindow.
+func (s *twoFactorService) Disable(ctx context.Context, userID, sessionID int64, password string) error {
+	hash, err := s.users.GetPasswordHash(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return helper.NewAppError(helper.CodeNotFound, "user not found", err)
+		}
+		return helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+	}
+
+	if hash == "" {
+		user, err := s.users.GetByID(ctx, userID)
+		if err != nil {
+			return helper.NewAppError(helper.CodeInternalError, "internal server error", err)
+		}
+		if !strings.EqualFold(password, user.Email) {
+			return helper.NewAppError(helper.CodeAuthFailed, "confirm with your email address", nil)
+		}
+		if err := requireRecentLogin(ctx, s.sessions, s.now(), userID, sessionID, "turn off two-factor authentication"); err != nil {
+			return err
+		}
+	} else if err := s.hasher.Compare(hash, password); err != nil {
+		return helper.NewAppError(helper.CodeAuthFailed, "invalid password", err)
+	}
+
+	if err := s.repo.Disable(ctx, userID); err != nil {
+		return helper.NewAppError(helper.CodeInternalError, "failed to disable two-factor authentication", err)
+	}
+
+	return nil
+}
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery
+// code. Both are consumed on success so they cannot be replayed.
+func verifySecondFactor(ctx context.Context, repo domain.TwoFactorRepository, tf *models.TwoFactor, code string) (bool, error) {
+	if step, ok := helper.VerifyTOTP(tf.Secret, code, time.Now(), totpSkew); ok {
+		return repo.UseStep(ctx, tf.UserID, step)
+	}
+
+	return repo.UseRecoveryCode(ctx, tf.UserID, helper.HashRecoveryCode(code))
+}
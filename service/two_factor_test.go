@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"native-free-pollings/helper"
+	"native-free-pollings/mocks"
+	"native-free-pollings/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTwoFactorService_Confirm(t *testing.T) {
+	secret, _ := helper.GenerateTOTPSecret()
+	code, _ := helper.TOTPCode(secret, helper.TOTPStep(time.Now()))
+
+	tests := []struct {
+		name       string
+		code       string
+		setupMocks func(repo *mocks.TwoFactorRepositoryMock)
+		wantErr    helper.ErrorCode
+	}{
+		{
+			name: "setup not started",
+			code: code,
+			setupMocks: func(repo *mocks.TwoFactorRepositoryMock) {
+				repo.On("Get", mock.Anything, int64(1)).Return(&models.TwoFactor{UserID: 1}, nil)
+			},
+			wantErr: "BAD_REQUEST",
+		},
+		{
+			name: "already enabled",
+			code: code,
+			setupMocks: func(repo *mocks.TwoFactorRepositoryMock) {
+				repo.On("Get", mock.Anything, int64(1)).Return(&models.TwoFactor{UserID: 1, Secret: secret, Enabled: true}, nil)
+			},
+			wantErr: "BAD_REQUEST",
+		},
+		{
+			name: "invalid code",
+			code: "000000",
+			setupMocks: func(repo *mocks.TwoFactorRepositoryMock) {
+				repo.On("Get", mock.Anything, int64(1)).Return(&models.TwoFactor{UserID: 1, Secret: secret}, nil)
+			},
+			wantErr: "BAD_REQUEST",
+		},
+		{
+			name: "success",
+			code: code,
+			setupMocks: func(repo *mocks.TwoFactorRepositoryMock) {
+				repo.On("Get", mock.Anything, int64(1)).Return(&models.TwoFactor{UserID: 1, Secret: secret}, nil)
+				repo.On("Enable", mock.Anything, int64(1), mock.AnythingOfType("int64"), mock.AnythingOfType("[]string")).Return(nil)
+			},
+			wantErr: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.TwoFactorRepositoryMock)
+			tt.setupMocks(repo)
+
+			svc := NewTwoFactorService(repo, new(mocks.UserRepositoryMock), new(mocks.SessionRepositoryMock), mocks.MockHasher{})
+			resp, err := svc.Confirm(context.Background(), 1, tt.code)
+
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				assert.Len(t, resp.RecoveryCodes, recoveryCodeCount)
+			} else {
+				assert.Nil(t, resp)
+				assert.Equal(t, tt.wantErr, err.(*helper.AppError).Code)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTwoFactorService_Disable(t *testing.T) {
+	now := time.Date(2025, 10, 26, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		password   string
+		hasher     mocks.MockHasher
+		setupMocks func(repo *mocks.TwoFactorRepositoryMock, users *mocks.UserRepositoryMock, sessions *mocks.SessionRepositoryMock)
+		wantErr    helper.ErrorCode
+	}{
+		{
+			name:     "wrong password",
+			password: "secret",
+			hasher:   mocks.MockHasher{ShouldFail: true},
+			setupMocks: func(repo *mocks.TwoFactorRepositoryMock, users *mocks.UserRepositoryMock, sessions *mocks.SessionRepositoryMock) {
+				users.On("GetPasswordHash", mock.Anything, int64(1)).Return("hash", nil)
+			},
+			wantErr: "AUTH_FAILED",
+		},
+		{
+			name:     "disable failed",
+			password: "secret",
+			hasher:   mocks.MockHasher{},
+			setupMocks: func(repo *mocks.TwoFactorRepositoryMock, users *mocks.UserRepositoryMock, sessions *mocks.SessionRepositoryMock) {
+				users.On("GetPasswordHash", mock.Anything, int64(1)).Return("hash", nil)
+				repo.On("Disable", mock.Anything, int64(1)).Return(errors.New("db error"))
+			},
+			wantErr: "INTERNAL_ERROR",
+		},
+		{
+			name:     "success",
+			password: "secret",
+			hasher:   mocks.MockHasher{},
+			setupMocks: func(repo *mocks.TwoFactorRepositoryMock, users *mocks.UserRepositoryMock, sessions *mocks.SessionRepositoryMock) {
+				users.On("GetPasswordHash", mock.Anything, int64(1)).Return("hash", nil)
+				repo.On("Disable", mock.Anything, int64(1)).Return(nil)
+			},
+			wantErr: "",
+		},
+		{
+			name:     "sso account confirms with email after a fresh login",
+			password: "A@mail.com",
+			setupMocks: func(repo *mocks.TwoFactorRepositoryMock, users *mocks.UserRepositoryMock, sessions *mocks.SessionRepositoryMock) {
+				users.On("GetPasswordHash", mock.Anything, int64(1)).Return("", nil)
+				users.On("GetByID", mock.Anything, int64(1)).Return(&models.User{ID: 1, Email: "a@mail.com"}, nil)
+				sessions.On("GetActive", mock.Anything, int64(1), int64(3)).Return(&models.Session{ID: 3, CreatedAt: now.Add(-time.Minute)}, nil)
+				repo.On("Disable", mock.Anything, int64(1)).Return(nil)
+			},
+			wantErr: "",
+		},
+		{
+			name:     "sso account with an old session",
+			password: "a@mail.com",
+			setupMocks: func(repo *mocks.TwoFactorRepositoryMock, users *mocks.UserRepositoryMock, sessions *mocks.SessionRepositoryMock) {
+				users.On("GetPasswordHash", mock.Anything, int64(1)).Return("", nil)
+				users.On("GetByID", mock.Anything, int64(1)).Return(&models.User{ID: 1, Email: "a@mail.com"}, nil)
+				sessions.On("GetActive", mock.Anything, int64(1), int64(3)).Return(&models.Session{ID: 3, CreatedAt: now.Add(-time.Hour)}, nil)
+			},
+			wantErr: "REAUTH_REQUIRED",
+		},
+		{
+			name:     "sso account with wrong confirmation",
+			password: "b@mail.com",
+			setupMocks: func(repo *mocks.TwoFactorRepositoryMock, users *mocks.UserRepositoryMock, sessions *mocks.SessionRepositoryMock) {
+				users.On("GetPasswordHash", mock.Anything, int64(1)).Return("", nil)
+				users.On("GetByID", mock.Anything, int64(1)).Return(&models.User{ID: 1, Email: "a@mail.com"}, nil)
+			},
+			wantErr: "AUTH_FAILED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.TwoFactorRepositoryMock)
+			users := new(mocks.UserRepositoryMock)
+			sessions := new(mocks.SessionRepositoryMock)
+			tt.setupMocks(repo, users, sessions)
+
+			svc := NewTwoFactorService(repo, users, sessions, tt.hasher).(*twoFactorService)
+			svc.now = func() time.Time { return now }
+			err := svc.Disable(context.Background(), 1, 3, tt.password)
+
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tt.wantErr, err.(*helper.AppError).Code)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
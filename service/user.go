@@ -23,9 +23,9 @@ func (u *userService) GetProfile(ctx context.Context, id int64) (*dto.ProfileRes
 	resp, err := u.repo.GetByID(ctx, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, helper.NewAppError("NOT_FOUND", "user not found", err)
+			return nil, helper.NewAppError(helper.CodeNotFound, "user not found", err)
 		}
-		return nil, helper.NewAppError("INTERNAL_ERROR", "failed to get user", err)
+		return nil, helper.NewAppError(helper.CodeInternalError, "failed to get user", err)
 	}
 
 	return &dto.ProfileResponse{
@@ -40,17 +40,17 @@ func (u *userService) GetProfile(ctx context.Context, id int64) (*dto.ProfileRes
 func (u *userService) UpdateProfile(ctx context.Context, user *models.User) (*dto.ProfileResponse, error) {
 	if err := u.repo.Update(ctx, user); err != nil {
 		if err == sql.ErrNoRows {
-			return nil, helper.NewAppError("NOT_FOUND", "user not found", err)
+			return nil, helper.NewAppError(helper.CodeNotFound, "user not found", err)
 		}
-		return nil, helper.NewAppError("INTERNAL_ERROR", "failed to update profile", err)
+		return nil, helper.NewAppError(helper.CodeInternalError, "failed to update profile", err)
 	}
 
 	user, err := u.repo.GetByID(ctx, user.ID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, helper.NewAppError("NOT_FOUND", "user not found", err)
+			return nil, helper.NewAppError(helper.CodeNotFound, "user not found", err)
 		}
-		return nil, helper.NewAppError("INTERNAL_ERROR", "failed to get user", err)
+		return nil, helper.NewAppError(helper.CodeInternalError, "failed to get user", err)
 	}
 
 	return &dto.ProfileResponse{
@@ -65,20 +65,20 @@ func (u *userService) UpdateProfile(ctx context.Context, user *models.User) (*dt
 
 func (u *userService) ChangePassword(ctx context.Context, id int64, password string) error {
 	if id <= 0 || password == "" {
-		return helper.NewAppError("BAD_REQUEST", "invalid input", nil)
+		return helper.NewAppError(helper.CodeBadRequest, "invalid input", nil)
 	}
 
 	hashed, err := u.hasher.Hash(password)
 	if err != nil {
-		return helper.NewAppError("HASH_FAILED", "failed hash password", err)
+		return helper.NewAppError(helper.CodeHashFailed, "failed hash password", err)
 	}
 
 	err = u.repo.UpdatePassword(ctx, id, hashed)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return helper.NewAppError("NOT_FOUND", "user not found", err)
+			return helper.NewAppError(helper.CodeNotFound, "user not found", err)
 		}
-		return helper.NewAppError("INTERNAL_ERROR", "failed to change password", err)
+		return helper.NewAppError(helper.CodeInternalError, "failed to change password", err)
 	}
 
 	return nil
@@ -86,13 +86,13 @@ func (u *userService) ChangePassword(ctx context.Context, id int64, password str
 
 func (u *userService) GetUserCreatedPollings(ctx context.Context, id int64) ([]dto.PollingSummaryForCreator, error) {
 	if id <= 0 {
-		return nil, helper.NewAppError("AUTH_FAILED", "user ID invalid", nil)
+		return nil, helper.NewAppError(helper.CodeAuthFailed, "user ID invalid", nil)
 	}
 
 	results := []dto.PollingSummaryForCreator{}
 	polls, err := u.repo.FindPollingsByID(ctx, id)
 	if err != nil {
-		return nil, helper.NewAppError("DB_ERROR", "failed to get pollings", err)
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to get pollings", err)
 	}
 
 	for _, poll := range polls {
@@ -110,13 +110,13 @@ func (u *userService) GetUserCreatedPollings(ctx context.Context, id int64) ([]d
 
 func (u *userService) GetUserVotedPollings(ctx context.Context, id int64) ([]dto.PollingSummaryForVoter, error) {
 	if id <= 0 {
-		return nil, helper.NewAppError("AUTH_FAILED", "user ID invalid", nil)
+		return nil, helper.NewAppError(helper.CodeAuthFailed, "user ID invalid", nil)
 	}
 
 	results := []dto.PollingSummaryForVoter{}
 	polls, err := u.repo.FindPollingsVotedByID(ctx, id)
 	if err != nil {
-		return nil, helper.NewAppError("DB_ERROR", "failed to get pollings", err)
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to get pollings", err)
 	}
 
 	for _, poll := range polls {
@@ -22,7 +22,7 @@ func TestUserService_GetProfile(t *testing.T) {
 		name       string
 		setupMocks func(repo *mocks.UserRepositoryMock)
 		id         int64
-		wantErr    string
+		wantErr    helper.ErrorCode
 	}{
 		{
 			name: "user not found",
@@ -85,7 +85,7 @@ func TestUserService_UpdateProfile(t *testing.T) {
 		name       string
 		setupMocks func(repo *mocks.UserRepositoryMock)
 		user       *models.User
-		wantErr    string
+		wantErr    helper.ErrorCode
 	}{
 		{
 			name: "user not found",
@@ -207,7 +207,7 @@ func TestUserService_ChangePassword(t *testing.T) {
 		password   string
 		setupMocks func(repo *mocks.UserRepositoryMock)
 		hasher     mocks.MockHasher
-		wantErr    string
+		wantErr    helper.ErrorCode
 	}{
 		{
 			name:       "invalid input",
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"native-free-pollings/authz"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/models"
+	"native-free-pollings/tracing"
+	"native-free-pollings/webhook"
+	"time"
+)
+
+// deliveryLogLimit is how many recent deliveries are listed per webhook.
+const deliveryLogLimit = 50
+
+type webhookService struct {
+	DB           *tracing.DB
+	repo         domain.WebhookRepository
+	pollRepo     domain.PollRepository
+	allowPrivate bool
+}
+
+func NewWebhookService(db *sql.DB, repo domain.WebhookRepository, pollRepo domain.PollRepository, allowPrivate bool) domain.WebhookService {
+	return &webhookService{DB: tracing.WrapDB(db), repo: repo, pollRepo: pollRepo, allowPrivate: allowPrivate}
+}
+
+func (s *webhookService) Create(ctx context.Context, actor authz.Subject, req *dto.CreateWebhookRequest) (*dto.WebhookCreatedResponse, error) {
+	if err := webhook.ValidateURL(req.URL, s.allowPrivate); err != nil {
+		return nil, helper.NewAppError(helper.CodeInvalidInput, err.Error(), err)
+	}
+
+	if req.PollID != nil {
+		poll, err := s.pollRepo.GetByID(ctx, s.DB, *req.PollID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, domain.ErrPollNotFound
+			}
+			return nil, helper.NewAppError(helper.CodeDBError, "failed get polling", err)
+		}
+		if !authz.CanManage(actor, poll.UserID, authz.PollManageAny) {
+			return nil, fmt.Errorf("only creator can subscribe to polling %d: %w", poll.ID, domain.ErrForbidden)
+		}
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeInternalError, "failed to generate secret", err)
+	}
+
+	hook := &models.Webhook{
+		UserID: actor.UserID,
+		PollID: req.PollID,
+		URL:    req.URL,
+		Secret: secret,
+		Events: req.Events,
+	}
+	if err := s.repo.Create(ctx, hook); err != nil {
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to save webhook", err)
+	}
+
+	return &dto.WebhookCreatedResponse{WebhookResponse: toWebhookResponse(hook), Secret: secret}, nil
+}
+
+func (s *webhookService) List(ctx context.Context, userID int64) ([]dto.WebhookResponse, error) {
+	hooks, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to get webhooks", err)
+	}
+
+	results := []dto.WebhookResponse{}
+	for i := range hooks {
+		results = append(results, toWebhookResponse(&hooks[i]))
+	}
+
+	return results, nil
+}
+
+func (s *webhookService) Update(ctx context.Context, userID int64, req *dto.UpdateWebhookRequest) (*dto.WebhookResponse, error) {
+	hook, err := s.ownWebhook(ctx, userID, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := webhook.ValidateURL(*req.URL, s.allowPrivate); err != nil {
+			return nil, helper.NewAppError(helper.CodeInvalidInput, err.Error(), err)
+		}
+		hook.URL = *req.URL
+	}
+	if req.Events != nil {
+		hook.Events = req.Events
+	}
+	if req.Active != nil {
+		switch {
+		case *req.Active:
+			hook.DisabledAt = nil
+		case hook.DisabledAt == nil:
+			now := time.Now()
+			hook.DisabledAt = &now
+		}
+	}
+
+	if err := s.repo.Update(ctx, hook); err != nil {
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to save webhook", err)
+	}
+
+	resp := toWebhookResponse(hook)
+	return &resp, nil
+}
+
+func (s *webhookService) Delete(ctx context.Context, userID, id int64) error {
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return helper.NewAppError(helper.CodeNotFound, "webhook not found", err)
+		}
+		return helper.NewAppError(helper.CodeDBError, "failed to delete webhook", err)
+	}
+
+	return nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, userID, webhookID int64) ([]dto.WebhookDeliveryResponse, error) {
+	if _, err := s.ownWebhook(ctx, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, webhookID, deliveryLogLimit)
+	if err != nil {
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to get deliveries", err)
+	}
+
+	results := []dto.WebhookDeliveryResponse{}
+	for i := range deliveries {
+		results = append(results, toDeliveryResponse(&deliveries[i]))
+	}
+
+	return results, nil
+}
+
+func (s *webhookService) Redeliver(ctx context.Context, userID, webhookID, deliveryID int64) (*dto.WebhookDeliveryResponse, error) {
+	if _, err := s.ownWebhook(ctx, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	past, err := s.repo.GetDelivery(ctx, webhookID, deliveryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, helper.NewAppError(helper.CodeNotFound, "delivery not found", err)
+		}
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to get delivery", err)
+	}
+
+	// The same event ID lets receivers that did process it skip it.
+	delivery := &models.WebhookDelivery{
+		WebhookID: webhookID,
+		EventID:   past.EventID,
+		Event:     past.Event,
+		Payload:   past.Payload,
+	}
+	if err := s.repo.Enqueue(ctx, delivery); err != nil {
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to queue delivery", err)
+	}
+
+	resp := toDeliveryResponse(delivery)
+	return &resp, nil
+}
+
+// ownWebhook loads a webhook of userID; other users' webhooks do not exist
+// as far as they are concerned.
+func (s *webhookService) ownWebhook(ctx context.Context, userID, id int64) (*models.Webhook, error) {
+	hook, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, helper.NewAppError(helper.CodeNotFound, "webhook not found", err)
+		}
+		return nil, helper.NewAppError(helper.CodeDBError, "failed to get webhook", err)
+	}
+	return hook, nil
+}
+
+// HandleEvent queues ev for every webhook subscribed to it. The webhook
+// dispatcher sends them later, so a slow receiver never holds up the event
+// bus. A repeated call for the same event queues nothing new.
+func (s *webhookService) HandleEvent(ctx context.Context, ev domain.Event, data json.RawMessage) error {
+	payload, err := json.Marshal(webhook.Envelope{ID: ev.ID, Event: ev.Name, CreatedAt: ev.OccurredAt, Data: data})
+	if err != nil {
+		return fmt.Errorf("encode webhook payload: %w", err)
+	}
+
+	if _, err := s.repo.EnqueueEvent(ctx, ev.OwnerID, ev.PollID, ev.ID, ev.Name, payload); err != nil {
+		return fmt.Errorf("queue deliveries of %s: %w", ev.ID, err)
+	}
+
+	return nil
+}
+
+func toWebhookResponse(h *models.Webhook) dto.WebhookResponse {
+	return dto.WebhookResponse{
+		ID:                  h.ID,
+		URL:                 h.URL,
+		PollID:              h.PollID,
+		Events:              h.Events,
+		Active:              h.DisabledAt == nil,
+		ConsecutiveFailures: h.ConsecutiveFailures,
+		DisabledAt:          h.DisabledAt,
+		CreatedAt:           h.CreatedAt,
+		UpdatedAt:           h.UpdatedAt,
+	}
+}
+
+func toDeliveryResponse(d *models.WebhookDelivery) dto.WebhookDeliveryResponse {
+	resp := dto.WebhookDeliveryResponse{
+		ID:          d.ID,
+		EventID:     d.EventID,
+		Event:       d.Event,
+		Payload:     d.Payload,
+		Status:      d.Status,
+		Attempts:    d.Attempts,
+		DeliveredAt: d.DeliveredAt,
+		CreatedAt:   d.CreatedAt,
+		AttemptLog:  []dto.WebhookAttemptResponse{},
+	}
+	if d.Status == models.DeliveryPending {
+		next := d.NextAttemptAt
+		resp.NextAttemptAt = &next
+	}
+	for _, a := range d.AttemptLog {
+		resp.AttemptLog = append(resp.AttemptLog, dto.WebhookAttemptResponse{
+			StatusCode:  a.StatusCode,
+			Error:       a.Error,
+			DurationMs:  a.DurationMs,
+			AttemptedAt: a.AttemptedAt,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"native-free-pollings/authz"
+	"native-free-pollings/domain"
+	"native-free-pollings/dto"
+	"native-free-pollings/helper"
+	"native-free-pollings/mocks"
+	"native-free-pollings/models"
+	"native-free-pollings/tracing"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookService_Create(t *testing.T) {
+	pollID := int64(3)
+	owner := authz.Subject{UserID: 1, Role: authz.RoleUser}
+
+	tests := []struct {
+		name       string
+		req        *dto.CreateWebhookRequest
+		actor      authz.Subject
+		setupMocks func(repo *mocks.WebhookRepositoryMock, pollRepo *mocks.PollRepositoryMock)
+		wantErr    helper.ErrorCode
+		wantErrIs  error
+	}{
+		{
+			name:       "internal address",
+			req:        &dto.CreateWebhookRequest{URL: "http://127.0.0.1/hook", Events: []string{domain.EventVoteCast}},
+			actor:      owner,
+			setupMocks: func(repo *mocks.WebhookRepositoryMock, pollRepo *mocks.PollRepositoryMock) {},
+			wantErr:    "INVALID_INPUT",
+		},
+		{
+			name:  "unknown poll",
+			req:   &dto.CreateWebhookRequest{URL: "https://hooks.example.com/in", Events: []string{domain.EventVoteCast}, PollID: &pollID},
+			actor: owner,
+			setupMocks: func(repo *mocks.WebhookRepositoryMock, pollRepo *mocks.PollRepositoryMock) {
+				pollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), pollID).Return(nil, sql.ErrNoRows)
+			},
+			wantErrIs: domain.ErrPollNotFound,
+		},
+		{
+			name:  "poll of another user",
+			req:   &dto.CreateWebhookRequest{URL: "https://hooks.example.com/in", Events: []string{domain.EventVoteCast}, PollID: &pollID},
+			actor: authz.Subject{UserID: 2, Role: authz.RoleUser},
+			setupMocks: func(repo *mocks.WebhookRepositoryMock, pollRepo *mocks.PollRepositoryMock) {
+				pollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), pollID).Return(&models.Polling{ID: pollID, UserID: 1}, nil)
+			},
+			wantErrIs: domain.ErrForbidden,
+		},
+		{
+			name:  "success",
+			req:   &dto.CreateWebhookRequest{URL: "https://hooks.example.com/in", Events: []string{domain.EventVoteCast}, PollID: &pollID},
+			actor: owner,
+			setupMocks: func(repo *mocks.WebhookRepositoryMock, pollRepo *mocks.PollRepositoryMock) {
+				pollRepo.On("GetByID", mock.Anything, mock.IsType(&tracing.DB{}), pollID).Return(&models.Polling{ID: pollID, UserID: 1}, nil)
+				repo.On("Create", mock.Anything, mock.AnythingOfType("*models.Webhook")).
+					Run(func(args mock.Arguments) { args.Get(1).(*models.Webhook).ID = 9 }).
+					Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _, _ := sqlmock.New()
+			defer db.Close()
+
+			repo := new(mocks.WebhookRepositoryMock)
+			pollRepo := new(mocks.PollRepositoryMock)
+			tt.setupMocks(repo, pollRepo)
+
+			svc := NewWebhookService(db, repo, pollRepo, false)
+			resp, err := svc.Create(context.Background(), tt.actor, tt.req)
+
+			if tt.wantErrIs != nil {
+				assert.ErrorIs(t, err, tt.wantErrIs)
+				assert.Nil(t, resp)
+				return
+			}
+			if tt.wantErr != "" {
+				var appErr *helper.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tt.wantErr, appErr.Code)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, int64(9), resp.ID)
+			assert.True(t, resp.Active)
+			assert.True(t, strings.HasPrefix(resp.Secret, "whsec_"))
+			saved := repo.Calls[0].Arguments.Get(1).(*models.Webhook)
+			assert.Equal(t, resp.Secret, saved.Secret)
+			assert.Equal(t, int64(1), saved.UserID)
+		})
+	}
+}
+
+func TestWebhookService_Update(t *testing.T) {
+	disabledAt := time.Now().Add(-time.Hour)
+	enable, disable := true, false
+
+	tests := []struct {
+		name         string
+		active       *bool
+		stored       *models.Webhook
+		wantActive   bool
+		wantNotFound bool
+	}{
+		{name: "not found", active: &enable, wantNotFound: true},
+		{name: "re-enable", active: &enable, stored: &models.Webhook{ID: 4, UserID: 1, DisabledAt: &disabledAt}, wantActive: true},
+		{name: "disable", active: &disable, stored: &models.Webhook{ID: 4, UserID: 1}, wantActive: false},
+		{name: "keep state", stored: &models.Webhook{ID: 4, UserID: 1, DisabledAt: &disabledAt}, wantActive: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _, _ := sqlmock.New()
+			defer db.Close()
+
+			repo := new(mocks.WebhookRepositoryMock)
+			if tt.stored == nil {
+				repo.On("GetByID", mock.Anything, int64(1), int64(4)).Return(nil, sql.ErrNoRows)
+			} else {
+				repo.On("GetByID", mock.Anything, int64(1), int64(4)).Return(tt.stored, nil)
+				repo.On("Update", mock.Anything, tt.stored).Return(nil)
+			}
+
+			svc := NewWebhookService(db, repo, new(mocks.PollRepositoryMock), false)
+			resp, err := svc.Update(context.Background(), 1, &dto.UpdateWebhookRequest{ID: 4, Active: tt.active})
+
+			if tt.wantNotFound {
+				var appErr *helper.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, helper.ErrorCode("NOT_FOUND"), appErr.Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantActive, resp.Active)
+		})
+	}
+}
+
+func TestWebhookService_HandleEvent(t *testing.T) {
+	db, _, _ := sqlmock.New()
+	defer db.Close()
+
+	repo := new(mocks.WebhookRepositoryMock)
+	repo.On("EnqueueEvent", mock.Anything, int64(5), int64(1), "evt_1", domain.EventVoteCast, mock.AnythingOfType("[]uint8")).
+		Return(int64(2), nil)
+
+	svc := NewWebhookService(db, repo, new(mocks.PollRepositoryMock), false)
+	data := json.RawMessage(`{"poll_id":1,"option_id":2}`)
+	err := svc.HandleEvent(context.Background(), domain.Event{
+		ID:         "evt_1",
+		Name:       domain.EventVoteCast,
+		PollID:     1,
+		OwnerID:    5,
+		OccurredAt: time.Date(2025, 10, 26, 9, 0, 0, 0, time.UTC),
+		Data:       data,
+	}, data)
+
+	require.NoError(t, err)
+	payload := repo.Calls[0].Arguments.Get(5).([]byte)
+	assert.JSONEq(t, `{"id":"evt_1","event":"vote.cast","created_at":"2025-10-26T09:00:00Z","data":{"poll_id":1,"option_id":2}}`, string(payload))
+}
+
+func TestWebhookService_HandleEventFails(t *testing.T) {
+	db, _, _ := sqlmock.New()
+	defer db.Close()
+
+	repo := new(mocks.WebhookRepositoryMock)
+	repo.On("EnqueueEvent", mock.Anything, int64(5), int64(1), "evt_1", domain.EventPollCreated, mock.Anything).
+		Return(int64(0), errors.New("connection reset"))
+
+	svc := NewWebhookService(db, repo, new(mocks.PollRepositoryMock), false)
+	err := svc.HandleEvent(context.Background(), domain.Event{ID: "evt_1", Name: domain.EventPollCreated, PollID: 1, OwnerID: 5}, json.RawMessage(`{}`))
+
+	// The outbox hands the event out again when its consumer fails.
+	assert.ErrorContains(t, err, "connection reset")
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	db, _, _ := sqlmock.New()
+	defer db.Close()
+
+	payload := []byte(`{"id":"evt_1"}`)
+	repo := new(mocks.WebhookRepositoryMock)
+	repo.On("GetByID", mock.Anything, int64(1), int64(4)).Return(&models.Webhook{ID: 4, UserID: 1}, nil)
+	repo.On("GetDelivery", mock.Anything, int64(4), int64(11)).
+		Return(&models.WebhookDelivery{ID: 11, WebhookID: 4, EventID: "evt_1", Event: domain.EventPollCreated, Payload: payload, Status: models.DeliveryFailed}, nil)
+	repo.On("Enqueue", mock.Anything, mock.AnythingOfType("*models.WebhookDelivery")).
+		Run(func(args mock.Arguments) {
+			d := args.Get(1).(*models.WebhookDelivery)
+			d.ID = 12
+			d.Status = models.DeliveryPending
+		}).
+		Return(nil)
+
+	svc := NewWebhookService(db, repo, new(mocks.PollRepositoryMock), false)
+	resp, err := svc.Redeliver(context.Background(), 1, 4, 11)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), resp.ID)
+	assert.Equal(t, "evt_1", resp.EventID)
+	assert.Equal(t, models.DeliveryPending, resp.Status)
+	assert.JSONEq(t, string(payload), string(resp.Payload))
+}
@@ -0,0 +1,137 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"native-free-pollings/domain"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DB wraps a domain.DB and records a client span for every query. When the
+// wrapped value can begin transactions (a *sql.DB), so can DB.
+type DB struct {
+	db domain.DB
+}
+
+func WrapDB(db domain.DB) *DB {
+	return &DB{db: db}
+}
+
+func (d *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := startQuery(ctx, query)
+	result, err := d.db.ExecContext(ctx, query, args...)
+	endSpan(span, err)
+	return result, err
+}
+
+// QueryContext's span covers running the query, not iterating the rows.
+func (d *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := startQuery(ctx, query)
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	endSpan(span, err)
+	return rows, err
+}
+
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := startQuery(ctx, query)
+	row := d.db.QueryRowContext(ctx, query, args...)
+	endSpan(span, row.Err())
+	return row
+}
+
+// BeginTx starts a transaction with a span that stays open until Commit or
+// Rollback; queries run through the returned Tx are its children.
+func (d *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	beginner, ok := d.db.(interface {
+		BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error)
+	})
+	if !ok {
+		return nil, errors.New("tracing: wrapped database cannot begin transactions")
+	}
+
+	ctx, span := Tracer().Start(ctx, "db.transaction",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemNamePostgreSQL),
+	)
+	tx, err := beginner.BeginTx(ctx, opts)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+
+	return &Tx{DB: DB{db: tx}, tx: tx, span: span}, nil
+}
+
+// Tx is a traced *sql.Tx. It satisfies domain.DB.
+type Tx struct {
+	DB
+	tx   *sql.Tx
+	span trace.Span
+}
+
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return t.DB.ExecContext(t.withSpan(ctx), query, args...)
+}
+
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return t.DB.QueryContext(t.withSpan(ctx), query, args...)
+}
+
+func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return t.DB.QueryRowContext(t.withSpan(ctx), query, args...)
+}
+
+func (t *Tx) Commit() error {
+	err := t.tx.Commit()
+	t.span.SetAttributes(attribute.String("db.transaction.outcome", "commit"))
+	endSpan(t.span, err)
+	return err
+}
+
+// Rollback is safe to defer after Commit; the span is only ended once.
+func (t *Tx) Rollback() error {
+	err := t.tx.Rollback()
+	if errors.Is(err, sql.ErrTxDone) {
+		return err
+	}
+	t.span.SetAttributes(attribute.String("db.transaction.outcome", "rollback"))
+	endSpan(t.span, err)
+	return err
+}
+
+// withSpan parents query spans on the transaction span even when the caller
+// passes the context it had before BeginTx.
+func (t *Tx) withSpan(ctx context.Context) context.Context {
+	return trace.ContextWithSpan(ctx, t.span)
+}
+
+func startQuery(ctx context.Context, query string) (context.Context, trace.Span) {
+	query = strings.TrimSpace(query)
+	operation := "QUERY"
+	if fields := strings.Fields(query); len(fields) > 0 {
+		operation = strings.ToUpper(strings.SplitN(fields[0], "(", 2)[0])
+	}
+
+	return Tracer().Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(query),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
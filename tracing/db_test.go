@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+func TestDB_QuerySpans(t *testing.T) {
+	recorder := recordSpans(t)
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE users`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT id`).WillReturnError(errors.New("boom"))
+
+	traced := WrapDB(db)
+	_, err = traced.ExecContext(context.Background(), "\n\t\tUPDATE users SET name = $1", "x")
+	assert.NoError(t, err)
+	_, err = traced.QueryContext(context.Background(), "SELECT id FROM users")
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "UPDATE", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, "SELECT", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDB_TransactionSpan(t *testing.T) {
+	recorder := recordSpans(t)
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO votes`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	ctx, parent := Tracer().Start(context.Background(), "polling.VoteOptionPolling")
+	tx, err := WrapDB(db).BeginTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(ctx, "INSERT INTO votes (option_id) VALUES ($1)", 1)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	assert.ErrorIs(t, tx.Rollback(), sql.ErrTxDone, "deferred rollback after commit ends no second span")
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	insert, txSpan, root := spans[0], spans[1], spans[2]
+	assert.Equal(t, "db.transaction", txSpan.Name())
+	assert.Equal(t, txSpan.SpanContext().SpanID(), insert.Parent().SpanID(), "queries are children of the transaction")
+	assert.Equal(t, root.SpanContext().SpanID(), txSpan.Parent().SpanID())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,34 @@
+package tracing
+
+import (
+	"context"
+	"native-free-pollings/config"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestSetup_NonePropagatesTraceContext(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.Tracing{Exporter: "none"})
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+	ctx, span := Tracer().Start(ctx, "GET /pollings/")
+	defer span.End()
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", TraceID(ctx))
+	assert.Equal(t, "", TraceID(context.Background()))
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), config.Tracing{Exporter: "zipkin"})
+
+	assert.Error(t, err)
+}
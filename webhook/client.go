@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned when a webhook URL resolves to an address
+// webhooks may not reach.
+var ErrForbiddenAddress = errors.New("webhook address not allowed")
+
+// sharedAddressSpace is the carrier-grade NAT range, private in practice
+// but not covered by netip.Addr.IsPrivate.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// NewClient returns the HTTP client deliveries are sent with. Unless
+// allowPrivate is set it refuses to connect to loopback, private and
+// link-local addresses, so a webhook cannot be used to probe the internal
+// network. The check runs on the address actually dialed, after DNS, which
+// also covers names that resolve to internal addresses. Redirects are not
+// followed and environment proxies are ignored for the same reason.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			return checkAddress(address)
+		}
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        20,
+			IdleConnTimeout:     time.Minute,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func checkAddress(address string) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	ip := ap.Addr().Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+	}
+	return nil
+}
+
+// ValidateURL checks a webhook URL when it is saved: it must be absolute
+// http or https, and a host given as an IP address must be reachable under
+// the same rules as NewClient. Host names are only checked when dialing.
+func ValidateURL(raw string, allowPrivate bool) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrForbiddenAddress)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: url must not contain credentials", ErrForbiddenAddress)
+	}
+	if allowPrivate {
+		return nil
+	}
+	if strings.EqualFold(u.Hostname(), "localhost") {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, u.Hostname())
+	}
+	if ip, err := netip.ParseAddr(u.Hostname()); err == nil {
+		return checkAddress(netip.AddrPortFrom(ip, 0).String())
+	}
+	return nil
+}
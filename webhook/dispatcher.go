@@ -0,0 +1,172 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"native-free-pollings/config"
+	"native-free-pollings/domain"
+	"native-free-pollings/helper"
+	"native-free-pollings/metrics"
+	"native-free-pollings/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// userAgent identifies deliveries to receivers.
+const userAgent = "native-free-pollings-webhooks/1"
+
+// maxErrorBody is how much of a failed response is kept in the attempt log.
+const maxErrorBody = 512
+
+// Dispatcher sends due deliveries from the queue. Any number of instances
+// may run one; a delivery is claimed by one of them at a time.
+type Dispatcher struct {
+	repo   domain.WebhookRepository
+	client *http.Client
+	conf   config.Webhooks
+	now    func() time.Time
+}
+
+func NewDispatcher(repo domain.WebhookRepository, client *http.Client, conf config.Webhooks) *Dispatcher {
+	return &Dispatcher{repo: repo, client: client, conf: conf, now: time.Now}
+}
+
+// Run dispatches until ctx is cancelled. It fits server.Workers.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.conf.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// A full batch suggests more are due, so the next one is claimed
+		// right away instead of after the interval.
+		for {
+			n, err := d.Dispatch(ctx)
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to dispatch webhooks", "error", err)
+			}
+			if err != nil || n < d.conf.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch claims one batch of due deliveries, sends them concurrently and
+// records the outcome. It returns how many deliveries it claimed.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	// The lease outlasts a request that runs into its timeout, so another
+	// instance only picks a delivery up again if this one died sending it.
+	deliveries, err := d.repo.ClaimDue(ctx, d.conf.BatchSize, 2*d.conf.Timeout+time.Minute)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(del *models.WebhookDelivery) {
+			defer wg.Done()
+			d.deliver(ctx, del)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, del *models.WebhookDelivery) {
+	start := d.now()
+	status, sendErr := d.send(ctx, del, start)
+	if sendErr != "" && ctx.Err() != nil {
+		// Cut off by shutdown, which is not the receiver's fault. The lease
+		// runs out and the delivery is sent again.
+		return
+	}
+	attempt := &models.WebhookAttempt{
+		Error:      sendErr,
+		DurationMs: int(time.Since(start).Milliseconds()),
+	}
+	if status != 0 {
+		attempt.StatusCode = &status
+	}
+
+	// Record with a fresh context: a result that came in just before
+	// shutdown must still be saved, or the delivery would be sent twice.
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	if sendErr == "" {
+		metrics.WebhookAttempts.Inc("succeeded")
+		if err := d.repo.RecordSuccess(recordCtx, del, attempt); err != nil {
+			slog.ErrorContext(ctx, "Failed to record webhook delivery", "delivery_id", del.ID, "error", err)
+		}
+		return
+	}
+
+	var retryAt *time.Time
+	if attempts := del.Attempts + 1; attempts < d.conf.MaxAttempts {
+		at := d.now().Add(helper.Backoff(attempts, d.conf.BackoffBase, d.conf.BackoffMax))
+		retryAt = &at
+		metrics.WebhookAttempts.Inc("retrying")
+	} else {
+		metrics.WebhookAttempts.Inc("failed")
+	}
+
+	disabled, err := d.repo.RecordFailure(recordCtx, del, attempt, retryAt, d.conf.DisableAfter)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record webhook delivery", "delivery_id", del.ID, "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "Webhook delivery failed", "delivery_id", del.ID, "webhook_id", del.WebhookID,
+		"attempt", del.Attempts+1, "error", sendErr, "will_retry", retryAt != nil)
+	if disabled {
+		metrics.WebhooksDisabled.Inc()
+		slog.WarnContext(ctx, "Disabled failing webhook", "webhook_id", del.WebhookID)
+	}
+}
+
+// send makes one attempt and returns the response status, if any, and a
+// description of the failure, empty on success. Any 2xx answer counts as
+// delivered.
+func (d *Dispatcher) send(ctx context.Context, del *models.WebhookDelivery, now time.Time) (int, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, del.URL, bytes.NewReader(del.Payload))
+	if err != nil {
+		return 0, err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEvent, del.Event)
+	req.Header.Set(HeaderEventID, del.EventID)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(del.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(del.Secret, now, del.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	// Drain a little more so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// The body is whatever the receiver sent; it is stored as text.
+		snippet := strings.ToValidUTF8(strings.ReplaceAll(string(bytes.TrimSpace(body)), "\x00", ""), "")
+		return resp.StatusCode, fmt.Sprintf("unexpected status %d: %s", resp.StatusCode, snippet)
+	}
+	return resp.StatusCode, ""
+}
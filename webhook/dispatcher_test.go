@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"native-free-pollings/config"
+	"native-free-pollings/helper"
+	"native-free-pollings/mocks"
+	"native-free-pollings/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testConf() config.Webhooks {
+	conf := config.Default().Webhooks
+	conf.Timeout = 2 * time.Second
+	conf.MaxAttempts = 3
+	return conf
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	now := time.Date(2025, 10, 26, 9, 0, 0, 0, time.UTC)
+	payload := []byte(`{"id":"evt_1","event":"vote.cast"}`)
+
+	tests := []struct {
+		name       string
+		status     int
+		attempts   int
+		setupMocks func(repo *mocks.WebhookRepositoryMock)
+	}{
+		{
+			name:   "success",
+			status: http.StatusNoContent,
+			setupMocks: func(repo *mocks.WebhookRepositoryMock) {
+				repo.On("RecordSuccess", mock.Anything, mock.Anything, mock.MatchedBy(func(a *models.WebhookAttempt) bool {
+					return a.StatusCode != nil && *a.StatusCode == http.StatusNoContent && a.Error == ""
+				})).Return(nil)
+			},
+		},
+		{
+			name:     "retry with backoff",
+			status:   http.StatusInternalServerError,
+			attempts: 1,
+			setupMocks: func(repo *mocks.WebhookRepositoryMock) {
+				retryAt := now.Add(helper.Backoff(2, testConf().BackoffBase, testConf().BackoffMax))
+				repo.On("RecordFailure", mock.Anything, mock.Anything, mock.MatchedBy(func(a *models.WebhookAttempt) bool {
+					return *a.StatusCode == http.StatusInternalServerError && a.Error == "unexpected status 500: boom"
+				}), &retryAt, testConf().DisableAfter).Return(false, nil)
+			},
+		},
+		{
+			name:     "last attempt fails for good",
+			status:   http.StatusGone,
+			attempts: 2,
+			setupMocks: func(repo *mocks.WebhookRepositoryMock) {
+				repo.On("RecordFailure", mock.Anything, mock.Anything, mock.Anything, (*time.Time)(nil), testConf().DisableAfter).
+					Return(true, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			var body []byte
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(" boom\x00\n"))
+			}))
+			defer srv.Close()
+
+			delivery := models.WebhookDelivery{
+				ID: 11, WebhookID: 4, EventID: "evt_1", Event: "vote.cast", Payload: payload,
+				Attempts: tt.attempts, URL: srv.URL + "/in", Secret: "whsec_test",
+			}
+			repo := new(mocks.WebhookRepositoryMock)
+			repo.On("ClaimDue", mock.Anything, testConf().BatchSize, mock.AnythingOfType("time.Duration")).
+				Return([]models.WebhookDelivery{delivery}, nil)
+			tt.setupMocks(repo)
+
+			d := NewDispatcher(repo, NewClient(time.Second, true), testConf())
+			d.now = func() time.Time { return now }
+
+			n, err := d.Dispatch(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, 1, n)
+			repo.AssertExpectations(t)
+
+			require.NotNil(t, got)
+			assert.Equal(t, http.MethodPost, got.Method)
+			assert.Equal(t, "/in", got.URL.Path)
+			assert.Equal(t, payload, body)
+			assert.Equal(t, "vote.cast", got.Header.Get(HeaderEvent))
+			assert.Equal(t, "evt_1", got.Header.Get(HeaderEventID))
+			assert.Equal(t, "11", got.Header.Get(HeaderDelivery))
+			assert.Equal(t, strconv.FormatInt(now.Unix(), 10), got.Header.Get(HeaderTimestamp))
+			assert.NoError(t, Verify("whsec_test", got.Header.Get(HeaderTimestamp), got.Header.Get(HeaderSignature), body, time.Minute, now))
+		})
+	}
+}
+
+func TestDispatcher_DispatchUnreachable(t *testing.T) {
+	repo := new(mocks.WebhookRepositoryMock)
+	repo.On("ClaimDue", mock.Anything, testConf().BatchSize, mock.AnythingOfType("time.Duration")).
+		Return([]models.WebhookDelivery{{ID: 1, WebhookID: 2, URL: "http://127.0.0.1:1/in", Secret: "whsec_test"}}, nil)
+	repo.On("RecordFailure", mock.Anything, mock.Anything, mock.MatchedBy(func(a *models.WebhookAttempt) bool {
+		return a.StatusCode == nil && a.Error != ""
+	}), mock.AnythingOfType("*time.Time"), testConf().DisableAfter).Return(false, nil)
+
+	d := NewDispatcher(repo, NewClient(time.Second, false), testConf())
+	n, err := d.Dispatch(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	repo.AssertExpectations(t)
+}
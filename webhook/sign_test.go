@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	ts := time.Unix(1761469200, 0)
+	body := []byte(`{"id":"evt_1"}`)
+
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte("1761469200." + string(body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	assert.Equal(t, want, Sign("whsec_test", ts, body))
+	assert.NotEqual(t, want, Sign("whsec_other", ts, body))
+	assert.NotEqual(t, want, Sign("whsec_test", ts.Add(time.Second), body))
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1761469200, 0)
+	body := []byte(`{"id":"evt_1"}`)
+	sig := Sign("whsec_test", now, body)
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      []byte
+		now       time.Time
+		wantErr   bool
+	}{
+		{name: "valid", secret: "whsec_test", timestamp: ts, signature: sig, body: body, now: now},
+		{name: "valid within tolerance", secret: "whsec_test", timestamp: ts, signature: sig, body: body, now: now.Add(4 * time.Minute)},
+		{name: "wrong secret", secret: "whsec_other", timestamp: ts, signature: sig, body: body, now: now, wantErr: true},
+		{name: "tampered body", secret: "whsec_test", timestamp: ts, signature: sig, body: []byte(`{"id":"evt_2"}`), now: now, wantErr: true},
+		{name: "replayed", secret: "whsec_test", timestamp: ts, signature: sig, body: body, now: now.Add(6 * time.Minute), wantErr: true},
+		{name: "bad timestamp", secret: "whsec_test", timestamp: "soon", signature: sig, body: body, now: now, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.timestamp, tt.signature, tt.body, 5*time.Minute, tt.now)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSignature)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := NewSecret()
+	require.NoError(t, err)
+	b, err := NewSecret()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(a, "whsec_"))
+	assert.Len(t, a, len("whsec_")+64)
+	assert.NotEqual(t, a, b)
+}